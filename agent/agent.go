@@ -1,17 +1,24 @@
 package agent
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,40 +26,190 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/ssh"
 
 	"github.com/sapslaj/mid/agent/rpc"
-	"github.com/sapslaj/mid/pkg/cast"
+	"github.com/sapslaj/mid/pkg/env"
 	"github.com/sapslaj/mid/pkg/syncmap"
 	"github.com/sapslaj/mid/pkg/telemetry"
 	"github.com/sapslaj/mid/version"
 )
 
 var (
-	ErrRunningRemoteCommand   = errors.New("error running remote command")
-	ErrInstallingAgent        = errors.New("error installing agent")
-	ErrConnectingToAgent      = errors.New("error connecting to agent")
-	ErrAgentShutDown          = errors.New("agent shut down")
-	ErrDisconnectingFromAgent = errors.New("error disconnecting from agent")
-	ErrCallingRPCSystem       = errors.New("error calling RPC system")
-	ErrStagingFile            = errors.New("error staging file")
+	ErrRunningRemoteCommand    = errors.New("error running remote command")
+	ErrInstallingAgent         = errors.New("error installing agent")
+	ErrConnectingToAgent       = errors.New("error connecting to agent")
+	ErrAgentShutDown           = errors.New("agent shut down")
+	ErrConnectionLost          = errors.New("connection to agent lost")
+	ErrCallNotSent             = errors.New("call was not sent to agent")
+	ErrDisconnectingFromAgent  = errors.New("error disconnecting from agent")
+	ErrCallingRPCSystem        = errors.New("error calling RPC system")
+	ErrStagingFile             = errors.New("error staging file")
+	ErrUnsupportedByAgent      = errors.New("unsupported by remote agent")
+	ErrWritingFile             = errors.New("error writing file")
+	ErrReadingFile             = errors.New("error reading file")
+	ErrUnsupportedArch         = errors.New("unsupported architecture")
+	ErrAgentChecksumMismatch   = errors.New("agent binary checksum mismatch")
+	ErrUninstallingAgent       = errors.New("error uninstalling agent")
+	ErrNotAgentDir             = errors.New("directory does not contain an agent")
+	ErrUnknownBecomeMethod     = errors.New("unknown become method")
+	ErrBecoming                = errors.New("error becoming user")
+	ErrBecomePasswordIncorrect = errors.New("incorrect become password")
 )
 
+// DefaultHeartbeatInterval is how often the agent is pinged if
+// Agent.HeartbeatInterval isn't set.
+const DefaultHeartbeatInterval = time.Minute
+
+// FileChunkSize is how much of a file is sent in each WriteFile call.
+const FileChunkSize = 1 << 20
+
+// legacyRPCFunctions are the RPC functions supported by agents that predate
+// capability negotiation.
+var legacyRPCFunctions = []rpc.RPCFunction{
+	rpc.RPCAgentPing,
+	rpc.RPCAnsibleExecute,
+	rpc.RPCClose,
+	rpc.RPCExec,
+	rpc.RPCFileStat,
+	rpc.RPCSystemdUnitShortStatus,
+	rpc.RPCUntar,
+}
+
+// MachineGOARCH maps the machine hardware names reported by `uname -m` to the
+// GOARCH of the agent binary that runs on them.
+var MachineGOARCH = map[string]string{
+	"x86_64":  "amd64",
+	"amd64":   "amd64",
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+	// the arm agent is built for ARMv6, which ARMv7 and 32-bit ARMv8 run too.
+	"armv6l":  "arm",
+	"armv7l":  "arm",
+	"armv8l":  "arm",
+	"riscv64": "riscv64",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+// MachineGOARCHFor returns the GOARCH for a `uname -m` machine name.
+func MachineGOARCHFor(machine string) (string, error) {
+	goarch, ok := MachineGOARCH[machine]
+	if !ok {
+		supported := slices.Sorted(maps.Keys(MachineGOARCH))
+		return "", fmt.Errorf(
+			"%w %q, supported architectures are: %s",
+			ErrUnsupportedArch,
+			machine,
+			strings.Join(supported, ", "),
+		)
+	}
+	return goarch, nil
+}
+
 var Tracer = otel.Tracer("mid/agent")
 
+// inFlightResult is what an in-flight call receives: either the result sent by
+// the agent or, if the call ended without one, the reason why.
+type inFlightResult struct {
+	res rpc.RPCResult[any]
+	err error
+}
+
 type Agent struct {
 	RemotePid    atomic.Int64
 	InstanceUUID string
+	// HeartbeatInterval is how often the agent is pinged once connected. Zero
+	// means DefaultHeartbeatInterval.
+	HeartbeatInterval time.Duration
+	// HeartbeatTimeout is passed to the agent at startup as the time it waits
+	// without a call before shutting down. Zero leaves the agent's default in
+	// place and a negative value disables the timeout.
+	HeartbeatTimeout time.Duration
+	// RemoteInfo is the result of the initial ping made by Connect. It reports
+	// the version, protocol version, and supported RPC functions of the agent.
+	RemoteInfo   rpc.AgentPingResult
 	ConnectMutex sync.Mutex
 	EncoderMutex sync.Mutex
 	Client       *ssh.Client
 	Session      *ssh.Session
-	Encoder      *json.Encoder
-	Decoder      *json.Decoder
+	Encoder      *rpc.Encoder
+	Decoder      *rpc.Decoder
+	Stdin        io.Writer
+	Stdout       io.Reader
 	Running      atomic.Bool
-	WaitGroup    sync.WaitGroup
-	InFlight     syncmap.Map[string, chan rpc.RPCResult[any]]
+	// Lost is set when the agent stopped because the connection to it dropped
+	// rather than because Disconnect was called.
+	Lost      atomic.Bool
+	WaitGroup sync.WaitGroup
+	InFlight  syncmap.Map[string, chan inFlightResult]
+	Streams   syncmap.Map[string, rpc.StreamFunc]
+	// CallContexts holds the context of every in-flight call so that log
+	// records forwarded from the agent can be attributed to the call that
+	// produced them.
+	CallContexts syncmap.Map[string, context.Context]
+	// OnRemoteLog, if set, is called with the context of the call that produced
+	// a log record forwarded from the agent, after the record has been logged.
+	// Records that can't be attributed to an in-flight call are only logged.
+	OnRemoteLog func(ctx context.Context, record rpc.RPCLogRecord)
+	// Dir is the directory the agent is installed in on the remote host.
+	// Relative paths are relative to the home directory of the SSH user. Empty
+	// means rpc.DefaultAgentDir.
+	Dir string
+	// GCAfter is passed to the agent at startup as how long other agent
+	// versions installed in Dir can go unused before the agent removes them.
+	// Zero or negative leaves them alone.
+	GCAfter time.Duration
+	// Become is how the agent is run as root, or another user.
+	Become Become
+	// OnStatus, if set, is called with a short message while Connect is held
+	// up, e.g. waiting for another install of the agent to finish. An empty
+	// message means it is no longer held up.
+	OnStatus func(ctx context.Context, msg string)
+}
+
+// status reports msg through OnStatus, if it is set.
+func (agent *Agent) status(ctx context.Context, msg string) {
+	if agent.OnStatus != nil {
+		agent.OnStatus(ctx, msg)
+	}
+}
+
+// AgentDir returns the cleaned agent directory. Connect refuses to use an
+// invalid directory, so this falls back to rpc.DefaultAgentDir only for agents
+// that were never connected.
+func (agent *Agent) AgentDir() string {
+	dir, err := rpc.CleanAgentDir(agent.Dir)
+	if err != nil {
+		return rpc.DefaultAgentDir
+	}
+	return dir
+}
+
+// InstallDir returns the directory this version of the agent is installed in
+// within the agent directory.
+func (agent *Agent) InstallDir() string {
+	return rpc.AgentVersionDir(agent.AgentDir(), version.Version)
+}
+
+// installPath joins elem onto the install directory.
+func (agent *Agent) installPath(elem ...string) string {
+	return path.Join(append([]string{agent.InstallDir()}, elem...)...)
+}
+
+// shellQuote quotes s for use as a single word in a POSIX shell command.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shCommand wraps script in a /bin/sh invocation so that it doesn't depend on
+// the login shell of the SSH user.
+func shCommand(script string) string {
+	return "/bin/sh -c " + shellQuote(script)
 }
 
 func (agent *Agent) EnsureUUID() (string, error) {
@@ -66,6 +223,34 @@ func (agent *Agent) EnsureUUID() (string, error) {
 	return agent.InstanceUUID, nil
 }
 
+// Supports reports whether the remote agent advertised support for the given
+// RPC function. Agents that predate capability negotiation don't advertise
+// anything, so they are assumed to support the functions that existed at the
+// time.
+func (agent *Agent) Supports(rpcFunction rpc.RPCFunction) bool {
+	if agent.RemoteInfo.ProtocolVersion == 0 {
+		return slices.Contains(legacyRPCFunctions, rpcFunction)
+	}
+	return slices.Contains(agent.RemoteInfo.RPCFunctions, rpcFunction)
+}
+
+// UnsupportedError returns an error explaining that the remote agent does not
+// support the given RPC function.
+func (agent *Agent) UnsupportedError(rpcFunction rpc.RPCFunction) error {
+	agentVersion := agent.RemoteInfo.AgentVersion
+	if agentVersion == "" {
+		agentVersion = "unknown"
+	}
+	return fmt.Errorf(
+		"%w: %s (agent version %s, protocol version %d, provider protocol version %d)",
+		ErrUnsupportedByAgent,
+		rpcFunction,
+		agentVersion,
+		agent.RemoteInfo.ProtocolVersion,
+		rpc.ProtocolVersion,
+	)
+}
+
 func (agent *Agent) GetLogger(ctx context.Context) *slog.Logger {
 	logger := telemetry.LoggerFromContext(ctx).With(slog.String("side", "local"))
 	if agent.InstanceUUID != "" {
@@ -89,17 +274,16 @@ func (agent *Agent) RunLocal() {
 		logger := agent.GetLogger(ctx)
 		logger.Debug("waiting for next result")
 
-		var res rpc.RPCResult[any]
-		err := agent.Decoder.Decode(&res)
+		res, err := agent.Decoder.DecodeResult()
 		if err != nil {
 			if !agent.Running.Load() {
 				logger.Debug("got error result from decode but agent should not be running")
 				return
 			}
 
-			if errors.Is(err, io.EOF) {
-				logger.Debug("got EOF from decode stream")
-				agent.Disconnect(context.Background(), false)
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				logger.Warn("got EOF from decode stream, connection to agent lost")
+				agent.connectionLost(ctx)
 				return
 			}
 
@@ -117,6 +301,31 @@ func (agent *Agent) RunLocal() {
 			}
 		}
 
+		if res.RPCFunction == rpc.RPCSetEncoding && res.Error == "" {
+			// the agent switches encodings right after sending this result, so the
+			// decoder has to be switched before reading anything else.
+			agent.switchDecoder(ctx, &res)
+		}
+
+		if res.Spans != nil {
+			agent.handleSpans(ctx, res)
+			continue
+		}
+
+		if res.Log != nil {
+			// log records are handled synchronously so that they stay in order and
+			// are delivered while the call that produced them is still in flight.
+			agent.handleLog(ctx, res)
+			continue
+		}
+
+		if res.Chunk != nil {
+			// stream chunks are handled synchronously so that they stay in order
+			// and are all delivered before the final result for the call.
+			agent.handleChunk(ctx, res)
+			continue
+		}
+
 		logger.Debug("result appears valid, handling")
 
 		go func(res rpc.RPCResult[any]) {
@@ -153,7 +362,7 @@ func (agent *Agent) RunLocal() {
 
 				resultLogger.Debug("channeling result")
 				select {
-				case ch <- res:
+				case ch <- inFlightResult{res: res}:
 					resultLogger.Debug("result channeled")
 					return
 				case <-time.After(time.Second):
@@ -181,8 +390,142 @@ func (agent *Agent) RunLocal() {
 	}
 }
 
+func (agent *Agent) switchDecoder(ctx context.Context, res *rpc.RPCResult[any]) {
+	logger := agent.GetLogger(ctx)
+
+	setEncodingResult, err := rpc.Decode[rpc.SetEncodingResult](res.Result)
+	if err == nil {
+		var decoder *rpc.Decoder
+		decoder, err = agent.Decoder.Switch(setEncodingResult.Encoding, agent.Stdout)
+		if err == nil {
+			logger.Info("switched decoder encoding", slog.String("encoding", string(decoder.Encoding())))
+			agent.Decoder = decoder
+			return
+		}
+	}
+
+	logger.Error("error switching decoder encoding", slog.Any("error", err))
+	res.Error = errors.Join(ErrCallingRPCSystem, err).Error()
+}
+
+func (agent *Agent) handleChunk(ctx context.Context, res rpc.RPCResult[any]) {
+	logger := agent.GetLogger(ctx).With(
+		slog.Any("name", res.RPCFunction),
+		slog.String("rpc.uuid", res.UUID),
+	)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("caught panic handling stream chunk", slog.Any("error", r))
+		}
+	}()
+
+	stream, loaded := agent.Streams.Load(res.UUID)
+	if !loaded || stream == nil {
+		logger.Debug("no stream handler for chunk, dropping")
+		return
+	}
+
+	stream(*res.Chunk)
+}
+
+// handleLog re-emits a log record forwarded from the agent through the logger of
+// the call that produced it.
+func (agent *Agent) handleLog(ctx context.Context, res rpc.RPCResult[any]) {
+	attributed := false
+	if res.UUID != "" {
+		callCtx, loaded := agent.CallContexts.Load(res.UUID)
+		if loaded && callCtx != nil {
+			ctx = callCtx
+			attributed = true
+		}
+	}
+
+	logger := telemetry.LoggerFromContext(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("caught panic handling agent log record", slog.Any("error", r))
+		}
+	}()
+
+	record := *res.Log
+	attrs := make([]slog.Attr, 0, len(record.Attrs)+1)
+	if record.Source != "" {
+		attrs = append(attrs, slog.String("agent.remote.source", record.Source))
+	}
+	for _, attr := range record.Attrs {
+		attrs = append(attrs, slog.String(attr.Key, attr.Value))
+	}
+	logger.LogAttrs(ctx, record.Level, record.Message, attrs...)
+
+	if attributed && agent.OnRemoteLog != nil {
+		agent.OnRemoteLog(ctx, record)
+	}
+}
+
+// handleSpans exports spans recorded by the agent. They are attributed to a
+// separate "mid-agent" service so that it's clear which side of the connection
+// they come from.
+func (agent *Agent) handleSpans(ctx context.Context, res rpc.RPCResult[any]) {
+	logger := agent.GetLogger(ctx)
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("caught panic handling agent spans", slog.Any("error", r))
+		}
+	}()
+
+	agentResource := resource.NewSchemaless(
+		attribute.String("service.name", "mid-agent"),
+		attribute.String("library.language", "go"),
+		attribute.String("agent.instance.uuid", agent.InstanceUUID),
+		attribute.Int64("agent.remote.pid", agent.RemotePid.Load()),
+		attribute.String("agent.remote.version", agent.RemoteInfo.AgentVersion),
+	)
+	spans := make([]sdktrace.ReadOnlySpan, 0, len(res.Spans))
+	for _, span := range res.Spans {
+		readOnlySpan, ok := span.ReadOnlySpan(agentResource)
+		if !ok {
+			logger.Warn("dropping agent span with invalid IDs", slog.String("span.name", span.Name))
+			continue
+		}
+		spans = append(spans, readOnlySpan)
+	}
+	logger.Debug("exporting agent spans", slog.Int("count", len(spans)))
+	telemetry.ExportSpans(spans...)
+}
+
+// logStderr logs anything the agent writes to stderr. With log forwarding
+// enabled that is only output that couldn't be forwarded, such as panics or
+// output from agents that don't support forwarding.
+func (agent *Agent) logStderr(ctx context.Context, stderr io.Reader) {
+	scanner := bufio.NewScanner(stderr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		agent.GetLogger(ctx).Warn("agent stderr", slog.String("line", scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		agent.GetLogger(ctx).Warn("error reading agent stderr", slog.Any("error", err))
+	}
+}
+
 func (agent *Agent) Disconnect(ctx context.Context, wait bool) error {
-	ctx, span := Tracer.Start(ctx, "mid/agent.Agent.Disconnect")
+	return agent.disconnect(ctx, wait, ErrAgentShutDown)
+}
+
+// connectionLost shuts down an agent whose connection has dropped. In-flight
+// calls fail with ErrConnectionLost so that callers can tell this apart from
+// an intentional shutdown.
+func (agent *Agent) connectionLost(ctx context.Context) error {
+	agent.Lost.Store(true)
+	return agent.disconnect(ctx, false, ErrConnectionLost)
+}
+
+func (agent *Agent) disconnect(ctx context.Context, wait bool, reason error) error {
+	ctx, span := Tracer.Start(ctx, "mid/agent.Agent.Disconnect", trace.WithAttributes(
+		attribute.String("agent.disconnect.reason", reason.Error()),
+	))
 	defer span.End()
 
 	alreadyStopped := !agent.Running.Load()
@@ -193,39 +536,38 @@ func (agent *Agent) Disconnect(ctx context.Context, wait bool) error {
 
 	for uuid, ch := range agent.InFlight.Items() {
 		if wait {
-			func(ctx context.Context, uuid string, ch chan rpc.RPCResult[any]) {
+			func(ctx context.Context, uuid string, ch chan inFlightResult) {
 				defer func() { recover() }()
 				select {
-				case ch <- rpc.RPCResult[any]{
-					UUID:  uuid,
-					Error: ErrAgentShutDown.Error(),
-				}:
+				case ch <- inFlightResult{err: reason}:
 				case <-ctx.Done():
+				case <-time.After(time.Second):
 				}
 			}(ctx, uuid, ch)
 		} else {
-			go func(uuid string, ch chan rpc.RPCResult[any]) {
+			go func(uuid string, ch chan inFlightResult) {
 				defer func() { recover() }()
 				select {
-				case ch <- rpc.RPCResult[any]{
-					UUID:  uuid,
-					Error: ErrAgentShutDown.Error(),
-				}:
+				case ch <- inFlightResult{err: reason}:
 				case <-time.After(time.Second):
 				}
 			}(uuid, ch)
 		}
 	}
 
-	err = errors.Join(
-		err,
-		agent.Session.Close(),
-	)
+	if agent.Session != nil {
+		err = errors.Join(
+			err,
+			agent.Session.Close(),
+		)
+	}
 
-	err = errors.Join(
-		err,
-		agent.Client.Close(),
-	)
+	if agent.Client != nil {
+		err = errors.Join(
+			err,
+			agent.Client.Close(),
+		)
+	}
 
 	if wait {
 		wg := make(chan int)
@@ -281,6 +623,136 @@ func (agent *Agent) Ping(ctx context.Context) (rpc.AgentPingResult, error) {
 	return pingResult.Result, nil
 }
 
+// Cancel asks the remote agent to cancel the in-flight call with the given
+// UUID, killing any processes it started.
+func (agent *Agent) Cancel(ctx context.Context, uuid string) (rpc.CancelResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.Agent.Cancel", trace.WithAttributes(
+		attribute.String("rpc.cancel.uuid", uuid),
+	))
+	defer span.End()
+
+	cancelResult, err := Call[rpc.CancelArgs, rpc.CancelResult](ctx, agent, rpc.RPCCall[rpc.CancelArgs]{
+		RPCFunction: rpc.RPCCancel,
+		Args: rpc.CancelArgs{
+			UUID: uuid,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("error sending cancel RPC: %w", err)
+		span.SetStatus(codes.Error, err.Error())
+		return cancelResult.Result, err
+	}
+
+	if cancelResult.Error != "" {
+		err = fmt.Errorf("error received from cancel RPC: %w", errors.New(cancelResult.Error))
+		span.SetStatus(codes.Error, err.Error())
+		return cancelResult.Result, err
+	}
+
+	span.SetAttributes(attribute.Bool("rpc.cancel.cancelled", cancelResult.Result.Cancelled))
+	span.SetStatus(codes.Ok, "")
+	return cancelResult.Result, nil
+}
+
+// SetEncoding switches the channel to the agent to another encoding. The
+// decoder is switched by RunLocal as soon as the result comes in, and the
+// encoder once this call returns, so nothing else may be calling the agent at
+// the same time.
+func (agent *Agent) SetEncoding(ctx context.Context, encoding rpc.Encoding) error {
+	ctx, span := Tracer.Start(ctx, "mid/agent.Agent.SetEncoding", trace.WithAttributes(
+		attribute.String("rpc.encoding", string(encoding)),
+	))
+	defer span.End()
+
+	encoder, err := rpc.NewEncoder(encoding, agent.Stdin)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	setEncodingResult, err := Call[rpc.SetEncodingArgs, rpc.SetEncodingResult](
+		ctx,
+		agent,
+		rpc.RPCCall[rpc.SetEncodingArgs]{
+			RPCFunction: rpc.RPCSetEncoding,
+			Args: rpc.SetEncodingArgs{
+				Encoding: encoding,
+			},
+		},
+	)
+	if err != nil {
+		err = fmt.Errorf("error sending set encoding RPC: %w", err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	if setEncodingResult.Error != "" {
+		err = fmt.Errorf("error received from set encoding RPC: %w", errors.New(setEncodingResult.Error))
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	agent.EncoderMutex.Lock()
+	agent.Encoder = encoder
+	agent.EncoderMutex.Unlock()
+
+	span.SetStatus(codes.Ok, "")
+	return nil
+}
+
+// cancelRemote is called when the context for a call is done before its result
+// arrived. It cancels the call on the remote side and then waits a short time
+// for the final result of the call so that it isn't dropped on the floor. The
+// error string of that final result is returned, if any.
+func (agent *Agent) cancelRemote(
+	ctx context.Context,
+	uuid string,
+	rpcFunction rpc.RPCFunction,
+	ch chan inFlightResult,
+) string {
+	switch rpcFunction {
+	case rpc.RPCCancel, rpc.RPCClose, rpc.RPCAgentPing:
+		return ""
+	}
+
+	if !agent.Running.Load() || !agent.Supports(rpc.RPCCancel) {
+		return ""
+	}
+
+	logger := agent.GetLogger(ctx).With(
+		slog.String("rpc.function", string(rpcFunction)),
+		slog.String("rpc.uuid", uuid),
+	)
+
+	timeout := 10 * time.Second
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	logger.InfoContext(ctx, "cancelling remote call")
+	cancelResult, err := agent.Cancel(cancelCtx, uuid)
+	if err != nil {
+		logger.ErrorContext(ctx, "error cancelling remote call", slog.Any("error", err))
+		return ""
+	}
+	if !cancelResult.Cancelled {
+		logger.WarnContext(ctx, "remote call was not running")
+		return ""
+	}
+
+	select {
+	case received := <-ch:
+		if received.err != nil {
+			logger.DebugContext(ctx, "cancelled call ended without a result", slog.Any("error", received.err))
+			return received.err.Error()
+		}
+		logger.DebugContext(ctx, "got final result for cancelled call", slog.String("error", received.res.Error))
+		return received.res.Error
+	case <-cancelCtx.Done():
+		logger.WarnContext(ctx, "timed out waiting for cancelled call to finish")
+		return ""
+	}
+}
+
 func (agent *Agent) Heartbeat(timeout time.Duration) (time.Duration, bool) {
 	ctx, span := Tracer.Start(context.Background(), "mid/agent.Agent.Heartbeat")
 	defer span.End()
@@ -313,7 +785,7 @@ func (agent *Agent) Heartbeat(timeout time.Duration) (time.Duration, bool) {
 
 	if err != nil {
 		logger.ErrorContext(ctx, "failed heartbeat")
-		err := agent.Disconnect(ctx, false)
+		err := agent.connectionLost(ctx)
 		if err != nil {
 			logger.ErrorContext(ctx, "error stopping", slog.Any("error", err))
 		}
@@ -327,8 +799,10 @@ func (agent *Agent) Heartbeat(timeout time.Duration) (time.Duration, bool) {
 }
 
 func (agent *Agent) RunHeartbeat() {
-	// TODO: make heartbeat interval configurable
-	interval := time.Minute
+	interval := agent.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
 
 	for {
 		if !agent.Running.Load() {
@@ -380,228 +854,647 @@ func RunRemoteCommand(ctx context.Context, agent *Agent, cmd string) ([]byte, er
 	return b, nil
 }
 
-func InstallAgent(ctx context.Context, agent *Agent) error {
-	ctx, span := Tracer.Start(ctx, "mid/agent.InstallAgent")
-	defer span.End()
+// InstallLockStaleAfter is how long an install lock can be held before it is
+// considered abandoned and broken. Installing the agent takes seconds, so this
+// is only reached if whoever held the lock went away without releasing it.
+const InstallLockStaleAfter = 10 * time.Minute
+
+// installLockPollInterval is how often a held install lock is checked again.
+const installLockPollInterval = 2 * time.Second
+
+// InstallLockOwner identifies who holds the install lock of an agent version.
+type InstallLockOwner struct {
+	// Host is the host name of the machine running the provider.
+	Host string
+	// PID is the process ID of the provider on Host.
+	PID int
+	// InstanceUUID is the instance UUID of the agent being installed.
+	InstanceUUID string
+	// Acquired is when the lock was taken, according to the remote host.
+	Acquired time.Time
+}
 
-	initOutput, err := RunRemoteCommand(ctx, agent, "touch .mid/install.lock && uname -m")
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		return err
+func (owner InstallLockOwner) String() string {
+	if owner.InstanceUUID == "" {
+		return "an unknown owner"
 	}
-	defer RunRemoteCommand(ctx, agent, "rm -f .mid/install.lock")
+	return fmt.Sprintf("pid %d on %s (agent instance %s)", owner.PID, owner.Host, owner.InstanceUUID)
+}
 
-	// only support linux for now
-	goos := "linux"
-	goarch := ""
-	switch strings.TrimSpace(string(initOutput)) {
-	case "aarch64":
-		goarch = "arm64"
-	case "x86_64":
-		goarch = "amd64"
-	default:
-		err = fmt.Errorf("%w: unexpected output while initializing agent: %q", ErrInstallingAgent, string(initOutput))
-		span.SetStatus(codes.Error, err.Error())
-		return err
+// parseInstallLockOwner parses the owner file of an install lock, which is a
+// single line of "<unix time> <pid> <instance UUID> <host>".
+func parseInstallLockOwner(line string) (InstallLockOwner, bool) {
+	fields := strings.Fields(line)
+	if len(fields) != 4 {
+		return InstallLockOwner{}, false
 	}
-
-	span.SetAttributes(
-		attribute.String("goos", goos),
-		attribute.String("goarch", goarch),
-	)
-
-	agentBinary, err := GetAgentBinary(goos, goarch)
+	acquired, err := strconv.ParseInt(fields[0], 10, 64)
 	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
+		return InstallLockOwner{}, false
 	}
-
-	sftpClient, err := sftp.NewClient(agent.Client)
+	pid, err := strconv.Atoi(fields[1])
 	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
+		return InstallLockOwner{}, false
 	}
-	defer sftpClient.Close()
+	return InstallLockOwner{
+		Host:         fields[3],
+		PID:          pid,
+		InstanceUUID: fields[2],
+		Acquired:     time.Unix(acquired, 0),
+	}, true
+}
 
-	uuid, err := uuid.NewRandom()
+// localProcessGone reports whether there is no process with the given PID on
+// this machine.
+func localProcessGone(pid int) bool {
+	proc, err := os.FindProcess(pid)
 	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
+		return true
 	}
+	return errors.Is(proc.Signal(syscall.Signal(0)), os.ErrProcessDone)
+}
 
-	file := ".mid/mid-agent." + uuid.String()
-
-	dest, err := sftpClient.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
-	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
-	}
-	defer dest.Close()
+// installLockCheck is what is known about a held install lock each time
+// acquireInstallLock finds it taken.
+type installLockCheck struct {
+	// OwnerLine is the content of the owner file of the lock, if any.
+	OwnerLine string
+	// RemoteNow is the current time on the remote host as a Unix timestamp.
+	RemoteNow string
+	// Now is the current time on this machine.
+	Now time.Time
+	// UnknownOwnerSince is when the lock was first seen without an owner, or
+	// zero if it wasn't.
+	UnknownOwnerSince time.Time
+	// Hostname and PID identify this provider process.
+	Hostname string
+	PID      int
+	// ProcessGone reports whether there is no process with the given PID on
+	// this machine.
+	ProcessGone func(pid int) bool
+}
 
-	_, err = io.Copy(dest, bytes.NewReader(agentBinary))
-	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
-	}
+// installLockState is the outcome of checkInstallLock.
+type installLockState struct {
+	Owner             InstallLockOwner
+	Held              time.Duration
+	UnknownOwnerSince time.Time
+	// StaleReason says why the lock should be broken, or is empty if it
+	// shouldn't.
+	StaleReason string
+}
 
-	err = sftpClient.PosixRename(file, ".mid/mid-agent")
-	if err != nil {
-		err = errors.Join(ErrInstallingAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
+// checkInstallLock decides whether a held install lock is stale. A lock is
+// stale if it has been held for longer than InstallLockStaleAfter, going by
+// the clock of the remote host, or if its owner is a provider process on this
+// machine that no longer exists. The owner file is written right after the
+// lock is taken, so a lock without one is either just being taken or its
+// owner died in between; it is timed from when it was first seen.
+func checkInstallLock(check installLockCheck) installLockState {
+	state := installLockState{}
+	owner, ownerKnown := parseInstallLockOwner(check.OwnerLine)
+	if ownerKnown {
+		state.Owner = owner
+		now, err := strconv.ParseInt(check.RemoteNow, 10, 64)
+		if err == nil {
+			state.Held = time.Unix(now, 0).Sub(owner.Acquired)
+		}
+	} else {
+		state.UnknownOwnerSince = check.UnknownOwnerSince
+		if state.UnknownOwnerSince.IsZero() {
+			state.UnknownOwnerSince = check.Now
+		}
+		state.Held = check.Now.Sub(state.UnknownOwnerSince)
 	}
 
-	err = sftpClient.Chmod(".mid/mid-agent", 0o700)
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		return err
+	if state.Held > InstallLockStaleAfter {
+		state.StaleReason = fmt.Sprintf("held for longer than %s", InstallLockStaleAfter)
+	} else if ownerKnown && owner.Host == check.Hostname && owner.PID != check.PID && check.ProcessGone(owner.PID) {
+		state.StaleReason = "owner process no longer exists"
 	}
+	return state
+}
 
-	stagingClean, err := RunRemoteCommand(ctx, agent, "/bin/sh -c 'if test -d .mid/staging; then rm -rf .mid/staging/*; fi'")
-	span.SetAttributes(
-		attribute.String("staging_clean.output", string(stagingClean)),
-	)
-	if err != nil {
-		span.SetAttributes(
-			attribute.Bool("staging_clean.success", false),
-			attribute.String("staging_clean.error", err.Error()),
-		)
-	} else {
-		span.SetAttributes(
-			attribute.Bool("staging_clean.success", true),
-		)
+// localHostname returns the host name recorded as the owner of install locks.
+func localHostname() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" || strings.ContainsAny(hostname, " \t\n") {
+		return "unknown"
 	}
-
-	return nil
+	return hostname
 }
 
-func Connect(ctx context.Context, agent *Agent) error {
-	ctx, span := Tracer.Start(ctx, "mid/agent.Connect")
+// acquireInstallLock takes the install lock of the agent version, waiting for
+// whoever holds it to finish. The lock is a directory, which mkdir creates
+// atomically, holding an owner file that says who took it and when. A lock
+// held for longer than InstallLockStaleAfter, or by a provider process on this
+// machine that no longer exists, is broken. It reports whether it had to wait
+// and returns a function that releases the lock.
+func acquireInstallLock(ctx context.Context, agent *Agent) (func(), bool, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.acquireInstallLock")
 	defer span.End()
 
-	agent.ConnectMutex.Lock()
-	defer agent.ConnectMutex.Unlock()
-
-	_, err := agent.EnsureUUID()
+	instanceUUID, err := agent.EnsureUUID()
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, err)
 		span.SetStatus(codes.Error, err.Error())
-		return err
+		return nil, false, err
 	}
 
 	logger := agent.GetLogger(ctx)
+	lock := shellQuote(agent.installPath("install.lock"))
+	hostname := localHostname()
+	ownerFields := fmt.Sprintf("%d %s %s", os.Getpid(), instanceUUID, hostname)
+	span.SetAttributes(attribute.String("install_lock.owner", ownerFields))
+
+	acquire := shCommand(fmt.Sprintf(
+		"mkdir -p %[1]s || exit 1; now=$(date +%%s); "+
+			"if mkdir %[2]s 2>/dev/null; then echo \"$now\" %[3]s > %[2]s/owner; echo acquired; else echo held; fi; "+
+			"echo \"$now\"; cat %[2]s/owner 2>/dev/null; exit 0",
+		shellQuote(agent.InstallDir()),
+		lock,
+		shellQuote(ownerFields),
+	))
 
-	if agent.Running.Load() {
-		logger.Warn("Connect called for already running agent")
-		return nil
+	release := func() {
+		// only remove the lock if it is still ours, it might have been broken as
+		// stale and taken by someone else in the meantime.
+		_, err := RunRemoteCommand(context.WithoutCancel(ctx), agent, shCommand(fmt.Sprintf(
+			"if grep -qF -- %[2]s %[1]s/owner 2>/dev/null; then rm -rf %[1]s; fi",
+			lock,
+			shellQuote(instanceUUID),
+		)))
+		if err != nil {
+			logger.Warn("error releasing agent install lock", slog.Any("error", err))
+		}
 	}
 
-	logger.Info("connecting agent")
-	initOutput, err := RunRemoteCommand(ctx, agent, "mkdir -p .mid")
-	if err != nil {
-		logger.Error(
-			"error creating .mid directory on remote",
-			slog.Any("error", err),
-			slog.String("stdout", string(initOutput)),
-		)
-		err = errors.Join(ErrConnectingToAgent, err)
-		span.SetStatus(codes.Error, err.Error())
-		return err
-	}
+	waited := false
+	lastOwner := ""
+	var unknownOwnerSince time.Time
+	for {
+		output, err := RunRemoteCommand(ctx, agent, acquire)
+		if err != nil {
+			err = fmt.Errorf("error acquiring install lock: %w: %s", err, strings.TrimSpace(string(output)))
+			span.SetStatus(codes.Error, err.Error())
+			return nil, waited, err
+		}
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		if lines[0] == "acquired" {
+			if waited {
+				agent.status(ctx, "")
+			}
+			span.SetAttributes(attribute.Bool("install_lock.waited", waited))
+			span.SetStatus(codes.Ok, "")
+			return release, waited, nil
+		}
+		if lines[0] != "held" || len(lines) < 2 {
+			err = fmt.Errorf("error acquiring install lock: unexpected output %q", string(output))
+			span.SetStatus(codes.Error, err.Error())
+			return nil, waited, err
+		}
 
-	agentNotInstalled := true
-	agentVersionMismatch := true
+		ownerLine := ""
+		if len(lines) > 2 {
+			ownerLine = strings.TrimSpace(lines[2])
+		}
+		state := checkInstallLock(installLockCheck{
+			OwnerLine:         ownerLine,
+			RemoteNow:         strings.TrimSpace(lines[1]),
+			Now:               time.Now(),
+			UnknownOwnerSince: unknownOwnerSince,
+			Hostname:          hostname,
+			PID:               os.Getpid(),
+			ProcessGone:       localProcessGone,
+		})
+		owner, held, staleReason := state.Owner, state.Held, state.StaleReason
+		unknownOwnerSince = state.UnknownOwnerSince
+
+		if staleReason != "" {
+			logger.Warn(
+				"breaking stale agent install lock",
+				slog.String("install_lock.owner", owner.String()),
+				slog.String("reason", staleReason),
+			)
+			// the lock is moved out of the way before it is removed so that it is
+			// gone at once, but only if it still has the owner we saw.
+			_, err = RunRemoteCommand(ctx, agent, shCommand(fmt.Sprintf(
+				"if test \"$(cat %[1]s/owner 2>/dev/null)\" = %[2]s && mv %[1]s %[3]s; then rm -rf %[3]s; fi",
+				lock,
+				shellQuote(ownerLine),
+				shellQuote(agent.installPath("install.lock.stale."+instanceUUID)),
+			)))
+			if err != nil {
+				logger.Warn("error breaking stale agent install lock", slog.Any("error", err))
+			} else {
+				unknownOwnerSince = time.Time{}
+				continue
+			}
+		}
 
-	for i := 0; i <= 10; i++ {
-		if i == 10 {
-			logger.Error("agent installation still in progress but should be finished by now; bailing")
-			err = errors.Join(ErrConnectingToAgent, fmt.Errorf("another agent installation is in progress"))
-			span.SetStatus(codes.Error, err.Error())
-			return err
+		msg := fmt.Sprintf(
+			"waiting for agent install by %s to finish (lock held for %s)",
+			owner,
+			held.Round(time.Second),
+		)
+		if ownerLine != lastOwner {
+			logger.Info(msg, slog.String("install_lock.owner", owner.String()))
+			lastOwner = ownerLine
+		} else {
+			logger.Debug(msg, slog.String("install_lock.owner", owner.String()))
 		}
+		agent.status(ctx, msg)
+		waited = true
 
-		// check for lock file
-		installLockOutput, lockErr := RunRemoteCommand(ctx, agent, "/bin/sh -c 'test -f .mid/install.lock ; echo $?'")
-		if lockErr == nil && strings.TrimSpace(string(installLockOutput)) == "0" {
-			// nope, installation is still going
-			logger.Info(fmt.Sprintf("agent installation in progress, waiting %d seconds", i*10))
-			time.Sleep(time.Duration(i) * 10 * time.Second)
-			continue
+		select {
+		case <-ctx.Done():
+			agent.status(ctx, "")
+			err = fmt.Errorf("error acquiring install lock: %w", ctx.Err())
+			span.SetStatus(codes.Error, err.Error())
+			return nil, waited, err
+		case <-time.After(installLockPollInterval):
 		}
-		if lockErr != nil {
-			logger.Warn(fmt.Sprintf("error checking for install lock, waiting %d seconds", i*10))
-			time.Sleep(time.Duration(i) * 10 * time.Second)
-			continue
+	}
+}
+
+// agentInstalled reports whether the agent binary of this version is installed
+// on the remote host.
+func agentInstalled(ctx context.Context, agent *Agent) bool {
+	output, err := RunRemoteCommand(ctx, agent, shellQuote(agent.installPath("mid-agent"))+" --version")
+	return err == nil && strings.Contains(string(output), fmt.Sprintf("mid-agent version %s", version.Version))
+}
+
+// agentInstallTools are the tools available on the remote host that
+// InstallAgent can use.
+type agentInstallTools struct {
+	gzip      bool
+	sha256sum bool
+}
+
+// uploadAgentBinary uploads the agent binary to file on the remote host and
+// returns the SHA-256 of what ended up there. If the host has gzip the
+// compressed binary is uploaded and decompressed remotely, otherwise it is
+// decompressed locally first. The checksum is computed with sha256sum if the
+// host has it, otherwise the file is read back over SFTP.
+func uploadAgentBinary(
+	ctx context.Context,
+	agent *Agent,
+	sftpClient *sftp.Client,
+	agentBinaryGzip []byte,
+	file string,
+	tools agentInstallTools,
+) (string, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.uploadAgentBinary", trace.WithAttributes(
+		attribute.String("file", file),
+		attribute.Bool("compressed", tools.gzip),
+	))
+	defer span.End()
+
+	var source io.Reader
+	uploadPath := file
+	if tools.gzip {
+		source = bytes.NewReader(agentBinaryGzip)
+		uploadPath = file + ".gz"
+	} else {
+		gzReader, err := gzip.NewReader(bytes.NewReader(agentBinaryGzip))
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return "", err
 		}
+		source = gzReader
+	}
 
-		break
+	dest, err := sftpClient.OpenFile(uploadPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	uploaded, err := io.Copy(dest, source)
+	closeErr := dest.Close()
+	if err == nil {
+		err = closeErr
+	}
+	span.SetAttributes(attribute.Int64("uploaded_bytes", uploaded))
+	if err != nil {
+		sftpClient.Remove(uploadPath)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
 	}
 
-	initOutput, err = RunRemoteCommand(ctx, agent, "file .mid/mid-agent && .mid/mid-agent --version")
-	agentNotInstalled = strings.Contains(string(initOutput), "No such file or directory")
-	agentVersionMismatch = !strings.Contains(string(initOutput), fmt.Sprintf("mid-agent version %s", version.Version))
+	if tools.gzip {
+		output, err := RunRemoteCommand(ctx, agent, shCommand(fmt.Sprintf(
+			"gzip -dc %[1]s > %[2]s; status=$?; rm -f %[1]s; exit $status",
+			shellQuote(uploadPath),
+			shellQuote(file),
+		)))
+		if err != nil {
+			err = fmt.Errorf("decompressing agent binary: %w: %s", err, strings.TrimSpace(string(output)))
+			span.SetStatus(codes.Error, err.Error())
+			return "", err
+		}
+	}
 
-	if err != nil || agentNotInstalled || agentVersionMismatch {
-		logger.Info("copying agent")
-		err = InstallAgent(ctx, agent)
+	var checksum string
+	if tools.sha256sum {
+		output, err := RunRemoteCommand(ctx, agent, "sha256sum "+shellQuote(file))
 		if err != nil {
-			err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error installing agent: %w", err))
+			err = fmt.Errorf("checksumming agent binary: %w: %s", err, strings.TrimSpace(string(output)))
 			span.SetStatus(codes.Error, err.Error())
-			return err
+			return "", err
+		}
+		fields := strings.Fields(string(output))
+		if len(fields) > 0 {
+			checksum = fields[0]
+		}
+	} else {
+		f, err := sftpClient.Open(file)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return "", err
 		}
+		defer f.Close()
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return "", err
+		}
+		checksum = fmt.Sprintf("%x", h.Sum(nil))
 	}
 
-	logger.Info("starting SSH session")
-	agent.Session, err = agent.Client.NewSession()
+	span.SetAttributes(attribute.String("sha256_checksum", checksum))
+	span.SetStatus(codes.Ok, "")
+	return checksum, nil
+}
+
+// InstallAgent uploads the agent binary for the remote host's architecture into
+// the install directory. It holds the install lock of the agent version while
+// doing so, and if it had to wait for the lock and the agent got installed in
+// the meantime it leaves that installation alone.
+func InstallAgent(ctx context.Context, agent *Agent) error {
+	ctx, span := Tracer.Start(ctx, "mid/agent.InstallAgent")
+	defer span.End()
+
+	releaseInstallLock, waited, err := acquireInstallLock(ctx, agent)
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent session: %w", err))
+		err = errors.Join(ErrInstallingAgent, err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
+	defer releaseInstallLock()
 
-	stderr, err := agent.Session.StderrPipe()
+	// whoever held the lock was most likely installing the same agent version.
+	if waited && agentInstalled(ctx, agent) {
+		span.SetAttributes(attribute.Bool("agent.installed_while_waiting", true))
+		span.SetStatus(codes.Ok, "")
+		return nil
+	}
+
+	// besides the architecture, find out if the host has the tools to decompress
+	// and verify the agent binary itself.
+	initOutput, err := RunRemoteCommand(
+		ctx,
+		agent,
+		shCommand("uname -m && for tool in gzip sha256sum; do if command -v $tool >/dev/null 2>&1; then echo $tool; fi; done"),
+	)
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent stderr pipe: %w", err))
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	go io.Copy(os.Stdout, stderr)
 
-	stdin, err := agent.Session.StdinPipe()
+	initFields := strings.Fields(string(initOutput))
+	machine := ""
+	if len(initFields) > 0 {
+		machine = initFields[0]
+	}
+	tools := agentInstallTools{}
+	if len(initFields) > 1 {
+		tools.gzip = slices.Contains(initFields[1:], "gzip")
+		tools.sha256sum = slices.Contains(initFields[1:], "sha256sum")
+	}
+
+	// only support linux for now
+	goos := "linux"
+	span.SetAttributes(attribute.String("machine", machine))
+	goarch, err := MachineGOARCHFor(machine)
+	if err != nil {
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	span.SetAttributes(
+		attribute.String("goos", goos),
+		attribute.String("goarch", goarch),
+		attribute.Bool("remote.gzip", tools.gzip),
+		attribute.Bool("remote.sha256sum", tools.sha256sum),
+	)
+
+	agentBinaryGzip, err := GetAgentBinary(goos, goarch)
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent stdin pipe: %w", err))
+		err = errors.Join(ErrInstallingAgent, err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	stdout, err := agent.Session.StdoutPipe()
+
+	checksum, err := GetAgentBinaryChecksum(goos, goarch)
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent stdout pipe: %w", err))
+		err = errors.Join(ErrInstallingAgent, err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	agent.Encoder = json.NewEncoder(stdin)
-	agent.Decoder = json.NewDecoder(stdout)
+	span.SetAttributes(attribute.String("agent_binary.sha256_checksum", checksum))
 
-	logger.Info("starting agent")
+	sftpClient, err := sftp.NewClient(agent.Client)
+	if err != nil {
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	defer sftpClient.Close()
+
+	uuid, err := uuid.NewRandom()
+	if err != nil {
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	file := agent.installPath("mid-agent." + uuid.String())
+
+	remoteChecksum, err := uploadAgentBinary(ctx, agent, sftpClient, agentBinaryGzip, file, tools)
+	if err == nil && remoteChecksum != checksum {
+		err = fmt.Errorf("%w: expected %s, got %s", ErrAgentChecksumMismatch, checksum, remoteChecksum)
+	}
+	if err != nil {
+		sftpClient.Remove(file)
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	err = sftpClient.Chmod(file, 0o700)
+	if err != nil {
+		sftpClient.Remove(file)
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	// the staging area is cleaned before the agent is moved into place, once
+	// it is there an agent of this version might be started and use it.
+	staging := shellQuote(agent.installPath("staging"))
+	stagingClean, err := RunRemoteCommand(ctx, agent, shCommand(fmt.Sprintf(
+		"if test -d %[1]s; then rm -rf %[1]s/*; fi",
+		staging,
+	)))
+	span.SetAttributes(
+		attribute.String("staging_clean.output", string(stagingClean)),
+	)
+	if err != nil {
+		span.SetAttributes(
+			attribute.Bool("staging_clean.success", false),
+			attribute.String("staging_clean.error", err.Error()),
+		)
+	} else {
+		span.SetAttributes(
+			attribute.Bool("staging_clean.success", true),
+		)
+	}
+
+	err = sftpClient.PosixRename(file, agent.installPath("mid-agent"))
+	if err != nil {
+		sftpClient.Remove(file)
+		err = errors.Join(ErrInstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UninstallAgent removes the agent directory from the remote host, and with it
+// every installed agent version along with their staging areas and Ansible
+// bundles. The agent must not be running. To guard against removing something
+// else, a directory that exists but doesn't contain an agent is left alone and
+// ErrNotAgentDir is returned. It reports whether there was an agent directory
+// to remove.
+func UninstallAgent(ctx context.Context, agent *Agent) (bool, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.UninstallAgent")
+	defer span.End()
+
+	agentDir, err := rpc.CleanAgentDir(agent.Dir)
+	if err != nil {
+		err = errors.Join(ErrUninstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+	span.SetAttributes(attribute.String("agent.dir", agentDir))
+
+	// the agent runs as the become user, so everything it created is owned by
+	// that user. The directory is only removed if it has an agent binary in it,
+	// so that a misconfigured agentDir doesn't take anything else with it.
+	cmd := shCommand(fmt.Sprintf(
+		"if ! test -e %[1]s; then echo absent; else found=; for f in %[2]s/*/mid-agent %[3]s; do test -f \"$f\" && found=1; done; if test -z \"$found\"; then echo not-agent; else rm -rf -- %[1]s && echo removed; fi; fi",
+		shellQuote(agentDir),
+		shellQuote(path.Join(agentDir, rpc.AgentVersionsDir)),
+		// agents before side by side versions were installed straight into the
+		// agent directory.
+		shellQuote(path.Join(agentDir, "mid-agent")),
+	))
+
+	output, err := runBecomeCommand(ctx, agent, cmd)
+	status := strings.TrimSpace(string(output))
+	span.SetAttributes(attribute.String("uninstall.status", status))
+	if err == nil && status == "not-agent" {
+		err = fmt.Errorf("%w: %s", ErrNotAgentDir, agentDir)
+	} else if err == nil && status != "absent" && status != "removed" {
+		err = fmt.Errorf("unexpected output: %q", status)
+	}
+	if err != nil {
+		err = errors.Join(ErrUninstallingAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return status == "removed", nil
+}
+
+func Connect(ctx context.Context, agent *Agent) error {
+	ctx, span := Tracer.Start(ctx, "mid/agent.Connect")
+	defer span.End()
+
+	agent.ConnectMutex.Lock()
+	defer agent.ConnectMutex.Unlock()
+
+	_, err := agent.EnsureUUID()
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	logger := agent.GetLogger(ctx)
 
-	// for some reason Ansible doesn't like Docker containers with sudo installed
-	// so have to jump through some hoops to not use sudo if we don't have to.
-	idOutput, err := RunRemoteCommand(ctx, agent, "id")
+	if agent.Running.Load() {
+		logger.Warn("Connect called for already running agent")
+		return nil
+	}
+
+	agentDir, err := rpc.CleanAgentDir(agent.Dir)
 	if err != nil {
-		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error getting UID: %w", err))
+		err = errors.Join(ErrConnectingToAgent, err)
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
-	useSudo := true
-	if strings.Contains(string(idOutput), "uid=0(") {
-		useSudo = false
+	span.SetAttributes(attribute.String("agent.dir", agentDir))
+
+	installDir := shellQuote(agent.InstallDir())
+	span.SetAttributes(attribute.String("agent.install_dir", agent.InstallDir()))
+
+	logger.Info("connecting agent", slog.String("agent.dir", agentDir))
+	// touching the install directory marks this version as in use so that it
+	// isn't garbage collected by another version before it is started.
+	initOutput, err := RunRemoteCommand(ctx, agent, fmt.Sprintf("mkdir -p %[1]s && touch %[1]s", installDir))
+	if err != nil {
+		logger.Error(
+			"error creating agent directory on remote",
+			slog.Any("error", err),
+			slog.String("stdout", string(initOutput)),
+		)
+		err = errors.Join(ErrConnectingToAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
 	}
 
+	if !agentInstalled(ctx, agent) {
+		logger.Info("copying agent")
+		err = InstallAgent(ctx, agent)
+		if err != nil {
+			err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error installing agent: %w", err))
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
+
+	logger.Info("starting SSH session")
+	agent.Session, err = agent.Client.NewSession()
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent session: %w", err))
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	stderr, err := agent.Session.StderrPipe()
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error creating agent stderr pipe: %w", err))
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	go agent.logStderr(context.WithoutCancel(ctx), stderr)
+
+	logger.Info("starting agent")
+
 	envvars := []string{}
 	for _, envvar := range os.Environ() {
 		if !strings.HasPrefix(envvar, "PULUMI_MID_") {
@@ -610,6 +1503,14 @@ func Connect(ctx context.Context, agent *Agent) error {
 		envvars = append(envvars, envvar)
 	}
 	envvars = append(envvars, "PULUMI_MID_AGENT_INSTANCE_UUID="+agent.InstanceUUID)
+	envvars = append(envvars, "PULUMI_MID_AGENT_FORWARD_LOGS=true")
+	envvars = append(envvars, rpc.AgentDirEnv+"="+agentDir)
+	if agent.GCAfter > 0 {
+		envvars = append(envvars, rpc.AgentGCAfterEnv+"="+agent.GCAfter.String())
+	}
+	if agent.HeartbeatTimeout != 0 {
+		envvars = append(envvars, "PULUMI_MID_AGENT_HEARTBEAT_TIMEOUT="+agent.HeartbeatTimeout.String())
+	}
 
 	logger.DebugContext(ctx, "passing through environment environment variables", telemetry.SlogJSON("env", envvars))
 
@@ -617,40 +1518,42 @@ func Connect(ctx context.Context, agent *Agent) error {
 	if len(envvars) > 0 {
 		sessionStartCmd += "env "
 		for _, envvar := range envvars {
-			sessionStartCmd += "'"
-			sessionStartCmd += envvar
-			sessionStartCmd += "' "
-		}
-	}
-	if useSudo {
-		sessionStartCmd += "sudo "
-		if len(envvars) > 0 {
-			sessionStartCmd += "--preserve-env="
-			for _, envvar := range envvars {
-				parts := strings.SplitN(envvar, "=", 2)
-				sessionStartCmd += parts[0]
-				sessionStartCmd += ","
-			}
+			sessionStartCmd += shellQuote(envvar)
 			sessionStartCmd += " "
 		}
 	}
-	sessionStartCmd += ".mid/mid-agent"
+	sessionStartCmd += shellQuote(agent.installPath("mid-agent"))
 
 	logger.DebugContext(ctx, "starting session", slog.String("cmd", sessionStartCmd))
 
-	// TODO: more extensible sudo configuration
-	err = agent.Session.Start(sessionStartCmd)
+	stdin, stdout, err := agent.startBecome(ctx, agent.Session, sessionStartCmd)
 	if err != nil {
 		err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error starting agent session: %w", err))
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
+	agent.Stdin = stdin
+	agent.Stdout = stdout
+	agent.Encoder, err = rpc.NewEncoder(rpc.EncodingJSON, stdin)
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	agent.Decoder, err = rpc.NewDecoder(rpc.EncodingJSON, stdout)
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 
 	agent.Running = atomic.Bool{}
 	agent.Running.Store(true)
 	agent.WaitGroup = sync.WaitGroup{}
 	agent.WaitGroup.Add(1)
-	agent.InFlight = syncmap.Map[string, chan rpc.RPCResult[any]]{}
+	agent.InFlight = syncmap.Map[string, chan inFlightResult]{}
+	agent.Streams = syncmap.Map[string, rpc.StreamFunc]{}
+	agent.CallContexts = syncmap.Map[string, context.Context]{}
 
 	go agent.RunLocal()
 
@@ -666,6 +1569,55 @@ func Connect(ctx context.Context, agent *Agent) error {
 
 	logger.Error("ping result", telemetry.SlogJSON("pingResult", pingResult))
 	agent.RemotePid.Store(int64(pingResult.Pid))
+	agent.RemoteInfo = pingResult
+	span.SetAttributes(
+		attribute.String("agent.remote.version", pingResult.AgentVersion),
+		attribute.Int("agent.remote.protocol_version", pingResult.ProtocolVersion),
+	)
+	if pingResult.AnsibleInstall != nil {
+		span.SetAttributes(
+			attribute.String("agent.remote.ansible.sha256", pingResult.AnsibleInstall.SHA256Checksum),
+			attribute.Bool("agent.remote.ansible.cached", pingResult.AnsibleInstall.Cached),
+			attribute.Float64("agent.remote.ansible.install_seconds", pingResult.AnsibleInstall.Duration.Seconds()),
+		)
+		logger.Info(
+			"remote agent installed Ansible package",
+			slog.Bool("agent.remote.ansible.cached", pingResult.AnsibleInstall.Cached),
+			slog.Duration("agent.remote.ansible.install_duration", pingResult.AnsibleInstall.Duration),
+		)
+	}
+
+	if pingResult.AgentVersion != version.Version {
+		logger.Warn(
+			"remote agent version does not match provider version",
+			slog.String("agent.remote.version", pingResult.AgentVersion),
+			slog.String("provider.version", version.Version),
+		)
+	}
+	if pingResult.ProtocolVersion != rpc.ProtocolVersion {
+		logger.Warn(
+			"remote agent protocol version does not match provider protocol version",
+			slog.Int("agent.remote.protocol_version", pingResult.ProtocolVersion),
+			slog.Int("provider.protocol_version", rpc.ProtocolVersion),
+		)
+	}
+
+	encodingName, err := env.GetDefault("PULUMI_MID_AGENT_ENCODING", string(rpc.EncodingGob))
+	if err != nil {
+		err = errors.Join(ErrConnectingToAgent, err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	encoding := rpc.Encoding(encodingName)
+	if encoding != rpc.EncodingJSON && agent.Supports(rpc.RPCSetEncoding) {
+		logger.Info("switching agent encoding", slog.String("encoding", string(encoding)))
+		err = agent.SetEncoding(ctx, encoding)
+		if err != nil {
+			err = errors.Join(ErrConnectingToAgent, fmt.Errorf("error switching agent encoding: %w", err))
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+	}
 
 	go agent.RunHeartbeat()
 
@@ -674,10 +1626,25 @@ func Connect(ctx context.Context, agent *Agent) error {
 }
 
 func Call[I any, O any](ctx context.Context, agent *Agent, call rpc.RPCCall[I]) (rpc.RPCResult[O], error) {
+	return CallStream[I, O](ctx, agent, call, nil)
+}
+
+// CallStream is the same as Call except that if stream is non-nil, the call is
+// made as a streaming call and stream is called for every chunk the agent sends
+// before the final result.
+func CallStream[I any, O any](
+	ctx context.Context,
+	agent *Agent,
+	call rpc.RPCCall[I],
+	stream rpc.StreamFunc,
+) (rpc.RPCResult[O], error) {
+	call.Stream = stream != nil
+
 	ctx, span := Tracer.Start(ctx, "mid/agent.Call", trace.WithAttributes(
 		telemetry.OtelJSON("rpc.call", call),
 		attribute.String("rpc.function", string(call.RPCFunction)),
 		telemetry.OtelJSON("rpc.args", call.Args),
+		attribute.Bool("rpc.stream", call.Stream),
 	))
 	defer span.End()
 
@@ -710,33 +1677,68 @@ func Call[I any, O any](ctx context.Context, agent *Agent, call rpc.RPCCall[I])
 	logger = logger.With(slog.String("rpc.uuid", call.UUID))
 	logger.DebugContext(ctx, "generated UUID")
 
-	logger.DebugContext(ctx, "creating result channel")
-	ch := make(chan rpc.RPCResult[any])
+	if !agent.Running.Load() && call.RPCFunction != rpc.RPCClose {
+		err = ErrAgentShutDown
+		if agent.Lost.Load() {
+			err = ErrConnectionLost
+		}
+		err = errors.Join(ErrCallNotSent, err)
+		logger.ErrorContext(ctx, "agent is not running", slog.Any("error", err))
+		span.SetStatus(codes.Error, err.Error())
+		return rpc.RPCResult[O]{
+			UUID:        call.UUID,
+			RPCFunction: call.RPCFunction,
+			Error:       err.Error(),
+		}, err
+	}
 
-	defer func() {
-		defer func() {
-			if r := recover(); r != nil {
-				logger.ErrorContext(ctx, "caught panic closing channel", slog.Any("error", r))
-			}
-		}()
-		logger.DebugContext(ctx, "closing result channel")
-		close(ch)
-	}()
+	// the channel is never closed: a result that arrives after the call gave up
+	// may still be on its way to it, and is dropped by RunLocal once nobody
+	// receives it.
+	logger.DebugContext(ctx, "creating result channel")
+	ch := make(chan inFlightResult)
 
 	logger.DebugContext(ctx, "registering result channel as in-flight")
 	agent.InFlight.Store(call.UUID, ch)
+	defer agent.InFlight.Delete(call.UUID)
+	agent.CallContexts.Store(call.UUID, ctx)
+	defer agent.CallContexts.Delete(call.UUID)
+
+	if stream != nil {
+		logger.DebugContext(ctx, "registering stream handler")
+		agent.Streams.Store(call.UUID, stream)
+		defer agent.Streams.Delete(call.UUID)
+	}
+
+	var traceContext propagation.MapCarrier
+	if span.SpanContext().IsSampled() {
+		traceContext = propagation.MapCarrier{}
+		propagation.TraceContext{}.Inject(ctx, traceContext)
+	}
 
 	logger.DebugContext(ctx, "acquiring encoder lock")
 	agent.EncoderMutex.Lock()
 
 	logger.DebugContext(ctx, "encoding call")
-	err = agent.Encoder.Encode(call)
+	err = agent.Encoder.EncodeCall(rpc.RPCCall[any]{
+		UUID:         call.UUID,
+		RPCFunction:  call.RPCFunction,
+		Args:         call.Args,
+		Stream:       call.Stream,
+		TraceContext: traceContext,
+	})
 
 	logger.DebugContext(ctx, "releasing encoder lock")
 	agent.EncoderMutex.Unlock()
 
 	if err != nil {
-		err = errors.Join(ErrCallingRPCSystem, err)
+		err = errors.Join(ErrCallingRPCSystem, ErrCallNotSent, err)
+		if call.RPCFunction != rpc.RPCClose && agent.Running.Load() {
+			// a failed write means the session is gone, there's no point waiting
+			// for the decoder to notice.
+			err = errors.Join(err, ErrConnectionLost)
+			agent.connectionLost(context.WithoutCancel(ctx))
+		}
 		logger.ErrorContext(ctx, "error encoding", slog.Any("error", err))
 		span.SetStatus(codes.Error, err.Error())
 		return rpc.RPCResult[O]{
@@ -758,23 +1760,44 @@ func Call[I any, O any](ctx context.Context, agent *Agent, call rpc.RPCCall[I])
 	logger.DebugContext(ctx, "waiting for result")
 	var rawResult rpc.RPCResult[any]
 	select {
-	case rawResult = <-ch:
+	case received := <-ch:
+		if received.err != nil {
+			// the agent stopped, or the connection to it was lost, before the
+			// result came in.
+			err := received.err
+			logger.ErrorContext(ctx, "call ended without a result", slog.Any("error", err))
+			span.SetStatus(codes.Error, err.Error())
+			return rpc.RPCResult[O]{
+				UUID:        call.UUID,
+				RPCFunction: call.RPCFunction,
+				Error:       err.Error(),
+			}, err
+		}
 		logger.DebugContext(ctx, "got result")
-		break
+		rawResult = received.res
 	case <-ctx.Done():
 		err := ctx.Err()
 		logger.ErrorContext(ctx, "timeout waiting for result", slog.Any("error", err))
 		span.SetStatus(codes.Error, err.Error())
-		return rpc.RPCResult[O]{
+		res := rpc.RPCResult[O]{
 			UUID:        call.UUID,
 			RPCFunction: call.RPCFunction,
 			Error:       err.Error(),
-		}, err
+		}
+		if remoteErr := agent.cancelRemote(ctx, call.UUID, call.RPCFunction, ch); remoteErr != "" {
+			res.Error = errors.Join(err, errors.New(remoteErr)).Error()
+		}
+		return res, err
 	}
 
 	logger.DebugContext(ctx, "casting result to final type", telemetry.SlogJSON("rpc.raw_result", rawResult))
 	span.SetAttributes(telemetry.OtelJSON("rpc.raw_result", rawResult))
-	res, err := cast.AnyToJSONT[rpc.RPCResult[O]](rawResult)
+	res := rpc.RPCResult[O]{
+		UUID:        rawResult.UUID,
+		RPCFunction: rawResult.RPCFunction,
+		Error:       rawResult.Error,
+	}
+	res.Result, err = rpc.Decode[O](rawResult.Result)
 	span.SetAttributes(telemetry.OtelJSON("rpc.result", res))
 	if err != nil {
 		err = errors.Join(ErrCallingRPCSystem, err)
@@ -799,10 +1822,204 @@ func Call[I any, O any](ctx context.Context, agent *Agent, call rpc.RPCCall[I])
 	return res, nil
 }
 
+// WriteFile copies f to path on the remote host in chunks using the WriteFile
+// RPC. The file is only moved into place once it has been completely written
+// and its checksum verified, and the partially written file is removed if
+// anything fails along the way. Mode, Owner, Group and CreateParents are taken
+// from options.
+func WriteFile(
+	ctx context.Context,
+	agent *Agent,
+	path string,
+	f io.Reader,
+	options rpc.WriteFileArgs,
+) (rpc.WriteFileResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.WriteFile", trace.WithAttributes(
+		attribute.String("rpc.write_file.path", path),
+	))
+	defer span.End()
+
+	args := options
+	args.Path = path
+	args.TempPath = ""
+	args.Offset = 0
+
+	var result rpc.WriteFileResult
+
+	// fail removes the temporary file on the remote host, if a chunk has been
+	// written to one, and returns err. Removing it is best-effort and done even
+	// if ctx is done, since that may be why writing failed.
+	fail := func(err error) (rpc.WriteFileResult, error) {
+		err = errors.Join(ErrWritingFile, err)
+		if args.TempPath != "" {
+			abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer cancel()
+			abortResult, abortErr := Call[rpc.WriteFileArgs, rpc.WriteFileResult](
+				abortCtx,
+				agent,
+				rpc.RPCCall[rpc.WriteFileArgs]{
+					RPCFunction: rpc.RPCWriteFile,
+					Args: rpc.WriteFileArgs{
+						Path:     path,
+						TempPath: args.TempPath,
+						Abort:    true,
+					},
+				},
+			)
+			if abortErr == nil && abortResult.Error != "" {
+				abortErr = errors.New(abortResult.Error)
+			}
+			if abortErr != nil {
+				err = errors.Join(err, fmt.Errorf("error removing %s: %w", args.TempPath, abortErr))
+			}
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	h := sha256.New()
+	buf := make([]byte, FileChunkSize)
+	for {
+		n, readErr := io.ReadFull(f, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return fail(readErr)
+		}
+		h.Write(buf[:n])
+
+		args.Data = buf[:n]
+		args.Final = readErr != nil
+		if args.Final {
+			args.SHA256Checksum = fmt.Sprintf("%x", h.Sum(nil))
+		}
+
+		callResult, err := Call[rpc.WriteFileArgs, rpc.WriteFileResult](ctx, agent, rpc.RPCCall[rpc.WriteFileArgs]{
+			RPCFunction: rpc.RPCWriteFile,
+			Args:        args,
+		})
+		result = callResult.Result
+		if err == nil && callResult.Error != "" {
+			err = errors.New(callResult.Error)
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		if args.Final {
+			break
+		}
+		args.TempPath = result.TempPath
+		args.Offset += int64(n)
+	}
+
+	span.SetAttributes(
+		attribute.String("rpc.write_file.absolute_path", result.Path),
+		attribute.Int64("rpc.write_file.size", result.Size),
+		attribute.String("rpc.write_file.sha256_checksum", result.SHA256Checksum),
+	)
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// ReadFile copies path on the remote host to w in chunks using the ReadFile
+// RPC and verifies its checksum. The returned result has no Data.
+func ReadFile(ctx context.Context, agent *Agent, path string, w io.Writer) (rpc.ReadFileResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.ReadFile", trace.WithAttributes(
+		attribute.String("rpc.read_file.path", path),
+	))
+	defer span.End()
+
+	var result rpc.ReadFileResult
+	h := sha256.New()
+	offset := int64(0)
+	for {
+		callResult, err := Call[rpc.ReadFileArgs, rpc.ReadFileResult](ctx, agent, rpc.RPCCall[rpc.ReadFileArgs]{
+			RPCFunction: rpc.RPCReadFile,
+			Args: rpc.ReadFileArgs{
+				Path:   path,
+				Offset: offset,
+				Length: FileChunkSize,
+			},
+		})
+		result = callResult.Result
+		if err == nil && callResult.Error != "" {
+			err = errors.New(callResult.Error)
+		}
+		if err == nil {
+			h.Write(result.Data)
+			_, err = w.Write(result.Data)
+		}
+		if err != nil {
+			err = errors.Join(ErrReadingFile, err)
+			span.SetStatus(codes.Error, err.Error())
+			return result, err
+		}
+
+		offset += int64(len(result.Data))
+		result.Data = nil
+		if result.EOF {
+			break
+		}
+	}
+
+	checksum := fmt.Sprintf("%x", h.Sum(nil))
+	if checksum != result.SHA256Checksum {
+		err := errors.Join(ErrReadingFile, fmt.Errorf(
+			"%w: expected %s, got %s",
+			rpc.ErrChecksumMismatch,
+			result.SHA256Checksum,
+			checksum,
+		))
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	span.SetAttributes(
+		attribute.String("rpc.read_file.absolute_path", result.Path),
+		attribute.Int64("rpc.read_file.size", offset),
+	)
+	span.SetStatus(codes.Ok, "")
+	return result, nil
+}
+
+// StageFile copies f to a new file in the staging directory of the agent on the
+// remote host and returns its absolute path.
 func StageFile(ctx context.Context, agent *Agent, f io.Reader) (string, error) {
 	ctx, span := Tracer.Start(ctx, "mid/agent.StageFile")
 	defer span.End()
 
+	if !agent.Supports(rpc.RPCWriteFile) {
+		agent.GetLogger(ctx).Warn("agent does not support WriteFile, staging file over SFTP")
+		return stageFileSFTP(ctx, agent, f)
+	}
+
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		err = errors.Join(ErrStagingFile, err)
+		span.SetStatus(codes.Error, err.Error())
+		return "", err
+	}
+	remotePath := agent.installPath("staging", strings.ToLower(uid.String()))
+	span.SetAttributes(attribute.String("rpc.stage_file.remote_path", remotePath))
+
+	result, err := WriteFile(ctx, agent, remotePath, f, rpc.WriteFileArgs{
+		CreateParents: true,
+	})
+	if err != nil {
+		err = errors.Join(ErrStagingFile, err)
+		span.SetStatus(codes.Error, err.Error())
+		return remotePath, err
+	}
+
+	span.SetAttributes(attribute.String("rpc.stage_file.absolute_remote_path", result.Path))
+	span.SetStatus(codes.Ok, "")
+	return result.Path, nil
+}
+
+// stageFileSFTP is StageFile for agents that predate the WriteFile RPC.
+func stageFileSFTP(ctx context.Context, agent *Agent, f io.Reader) (string, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.stageFileSFTP")
+	defer span.End()
+
 	var err error
 
 	for attempt := 1; attempt <= 10; attempt++ {
@@ -814,7 +2031,7 @@ func StageFile(ctx context.Context, agent *Agent, f io.Reader) (string, error) {
 			attribute.Int("retry.attempt", attempt),
 		))
 
-		_, err = RunRemoteCommand(attemptCtx, agent, "mkdir -p .mid/staging")
+		_, err = RunRemoteCommand(attemptCtx, agent, "mkdir -p "+shellQuote(agent.installPath("staging")))
 		if err == nil {
 			attemptSpan.SetStatus(codes.Ok, "")
 		} else {
@@ -855,7 +2072,7 @@ func StageFile(ctx context.Context, agent *Agent, f io.Reader) (string, error) {
 		span.SetStatus(codes.Error, err.Error())
 		return "", err
 	}
-	remotePath := ".mid/staging/" + strings.ToLower(uid.String())
+	remotePath := agent.installPath("staging", strings.ToLower(uid.String()))
 	span.SetAttributes(attribute.String("rpc.stage_file.remote_path", remotePath))
 
 	var realPathOutput []byte
@@ -868,7 +2085,7 @@ func StageFile(ctx context.Context, agent *Agent, f io.Reader) (string, error) {
 			attribute.Int("retry.attempt", attempt),
 		))
 
-		realPathOutput, err = RunRemoteCommand(attemptCtx, agent, "realpath "+remotePath)
+		realPathOutput, err = RunRemoteCommand(attemptCtx, agent, "realpath "+shellQuote(remotePath))
 		if err == nil {
 			attemptSpan.SetStatus(codes.Ok, "")
 		} else {
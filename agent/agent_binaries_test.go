@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAgentBinaryChecksum(t *testing.T) {
+	t.Parallel()
+
+	for _, goarch := range slices.Compact(slices.Sorted(maps.Values(MachineGOARCH))) {
+		t.Run(goarch, func(t *testing.T) {
+			t.Parallel()
+
+			binary, err := GetAgentBinary("linux", goarch)
+			require.NoError(t, err)
+			checksum, err := GetAgentBinaryChecksum("linux", goarch)
+			require.NoError(t, err)
+
+			gzReader, err := gzip.NewReader(bytes.NewReader(binary))
+			require.NoError(t, err)
+			h := sha256.New()
+			_, err = io.Copy(h, gzReader)
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("%x", h.Sum(nil)), checksum)
+		})
+	}
+
+	_, err := GetAgentBinaryChecksum("linux", "mips")
+	assert.Error(t, err)
+	_, err = GetAgentBinaryChecksum("darwin", "amd64")
+	assert.Error(t, err)
+}
@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInstallLockOwner(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		line   string
+		expect InstallLockOwner
+		ok     bool
+	}{
+		"valid": {
+			line: "1700000000 1234 8a1f0c2e-uuid builder",
+			expect: InstallLockOwner{
+				Host:         "builder",
+				PID:          1234,
+				InstanceUUID: "8a1f0c2e-uuid",
+				Acquired:     time.Unix(1700000000, 0),
+			},
+			ok: true,
+		},
+		"extra whitespace": {
+			line: "  1700000000\t1234  uuid   builder\n",
+			expect: InstallLockOwner{
+				Host:         "builder",
+				PID:          1234,
+				InstanceUUID: "uuid",
+				Acquired:     time.Unix(1700000000, 0),
+			},
+			ok: true,
+		},
+		"empty":          {line: ""},
+		"missing host":   {line: "1700000000 1234 uuid"},
+		"too many":       {line: "1700000000 1234 uuid builder extra"},
+		"bad time":       {line: "yesterday 1234 uuid builder"},
+		"bad pid":        {line: "1700000000 pid uuid builder"},
+		"partial output": {line: "1700000000 12"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			owner, ok := parseInstallLockOwner(tc.line)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expect, owner)
+		})
+	}
+}
+
+func TestCheckInstallLock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	owner := func(pid int, host string) string {
+		return fmt.Sprintf("1700000000 %d uuid %s", pid, host)
+	}
+	remoteNow := func(held time.Duration) string {
+		return strconv.FormatInt(time.Unix(1700000000, 0).Add(held).Unix(), 10)
+	}
+	// this process is reported gone as well, to make sure it's never
+	// checked.
+	goneProcess := func(pid int) bool {
+		return pid == 999 || pid == 42
+	}
+
+	tests := map[string]struct {
+		check             installLockCheck
+		held              time.Duration
+		unknownOwnerSince time.Time
+		stale             bool
+	}{
+		"fresh": {
+			check: installLockCheck{OwnerLine: owner(999, "elsewhere"), RemoteNow: remoteNow(time.Minute)},
+			held:  time.Minute,
+		},
+
+		"old": {
+			check: installLockCheck{OwnerLine: owner(100, "elsewhere"), RemoteNow: remoteNow(InstallLockStaleAfter + time.Second)},
+			held:  InstallLockStaleAfter + time.Second,
+			stale: true,
+		},
+
+		"just at the limit": {
+			check: installLockCheck{OwnerLine: owner(100, "elsewhere"), RemoteNow: remoteNow(InstallLockStaleAfter)},
+			held:  InstallLockStaleAfter,
+		},
+
+		"same host, dead process": {
+			check: installLockCheck{OwnerLine: owner(999, "here"), RemoteNow: remoteNow(time.Second)},
+			held:  time.Second,
+			stale: true,
+		},
+
+		"same host, live process": {
+			check: installLockCheck{OwnerLine: owner(100, "here"), RemoteNow: remoteNow(time.Second)},
+			held:  time.Second,
+		},
+
+		"same host, this process": {
+			// a lock left behind by an earlier attempt of this process is waited
+			// out rather than broken.
+			check: installLockCheck{OwnerLine: owner(42, "here"), RemoteNow: remoteNow(time.Second)},
+			held:  time.Second,
+		},
+
+		"other host, dead process": {
+			// the PID means nothing on another machine.
+			check: installLockCheck{OwnerLine: owner(999, "elsewhere"), RemoteNow: remoteNow(time.Second)},
+			held:  time.Second,
+		},
+
+		"remote clock unreadable": {
+			check: installLockCheck{OwnerLine: owner(100, "elsewhere"), RemoteNow: "garbage"},
+		},
+
+		"unknown owner, first seen": {
+			check:             installLockCheck{},
+			unknownOwnerSince: now,
+		},
+
+		"unknown owner, seen before": {
+			check:             installLockCheck{OwnerLine: "1700000000 12", UnknownOwnerSince: now.Add(-time.Minute)},
+			held:              time.Minute,
+			unknownOwnerSince: now.Add(-time.Minute),
+		},
+
+		"unknown owner, for too long": {
+			check:             installLockCheck{UnknownOwnerSince: now.Add(-InstallLockStaleAfter - time.Second)},
+			held:              InstallLockStaleAfter + time.Second,
+			unknownOwnerSince: now.Add(-InstallLockStaleAfter - time.Second),
+			stale:             true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			check := tc.check
+			check.Now = now
+			check.Hostname = "here"
+			check.PID = 42
+			check.ProcessGone = goneProcess
+			state := checkInstallLock(check)
+			assert.Equal(t, tc.held, state.Held)
+			assert.Equal(t, tc.unknownOwnerSince, state.UnknownOwnerSince)
+			assert.Equal(t, tc.stale, state.StaleReason != "", state.StaleReason)
+
+			parsed, ok := parseInstallLockOwner(check.OwnerLine)
+			assert.Equal(t, ok, state.UnknownOwnerSince.IsZero())
+			assert.Equal(t, parsed, state.Owner)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ssh"
+)
+
+// BecomeMethod is how the agent gets the privileges of the become user.
+type BecomeMethod string
+
+const (
+	// BecomeAuto uses sudo unless the SSH user already is the become user.
+	BecomeAuto BecomeMethod = ""
+	BecomeSudo BecomeMethod = "sudo"
+	BecomeDoas BecomeMethod = "doas"
+	BecomeSu   BecomeMethod = "su"
+	// BecomeNone runs the agent as the SSH user.
+	BecomeNone BecomeMethod = "none"
+)
+
+// DefaultBecomeUser is the user the agent runs as if Become.User isn't set.
+const DefaultBecomeUser = "root"
+
+// BecomeTimeout is how long the become command has to ask for the password
+// and, once it is given, to start the command.
+const BecomeTimeout = 30 * time.Second
+
+// Become configures how the agent is run with the privileges of another user.
+type Become struct {
+	Method BecomeMethod
+	// User is the user to become. Empty means DefaultBecomeUser.
+	User string
+	// Password is written to the become command's terminal when it prompts for
+	// one. It is never logged. Without a password, methods that can are told
+	// not to prompt at all.
+	Password string
+	// Flags are passed to the become command before the command to run.
+	Flags []string
+}
+
+// BecomeMethodFor returns the BecomeMethod named method. "auto" and empty are
+// both BecomeAuto.
+func BecomeMethodFor(method string) (BecomeMethod, error) {
+	switch BecomeMethod(method) {
+	case "auto", BecomeAuto:
+		return BecomeAuto, nil
+	case BecomeSudo, BecomeDoas, BecomeSu, BecomeNone:
+		return BecomeMethod(method), nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownBecomeMethod, method)
+}
+
+func (become Become) user() string {
+	if become.User == "" {
+		return DefaultBecomeUser
+	}
+	return become.User
+}
+
+// resolveMethod resolves BecomeAuto to the method to use on the remote host.
+func (become Become) resolveMethod(ctx context.Context, agent *Agent) (BecomeMethod, error) {
+	method, err := BecomeMethodFor(string(become.Method))
+	if err != nil {
+		return "", err
+	}
+	if method != BecomeAuto {
+		return method, nil
+	}
+	// for some reason Ansible doesn't like Docker containers with sudo installed
+	// so have to jump through some hoops to not use sudo if we don't have to.
+	output, err := RunRemoteCommand(ctx, agent, "id -un")
+	if err != nil {
+		return "", fmt.Errorf("error getting remote user: %w", err)
+	}
+	if strings.TrimSpace(string(output)) == become.user() {
+		return BecomeNone, nil
+	}
+	return BecomeSudo, nil
+}
+
+// command wraps cmd, a shell command line, so that it is run by method as the
+// become user. prompt is the password prompt sudo is told to use, if empty
+// sudo and doas are told not to prompt.
+func (become Become) command(method BecomeMethod, cmd string, prompt string) string {
+	flags := ""
+	for _, flag := range become.Flags {
+		flags += shellQuote(flag) + " "
+	}
+	user := shellQuote(become.user())
+
+	switch method {
+	case BecomeSudo:
+		if prompt == "" {
+			return "sudo -n -u " + user + " " + flags + "-- " + cmd
+		}
+		return "sudo -p " + shellQuote(prompt) + " -u " + user + " " + flags + "-- " + cmd
+	case BecomeDoas:
+		if prompt == "" {
+			return "doas -n -u " + user + " " + flags + "-- " + cmd
+		}
+		return "doas -u " + user + " " + flags + "-- " + cmd
+	case BecomeSu:
+		return "su " + flags + "-c " + shellQuote(cmd) + " " + user
+	}
+	return cmd
+}
+
+// startBecome starts cmd, a shell command line, on session as the become user
+// and returns its stdin and stdout. The session's stderr must be set up before
+// calling it.
+//
+// If a password is needed the session gets a terminal, since su and doas only
+// read passwords from one. The password is written to it when the become
+// command prompts for it, then the terminal is switched to raw mode and a
+// marker is printed before cmd is run, so that from then on it passes data
+// through untouched like plain pipes would. cmd's stderr goes to /dev/null in
+// that case as it would otherwise be mixed into stdout.
+func (agent *Agent) startBecome(ctx context.Context, session *ssh.Session, cmd string) (io.WriteCloser, io.Reader, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.Agent.startBecome")
+	defer span.End()
+
+	method, err := agent.Become.resolveMethod(ctx, agent)
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	usePassword := agent.Become.Password != "" && method != BecomeNone
+	span.SetAttributes(
+		attribute.String("become.method", string(method)),
+		attribute.String("become.user", agent.Become.user()),
+		attribute.Bool("become.password", usePassword),
+	)
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+
+	if !usePassword {
+		err = session.Start(agent.Become.command(method, cmd, ""))
+		if err != nil {
+			err = errors.Join(ErrBecoming, err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, nil, err
+		}
+		span.SetStatus(codes.Ok, "")
+		return stdin, stdout, nil
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	prompt := "mid-become-password-" + id.String() + ":"
+	marker := "mid-become-success-" + id.String()
+
+	err = session.RequestPty("dumb", 0, 0, ssh.TerminalModes{ssh.ECHO: 0})
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	err = session.Start(agent.Become.command(method, shCommand(fmt.Sprintf(
+		"stty raw -echo -iexten && echo %s && exec %s 2>/dev/null",
+		marker,
+		cmd,
+	)), prompt))
+	if err != nil {
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+
+	reader := bufio.NewReader(stdout)
+	done := make(chan error, 1)
+	go func() {
+		done <- agent.Become.answerPrompt(stdin, reader, prompt, marker)
+	}()
+	select {
+	case err = <-done:
+	case <-time.After(BecomeTimeout):
+		err = fmt.Errorf("timed out after %s", BecomeTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		session.Close()
+		err = errors.Join(ErrBecoming, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return stdin, reader, nil
+}
+
+// answerPrompt reads the output of a become command running on a terminal up
+// to the line with marker, writing the password to it the first time it
+// prompts. A prompt is the given prompt or, since su and doas don't let it be
+// set, a line ending in a colon that is waiting for input.
+func (become Become) answerPrompt(stdin io.Writer, stdout *bufio.Reader, prompt string, marker string) error {
+	answered := false
+	output := strings.Builder{}
+	line := strings.Builder{}
+	for {
+		b, err := stdout.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("exited before starting: %s", strings.TrimSpace(output.String()+line.String()))
+			}
+			if answered {
+				err = errors.Join(ErrBecomePasswordIncorrect, err)
+			}
+			return err
+		}
+
+		if b == '\n' {
+			text := strings.TrimRight(line.String(), "\r")
+			if text == marker {
+				return nil
+			}
+			output.WriteString(text + "\n")
+			line.Reset()
+			continue
+		}
+		line.WriteByte(b)
+
+		// a prompt is only complete once the become command waits for input.
+		if stdout.Buffered() > 0 {
+			continue
+		}
+		text := strings.TrimSpace(line.String())
+		if text != prompt && !strings.HasSuffix(text, ":") {
+			continue
+		}
+		if answered {
+			return ErrBecomePasswordIncorrect
+		}
+		_, err = io.WriteString(stdin, become.Password+"\n")
+		if err != nil {
+			return err
+		}
+		answered = true
+		// our own prompt is nothing worth reporting.
+		if text != prompt {
+			output.WriteString(text + "\n")
+		}
+		line.Reset()
+	}
+}
+
+// runBecomeCommand runs cmd, a shell command line, as the become user and
+// returns its stdout.
+func runBecomeCommand(ctx context.Context, agent *Agent, cmd string) ([]byte, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent.runBecomeCommand", trace.WithAttributes(
+		attribute.String("cmd", cmd),
+	))
+	defer span.End()
+
+	session, err := agent.Client.NewSession()
+	if err != nil {
+		err = errors.Join(ErrRunningRemoteCommand, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	defer session.Close()
+
+	_, stdout, err := agent.startBecome(ctx, session, cmd)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	output, err := io.ReadAll(stdout)
+	if err == nil {
+		err = session.Wait()
+	}
+	span.SetAttributes(attribute.String("stdout", string(output)))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return output, err
+	}
+
+	span.SetStatus(codes.Ok, "")
+	return output, nil
+}
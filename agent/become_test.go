@@ -0,0 +1,282 @@
+package agent
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBecomeMethodFor(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		expect BecomeMethod
+		err    bool
+	}{
+		"empty":   {input: "", expect: BecomeAuto},
+		"auto":    {input: "auto", expect: BecomeAuto},
+		"sudo":    {input: "sudo", expect: BecomeSudo},
+		"doas":    {input: "doas", expect: BecomeDoas},
+		"su":      {input: "su", expect: BecomeSu},
+		"none":    {input: "none", expect: BecomeNone},
+		"unknown": {input: "pbrun", err: true},
+		"case":    {input: "SUDO", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			method, err := BecomeMethodFor(tc.input)
+			if tc.err {
+				assert.ErrorIs(t, err, ErrUnknownBecomeMethod)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, method)
+		})
+	}
+}
+
+func TestBecomeCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		become Become
+		method BecomeMethod
+		prompt string
+		expect string
+	}{
+		"sudo": {
+			method: BecomeSudo,
+			expect: "sudo -n -u 'root' -- /bin/sh -c 'id'",
+		},
+
+		"sudo with password": {
+			become: Become{User: "deploy", Password: "hunter2"},
+			method: BecomeSudo,
+			prompt: "mid-prompt:",
+			expect: "sudo -p 'mid-prompt:' -u 'deploy' -- /bin/sh -c 'id'",
+		},
+
+		"sudo with flags": {
+			become: Become{Flags: []string{"-H", "--preserve-env=A B"}},
+			method: BecomeSudo,
+			expect: "sudo -n -u 'root' '-H' '--preserve-env=A B' -- /bin/sh -c 'id'",
+		},
+
+		"doas": {
+			become: Become{User: "deploy"},
+			method: BecomeDoas,
+			expect: "doas -n -u 'deploy' -- /bin/sh -c 'id'",
+		},
+
+		"doas with password": {
+			become: Become{Password: "hunter2"},
+			method: BecomeDoas,
+			prompt: "mid-prompt:",
+			expect: "doas -u 'root' -- /bin/sh -c 'id'",
+		},
+
+		"doas with flags": {
+			become: Become{Flags: []string{"-s"}},
+			method: BecomeDoas,
+			expect: "doas -n -u 'root' '-s' -- /bin/sh -c 'id'",
+		},
+
+		"su": {
+			method: BecomeSu,
+			expect: "su -c '/bin/sh -c '\\''id'\\''' 'root'",
+		},
+
+		"su with password": {
+			become: Become{User: "deploy", Password: "hunter2"},
+			method: BecomeSu,
+			prompt: "mid-prompt:",
+			expect: "su -c '/bin/sh -c '\\''id'\\''' 'deploy'",
+		},
+
+		"su with flags": {
+			become: Become{Flags: []string{"-l"}},
+			method: BecomeSu,
+			expect: "su '-l' -c '/bin/sh -c '\\''id'\\''' 'root'",
+		},
+
+		"none": {
+			become: Become{User: "deploy", Password: "hunter2", Flags: []string{"-H"}},
+			method: BecomeNone,
+			prompt: "mid-prompt:",
+			expect: "/bin/sh -c 'id'",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, tc.become.command(tc.method, shCommand("id"), tc.prompt))
+		})
+	}
+}
+
+// fakeBecomeStep is a step of a become command in TestBecomeAnswerPrompt.
+// It either writes Output or, if Output is empty, reads a line of input and
+// records it.
+type fakeBecomeStep struct {
+	Output string
+}
+
+func TestBecomeAnswerPrompt(t *testing.T) {
+	t.Parallel()
+
+	const prompt = "mid-become-password-1234:"
+	const marker = "mid-become-success-1234"
+
+	tests := map[string]struct {
+		steps []fakeBecomeStep
+		// answers is what the become command reads as input.
+		answers []string
+		err     error
+		errMsg  string
+		// rest is what is left to read after the marker.
+		rest string
+	}{
+		"custom prompt": {
+			steps: []fakeBecomeStep{
+				{Output: prompt},
+				{},
+				{Output: "\r\n" + marker + "\r\nagent output"},
+			},
+			answers: []string{"hunter2"},
+			rest:    "agent output",
+		},
+
+		"su-style prompt": {
+			steps: []fakeBecomeStep{
+				{Output: "Password: "},
+				{},
+				{Output: "\n" + marker + "\n"},
+			},
+			answers: []string{"hunter2"},
+		},
+
+		"doas-style prompt after output": {
+			steps: []fakeBecomeStep{
+				{Output: "Last login: yesterday\n"},
+				{Output: "doas (deploy@host) password: "},
+				{},
+				{Output: "\n" + marker + "\n{}"},
+			},
+			answers: []string{"hunter2"},
+			rest:    "{}",
+		},
+
+		"wrong password": {
+			steps: []fakeBecomeStep{
+				{Output: prompt},
+				{},
+				{Output: "\nSorry, try again.\n"},
+				{Output: prompt},
+			},
+			answers: []string{"hunter2"},
+			err:     ErrBecomePasswordIncorrect,
+		},
+
+		"exits after wrong password": {
+			steps: []fakeBecomeStep{
+				{Output: "Password: "},
+				{},
+				{Output: "\nsu: Authentication failure\n"},
+			},
+			answers: []string{"hunter2"},
+			err:     ErrBecomePasswordIncorrect,
+			errMsg:  "su: Authentication failure",
+		},
+
+		"exits before prompting": {
+			steps: []fakeBecomeStep{
+				{Output: "sudo: a terminal is required\n"},
+			},
+			errMsg: "exited before starting: sudo: a terminal is required",
+		},
+
+		"no prompt": {
+			steps: []fakeBecomeStep{
+				{Output: "motd\n" + marker + "\nagent output"},
+			},
+			rest: "agent output",
+		},
+
+		"colon in later output": {
+			// a colon is only a prompt while the become command waits for
+			// input, and only until the marker.
+			steps: []fakeBecomeStep{
+				{Output: "note: no password needed\n" + marker + "\nkey: value"},
+			},
+			rest: "key: value",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			stdinReader, stdinWriter := io.Pipe()
+			stdoutReader, stdoutWriter := io.Pipe()
+
+			answers := make(chan []string, 1)
+			go func() {
+				input := bufio.NewReader(stdinReader)
+				read := []string{}
+				var err error
+				for _, step := range tc.steps {
+					if step.Output != "" {
+						_, err = io.WriteString(stdoutWriter, step.Output)
+					} else {
+						var line string
+						line, err = input.ReadString('\n')
+						read = append(read, strings.TrimSuffix(line, "\n"))
+					}
+					if err != nil {
+						break
+					}
+				}
+				stdoutWriter.Close()
+				answers <- read
+			}()
+
+			become := Become{Password: "hunter2"}
+			stdout := bufio.NewReader(stdoutReader)
+			err := become.answerPrompt(stdinWriter, stdout, prompt, marker)
+			if tc.err != nil || tc.errMsg != "" {
+				if tc.err != nil {
+					assert.ErrorIs(t, err, tc.err)
+				} else {
+					assert.False(t, errors.Is(err, ErrBecomePasswordIncorrect))
+				}
+				assert.ErrorContains(t, err, tc.errMsg)
+				// our own prompt isn't part of the error.
+				assert.NotContains(t, err.Error(), prompt)
+			} else {
+				require.NoError(t, err)
+				rest, err := io.ReadAll(stdout)
+				require.NoError(t, err)
+				assert.Equal(t, tc.rest, string(rest))
+			}
+
+			stdinWriter.Close()
+			stdoutReader.Close()
+			expect := tc.answers
+			if expect == nil {
+				expect = []string{}
+			}
+			assert.Equal(t, expect, <-answers)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// testRoute handles a call made to a test agent, like rpc.ServerRoute.
+type testRoute func(ctx context.Context, rpcFunction rpc.RPCFunction, args any, stream rpc.StreamFunc) (any, error)
+
+// newTestAgent returns a running Agent connected over pipes to a stand-in for
+// the remote agent that handles every call with route. Calls are handled one
+// at a time in the order they are made.
+func newTestAgent(t *testing.T, route testRoute) *Agent {
+	t.Helper()
+
+	agent, _ := newTestConnection(t, route)
+	return agent
+}
+
+// newTestConnection is the same as newTestAgent but also returns a function
+// that drops the connection like a dropped SSH session would: the agent reads
+// EOF and anything it sends fails.
+func newTestConnection(t *testing.T, route testRoute) (*Agent, func()) {
+	t.Helper()
+
+	callsReader, callsWriter := io.Pipe()
+	resultsReader, resultsWriter := io.Pipe()
+
+	agent := &Agent{
+		Stdin:  callsWriter,
+		Stdout: resultsReader,
+		RemoteInfo: rpc.AgentPingResult{
+			ProtocolVersion: rpc.ProtocolVersion,
+			RPCFunctions:    rpc.RPCFunctions,
+		},
+	}
+	var err error
+	agent.Encoder, err = rpc.NewEncoder(rpc.EncodingJSON, callsWriter)
+	require.NoError(t, err)
+	agent.Decoder, err = rpc.NewDecoder(rpc.EncodingJSON, resultsReader)
+	require.NoError(t, err)
+
+	decoder, err := rpc.NewDecoder(rpc.EncodingJSON, callsReader)
+	require.NoError(t, err)
+	encoder, err := rpc.NewEncoder(rpc.EncodingJSON, resultsWriter)
+	require.NoError(t, err)
+	mutex := sync.Mutex{}
+	send := func(result rpc.RPCResult[any]) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		encoder.EncodeResult(result)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			call, err := decoder.DecodeCall()
+			if err != nil || call.RPCFunction == rpc.RPCClose {
+				return
+			}
+			if call.RPCFunction == rpc.RPCCancel {
+				// calls are handled one at a time, so there's never one to cancel.
+				targs, _ := rpc.Decode[rpc.CancelArgs](call.Args)
+				send(rpc.RPCResult[any]{
+					UUID:        call.UUID,
+					RPCFunction: call.RPCFunction,
+					Result:      rpc.CancelResult{UUID: targs.UUID},
+				})
+				continue
+			}
+			var stream rpc.StreamFunc
+			if call.Stream {
+				stream = func(chunk rpc.RPCStreamChunk) {
+					send(rpc.RPCResult[any]{
+						UUID:        call.UUID,
+						RPCFunction: call.RPCFunction,
+						Chunk:       &chunk,
+					})
+				}
+			}
+			res, err := route(context.Background(), call.RPCFunction, call.Args, stream)
+			result := rpc.RPCResult[any]{
+				UUID:        call.UUID,
+				RPCFunction: call.RPCFunction,
+				Result:      res,
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			send(result)
+		}
+	}()
+
+	// Connect makes sure there's a UUID before anything else uses the agent.
+	_, err = agent.EnsureUUID()
+	require.NoError(t, err)
+	agent.Running.Store(true)
+	agent.WaitGroup.Add(1)
+	go agent.RunLocal()
+
+	t.Cleanup(func() {
+		agent.Disconnect(context.Background(), false)
+		callsReader.Close()
+		resultsWriter.Close()
+		<-done
+		agent.WaitGroup.Wait()
+	})
+
+	drop := func() {
+		callsReader.Close()
+		resultsWriter.Close()
+	}
+	return agent, drop
+}
+
+func TestCallStream(t *testing.T) {
+	t.Parallel()
+
+	sent := []rpc.RPCStreamChunk{
+		{Stdout: []byte("one\n")},
+		{Stderr: []byte("warning\n")},
+		{Stdout: []byte("two\n")},
+		{Stdout: []byte("partial")},
+	}
+	agent := newTestAgent(t, func(
+		ctx context.Context,
+		rpcFunction rpc.RPCFunction,
+		args any,
+		stream rpc.StreamFunc,
+	) (any, error) {
+		for _, chunk := range sent {
+			stream(chunk)
+		}
+		return rpc.ExecResult{Stdout: []byte("one\ntwo\npartial"), Stderr: []byte("warning\n")}, nil
+	})
+
+	received := []rpc.RPCStreamChunk{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := CallStream[rpc.ExecArgs, rpc.ExecResult](ctx, agent, rpc.RPCCall[rpc.ExecArgs]{
+		RPCFunction: rpc.RPCExec,
+		Args:        rpc.ExecArgs{Command: []string{"true"}},
+	}, func(chunk rpc.RPCStreamChunk) {
+		received = append(received, chunk)
+	})
+	require.NoError(t, err)
+	assert.Empty(t, res.Error)
+	assert.Equal(t, "one\ntwo\npartial", string(res.Result.Stdout))
+
+	// every chunk is delivered, in order, by the time the call returns.
+	assert.Equal(t, sent, received)
+}
+
+func TestCallStreamWithoutHandler(t *testing.T) {
+	t.Parallel()
+
+	agent := newTestAgent(t, func(
+		ctx context.Context,
+		rpcFunction rpc.RPCFunction,
+		args any,
+		stream rpc.StreamFunc,
+	) (any, error) {
+		assert.Nil(t, stream)
+		return rpc.ExecResult{Stdout: []byte("done\n")}, nil
+	})
+
+	// chunks for calls that aren't streaming are dropped.
+	agent.handleChunk(context.Background(), rpc.RPCResult[any]{
+		UUID:  "unknown",
+		Chunk: &rpc.RPCStreamChunk{Stdout: []byte("dropped\n")},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := Call[rpc.ExecArgs, rpc.ExecResult](ctx, agent, rpc.RPCCall[rpc.ExecArgs]{
+		RPCFunction: rpc.RPCExec,
+		Args:        rpc.ExecArgs{Command: []string{"true"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "done\n", string(res.Result.Stdout))
+}
+
+func TestCallLateResult(t *testing.T) {
+	t.Parallel()
+
+	handled := make(chan struct{}, 2)
+	agent := newTestAgent(t, func(
+		ctx context.Context,
+		rpcFunction rpc.RPCFunction,
+		args any,
+		stream rpc.StreamFunc,
+	) (any, error) {
+		defer func() { handled <- struct{}{} }()
+		targs, err := rpc.Decode[rpc.ExecArgs](args)
+		require.NoError(t, err)
+		if targs.Dir == "slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return rpc.ExecResult{Stdout: []byte(targs.Dir)}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := Call[rpc.ExecArgs, rpc.ExecResult](ctx, agent, rpc.RPCCall[rpc.ExecArgs]{
+		RPCFunction: rpc.RPCExec,
+		Args:        rpc.ExecArgs{Dir: "slow"},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the result of the call that gave up arrives after it returned and is
+	// dropped without getting in the way of the next call.
+	<-handled
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	res, err := Call[rpc.ExecArgs, rpc.ExecResult](ctx, agent, rpc.RPCCall[rpc.ExecArgs]{
+		RPCFunction: rpc.RPCExec,
+		Args:        rpc.ExecArgs{Dir: "fast"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fast", string(res.Result.Stdout))
+}
+
+func TestCallConnectionLost(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	agent, drop := newTestConnection(t, func(
+		ctx context.Context,
+		rpcFunction rpc.RPCFunction,
+		args any,
+		stream rpc.StreamFunc,
+	) (any, error) {
+		close(started)
+		<-release
+		return rpc.ExecResult{}, nil
+	})
+	t.Cleanup(func() { close(release) })
+
+	type callReturn struct {
+		res rpc.RPCResult[rpc.ExecResult]
+		err error
+	}
+	returned := make(chan callReturn, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		res, err := Call[rpc.ExecArgs, rpc.ExecResult](ctx, agent, rpc.RPCCall[rpc.ExecArgs]{
+			RPCFunction: rpc.RPCExec,
+			Args:        rpc.ExecArgs{Command: []string{"true"}},
+		})
+		returned <- callReturn{res: res, err: err}
+	}()
+
+	<-started
+	drop()
+
+	select {
+	case ret := <-returned:
+		// the call was sent, so it isn't known whether it completed.
+		assert.ErrorIs(t, ret.err, ErrConnectionLost)
+		assert.NotErrorIs(t, ret.err, ErrCallNotSent)
+		assert.Equal(t, ErrConnectionLost.Error(), ret.res.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call didn't return after the connection was lost")
+	}
+	assert.True(t, agent.Lost.Load())
+}
@@ -2,10 +2,17 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
 	_ "embed"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"strings"
+	"time"
 
+	"github.com/sapslaj/mid/agent/rpc"
 	"github.com/sapslaj/mid/agent/untar"
 )
 
@@ -13,12 +20,125 @@ import (
 //go:embed ansible.tar.gz
 var AnsibleTarball []byte
 
-func InstallAnsible() error {
-	targetDir := path.Join(".mid", "ansible")
-	return untar.Untar(bytes.NewReader(AnsibleTarball), targetDir)
+// InstallAnsible makes sure the embedded Ansible bundle is extracted to the
+// ansible directory in rpc.InstallDir(). Every bundle is extracted into its
+// own directory named after its hash and ansible is a symlink to the current
+// one, so an unchanged bundle is never extracted twice and switching bundles
+// is atomic even if another agent is running modules from the old one.
+func InstallAnsible() (rpc.AnsibleInstallInfo, error) {
+	return installAnsible(rpc.InstallDir(), AnsibleTarball)
+}
+
+// installAnsible is InstallAnsible for the given install directory and bundle.
+func installAnsible(installDir string, tarball []byte) (rpc.AnsibleInstallInfo, error) {
+	start := time.Now()
+	info := rpc.AnsibleInstallInfo{
+		SHA256Checksum: fmt.Sprintf("%x", sha256.Sum256(tarball)),
+	}
+	linkPath := path.Join(installDir, "ansible")
+	bundleName := "ansible-" + info.SHA256Checksum[:16]
+	bundleDir := path.Join(installDir, bundleName)
+
+	target, err := os.Readlink(linkPath)
+	if err == nil && target == bundleName && bundleInstalled(bundleDir, info.SHA256Checksum) {
+		info.Cached = true
+		info.Duration = time.Since(start)
+		return info, nil
+	}
+
+	if !bundleInstalled(bundleDir, info.SHA256Checksum) {
+		err = extractBundle(bundleDir, tarball, info.SHA256Checksum)
+		if err != nil {
+			info.Duration = time.Since(start)
+			return info, err
+		}
+	} else {
+		info.Cached = true
+	}
+
+	err = swapSymlink(linkPath, bundleName)
+	info.Duration = time.Since(start)
+	return info, err
 }
 
 func UninstallAnsible() error {
-	targetDir := path.Join(".mid", "ansible")
-	return os.RemoveAll(targetDir)
+	linkPath := path.Join(rpc.InstallDir(), "ansible")
+	target, err := os.Readlink(linkPath)
+	if err == nil {
+		err = os.RemoveAll(path.Join(rpc.InstallDir(), target))
+		if err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(linkPath)
+}
+
+func bundleInstalled(bundleDir string, checksum string) bool {
+	stamp, err := os.ReadFile(path.Join(bundleDir, rpc.AnsibleHashFile))
+	return err == nil && strings.TrimSpace(string(stamp)) == checksum
+}
+
+// extractBundle extracts tarball into a temporary directory and renames it into
+// place once it is complete, so a half-extracted bundle is never used.
+func extractBundle(bundleDir string, tarball []byte, checksum string) error {
+	err := os.MkdirAll(path.Dir(bundleDir), 0o700)
+	if err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(path.Dir(bundleDir), ".ansible-extract-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = untar.Untar(bytes.NewReader(tarball), tmpDir)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path.Join(tmpDir, rpc.AnsibleHashFile), []byte(checksum+"\n"), 0o600)
+	if err != nil {
+		return err
+	}
+
+	if bundleInstalled(bundleDir, checksum) {
+		// another agent finished extracting first
+		return nil
+	}
+	// a directory without a valid stamp is left over from a crash.
+	err = os.RemoveAll(bundleDir)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpDir, bundleDir)
+	if err != nil && bundleInstalled(bundleDir, checksum) {
+		// another agent won the race
+		return nil
+	}
+	return err
+}
+
+// swapSymlink atomically points linkPath at target.
+func swapSymlink(linkPath string, target string) error {
+	info, err := os.Lstat(linkPath)
+	if err == nil && info.Mode()&fs.ModeSymlink == 0 {
+		// agents before bundles were cached extracted straight into linkPath.
+		err = os.RemoveAll(linkPath)
+		if err != nil {
+			return err
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	tmpLink := fmt.Sprintf("%s.%d.tmp", linkPath, os.Getpid())
+	os.Remove(tmpLink)
+	err = os.Symlink(target, tmpLink)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmpLink, linkPath)
+	if err != nil {
+		os.Remove(tmpLink)
+	}
+	return err
 }
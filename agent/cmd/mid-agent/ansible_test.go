@@ -0,0 +1,221 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// testBundle returns a gzipped tarball containing a single module whose
+// contents are body.
+func testBundle(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     "modules/",
+		Mode:     0o755,
+	}))
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "modules/ping.py",
+		Mode:     0o644,
+		Size:     int64(len(body)),
+	}))
+	_, err := tarWriter.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, gzipWriter.Close())
+	return buf.Bytes()
+}
+
+// bundleName returns the name of the directory tarball is extracted into.
+func bundleName(tarball []byte) string {
+	return "ansible-" + fmt.Sprintf("%x", sha256.Sum256(tarball))[:16]
+}
+
+func TestInstallAnsible(t *testing.T) {
+	t.Parallel()
+
+	tarball := testBundle(t, "current")
+	checksum := fmt.Sprintf("%x", sha256.Sum256(tarball))
+	previous := testBundle(t, "previous")
+
+	tests := map[string]struct {
+		// setup prepares installDir before the bundle is installed.
+		setup func(t *testing.T, installDir string)
+		// cached is whether the bundle is expected to be reused rather than
+		// extracted.
+		cached bool
+		// marker is whether a file added to an already extracted bundle is
+		// expected to survive, i.e. whether it wasn't extracted again.
+		marker bool
+	}{
+		"fresh install": {
+			setup: func(t *testing.T, installDir string) {},
+		},
+
+		"cache hit": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, tarball)
+				require.NoError(t, err)
+			},
+			cached: true,
+			marker: true,
+		},
+
+		"stale stamp": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, tarball)
+				require.NoError(t, err)
+				stamp := filepath.Join(installDir, bundleName(tarball), rpc.AnsibleHashFile)
+				require.NoError(t, os.WriteFile(stamp, []byte("0123456789abcdef\n"), 0o600))
+			},
+		},
+
+		"missing stamp": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, tarball)
+				require.NoError(t, err)
+				// e.g. the agent crashed before the stamp was written.
+				require.NoError(t, os.Remove(filepath.Join(installDir, bundleName(tarball), rpc.AnsibleHashFile)))
+			},
+		},
+
+		"missing symlink": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, tarball)
+				require.NoError(t, err)
+				require.NoError(t, os.Remove(filepath.Join(installDir, "ansible")))
+			},
+			cached: true,
+			marker: true,
+		},
+
+		"previous bundle": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, previous)
+				require.NoError(t, err)
+			},
+		},
+
+		"switching back to an extracted bundle": {
+			setup: func(t *testing.T, installDir string) {
+				_, err := installAnsible(installDir, tarball)
+				require.NoError(t, err)
+				_, err = installAnsible(installDir, previous)
+				require.NoError(t, err)
+			},
+			cached: true,
+			marker: true,
+		},
+
+		"legacy directory": {
+			setup: func(t *testing.T, installDir string) {
+				// agents before bundles were cached extracted straight into ansible.
+				legacy := filepath.Join(installDir, "ansible", "modules")
+				require.NoError(t, os.MkdirAll(legacy, 0o700))
+				require.NoError(t, os.WriteFile(filepath.Join(legacy, "old.py"), []byte("old"), 0o600))
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			installDir := filepath.Join(t.TempDir(), "install")
+			tc.setup(t, installDir)
+			bundleDir := filepath.Join(installDir, bundleName(tarball))
+			marker := filepath.Join(bundleDir, "marker")
+			if _, err := os.Stat(bundleDir); err == nil {
+				require.NoError(t, os.WriteFile(marker, []byte("x"), 0o600))
+			}
+
+			info, err := installAnsible(installDir, tarball)
+			require.NoError(t, err)
+			assert.Equal(t, checksum, info.SHA256Checksum)
+			assert.Equal(t, tc.cached, info.Cached)
+			if tc.marker {
+				assert.FileExists(t, marker)
+			} else {
+				assert.NoFileExists(t, marker)
+			}
+
+			linkPath := filepath.Join(installDir, "ansible")
+			target, err := os.Readlink(linkPath)
+			require.NoError(t, err)
+			assert.Equal(t, bundleName(tarball), target)
+
+			module, err := os.ReadFile(filepath.Join(linkPath, "modules", "ping.py"))
+			require.NoError(t, err)
+			assert.Equal(t, "current", string(module))
+			assert.NoFileExists(t, filepath.Join(linkPath, "modules", "old.py"))
+
+			stamp, err := os.ReadFile(filepath.Join(bundleDir, rpc.AnsibleHashFile))
+			require.NoError(t, err)
+			assert.Equal(t, checksum+"\n", string(stamp))
+
+			// nothing is left behind by extracting or swapping the symlink.
+			leftovers, err := filepath.Glob(filepath.Join(installDir, ".ansible-extract-*"))
+			require.NoError(t, err)
+			assert.Empty(t, leftovers)
+			leftovers, err = filepath.Glob(filepath.Join(installDir, "ansible.*.tmp"))
+			require.NoError(t, err)
+			assert.Empty(t, leftovers)
+		})
+	}
+}
+
+func TestInstallAnsibleInvalidBundle(t *testing.T) {
+	t.Parallel()
+
+	installDir := t.TempDir()
+	_, err := installAnsible(installDir, []byte("not a tarball"))
+	assert.Error(t, err)
+
+	// the failed extraction isn't used or left behind.
+	entries, err := os.ReadDir(installDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSwapSymlink(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	linkPath := filepath.Join(dir, "ansible")
+
+	require.NoError(t, swapSymlink(linkPath, "ansible-1"))
+	target, err := os.Readlink(linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ansible-1", target)
+
+	require.NoError(t, swapSymlink(linkPath, "ansible-2"))
+	target, err = os.Readlink(linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ansible-2", target)
+
+	// a leftover temporary link from an agent with the same PID is replaced.
+	tmpLink := fmt.Sprintf("%s.%d.tmp", linkPath, os.Getpid())
+	require.NoError(t, os.Symlink("stale", tmpLink))
+	require.NoError(t, swapSymlink(linkPath, "ansible-3"))
+	target, err = os.Readlink(linkPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ansible-3", target)
+	_, err = os.Lstat(tmpLink)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
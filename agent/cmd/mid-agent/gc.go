@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/version"
+)
+
+// InstallDirTouchInterval is how often a running agent marks its version as
+// in use, so that it isn't garbage collected by another version while it runs.
+const InstallDirTouchInterval = time.Minute
+
+// MinGCAfter is the shortest period other agent versions have to go unused
+// before they are garbage collected. Anything shorter risks removing an agent
+// that is about to be started.
+const MinGCAfter = time.Hour
+
+// TouchInstallDir marks the version of this agent as in use by updating the
+// modification time of its install directory.
+func TouchInstallDir() error {
+	now := time.Now()
+	return os.Chtimes(rpc.InstallDir(), now, now)
+}
+
+// KeepInstallDirTouched calls TouchInstallDir every InstallDirTouchInterval
+// for as long as the agent runs.
+func KeepInstallDirTouched(logger *slog.Logger) {
+	ticker := time.NewTicker(InstallDirTouchInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		err := TouchInstallDir()
+		if err != nil {
+			logger.Warn("error marking agent version as in use", slog.Any("error", err))
+		}
+	}
+}
+
+// CollectUnusedVersions removes the install directories of other agent
+// versions in agentDir that haven't been used in gcAfter, along with what
+// agents before side by side versions left in agentDir itself, and returns
+// their names relative to agentDir.
+func CollectUnusedVersions(agentDir string, gcAfter time.Duration) ([]string, error) {
+	current := path.Base(rpc.AgentVersionDir(agentDir, version.Version))
+
+	removed := []string{}
+	var errs error
+	collect := func(dir string, keep func(entry fs.DirEntry) bool) {
+		entries, err := os.ReadDir(path.Join(agentDir, dir))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = errors.Join(errs, err)
+			}
+			return
+		}
+		for _, entry := range entries {
+			if keep(entry) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+			if time.Since(info.ModTime()) < gcAfter {
+				continue
+			}
+			name := path.Join(dir, entry.Name())
+			err = os.RemoveAll(path.Join(agentDir, name))
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+			removed = append(removed, name)
+		}
+	}
+
+	collect(rpc.AgentVersionsDir, func(entry fs.DirEntry) bool {
+		return !entry.IsDir() || entry.Name() == current
+	})
+	collect(".", func(entry fs.DirEntry) bool {
+		return !isLegacyInstall(entry.Name())
+	})
+	return removed, errs
+}
+
+// isLegacyInstall reports whether name is something agents before side by side
+// versions installed straight into the agent directory.
+func isLegacyInstall(name string) bool {
+	switch {
+	case name == "mid-agent" || strings.HasPrefix(name, "mid-agent."):
+		return true
+	case name == "ansible" || strings.HasPrefix(name, "ansible-"):
+		return true
+	case name == "staging":
+		return true
+	}
+	return false
+}
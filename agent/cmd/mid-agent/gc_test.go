@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/version"
+)
+
+func TestCollectUnusedVersions(t *testing.T) {
+	t.Parallel()
+
+	agentDir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	current := filepath.Base(rpc.AgentVersionDir(agentDir, version.Version))
+
+	entries := map[string]struct {
+		dir bool
+		old bool
+	}{
+		"agent/" + current:       {dir: true, old: true},
+		"agent/v0.0.1":           {dir: true, old: true},
+		"agent/v0.0.2":           {dir: true},
+		"mid-agent":              {old: true},
+		"mid-agent.1234":         {old: true},
+		"ansible":                {dir: true, old: true},
+		"ansible-0123456789abcd": {dir: true, old: true},
+		"staging":                {dir: true, old: true},
+		"install.lock":           {old: true},
+		"other":                  {dir: true, old: true},
+	}
+	for name, entry := range entries {
+		p := filepath.Join(agentDir, name)
+		if entry.dir {
+			require.NoError(t, os.MkdirAll(p, 0o700))
+			require.NoError(t, os.WriteFile(filepath.Join(p, "file"), []byte("x"), 0o600))
+		} else {
+			require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+			require.NoError(t, os.WriteFile(p, []byte("x"), 0o600))
+		}
+		if entry.old {
+			require.NoError(t, os.Chtimes(p, old, old))
+		}
+	}
+	// a plain file in the versions directory isn't a version.
+	stray := filepath.Join(agentDir, rpc.AgentVersionsDir, "stray")
+	require.NoError(t, os.WriteFile(stray, []byte("x"), 0o600))
+	require.NoError(t, os.Chtimes(stray, old, old))
+
+	removed, err := CollectUnusedVersions(agentDir, 24*time.Hour)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"agent/v0.0.1",
+		"mid-agent",
+		"mid-agent.1234",
+		"ansible",
+		"ansible-0123456789abcd",
+		"staging",
+	}, removed)
+
+	for _, name := range removed {
+		assert.NoFileExists(t, filepath.Join(agentDir, name))
+		assert.NoDirExists(t, filepath.Join(agentDir, name))
+	}
+	for _, name := range []string{"agent/" + current, "agent/v0.0.2", "install.lock", "other", "agent/stray"} {
+		_, err := os.Lstat(filepath.Join(agentDir, name))
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestCollectUnusedVersionsEmpty(t *testing.T) {
+	t.Parallel()
+
+	removed, err := CollectUnusedVersions(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
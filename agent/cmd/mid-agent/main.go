@@ -5,7 +5,12 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"time"
 
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/sapslaj/mid/agent/rpc"
 	"github.com/sapslaj/mid/agent/rpc/server"
 	"github.com/sapslaj/mid/pkg/log"
 	"github.com/sapslaj/mid/version"
@@ -20,44 +25,139 @@ func main() {
 		}
 	}
 
-	logfile, err := os.OpenFile(".mid-agent.log", os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-	if err != nil {
-		panic(err)
+	instanceUUID := os.Getenv("PULUMI_MID_AGENT_INSTANCE_UUID")
+	level := log.LogLevelFromEnv()
+	handlerOptions := &slog.HandlerOptions{
+		AddSource: true,
+		Level:     level,
 	}
 
-	instanceUUID := os.Getenv("PULUMI_MID_AGENT_INSTANCE_UUID")
+	// the provider asks for logs to be forwarded over the RPC channel, in which
+	// case stderr is only used for whatever can't be forwarded (e.g. panics).
+	// Writing a log file on the remote host is opt-in.
+	var writers []io.Writer
+	forwardLogs := os.Getenv("PULUMI_MID_AGENT_FORWARD_LOGS") == "true"
+	if !forwardLogs {
+		writers = append(writers, os.Stderr)
+	}
+	var logfile *os.File
+	if path := os.Getenv("PULUMI_MID_AGENT_LOG_FILE"); path != "" {
+		var err error
+		logfile, err = os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+		if err != nil {
+			panic(err)
+		}
+		defer logfile.Close()
+		writers = append(writers, logfile)
+	}
+
+	var handler slog.Handler
+	if len(writers) > 0 {
+		handler = slog.NewTextHandler(io.MultiWriter(writers...), handlerOptions)
+	}
+	var logForwarder *server.LogForwarder
+	if forwardLogs {
+		logForwarder = server.NewLogForwarder()
+		handler = logForwarder.Handler(level, handler)
+	}
 
-	logger := slog.New(
-		slog.NewTextHandler(
-			io.MultiWriter(
-				logfile,
-				os.Stderr,
-			),
-			&slog.HandlerOptions{
-				AddSource: true,
-				Level:     log.LogLevelFromEnv(),
-			},
-		),
-	).With(
+	logger := slog.New(handler).With(
 		slog.String("side", "remote"),
 		slog.String("agent.instance.uuid", instanceUUID),
 		slog.Int("agent.remote.pid", os.Getpid()),
 	)
-	defer logfile.Close()
+
+	if dir := os.Getenv(rpc.AgentDirEnv); dir != "" {
+		agentDir, err := rpc.CleanAgentDir(dir)
+		if err != nil {
+			panic(err)
+		}
+		rpc.AgentDir = agentDir
+	}
 
 	logger.Info("installing Ansible package")
-	err = InstallAnsible()
+	ansibleInstall, err := InstallAnsible()
 	if err != nil {
 		panic(err)
 	}
+	logger.Info(
+		"installed Ansible package",
+		slog.String("sha256", ansibleInstall.SHA256Checksum),
+		slog.Bool("cached", ansibleInstall.Cached),
+		slog.Duration("duration", ansibleInstall.Duration),
+	)
+	rpc.AnsibleInstall = &ansibleInstall
+
+	err = TouchInstallDir()
+	if err != nil {
+		logger.Warn("error marking agent version as in use", slog.Any("error", err))
+	}
+	go KeepInstallDirTouched(logger)
+
+	if value := os.Getenv(rpc.AgentGCAfterEnv); value != "" {
+		gcAfter, err := time.ParseDuration(value)
+		switch {
+		case err != nil:
+			logger.Warn(
+				"invalid agent GC period, not collecting unused versions",
+				slog.String("value", value),
+				slog.Any("error", err),
+			)
+		case gcAfter < MinGCAfter:
+			logger.Warn(
+				"agent GC period is too short, not collecting unused versions",
+				slog.Duration("gc_after", gcAfter),
+				slog.Duration("min_gc_after", MinGCAfter),
+			)
+		default:
+			go func() {
+				removed, err := CollectUnusedVersions(rpc.AgentDir, gcAfter)
+				if err != nil {
+					logger.Warn("error collecting unused agent versions", slog.Any("error", err))
+				}
+				if len(removed) > 0 {
+					logger.Info("removed unused agent versions", slog.Any("versions", removed))
+				}
+			}()
+		}
+	}
 
-	logger.Info("starting RPC server")
+	heartbeatTimeout := time.Duration(0)
+	if value := os.Getenv("PULUMI_MID_AGENT_HEARTBEAT_TIMEOUT"); value != "" {
+		heartbeatTimeout, err = time.ParseDuration(value)
+		if err != nil {
+			logger.Warn(
+				"invalid heartbeat timeout, using default",
+				slog.String("value", value),
+				slog.Any("error", err),
+			)
+			heartbeatTimeout = 0
+		}
+	}
+
+	// spans are only recorded for calls that carry a sampled trace context,
+	// which the provider only sends if it has telemetry enabled itself.
+	spanForwarder := server.NewSpanForwarder()
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.NeverSample())),
+		sdktrace.WithSyncer(spanForwarder),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	logger.Info("starting RPC server", slog.Duration("heartbeat_timeout", heartbeatTimeout))
 	defer logger.Info("stopping RPC server")
 	server := &server.Server{
-		Logger: logger,
+		Logger:           logger,
+		HeartbeatTimeout: heartbeatTimeout,
+		LogForwarder:     logForwarder,
+		SpanForwarder:    spanForwarder,
 	}
 	err = server.Start()
 	if err != nil {
-		panic(err)
+		logger.Error("RPC server stopped", slog.Any("error", err))
+		if logfile != nil {
+			logfile.Close()
+		}
+		os.Exit(1)
 	}
 }
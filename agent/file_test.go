@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// testFileData returns size bytes that differ from chunk to chunk.
+func testFileData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+// tempFiles returns the temporary files WriteFile left in dir.
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.mid-*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestWriteFileReadFile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		size int
+	}{
+		"empty":               {size: 0},
+		"small":               {size: 100},
+		"exactly one chunk":   {size: FileChunkSize},
+		"several chunks":      {size: 2*FileChunkSize + FileChunkSize/2},
+		"one byte over chunk": {size: FileChunkSize + 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			agent := newTestAgent(t, rpc.ServerRoute)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			data := testFileData(tc.size)
+			checksum := fmt.Sprintf("%x", sha256.Sum256(data))
+
+			written, err := WriteFile(ctx, agent, path, bytes.NewReader(data), rpc.WriteFileArgs{})
+			require.NoError(t, err)
+			assert.Equal(t, path, written.Path)
+			assert.Equal(t, int64(tc.size), written.Size)
+			assert.Equal(t, checksum, written.SHA256Checksum)
+			assert.Empty(t, tempFiles(t, dir))
+
+			var buf bytes.Buffer
+			read, err := ReadFile(ctx, agent, path, &buf)
+			require.NoError(t, err)
+			assert.Equal(t, path, read.Path)
+			assert.Equal(t, int64(tc.size), read.Size)
+			assert.Equal(t, checksum, read.SHA256Checksum)
+			assert.Empty(t, read.Data)
+			assert.True(t, bytes.Equal(data, buf.Bytes()))
+		})
+	}
+}
+
+func TestReadFileMissing(t *testing.T) {
+	t.Parallel()
+
+	agent := newTestAgent(t, rpc.ServerRoute)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := ReadFile(ctx, agent, filepath.Join(t.TempDir(), "missing"), io.Discard)
+	assert.ErrorIs(t, err, ErrReadingFile)
+	assert.ErrorContains(t, err, "no such file or directory")
+}
+
+// failingReader returns err once all of data has been read.
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestWriteFileAbort(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+	errRemote := errors.New("disk full")
+
+	tests := map[string]struct {
+		reader func() io.Reader
+		// failChunk makes the remote side fail the chunk with this index.
+		failChunk int
+		// cancelChunk cancels the context while the chunk with this index is
+		// being written.
+		cancelChunk int
+		err         error
+	}{
+		"read error": {
+			reader: func() io.Reader {
+				return &failingReader{data: testFileData(FileChunkSize + 10), err: errRead}
+			},
+			err: errRead,
+		},
+
+		"remote error": {
+			reader: func() io.Reader {
+				return bytes.NewReader(testFileData(3 * FileChunkSize))
+			},
+			failChunk: 2,
+			err:       errRemote,
+		},
+
+		"remote error on final chunk": {
+			reader: func() io.Reader {
+				return bytes.NewReader(testFileData(FileChunkSize + 10))
+			},
+			failChunk: 2,
+			err:       errRemote,
+		},
+
+		"context cancelled": {
+			reader: func() io.Reader {
+				return bytes.NewReader(testFileData(3 * FileChunkSize))
+			},
+			cancelChunk: 2,
+			err:         context.Canceled,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			writeCtx, cancelWrite := context.WithCancel(ctx)
+			defer cancelWrite()
+
+			chunks := atomic.Int32{}
+			aborts := atomic.Int32{}
+			agent := newTestAgent(t, func(
+				ctx context.Context,
+				rpcFunction rpc.RPCFunction,
+				args any,
+				stream rpc.StreamFunc,
+			) (any, error) {
+				if rpcFunction == rpc.RPCWriteFile {
+					targs, err := rpc.Decode[rpc.WriteFileArgs](args)
+					require.NoError(t, err)
+					if targs.Abort {
+						aborts.Add(1)
+					} else {
+						chunk := int(chunks.Add(1))
+						if chunk == tc.failChunk {
+							return nil, errRemote
+						}
+						if chunk == tc.cancelChunk {
+							cancelWrite()
+							// give the call a chance to notice before its result arrives.
+							time.Sleep(100 * time.Millisecond)
+						}
+					}
+				}
+				return rpc.ServerRoute(ctx, rpcFunction, args, stream)
+			})
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+			_, err := WriteFile(writeCtx, agent, path, tc.reader(), rpc.WriteFileArgs{})
+			assert.ErrorIs(t, err, ErrWritingFile)
+			// errors from the remote side only come back as strings.
+			assert.ErrorContains(t, err, tc.err.Error())
+
+			assert.Equal(t, int32(1), aborts.Load())
+			assert.Empty(t, tempFiles(t, dir))
+			assert.NoFileExists(t, path)
+		})
+	}
+}
+
+func TestWriteFileFirstChunkFails(t *testing.T) {
+	t.Parallel()
+
+	aborts := atomic.Int32{}
+	agent := newTestAgent(t, func(
+		ctx context.Context,
+		rpcFunction rpc.RPCFunction,
+		args any,
+		stream rpc.StreamFunc,
+	) (any, error) {
+		targs, err := rpc.Decode[rpc.WriteFileArgs](args)
+		require.NoError(t, err)
+		if targs.Abort {
+			aborts.Add(1)
+		}
+		return nil, os.ErrPermission
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := WriteFile(ctx, agent, "/file", bytes.NewReader(testFileData(10)), rpc.WriteFileArgs{})
+	assert.ErrorIs(t, err, ErrWritingFile)
+	assert.ErrorContains(t, err, os.ErrPermission.Error())
+
+	// there's no temporary file to remove before the first chunk is written.
+	assert.Zero(t, aborts.Load())
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sapslaj/mid/version"
+)
+
+// DefaultAgentDir is where the agent, its staging area, and the Ansible bundle
+// live if no directory is configured.
+const DefaultAgentDir = ".mid"
+
+// AgentVersionsDir is the directory within the agent directory that every
+// agent version is installed into side by side.
+const AgentVersionsDir = "agent"
+
+// AgentGCAfterEnv is the environment variable the provider uses to tell the
+// agent how long other agent versions can go unused before it removes them.
+const AgentGCAfterEnv = "PULUMI_MID_AGENT_GC_AFTER"
+
+// AgentDirEnv is the environment variable the provider uses to tell the agent
+// which directory it was installed in.
+const AgentDirEnv = "PULUMI_MID_AGENT_DIR"
+
+// AgentDir is the directory the agent was installed in. It is set by the agent
+// at startup. Relative paths are relative to the home directory of the SSH
+// user, which is the working directory of the agent.
+var AgentDir = DefaultAgentDir
+
+var ErrInvalidAgentDir = errors.New("invalid agent directory")
+
+// CleanAgentDir normalizes an agent directory setting. An empty setting means
+// DefaultAgentDir and a leading "~/" is dropped since relative paths are
+// relative to the home directory already. Since the agent directory is removed
+// wholesale when uninstalling the agent, directories that aren't below the
+// home directory (e.g. "../..") and absolute paths directly below the
+// filesystem root (e.g. "/usr") are rejected.
+func CleanAgentDir(dir string) (string, error) {
+	if dir == "" {
+		return DefaultAgentDir, nil
+	}
+	cleaned := path.Clean(strings.TrimPrefix(dir, "~/"))
+	invalid := false
+	switch {
+	case cleaned == "." || cleaned == "~":
+		invalid = true
+	case cleaned == ".." || strings.HasPrefix(cleaned, "../"):
+		invalid = true
+	case path.IsAbs(cleaned):
+		// at least two elements, e.g. "/opt/mid".
+		invalid = path.Dir(cleaned) == "/"
+	}
+	if invalid {
+		return "", fmt.Errorf("%w: %q", ErrInvalidAgentDir, dir)
+	}
+	return cleaned, nil
+}
+
+// AgentVersionDir returns the directory agentVersion is installed in within
+// agentDir. Every version has its own binary, staging area, and Ansible bundle
+// so that providers of different versions using the same host don't replace
+// each other's agent.
+func AgentVersionDir(agentDir string, agentVersion string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		case r == '.' || r == '-' || r == '_' || r == '+':
+			return r
+		}
+		return '_'
+	}, agentVersion)
+	if name == "" || strings.Trim(name, ".") == "" {
+		name = "_" + name
+	}
+	return path.Join(agentDir, AgentVersionsDir, name)
+}
+
+// InstallDir returns the directory this agent is installed in.
+func InstallDir() string {
+	return AgentVersionDir(AgentDir, version.Version)
+}
@@ -0,0 +1,92 @@
+package rpc_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+func TestCleanAgentDir(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		"empty":                  {input: "", expect: rpc.DefaultAgentDir},
+		"relative":               {input: ".mid", expect: ".mid"},
+		"nested relative":        {input: "tools/mid/", expect: "tools/mid"},
+		"home prefix":            {input: "~/.mid", expect: ".mid"},
+		"absolute":               {input: "/opt/mid", expect: "/opt/mid"},
+		"absolute deeper":        {input: "/var/lib/mid/", expect: "/var/lib/mid"},
+		"cleaned":                {input: "./a/../b//c", expect: "b/c"},
+		"dot dot inside":         {input: "a/../../a/b", err: true},
+		"dot":                    {input: ".", err: true},
+		"home":                   {input: "~", err: true},
+		"home slash":             {input: "~/", err: true},
+		"parent":                 {input: "..", err: true},
+		"grandparent":            {input: "../..", err: true},
+		"outside home":           {input: "../../etc", err: true},
+		"outside home from home": {input: "~/../other", err: true},
+		"root":                   {input: "/", err: true},
+		"root child":             {input: "/usr", err: true},
+		"root child slash":       {input: "/usr/", err: true},
+		"root child cleaned":     {input: "/usr/lib/..", err: true},
+		"root dot dot":           {input: "/../..", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := rpc.CleanAgentDir(tc.input)
+			if tc.err {
+				assert.ErrorIs(t, err, rpc.ErrInvalidAgentDir)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, dir)
+		})
+	}
+}
+
+func TestAgentVersionDir(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		version string
+		expect  string
+	}{
+		"release":         {version: "v0.0.20", expect: "v0.0.20"},
+		"prerelease":      {version: "v0.1.0-rc.1+dirty", expect: "v0.1.0-rc.1+dirty"},
+		"slash":           {version: "feature/thing", expect: "feature_thing"},
+		"parent":          {version: "../../etc", expect: ".._.._etc"},
+		"dot":             {version: ".", expect: "_."},
+		"dot dot":         {version: "..", expect: "_.."},
+		"empty":           {version: "", expect: "_"},
+		"spaces and tabs": {version: "a b\tc", expect: "a_b_c"},
+	}
+
+	agentDir := t.TempDir()
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := rpc.AgentVersionDir(agentDir, tc.version)
+			assert.Equal(t, filepath.Join(agentDir, rpc.AgentVersionsDir, tc.expect), dir)
+
+			// every version gets its own directory within the versions
+			// directory.
+			require.NoError(t, os.MkdirAll(dir, 0o700))
+			rel, err := filepath.Rel(filepath.Join(agentDir, rpc.AgentVersionsDir), dir)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, rel)
+		})
+	}
+}
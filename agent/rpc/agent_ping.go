@@ -1,21 +1,65 @@
 package rpc
 
-import "os"
+import (
+	"os"
+	"time"
+
+	"github.com/sapslaj/mid/version"
+)
+
+// ProtocolVersion is bumped whenever the RPC protocol changes in a way the
+// other side needs to know about. Agents that predate protocol negotiation do
+// not report a version at all, which decodes as 0.
+//
+//   - 1: AgentPing reports versions and supported functions.
+//   - 2: SetEncoding switches the channel to a binary encoding.
+//   - 3: WriteFile and ReadFile transfer files without SFTP.
+//   - 4: agent logs can be forwarded as RPCLogRecords.
+//   - 5: calls carry a trace context and the agent sends back RPCSpans.
+const ProtocolVersion = 5
+
+// AnsibleInstallInfo describes how the agent installed its bundled Ansible
+// modules when it started.
+type AnsibleInstallInfo struct {
+	SHA256Checksum string
+	// Cached is set if the bundle was already extracted and didn't need to be
+	// extracted again.
+	Cached   bool
+	Duration time.Duration
+}
+
+// AnsibleHashFile is written into every extracted Ansible bundle with the
+// SHA-256 of the tarball it came from.
+const AnsibleHashFile = ".mid-bundle-sha256"
+
+// AnsibleInstall is set by the agent at startup and reported in
+// AgentPingResult.
+var AnsibleInstall *AnsibleInstallInfo
 
 type AgentPingArgs struct {
 	Ping string
 }
 
 type AgentPingResult struct {
-	Ping string
-	Pong string
-	Pid  int
+	Ping            string
+	Pong            string
+	Pid             int
+	AgentVersion    string        `json:",omitempty"`
+	ProtocolVersion int           `json:",omitempty"`
+	RPCFunctions    []RPCFunction `json:",omitempty"`
+	// AnsibleInstall is how the agent installed its bundled Ansible modules on
+	// startup.
+	AnsibleInstall *AnsibleInstallInfo `json:",omitempty"`
 }
 
 func AgentPing(args AgentPingArgs) (AgentPingResult, error) {
 	return AgentPingResult{
-		Ping: args.Ping,
-		Pong: "pong",
-		Pid:  os.Getpid(),
+		Ping:            args.Ping,
+		Pong:            "pong",
+		Pid:             os.Getpid(),
+		AgentVersion:    version.Version,
+		ProtocolVersion: ProtocolVersion,
+		RPCFunctions:    RPCFunctions,
+		AnsibleInstall:  AnsibleInstall,
 	}, nil
 }
@@ -1,12 +1,23 @@
 package rpc
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sapslaj/mid/pkg/cast"
 )
 
 type AnsibleExecuteArgs struct {
@@ -15,6 +26,38 @@ type AnsibleExecuteArgs struct {
 	Environment        map[string]string
 	Check              bool
 	DebugKeepTempFiles bool
+	// Timeout is the maximum time the module is allowed to run. See
+	// ExecArgs.Timeout.
+	Timeout time.Duration `json:",omitempty"`
+	// PythonInterpreter is the interpreter to run the module with. If empty one
+	// is found with DiscoverPythonInterpreter.
+	PythonInterpreter string `json:",omitempty"`
+	// RunAs is the user, groups, and environment the module is run with.
+	RunAs
+}
+
+// GobEncode normalizes Args to plain JSON values before encoding, since gob
+// can only encode values in a map[string]any if their types were registered
+// ahead of time.
+func (args AnsibleExecuteArgs) GobEncode() ([]byte, error) {
+	type plain AnsibleExecuteArgs
+	normalized, err := cast.AnyToJSONT[map[string]any](args.Args)
+	if err != nil {
+		return nil, err
+	}
+	wire := plain(args)
+	wire.Args = normalized
+	var buf bytes.Buffer
+	err = gob.NewEncoder(&buf).Encode(wire)
+	return buf.Bytes(), err
+}
+
+func (args *AnsibleExecuteArgs) GobDecode(data []byte) error {
+	type plain AnsibleExecuteArgs
+	var wire plain
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&wire)
+	*args = AnsibleExecuteArgs(wire)
+	return err
 }
 
 type AnsibleExecuteResult struct {
@@ -24,9 +67,50 @@ type AnsibleExecuteResult struct {
 	Success      bool
 	Result       map[string]any
 	DebugTempDir string
+	// PythonInterpreter is the path of the interpreter the module ran with.
+	PythonInterpreter string `json:",omitempty"`
 }
 
 func AnsibleExecute(args AnsibleExecuteArgs) (AnsibleExecuteResult, error) {
+	return AnsibleExecuteStream(context.Background(), args, nil)
+}
+
+// AnsibleExecuteStream is the same as AnsibleExecute except that the module's
+// output is also sent line-by-line to stream as it runs.
+func AnsibleExecuteStream(
+	ctx context.Context,
+	args AnsibleExecuteArgs,
+	stream StreamFunc,
+) (AnsibleExecuteResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent/rpc.AnsibleExecute", trace.WithAttributes(
+		attribute.String("ansible.module", args.Name),
+		attribute.Bool("ansible.check", args.Check),
+		attribute.String("ansible.user", args.User),
+	))
+	defer span.End()
+
+	result, err := ansibleExecuteStream(ctx, args, stream)
+	span.SetAttributes(
+		attribute.Int("ansible.exit_code", result.ExitCode),
+		attribute.Bool("ansible.success", result.Success),
+		attribute.String("ansible.python_interpreter", result.PythonInterpreter),
+	)
+	if changed, ok := result.Result["changed"].(bool); ok {
+		span.SetAttributes(attribute.Bool("ansible.changed", changed))
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return result, err
+}
+
+func ansibleExecuteStream(
+	ctx context.Context,
+	args AnsibleExecuteArgs,
+	stream StreamFunc,
+) (AnsibleExecuteResult, error) {
 	result := AnsibleExecuteResult{}
 
 	tmpdir, err := os.MkdirTemp(os.TempDir(), "mid-ansible-"+args.Name+"-"+strings.ToLower(rand.Text()))
@@ -39,6 +123,26 @@ func AnsibleExecute(args AnsibleExecuteArgs) (AnsibleExecuteResult, error) {
 		defer os.RemoveAll(tmpdir)
 	}
 
+	// another user can't get into the agent directory, so the module gets a
+	// copy of Ansible it can read and a temp directory it can write to.
+	ansibleDir := path.Join(InstallDir(), "ansible")
+	if !args.RunAs.IsZero() {
+		cred, err := args.RunAs.Resolve()
+		if err != nil {
+			return result, errors.Join(ErrRunAs, err)
+		}
+		if !cred.IsAgentUser() {
+			ansibleDir, err = SharedAnsibleDir()
+			if err != nil {
+				return result, fmt.Errorf("error sharing Ansible package: %w", err)
+			}
+			err = os.Chown(tmpdir, int(cred.Uid), int(cred.Gid))
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
 	args.Args["_ansible_check_mode"] = args.Check
 	args.Args["_ansible_no_log"] = false
 	args.Args["_ansible_debug"] = false
@@ -66,17 +170,24 @@ func AnsibleExecute(args AnsibleExecuteArgs) (AnsibleExecuteResult, error) {
 		return result, err
 	}
 
-	// TODO: support supplying Python3 location
-	execResult, err := Exec(ExecArgs{
+	python, err := DiscoverPythonInterpreter(ctx, args.PythonInterpreter)
+	if err != nil {
+		return result, err
+	}
+	result.PythonInterpreter = python.Path
+
+	execResult, err := ExecStream(ctx, ExecArgs{
 		Command: []string{
-			"python3",
+			python.Path,
 			"-m",
 			"ansible.modules." + args.Name,
 			string(dataEncoded),
 		},
 		Environment: args.Environment,
-		Dir:         path.Join(".mid", "ansible"),
-	})
+		Dir:         ansibleDir,
+		Timeout:     args.Timeout,
+		RunAs:       args.RunAs,
+	}, stream)
 	result.Stderr = execResult.Stderr
 	result.Stdout = execResult.Stdout
 	result.ExitCode = execResult.ExitCode
@@ -0,0 +1,10 @@
+package rpc
+
+type CancelArgs struct {
+	UUID string
+}
+
+type CancelResult struct {
+	UUID      string
+	Cancelled bool
+}
@@ -0,0 +1,255 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sapslaj/mid/pkg/cast"
+)
+
+// Encoding is the wire format used for calls and results on the agent channel.
+type Encoding string
+
+const (
+	// EncodingJSON is newline-delimited JSON. Every connection starts out using
+	// it and it is kept around as a human-readable fallback for debugging.
+	EncodingJSON Encoding = "json"
+	// EncodingGob is a binary stream of gob messages. []byte values are sent
+	// as-is instead of being base64 encoded.
+	EncodingGob Encoding = "gob"
+)
+
+var ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+func init() {
+	// AnsibleExecuteResult.Result and the normalized AnsibleExecuteArgs.Args
+	// only ever contain values decoded from JSON.
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+}
+
+// RawMessage is an Args or Result value that has been encoded separately from
+// the rest of the call or result so that it can be decoded straight into its
+// concrete type once that is known. In JSON it is inlined as-is.
+type RawMessage []byte
+
+func (m RawMessage) MarshalJSON() ([]byte, error) {
+	return json.RawMessage(m).MarshalJSON()
+}
+
+func (m *RawMessage) UnmarshalJSON(data []byte) error {
+	return (*json.RawMessage)(m).UnmarshalJSON(data)
+}
+
+// Payload is an Args or Result value as it was received from the wire, before
+// it has been decoded into its concrete type. Use Decode to do that.
+type Payload struct {
+	Encoding Encoding
+	Data     RawMessage
+}
+
+// MarshalJSON makes payloads readable in logs and traces.
+func (payload Payload) MarshalJSON() ([]byte, error) {
+	if payload.Encoding == EncodingJSON && len(payload.Data) > 0 {
+		return payload.Data, nil
+	}
+	return json.Marshal(map[string]any{
+		"Encoding": payload.Encoding,
+		"Size":     len(payload.Data),
+	})
+}
+
+// Marshal encodes a single Args or Result value.
+func Marshal(encoding Encoding, v any) (RawMessage, error) {
+	switch encoding {
+	case EncodingJSON:
+		return json.Marshal(v)
+	case EncodingGob:
+		if v == nil {
+			return nil, nil
+		}
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+// Unmarshal decodes a single Args or Result value produced by Marshal. Empty
+// data leaves v untouched.
+func Unmarshal(encoding Encoding, data RawMessage, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	switch encoding {
+	case EncodingJSON:
+		return json.Unmarshal(data, v)
+	case EncodingGob:
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+	}
+	return fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+// Decode converts an Args or Result value into T. Payloads are decoded
+// directly from their wire encoding, anything else is converted by way of
+// JSON.
+func Decode[T any](v any) (T, error) {
+	var result T
+	switch v := v.(type) {
+	case nil:
+		return result, nil
+	case T:
+		return v, nil
+	case Payload:
+		err := Unmarshal(v.Encoding, v.Data, &result)
+		return result, err
+	}
+	return cast.AnyToJSONT[T](v)
+}
+
+// Encoder writes calls and results to the agent channel. It is not safe for
+// concurrent use.
+type Encoder struct {
+	encoding Encoding
+	encoder  interface{ Encode(v any) error }
+}
+
+func NewEncoder(encoding Encoding, w io.Writer) (*Encoder, error) {
+	switch encoding {
+	case EncodingJSON:
+		return &Encoder{encoding: encoding, encoder: json.NewEncoder(w)}, nil
+	case EncodingGob:
+		return &Encoder{encoding: encoding, encoder: gob.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+func (e *Encoder) Encoding() Encoding {
+	return e.encoding
+}
+
+func (e *Encoder) EncodeCall(call RPCCall[any]) error {
+	args, err := Marshal(e.encoding, call.Args)
+	if err != nil {
+		return err
+	}
+	return e.encoder.Encode(RPCCall[RawMessage]{
+		UUID:         call.UUID,
+		RPCFunction:  call.RPCFunction,
+		Args:         args,
+		Stream:       call.Stream,
+		TraceContext: call.TraceContext,
+	})
+}
+
+func (e *Encoder) EncodeResult(result RPCResult[any]) error {
+	var data RawMessage
+	if result.Chunk == nil && result.Log == nil && result.Spans == nil {
+		var err error
+		data, err = Marshal(e.encoding, result.Result)
+		if err != nil {
+			return err
+		}
+	}
+	return e.encoder.Encode(RPCResult[RawMessage]{
+		UUID:        result.UUID,
+		RPCFunction: result.RPCFunction,
+		Result:      data,
+		Error:       result.Error,
+		Chunk:       result.Chunk,
+		Log:         result.Log,
+		Spans:       result.Spans,
+	})
+}
+
+// Decoder reads calls and results from the agent channel. Args and Result are
+// returned as Payloads. It is not safe for concurrent use.
+type Decoder struct {
+	encoding Encoding
+	decoder  interface{ Decode(v any) error }
+	buffered func() io.Reader
+}
+
+func NewDecoder(encoding Encoding, r io.Reader) (*Decoder, error) {
+	switch encoding {
+	case EncodingJSON:
+		decoder := json.NewDecoder(r)
+		return &Decoder{encoding: encoding, decoder: decoder, buffered: decoder.Buffered}, nil
+	case EncodingGob:
+		return &Decoder{encoding: encoding, decoder: gob.NewDecoder(r)}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedEncoding, encoding)
+}
+
+func (d *Decoder) Encoding() Encoding {
+	return d.encoding
+}
+
+// Switch returns a Decoder for a different encoding that continues reading from
+// r where this one left off, including any data it has already buffered.
+// Switching is only supported away from EncodingJSON.
+func (d *Decoder) Switch(encoding Encoding, r io.Reader) (*Decoder, error) {
+	if d.buffered == nil {
+		return nil, fmt.Errorf("%w: cannot switch from %q", ErrUnsupportedEncoding, d.encoding)
+	}
+	return NewDecoder(encoding, &newlineSkipper{
+		reader: io.MultiReader(d.buffered(), r),
+	})
+}
+
+// newlineSkipper drops the newline json.Encoder writes after every value, which
+// is left unread after the last JSON message before an encoding switch.
+type newlineSkipper struct {
+	reader  io.Reader
+	skipped bool
+}
+
+func (s *newlineSkipper) Read(p []byte) (int, error) {
+	for !s.skipped {
+		var b [1]byte
+		n, err := s.reader.Read(b[:])
+		if n == 1 {
+			if b[0] != '\n' {
+				return 0, fmt.Errorf("expected newline before encoding switch, got %q", b[0])
+			}
+			s.skipped = true
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return s.reader.Read(p)
+}
+
+func (d *Decoder) DecodeCall() (RPCCall[any], error) {
+	var call RPCCall[RawMessage]
+	err := d.decoder.Decode(&call)
+	return RPCCall[any]{
+		UUID:         call.UUID,
+		RPCFunction:  call.RPCFunction,
+		Args:         Payload{Encoding: d.encoding, Data: call.Args},
+		Stream:       call.Stream,
+		TraceContext: call.TraceContext,
+	}, err
+}
+
+func (d *Decoder) DecodeResult() (RPCResult[any], error) {
+	var result RPCResult[RawMessage]
+	err := d.decoder.Decode(&result)
+	return RPCResult[any]{
+		UUID:        result.UUID,
+		RPCFunction: result.RPCFunction,
+		Result:      Payload{Encoding: d.encoding, Data: result.Result},
+		Error:       result.Error,
+		Chunk:       result.Chunk,
+		Log:         result.Log,
+		Spans:       result.Spans,
+	}, err
+}
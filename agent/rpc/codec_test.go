@@ -0,0 +1,282 @@
+package rpc_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input any
+		into  func() any
+	}{
+		"exec args": {
+			input: rpc.ExecArgs{
+				Command:     []string{"sh", "-c", "cat"},
+				Environment: map[string]string{"FOO": "bar"},
+				Stdin:       []byte{0, 1, 2, 0xff},
+			},
+			into: func() any { return &rpc.ExecArgs{} },
+		},
+
+		"exec result": {
+			input: rpc.ExecResult{Stdout: []byte("out\n"), Stderr: []byte("err\n"), ExitCode: 3, Pid: 42},
+			into:  func() any { return &rpc.ExecResult{} },
+		},
+	}
+
+	for name, tc := range tests {
+		for _, encoding := range []rpc.Encoding{rpc.EncodingJSON, rpc.EncodingGob} {
+			t.Run(name+"/"+string(encoding), func(t *testing.T) {
+				t.Parallel()
+
+				data, err := rpc.Marshal(encoding, tc.input)
+				require.NoError(t, err)
+				into := tc.into()
+				require.NoError(t, rpc.Unmarshal(encoding, data, into))
+				assert.Equal(t, tc.input, reflect.ValueOf(into).Elem().Interface())
+			})
+		}
+	}
+}
+
+func TestMarshalUnsupportedEncoding(t *testing.T) {
+	t.Parallel()
+
+	_, err := rpc.Marshal("xml", rpc.ExecArgs{})
+	assert.ErrorIs(t, err, rpc.ErrUnsupportedEncoding)
+	err = rpc.Unmarshal("xml", rpc.RawMessage("x"), &rpc.ExecArgs{})
+	assert.ErrorIs(t, err, rpc.ErrUnsupportedEncoding)
+	_, err = rpc.NewEncoder("xml", io.Discard)
+	assert.ErrorIs(t, err, rpc.ErrUnsupportedEncoding)
+	_, err = rpc.NewDecoder("xml", bytes.NewReader(nil))
+	assert.ErrorIs(t, err, rpc.ErrUnsupportedEncoding)
+}
+
+func TestAnsibleExecuteArgsGob(t *testing.T) {
+	t.Parallel()
+
+	args := rpc.AnsibleExecuteArgs{
+		Name: "ansible.builtin.copy",
+		Args: map[string]any{
+			"dest":    "/etc/motd",
+			"mode":    0o644,
+			"backup":  true,
+			"content": []string{"a", "b"},
+			"nested": map[string]any{
+				"list": []any{1, "two", map[string]any{"three": 3.5}},
+				"map":  map[string]string{"k": "v"},
+			},
+		},
+		Environment: map[string]string{"LANG": "C"},
+	}
+
+	data, err := rpc.Marshal(rpc.EncodingGob, args)
+	require.NoError(t, err)
+	decoded, err := rpc.Decode[rpc.AnsibleExecuteArgs](rpc.Payload{Encoding: rpc.EncodingGob, Data: data})
+	require.NoError(t, err)
+
+	// Args comes back as the plain JSON values it was normalized to.
+	assert.Equal(t, map[string]any{
+		"dest":    "/etc/motd",
+		"mode":    float64(0o644),
+		"backup":  true,
+		"content": []any{"a", "b"},
+		"nested": map[string]any{
+			"list": []any{float64(1), "two", map[string]any{"three": 3.5}},
+			"map":  map[string]any{"k": "v"},
+		},
+	}, decoded.Args)
+	assert.Equal(t, args.Name, decoded.Name)
+	assert.Equal(t, args.Environment, decoded.Environment)
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := rpc.Decode[rpc.ExecResult](nil)
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ExecResult{}, result)
+	})
+
+	t.Run("same type", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := rpc.Decode[rpc.ExecResult](rpc.ExecResult{ExitCode: 1})
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ExecResult{ExitCode: 1}, result)
+	})
+
+	t.Run("by way of JSON", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := rpc.Decode[rpc.ExecResult](map[string]any{"ExitCode": 2})
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ExecResult{ExitCode: 2}, result)
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := rpc.Decode[rpc.ExecResult](rpc.Payload{Encoding: rpc.EncodingGob})
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ExecResult{}, result)
+	})
+}
+
+// writeSwitchedStream writes a SetEncoding call in JSON followed by calls in
+// gob, the way a client switches encodings.
+func writeSwitchedStream(w io.Writer, calls []rpc.RPCCall[any]) error {
+	jsonEncoder, err := rpc.NewEncoder(rpc.EncodingJSON, w)
+	if err != nil {
+		return err
+	}
+	err = jsonEncoder.EncodeCall(rpc.RPCCall[any]{
+		UUID:        "switch",
+		RPCFunction: rpc.RPCSetEncoding,
+		Args:        rpc.SetEncodingArgs{Encoding: rpc.EncodingGob},
+	})
+	if err != nil {
+		return err
+	}
+	gobEncoder, err := rpc.NewEncoder(rpc.EncodingGob, w)
+	if err != nil {
+		return err
+	}
+	for _, call := range calls {
+		err = gobEncoder.EncodeCall(call)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readSwitchedStream reads what writeSwitchedStream wrote.
+func readSwitchedStream(t *testing.T, r io.Reader, n int) []rpc.ExecArgs {
+	t.Helper()
+
+	decoder, err := rpc.NewDecoder(rpc.EncodingJSON, r)
+	require.NoError(t, err)
+	call, err := decoder.DecodeCall()
+	require.NoError(t, err)
+	require.Equal(t, rpc.RPCSetEncoding, call.RPCFunction)
+	setEncoding, err := rpc.Decode[rpc.SetEncodingArgs](call.Args)
+	require.NoError(t, err)
+
+	decoder, err = decoder.Switch(setEncoding.Encoding, r)
+	require.NoError(t, err)
+	assert.Equal(t, rpc.EncodingGob, decoder.Encoding())
+
+	args := []rpc.ExecArgs{}
+	for range n {
+		call, err := decoder.DecodeCall()
+		require.NoError(t, err)
+		require.Equal(t, rpc.RPCExec, call.RPCFunction)
+		execArgs, err := rpc.Decode[rpc.ExecArgs](call.Args)
+		require.NoError(t, err)
+		args = append(args, execArgs)
+	}
+	return args
+}
+
+func TestDecoderSwitch(t *testing.T) {
+	t.Parallel()
+
+	calls := []rpc.RPCCall[any]{}
+	expect := []rpc.ExecArgs{}
+	for _, stdin := range [][]byte{[]byte("first"), {'\n', 0, '\n'}, bytes.Repeat([]byte{'x'}, 64*1024)} {
+		args := rpc.ExecArgs{Command: []string{"cat"}, Stdin: stdin}
+		calls = append(calls, rpc.RPCCall[any]{UUID: "exec", RPCFunction: rpc.RPCExec, Args: args})
+		expect = append(expect, args)
+	}
+
+	t.Run("buffered", func(t *testing.T) {
+		t.Parallel()
+
+		// the JSON decoder reads ahead into the gob messages.
+		var buf bytes.Buffer
+		require.NoError(t, writeSwitchedStream(&buf, calls))
+		assert.Equal(t, expect, readSwitchedStream(t, &buf, len(calls)))
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		t.Parallel()
+
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(writeSwitchedStream(w, calls))
+		}()
+		assert.Equal(t, expect, readSwitchedStream(t, r, len(calls)))
+	})
+
+	t.Run("missing newline", func(t *testing.T) {
+		t.Parallel()
+
+		decoder, err := rpc.NewDecoder(rpc.EncodingJSON, bytes.NewReader([]byte(`{"UUID":"a"}x`)))
+		require.NoError(t, err)
+		_, err = decoder.DecodeCall()
+		require.NoError(t, err)
+		decoder, err = decoder.Switch(rpc.EncodingGob, bytes.NewReader(nil))
+		require.NoError(t, err)
+		_, err = decoder.DecodeCall()
+		assert.ErrorContains(t, err, "expected newline")
+	})
+
+	t.Run("from gob", func(t *testing.T) {
+		t.Parallel()
+
+		decoder, err := rpc.NewDecoder(rpc.EncodingGob, bytes.NewReader(nil))
+		require.NoError(t, err)
+		_, err = decoder.Switch(rpc.EncodingJSON, bytes.NewReader(nil))
+		assert.ErrorIs(t, err, rpc.ErrUnsupportedEncoding)
+	})
+}
+
+func TestEncodeResultStreamed(t *testing.T) {
+	t.Parallel()
+
+	for _, encoding := range []rpc.Encoding{rpc.EncodingJSON, rpc.EncodingGob} {
+		t.Run(string(encoding), func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			encoder, err := rpc.NewEncoder(encoding, &buf)
+			require.NoError(t, err)
+			require.NoError(t, encoder.EncodeResult(rpc.RPCResult[any]{
+				UUID:  "a",
+				Chunk: &rpc.RPCStreamChunk{Stdout: []byte("partial")},
+			}))
+			require.NoError(t, encoder.EncodeResult(rpc.RPCResult[any]{
+				UUID:   "a",
+				Result: rpc.ExecResult{Stdout: []byte("partial"), ExitCode: 1},
+			}))
+
+			decoder, err := rpc.NewDecoder(encoding, &buf)
+			require.NoError(t, err)
+			chunk, err := decoder.DecodeResult()
+			require.NoError(t, err)
+			require.NotNil(t, chunk.Chunk)
+			assert.Equal(t, []byte("partial"), chunk.Chunk.Stdout)
+
+			final, err := decoder.DecodeResult()
+			require.NoError(t, err)
+			assert.Nil(t, final.Chunk)
+			result, err := rpc.Decode[rpc.ExecResult](final.Result)
+			require.NoError(t, err)
+			assert.Equal(t, rpc.ExecResult{Stdout: []byte("partial"), ExitCode: 1}, result)
+		})
+	}
+}
@@ -2,9 +2,19 @@ package rpc
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ExecArgs struct {
@@ -13,6 +23,12 @@ type ExecArgs struct {
 	Environment        map[string]string
 	Stdin              []byte
 	ExpandArgumentVars bool
+	// Timeout is the maximum time the command is allowed to run. When exceeded
+	// the process group is sent SIGTERM, followed by SIGKILL after
+	// TimeoutKillDelay. Zero means no timeout.
+	Timeout time.Duration `json:",omitempty"`
+	// RunAs is the user, groups, and environment the command is run with.
+	RunAs
 }
 
 type ExecResult struct {
@@ -22,18 +38,83 @@ type ExecResult struct {
 	Pid      int
 }
 
+// TimeoutKillDelay is how long a timed out process group has to exit after
+// SIGTERM before it is sent SIGKILL.
+const TimeoutKillDelay = 10 * time.Second
+
 func Exec(args ExecArgs) (ExecResult, error) {
+	return ExecStream(context.Background(), args, nil)
+}
+
+// ExecStream is the same as Exec except that stdout and stderr are also sent
+// line-by-line to stream as the process produces them. If ctx is cancelled the
+// whole process group of the command is killed and ErrCancelled is returned
+// along with any output produced so far. The same goes for ErrTimeout if
+// args.Timeout is exceeded.
+func ExecStream(ctx context.Context, args ExecArgs, stream StreamFunc) (ExecResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent/rpc.Exec", trace.WithAttributes(
+		attribute.StringSlice("exec.command", args.Command),
+		attribute.String("exec.dir", args.Dir),
+		attribute.Bool("exec.stream", stream != nil),
+		attribute.String("exec.user", args.User),
+	))
+	defer span.End()
+
+	result, err := execStream(ctx, span, args, stream)
+	span.SetAttributes(
+		attribute.Int("exec.exit_code", result.ExitCode),
+		attribute.Int("exec.stdout_bytes", len(result.Stdout)),
+		attribute.Int("exec.stderr_bytes", len(result.Stderr)),
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return result, err
+}
+
+func execStream(ctx context.Context, span trace.Span, args ExecArgs, stream StreamFunc) (ExecResult, error) {
 	if len(args.Command) == 0 {
 		return ExecResult{}, errors.New("no command specified")
 	}
 
+	if args.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, args.Timeout, ErrTimeout)
+		defer cancel()
+	}
+
+	env := os.Environ()
+	var cred *Credential
+	if !args.RunAs.IsZero() {
+		resolved, err := args.RunAs.Resolve()
+		if err != nil {
+			return ExecResult{ExitCode: -1}, errors.Join(ErrRunAs, err)
+		}
+		cred = &resolved
+		if args.LoginEnv {
+			env = cred.LoginEnv()
+			// users like nobody have a home directory that doesn't exist.
+			if info, err := os.Stat(cred.HomeDir); args.Dir == "" && err == nil && info.IsDir() {
+				args.Dir = cred.HomeDir
+			}
+		}
+	}
+
 	if args.ExpandArgumentVars {
 		mapping := func(key string) string {
 			value, ok := args.Environment[key]
 			if ok {
 				return value
 			}
-			return os.Getenv(key)
+			for _, kv := range env {
+				value, ok = strings.CutPrefix(kv, key+"=")
+				if ok {
+					return value
+				}
+			}
+			return ""
 		}
 
 		for i := range args.Command {
@@ -44,16 +125,77 @@ func Exec(args ExecArgs) (ExecResult, error) {
 	stdin := bytes.NewReader(args.Stdin)
 	var stdout bytes.Buffer
 	var stderr bytes.Buffer
-	cmd := exec.Command(args.Command[0], args.Command[1:]...)
+	cmd := exec.CommandContext(ctx, args.Command[0], args.Command[1:]...)
+	setProcessGroup(ctx, cmd)
+	if cred != nil && !cred.IsAgentUser() {
+		err := setCredential(cmd, *cred)
+		if err != nil {
+			return ExecResult{ExitCode: -1}, err
+		}
+	}
+	cmd.WaitDelay = TimeoutKillDelay + 5*time.Second
 	cmd.Dir = args.Dir
 	cmd.Stdin = stdin
 	cmd.Stderr = &stderr
 	cmd.Stdout = &stdout
-	cmd.Env = os.Environ()
+	cmd.Env = env
 	for key, value := range args.Environment {
 		cmd.Env = append(cmd.Env, key+"="+value)
 	}
-	err := cmd.Run()
+
+	var stdoutLines *lineWriter
+	var stderrLines *lineWriter
+	if stream != nil {
+		mutex := &sync.Mutex{}
+		stdoutLines = &lineWriter{
+			mutex: mutex,
+			emit: func(line []byte) {
+				stream(RPCStreamChunk{Stdout: line})
+			},
+		}
+		stderrLines = &lineWriter{
+			mutex: mutex,
+			emit: func(line []byte) {
+				stream(RPCStreamChunk{Stderr: line})
+			},
+		}
+		cmd.Stdout = io.MultiWriter(&stdout, stdoutLines)
+		cmd.Stderr = io.MultiWriter(&stderr, stderrLines)
+	}
+
+	// starting and waiting are split up so that the trace shows how long it
+	// took to get the process going separately from how long it ran.
+	err := cmd.Start()
+	if err == nil {
+		span.AddEvent("process started", trace.WithAttributes(
+			attribute.Int("exec.pid", cmd.Process.Pid),
+		))
+		err = cmd.Wait()
+	}
+
+	if stream != nil {
+		stdoutLines.Flush()
+		stderrLines.Flush()
+	}
+
+	if ctx.Err() != nil {
+		exitCode := -1
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		}
+		err = context.Cause(ctx)
+		if errors.Is(err, ErrTimeout) {
+			err = fmt.Errorf("%w after %s", ErrTimeout, args.Timeout)
+		} else if !errors.Is(err, ErrCancelled) {
+			err = fmt.Errorf("%w: %w", ErrCancelled, err)
+		}
+		return ExecResult{
+			Stdout:   stdout.Bytes(),
+			Stderr:   stderr.Bytes(),
+			ExitCode: exitCode,
+		}, err
+	}
+
 	if err != nil {
 		_, isExitError := err.(*exec.ExitError)
 		if !isExitError {
@@ -71,3 +213,44 @@ func Exec(args ExecArgs) (ExecResult, error) {
 		Pid:      cmd.ProcessState.Pid(),
 	}, nil
 }
+
+// lineWriter buffers writes and calls emit once for every complete line. The
+// mutex is shared between the stdout and stderr writers of a process so that
+// lines are emitted one at a time.
+type lineWriter struct {
+	mutex *sync.Mutex
+	buf   []byte
+	emit  func(line []byte)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := make([]byte, i+1)
+		copy(line, w.buf[:i+1])
+		w.buf = w.buf[i+1:]
+		w.emit(line)
+	}
+
+	return len(p), nil
+}
+
+// Flush emits any remaining partial line.
+func (w *lineWriter) Flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.buf) == 0 {
+		return
+	}
+	line := w.buf
+	w.buf = nil
+	w.emit(line)
+}
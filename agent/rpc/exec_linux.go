@@ -0,0 +1,49 @@
+//go:build linux
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts the command in its own process group and makes
+// context cancellation kill the entire group instead of just the direct child.
+// Timed out commands get SIGTERM first and SIGKILL after TimeoutKillDelay.
+func setProcessGroup(ctx context.Context, cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		if !errors.Is(context.Cause(ctx), ErrTimeout) {
+			return syscall.Kill(pgid, syscall.SIGKILL)
+		}
+		time.AfterFunc(TimeoutKillDelay, func() {
+			syscall.Kill(pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+}
+
+// setCredential makes the command run with the user and groups of cred.
+func setCredential(cmd *exec.Cmd, cred Credential) error {
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    cred.Uid,
+		Gid:    cred.Gid,
+		Groups: cred.Groups,
+	}
+	return nil
+}
+
+// ownedByAgentUser reports whether the file described by info belongs to the
+// agent's user.
+func ownedByAgentUser(info fs.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
@@ -0,0 +1,110 @@
+//go:build linux
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// processRunning reports whether the process with the given PID exists and
+// hasn't exited. Zombies count as exited since whoever reaps them might not be
+// around to do so in a container.
+func processRunning(pid int) bool {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// the state comes right after the command name, which is in parentheses.
+	i := strings.LastIndexByte(string(stat), ')')
+	if i < 0 || i+2 >= len(stat) {
+		return false
+	}
+	return stat[i+2] != 'Z'
+}
+
+func TestExecStreamKillsProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cancel  bool
+		timeout time.Duration
+		err     error
+	}{
+		"cancelled": {
+			cancel: true,
+			err:    ErrCancelled,
+		},
+
+		"timed out": {
+			timeout: 500 * time.Millisecond,
+			err:     ErrTimeout,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+
+			// the shell prints the PID of its background child and waits on it, so
+			// the sleep is a grandchild of the agent.
+			pids := make(chan int, 1)
+			type execReturn struct {
+				result ExecResult
+				err    error
+			}
+			returned := make(chan execReturn, 1)
+			start := time.Now()
+			go func() {
+				result, err := ExecStream(ctx, ExecArgs{
+					Command: []string{"/bin/sh", "-c", "sleep 100 & echo $!; wait"},
+					Timeout: tc.timeout,
+				}, func(chunk RPCStreamChunk) {
+					pid, err := strconv.Atoi(strings.TrimSpace(string(chunk.Stdout)))
+					if err == nil {
+						pids <- pid
+					}
+				})
+				returned <- execReturn{result: result, err: err}
+			}()
+
+			var pid int
+			select {
+			case pid = <-pids:
+			case <-time.After(10 * time.Second):
+				t.Fatal("command didn't start")
+			}
+			require.True(t, processRunning(pid))
+
+			if tc.cancel {
+				// the same as the server does when it receives a Cancel call.
+				cancel(ErrCancelled)
+			}
+
+			select {
+			case ret := <-returned:
+				assert.ErrorIs(t, ret.err, tc.err)
+				assert.Equal(t, -1, ret.result.ExitCode)
+			case <-time.After(5 * time.Second):
+				t.Fatal("command didn't return after being stopped")
+			}
+			// well short of both the sleep and TimeoutKillDelay.
+			assert.Less(t, time.Since(start), 5*time.Second)
+
+			assert.Eventually(t, func() bool {
+				return !processRunning(pid)
+			}, 5*time.Second, 50*time.Millisecond, "grandchild %d is still running", pid)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"runtime"
+)
+
+func setProcessGroup(ctx context.Context, cmd *exec.Cmd) {}
+
+func setCredential(cmd *exec.Cmd, cred Credential) error {
+	return fmt.Errorf("%w: not supported on %s", ErrRunAs, runtime.GOOS)
+}
+
+func ownedByAgentUser(info fs.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		writes []string
+		lines  []string
+		flush  []string
+	}{
+		"single line": {
+			writes: []string{"hello\n"},
+			lines:  []string{"hello\n"},
+		},
+
+		"several lines in one write": {
+			writes: []string{"one\ntwo\nthree\n"},
+			lines:  []string{"one\n", "two\n", "three\n"},
+		},
+
+		"line split across writes": {
+			writes: []string{"he", "ll", "o\nwor", "ld\n"},
+			lines:  []string{"hello\n", "world\n"},
+		},
+
+		"empty lines": {
+			writes: []string{"\n\na\n"},
+			lines:  []string{"\n", "\n", "a\n"},
+		},
+
+		"trailing partial line": {
+			writes: []string{"one\ntw", "o"},
+			lines:  []string{"one\n"},
+			flush:  []string{"two"},
+		},
+
+		"only a partial line": {
+			writes: []string{"no newline"},
+			flush:  []string{"no newline"},
+		},
+
+		"nothing written": {},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := []string{}
+			w := &lineWriter{
+				mutex: &sync.Mutex{},
+				emit: func(line []byte) {
+					lines = append(lines, string(line))
+				},
+			}
+			for _, data := range tc.writes {
+				n, err := w.Write([]byte(data))
+				require.NoError(t, err)
+				assert.Equal(t, len(data), n)
+			}
+			assert.Equal(t, append([]string{}, tc.lines...), lines)
+
+			w.Flush()
+			assert.Equal(t, append(append([]string{}, tc.lines...), tc.flush...), lines)
+
+			// flushing again doesn't emit the partial line twice.
+			w.Flush()
+			assert.Len(t, lines, len(tc.lines)+len(tc.flush))
+		})
+	}
+}
+
+func TestLineWriterDoesNotRetainInput(t *testing.T) {
+	t.Parallel()
+
+	var line []byte
+	w := &lineWriter{
+		mutex: &sync.Mutex{},
+		emit: func(l []byte) {
+			line = l
+		},
+	}
+	input := []byte("abc\n")
+	_, err := w.Write(input)
+	require.NoError(t, err)
+	copy(input, "xyz\n")
+	assert.Equal(t, "abc\n", string(line))
+}
+
+func TestExecStream(t *testing.T) {
+	t.Parallel()
+
+	chunks := []RPCStreamChunk{}
+	result, err := ExecStream(context.Background(), ExecArgs{
+		Command: []string{"/bin/sh", "-c", `printf 'out 1\nout 2\n'; printf 'err\n' >&2; printf 'partial'`},
+	}, func(chunk RPCStreamChunk) {
+		chunks = append(chunks, chunk)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "out 1\nout 2\npartial", string(result.Stdout))
+	assert.Equal(t, "err\n", string(result.Stderr))
+
+	stdout := []string{}
+	stderr := []string{}
+	for _, chunk := range chunks {
+		// every chunk carries one line of one stream.
+		assert.True(t, (chunk.Stdout == nil) != (chunk.Stderr == nil))
+		if chunk.Stdout != nil {
+			stdout = append(stdout, string(chunk.Stdout))
+		} else {
+			stderr = append(stderr, string(chunk.Stderr))
+		}
+	}
+	assert.Equal(t, []string{"out 1\n", "out 2\n", "partial"}, stdout)
+	assert.Equal(t, []string{"err\n"}, stderr)
+}
@@ -0,0 +1,24 @@
+package rpc
+
+import (
+	"log/slog"
+	"time"
+)
+
+// RPCLogRecord is a log record from the agent, sent to the provider in an
+// RPCResult when log forwarding is enabled.
+type RPCLogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Source is the file:line the record was logged from, if known.
+	Source string       `json:",omitempty"`
+	Attrs  []RPCLogAttr `json:",omitempty"`
+}
+
+// RPCLogAttr is a flattened slog.Attr. Keys of attributes inside groups are
+// prefixed with the group name and a dot.
+type RPCLogAttr struct {
+	Key   string
+	Value string
+}
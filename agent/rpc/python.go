@@ -0,0 +1,209 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MinPythonVersion is the oldest Python the bundled Ansible modules run on.
+// Notably, this rules out /usr/libexec/platform-python on RHEL 8 and its
+// derivatives, which is Python 3.6; one of the newer python3.x packages has to
+// be installed on those.
+var MinPythonVersion = PythonVersion{Major: 3, Minor: 8}
+
+// PythonInterpreterCandidates are tried in order when no interpreter is
+// configured. The system interpreter comes first since that is the one with
+// the distro's Python bindings (apt, dnf, selinux, ...) installed.
+var PythonInterpreterCandidates = []string{
+	"python3",
+	"/usr/bin/python3",
+	"/usr/libexec/platform-python",
+	"python3.13",
+	"python3.12",
+	"python3.11",
+	"python3.10",
+	"python3.9",
+	"python3.8",
+	"/usr/bin/python",
+	"python",
+}
+
+// PythonProbeTimeout is how long an interpreter has to report its version.
+const PythonProbeTimeout = 10 * time.Second
+
+var ErrNoPythonInterpreter = errors.New("no usable Python interpreter found")
+
+type PythonVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v PythonVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is the same as or newer than other, ignoring the
+// patch version.
+func (v PythonVersion) AtLeast(other PythonVersion) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	return v.Minor >= other.Minor
+}
+
+// ParsePythonVersion parses a version like "3.12.1", optionally prefixed with
+// "Python " as printed by `python3 --version`. Anything after the number of
+// the last part (e.g. "rc1" or "+") is ignored.
+func ParsePythonVersion(s string) (PythonVersion, error) {
+	var version PythonVersion
+	trimmed := strings.TrimPrefix(strings.TrimSpace(s), "Python ")
+	parts := strings.Split(trimmed, ".")
+	if len(parts) < 2 {
+		return version, fmt.Errorf("invalid Python version %q", s)
+	}
+	fields := []*int{&version.Major, &version.Minor, &version.Patch}
+	for i, part := range parts {
+		if i >= len(fields) {
+			break
+		}
+		if i == len(parts)-1 {
+			if end := strings.IndexFunc(part, isNotDigit); end > 0 {
+				part = part[:end]
+			}
+		}
+		if part == "" || strings.IndexFunc(part, isNotDigit) >= 0 {
+			return version, fmt.Errorf("invalid Python version %q", s)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return version, fmt.Errorf("invalid Python version %q: %w", s, err)
+		}
+		*fields[i] = n
+	}
+	return version, nil
+}
+
+func isNotDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+type PythonInterpreter struct {
+	// Path is the absolute path of the interpreter.
+	Path    string
+	Version PythonVersion
+}
+
+var (
+	pythonInterpretersMutex sync.Mutex
+	pythonInterpreters      = map[string]PythonInterpreter{}
+)
+
+// DiscoverPythonInterpreter finds a Python interpreter that can run the bundled
+// Ansible modules. If interpreter is set only it is considered, otherwise the
+// PythonInterpreterCandidates are tried in order. Successful lookups are cached
+// for the lifetime of the agent.
+func DiscoverPythonInterpreter(ctx context.Context, interpreter string) (PythonInterpreter, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent/rpc.DiscoverPythonInterpreter", trace.WithAttributes(
+		attribute.String("python.configured", interpreter),
+	))
+	defer span.End()
+
+	pythonInterpretersMutex.Lock()
+	defer pythonInterpretersMutex.Unlock()
+
+	found, cached := pythonInterpreters[interpreter]
+	span.SetAttributes(attribute.Bool("python.cached", cached))
+	if cached {
+		span.SetStatus(codes.Ok, "")
+		return found, nil
+	}
+
+	candidates := PythonInterpreterCandidates
+	if interpreter != "" {
+		candidates = []string{interpreter}
+	}
+
+	found, err := findPythonInterpreter(ctx, candidates)
+	if err != nil {
+		if interpreter == "" {
+			err = fmt.Errorf("%w; install a newer Python or set pythonInterpreter to choose one", err)
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return PythonInterpreter{}, err
+	}
+	pythonInterpreters[interpreter] = found
+	span.SetAttributes(
+		attribute.String("python.path", found.Path),
+		attribute.String("python.version", found.Version.String()),
+	)
+	span.SetStatus(codes.Ok, "")
+	return found, nil
+}
+
+// findPythonInterpreter returns the first of candidates that is new enough.
+func findPythonInterpreter(ctx context.Context, candidates []string) (PythonInterpreter, error) {
+	problems := []string{}
+	for _, candidate := range candidates {
+		found, err := probePythonInterpreter(ctx, candidate)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", candidate, err))
+			continue
+		}
+		return found, nil
+	}
+	return PythonInterpreter{}, fmt.Errorf(
+		"%w (Python %d.%d or newer is required): %s",
+		ErrNoPythonInterpreter,
+		MinPythonVersion.Major,
+		MinPythonVersion.Minor,
+		strings.Join(problems, "; "),
+	)
+}
+
+func probePythonInterpreter(ctx context.Context, candidate string) (PythonInterpreter, error) {
+	path, err := exec.LookPath(candidate)
+	if err != nil {
+		return PythonInterpreter{}, errors.New("not found")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PythonProbeTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(
+		ctx,
+		path,
+		"-c",
+		"import sys; print('%d.%d.%d' % sys.version_info[:3])",
+	).Output()
+	if err != nil {
+		return PythonInterpreter{}, fmt.Errorf("could not get version: %w", err)
+	}
+
+	version, err := ParsePythonVersion(string(output))
+	if err != nil {
+		return PythonInterpreter{}, err
+	}
+	if !version.AtLeast(MinPythonVersion) {
+		return PythonInterpreter{}, fmt.Errorf(
+			"version %s is older than the required %d.%d",
+			version,
+			MinPythonVersion.Major,
+			MinPythonVersion.Minor,
+		)
+	}
+
+	return PythonInterpreter{
+		Path:    path,
+		Version: version,
+	}, nil
+}
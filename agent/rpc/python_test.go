@@ -0,0 +1,165 @@
+package rpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePythonVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		expect PythonVersion
+		err    bool
+	}{
+		"full":                  {input: "3.11.4", expect: PythonVersion{3, 11, 4}},
+		"trailing newline":      {input: "3.8.10\n", expect: PythonVersion{3, 8, 10}},
+		"major and minor":       {input: "3.12", expect: PythonVersion{3, 12, 0}},
+		"--version output":      {input: "Python 3.6.8", expect: PythonVersion{3, 6, 8}},
+		"release candidate":     {input: "Python 3.12.0rc1", expect: PythonVersion{3, 12, 0}},
+		"dev build":             {input: "3.14.0a1+", expect: PythonVersion{3, 14, 0}},
+		"extra parts":           {input: "3.9.2.1", expect: PythonVersion{3, 9, 2}},
+		"python 2":              {input: "Python 2.7.18", expect: PythonVersion{2, 7, 18}},
+		"empty":                 {input: "", err: true},
+		"major only":            {input: "3", err: true},
+		"garbage":               {input: "command not found", err: true},
+		"garbage with dots":     {input: "a.b.c", err: true},
+		"suffix on minor":       {input: "3rc.1", err: true},
+		"no number before rc":   {input: "3.rc1", err: true},
+		"negative":              {input: "3.-1", err: true},
+		"prefix not at start":   {input: "not Python 3.8", err: true},
+		"other interpreter tag": {input: "PyPy 7.3.1", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			version, err := ParsePythonVersion(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, version)
+		})
+	}
+}
+
+func TestPythonVersionAtLeast(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		version PythonVersion
+		other   PythonVersion
+		expect  bool
+	}{
+		"same":                  {PythonVersion{3, 8, 0}, PythonVersion{3, 8, 0}, true},
+		"newer minor":           {PythonVersion{3, 12, 0}, PythonVersion{3, 8, 0}, true},
+		"older minor":           {PythonVersion{3, 6, 8}, PythonVersion{3, 8, 0}, false},
+		"newer major":           {PythonVersion{4, 0, 0}, PythonVersion{3, 8, 0}, true},
+		"older major":           {PythonVersion{2, 9, 0}, PythonVersion{3, 8, 0}, false},
+		"patch ignored (older)": {PythonVersion{3, 8, 0}, PythonVersion{3, 8, 5}, true},
+		"patch ignored (newer)": {PythonVersion{3, 7, 99}, PythonVersion{3, 8, 0}, false},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, tc.version.AtLeast(tc.other))
+		})
+	}
+}
+
+// fakePython writes a script to dir that prints output like
+// probePythonInterpreter expects and returns its path.
+func fakePython(t *testing.T, dir string, name string, output string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+output+"\n"), 0o755))
+	return path
+}
+
+func TestFindPythonInterpreter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	old := fakePython(t, dir, "python-old", "echo 3.6.8")
+	broken := fakePython(t, dir, "python-broken", "exit 1")
+	garbage := fakePython(t, dir, "python-garbage", "echo hello")
+	newer := fakePython(t, dir, "python-new", "echo 3.12.1")
+	newest := fakePython(t, dir, "python-newest", "echo 3.13.0")
+	missing := filepath.Join(dir, "python-missing")
+
+	tests := map[string]struct {
+		candidates []string
+		expect     PythonInterpreter
+		problems   []string
+	}{
+		"first usable wins": {
+			candidates: []string{missing, old, broken, garbage, newer, newest},
+			expect:     PythonInterpreter{Path: newer, Version: PythonVersion{3, 12, 1}},
+		},
+
+		"only one": {
+			candidates: []string{newest},
+			expect:     PythonInterpreter{Path: newest, Version: PythonVersion{3, 13, 0}},
+		},
+
+		"none usable": {
+			candidates: []string{missing, old, broken, garbage},
+			problems: []string{
+				missing + ": not found",
+				old + ": version 3.6.8 is older than the required 3.8",
+				broken + ": could not get version",
+				garbage + `: invalid Python version "hello\n"`,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			found, err := findPythonInterpreter(context.Background(), tc.candidates)
+			if tc.problems != nil {
+				assert.ErrorIs(t, err, ErrNoPythonInterpreter)
+				for _, problem := range tc.problems {
+					assert.ErrorContains(t, err, problem)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, found)
+		})
+	}
+}
+
+func TestDiscoverPythonInterpreter(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := fakePython(t, dir, "python", "echo 3.10.2")
+
+	found, err := DiscoverPythonInterpreter(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, PythonInterpreter{Path: path, Version: PythonVersion{3, 10, 2}}, found)
+
+	// the result is cached, so the interpreter isn't run again.
+	require.NoError(t, os.Remove(path))
+	found, err = DiscoverPythonInterpreter(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, path, found.Path)
+
+	_, err = DiscoverPythonInterpreter(context.Background(), filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, ErrNoPythonInterpreter)
+	assert.NotContains(t, err.Error(), "set pythonInterpreter")
+}
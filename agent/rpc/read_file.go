@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// MaxReadFileLength is the most ReadFile returns in a single call.
+const MaxReadFileLength = 4 << 20
+
+// ReadFileArgs reads one chunk of a file. Large files are read with several
+// calls, advancing Offset until the result has EOF set.
+type ReadFileArgs struct {
+	Path   string
+	Offset int64 `json:",omitempty"`
+	// Length defaults to (and is capped at) MaxReadFileLength.
+	Length int64 `json:",omitempty"`
+}
+
+type ReadFileResult struct {
+	// Path is always absolute.
+	Path string
+	Data []byte
+	Size int64
+	Mode fs.FileMode
+	EOF  bool
+	// SHA256Checksum is the checksum of the whole file. It is only set on the
+	// call that reaches EOF.
+	SHA256Checksum string `json:",omitempty"`
+}
+
+func ReadFile(args ReadFileArgs) (ReadFileResult, error) {
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return ReadFileResult{}, err
+	}
+	result := ReadFileResult{
+		Path: path,
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return result, err
+	}
+	if info.IsDir() {
+		return result, fmt.Errorf("%s: is a directory", path)
+	}
+	result.Size = info.Size()
+	result.Mode = info.Mode()
+
+	length := args.Length
+	if length <= 0 || length > MaxReadFileLength {
+		length = MaxReadFileLength
+	}
+
+	data := make([]byte, length)
+	n, err := f.ReadAt(data, args.Offset)
+	result.Data = data[:n]
+	if errors.Is(err, io.EOF) || args.Offset+int64(n) >= result.Size {
+		result.EOF = true
+	} else if err != nil {
+		return result, err
+	}
+
+	if result.EOF {
+		_, err = f.Seek(0, io.SeekStart)
+		if err != nil {
+			return result, err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		if err != nil {
+			return result, err
+		}
+		result.SHA256Checksum = fmt.Sprintf("%x", h.Sum(nil))
+	}
+
+	return result, nil
+}
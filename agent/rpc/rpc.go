@@ -1,10 +1,14 @@
 package rpc
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
-	"github.com/sapslaj/mid/pkg/cast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RPCFunction string
@@ -12,17 +16,55 @@ type RPCFunction string
 const (
 	RPCAgentPing              RPCFunction = "AgentPing"
 	RPCAnsibleExecute         RPCFunction = "AnsibleExecute"
+	RPCCancel                 RPCFunction = "Cancel"
 	RPCClose                  RPCFunction = "Close"
 	RPCExec                   RPCFunction = "Exec"
 	RPCFileStat               RPCFunction = "FileStat"
+	RPCReadFile               RPCFunction = "ReadFile"
+	RPCSetEncoding            RPCFunction = "SetEncoding"
 	RPCSystemdUnitShortStatus RPCFunction = "SystemdUnitShortStatus"
 	RPCUntar                  RPCFunction = "Untar"
+	RPCWriteFile              RPCFunction = "WriteFile"
 )
 
+// RPCFunctions lists every RPCFunction this version of the agent supports. It
+// is reported to the provider in AgentPingResult.
+var RPCFunctions = []RPCFunction{
+	RPCAgentPing,
+	RPCAnsibleExecute,
+	RPCCancel,
+	RPCClose,
+	RPCExec,
+	RPCFileStat,
+	RPCReadFile,
+	RPCSetEncoding,
+	RPCSystemdUnitShortStatus,
+	RPCUntar,
+	RPCWriteFile,
+}
+
+// ErrUnsupportedRPCFunction is returned by ServerRoute for functions it does
+// not know about.
+var ErrUnsupportedRPCFunction = errors.New("unsupported RPCFunction")
+
+// ErrCancelled is returned by RPC functions that were interrupted by a Cancel
+// call for their UUID.
+var ErrCancelled = errors.New("call cancelled")
+
+// ErrTimeout is returned by RPC functions that ran longer than their requested
+// timeout.
+var ErrTimeout = errors.New("call timed out")
+
 type RPCCall[T any] struct {
 	UUID        string
 	RPCFunction RPCFunction
 	Args        T
+	// Stream requests that the server send incremental RPCResult chunks for
+	// this call (if the function supports it) before the final result.
+	Stream bool `json:",omitempty"`
+	// TraceContext carries the W3C trace context (traceparent and tracestate)
+	// of the caller so that spans recorded by the agent join the same trace.
+	TraceContext map[string]string `json:",omitempty"`
 }
 
 type RPCResult[T any] struct {
@@ -30,55 +72,109 @@ type RPCResult[T any] struct {
 	RPCFunction RPCFunction
 	Result      T
 	Error       string
+	// Chunk is set on incremental results sent for streaming calls. The final
+	// result for a call always has a nil Chunk.
+	Chunk *RPCStreamChunk `json:",omitempty"`
+	// Log is set on results that carry a forwarded agent log record instead of
+	// a call result. UUID is the call that produced the record, if any.
+	Log *RPCLogRecord `json:",omitempty"`
+	// Spans is set on results that carry spans recorded by the agent instead of
+	// a call result.
+	Spans []RPCSpan `json:",omitempty"`
+}
+
+// RPCStreamChunk is a piece of process output sent while a streaming call is
+// still running. Each chunk usually contains a single line.
+type RPCStreamChunk struct {
+	Stdout []byte `json:",omitempty"`
+	Stderr []byte `json:",omitempty"`
+}
+
+// StreamFunc receives chunks from streaming RPC functions. A nil StreamFunc
+// disables streaming.
+type StreamFunc func(chunk RPCStreamChunk)
+
+// ServerRoute dispatches a call to its RPC function. Long-running functions
+// stop early when ctx is cancelled. Cancel is handled by the server itself
+// since it needs access to the other in-flight calls, as is SetEncoding since it
+// swaps out the server's encoder and decoder.
+func ServerRoute(ctx context.Context, rpcFunction RPCFunction, args any, stream StreamFunc) (any, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent/rpc.ServerRoute", trace.WithAttributes(
+		attribute.String("rpc.function", string(rpcFunction)),
+		attribute.Bool("rpc.stream", stream != nil),
+	))
+	defer span.End()
+
+	result, err := serverRoute(ctx, rpcFunction, args, stream)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	return result, err
 }
 
-func ServerRoute(rpcFunction RPCFunction, args any) (any, error) {
+func serverRoute(ctx context.Context, rpcFunction RPCFunction, args any, stream StreamFunc) (any, error) {
 	switch rpcFunction {
 	case RPCClose:
 		os.Exit(0)
 	case RPCAgentPing:
 		var targs AgentPingArgs
-		targs, err := cast.AnyToJSONT[AgentPingArgs](args)
+		targs, err := Decode[AgentPingArgs](args)
 		if err != nil {
 			return nil, err
 		}
 		return AgentPing(targs)
 	case RPCAnsibleExecute:
 		var targs AnsibleExecuteArgs
-		targs, err := cast.AnyToJSONT[AnsibleExecuteArgs](args)
+		targs, err := Decode[AnsibleExecuteArgs](args)
 		if err != nil {
 			return nil, err
 		}
-		return AnsibleExecute(targs)
+		return AnsibleExecuteStream(ctx, targs, stream)
 	case RPCExec:
 		var targs ExecArgs
-		targs, err := cast.AnyToJSONT[ExecArgs](args)
+		targs, err := Decode[ExecArgs](args)
 		if err != nil {
 			return nil, err
 		}
-		return Exec(targs)
+		return ExecStream(ctx, targs, stream)
 	case RPCFileStat:
 		var targs FileStatArgs
-		targs, err := cast.AnyToJSONT[FileStatArgs](args)
+		targs, err := Decode[FileStatArgs](args)
 		if err != nil {
 			return nil, err
 		}
 		return FileStat(targs)
+	case RPCReadFile:
+		var targs ReadFileArgs
+		targs, err := Decode[ReadFileArgs](args)
+		if err != nil {
+			return nil, err
+		}
+		return ReadFile(targs)
 	case RPCSystemdUnitShortStatus:
 		var targs SystemdUnitShortStatusArgs
-		targs, err := cast.AnyToJSONT[SystemdUnitShortStatusArgs](args)
+		targs, err := Decode[SystemdUnitShortStatusArgs](args)
 		if err != nil {
 			return nil, err
 		}
-		return SystemdUnitShortStatus(targs)
+		return SystemdUnitShortStatusContext(ctx, targs)
 	case RPCUntar:
 		var targs UntarArgs
-		targs, err := cast.AnyToJSONT[UntarArgs](args)
+		targs, err := Decode[UntarArgs](args)
 		if err != nil {
 			return nil, err
 		}
 		return Untar(targs)
+	case RPCWriteFile:
+		var targs WriteFileArgs
+		targs, err := Decode[WriteFileArgs](args)
+		if err != nil {
+			return nil, err
+		}
+		return WriteFile(targs)
 	}
 
-	return nil, fmt.Errorf("unsupported RPCFunction: %s", rpcFunction)
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedRPCFunction, rpcFunction)
 }
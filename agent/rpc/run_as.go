@@ -0,0 +1,353 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownUser  = errors.New("unknown user")
+	ErrUnknownGroup = errors.New("unknown group")
+	ErrRunAs        = errors.New("cannot run as another user")
+)
+
+// RunAs is who a process is run as. The zero value runs it as the agent's own
+// user with the agent's environment.
+type RunAs struct {
+	// User is the name or numeric ID of the user to run as.
+	User string `json:",omitempty"`
+	// Group is the name or numeric ID of the primary group. Empty means the
+	// primary group of User.
+	Group string `json:",omitempty"`
+	// Groups are the names or numeric IDs of the supplementary groups. Empty
+	// means the groups User is a member of.
+	Groups []string `json:",omitempty"`
+	// LoginEnv replaces the agent's environment with one like a login of User
+	// would get: HOME, USER, LOGNAME, SHELL, and PATH, the locale, and, if User
+	// has a running systemd user instance, what is needed to talk to it. The
+	// process also starts in the home directory unless told otherwise.
+	LoginEnv bool `json:",omitempty"`
+}
+
+// IsZero reports whether r leaves the agent's user and environment alone.
+func (r RunAs) IsZero() bool {
+	return r.User == "" && r.Group == "" && len(r.Groups) == 0 && !r.LoginEnv
+}
+
+// Credential is a resolved RunAs.
+type Credential struct {
+	Uid    uint32
+	Gid    uint32
+	Groups []uint32
+	// Username, HomeDir, and Shell are those of the user from the passwd
+	// database.
+	Username string
+	HomeDir  string
+	Shell    string
+}
+
+// Resolve looks up the user and groups of r. The agent's own user and
+// supplementary groups are used if r.User is empty.
+func (r RunAs) Resolve() (Credential, error) {
+	var u *user.User
+	var err error
+	if r.User == "" {
+		u, err = user.LookupId(strconv.Itoa(os.Getuid()))
+	} else {
+		u, err = lookupUser(r.User)
+	}
+	if err != nil {
+		return Credential{}, err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w %q: invalid uid %q", ErrUnknownUser, u.Username, u.Uid)
+	}
+	cred := Credential{
+		Uid:      uint32(uid),
+		Username: u.Username,
+		HomeDir:  u.HomeDir,
+		Shell:    loginShell(u.Username),
+	}
+
+	primary := u.Gid
+	if r.Group != "" {
+		primary, err = lookupGroupID(r.Group)
+		if err != nil {
+			return Credential{}, err
+		}
+	}
+	cred.Gid, err = parseID(primary)
+	if err != nil {
+		return Credential{}, fmt.Errorf("%w %q: invalid gid %q", ErrUnknownGroup, r.Group, primary)
+	}
+
+	groupIDs := []string{}
+	if len(r.Groups) > 0 {
+		for _, group := range r.Groups {
+			gid, err := lookupGroupID(group)
+			if err != nil {
+				return Credential{}, err
+			}
+			groupIDs = append(groupIDs, gid)
+		}
+	} else if r.User != "" {
+		groupIDs, err = u.GroupIds()
+		if err != nil {
+			return Credential{}, fmt.Errorf("error listing groups of %s: %w", u.Username, err)
+		}
+	} else {
+		groups, err := os.Getgroups()
+		if err != nil {
+			return Credential{}, fmt.Errorf("error listing groups: %w", err)
+		}
+		for _, gid := range groups {
+			groupIDs = append(groupIDs, strconv.Itoa(gid))
+		}
+	}
+	for _, id := range groupIDs {
+		gid, err := parseID(id)
+		if err != nil {
+			return Credential{}, fmt.Errorf("%w: invalid gid %q", ErrUnknownGroup, id)
+		}
+		cred.Groups = append(cred.Groups, gid)
+	}
+
+	return cred, nil
+}
+
+// IsAgentUser reports whether cred is the agent's own user and groups, so
+// there is nothing to switch.
+func (cred Credential) IsAgentUser() bool {
+	if int(cred.Uid) != os.Getuid() || int(cred.Gid) != os.Getgid() {
+		return false
+	}
+	groups, err := os.Getgroups()
+	if err != nil || len(groups) != len(cred.Groups) {
+		return false
+	}
+	for i, gid := range groups {
+		if uint32(gid) != cred.Groups[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// LoginEnv returns the environment a login of the user would start with.
+func (cred Credential) LoginEnv() []string {
+	env := []string{
+		"HOME=" + cred.HomeDir,
+		"USER=" + cred.Username,
+		"LOGNAME=" + cred.Username,
+		"SHELL=" + cred.Shell,
+	}
+	for _, key := range []string{"PATH", "LANG", "LANGUAGE", "LC_ALL", "TZ", "TERM"} {
+		value, ok := os.LookupEnv(key)
+		if ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	// systemctl --user and friends need to be able to find the user's instance.
+	runtimeDir := fmt.Sprintf("/run/user/%d", cred.Uid)
+	if _, err := os.Stat(runtimeDir); err == nil {
+		env = append(env, "XDG_RUNTIME_DIR="+runtimeDir)
+		bus := filepath.Join(runtimeDir, "bus")
+		if _, err := os.Stat(bus); err == nil {
+			env = append(env, "DBUS_SESSION_BUS_ADDRESS=unix:path="+bus)
+		}
+	}
+	return env
+}
+
+func parseID(id string) (uint32, error) {
+	n, err := strconv.ParseUint(id, 10, 32)
+	return uint32(n), err
+}
+
+func isNumeric(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 32)
+	return err == nil
+}
+
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err != nil && isNumeric(name) {
+		u, err = user.LookupId(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %w", ErrUnknownUser, name, err)
+	}
+	return u, nil
+}
+
+func lookupGroupID(name string) (string, error) {
+	g, err := user.LookupGroup(name)
+	if err != nil && isNumeric(name) {
+		// supplementary groups don't need an entry in the group database.
+		return name, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w %q: %w", ErrUnknownGroup, name, err)
+	}
+	return g.Gid, nil
+}
+
+// loginShell returns the login shell of username from /etc/passwd, which
+// os/user doesn't expose.
+func loginShell(username string) string {
+	f, err := os.Open("/etc/passwd")
+	if err != nil {
+		return "/bin/sh"
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) == 7 && fields[0] == username && fields[6] != "" {
+			return fields[6]
+		}
+	}
+	return "/bin/sh"
+}
+
+var sharedAnsible struct {
+	sync.Mutex
+	dir string
+}
+
+// SharedAnsibleDir returns a copy of the Ansible bundle that every user can
+// read, for running modules as users that can't get into the agent directory.
+// The copy is in the temp directory and named after the agent user and the
+// bundle, so it is made once and then shared by every agent process using the
+// same bundle instead of each leaving a copy behind.
+func SharedAnsibleDir() (string, error) {
+	sharedAnsible.Lock()
+	defer sharedAnsible.Unlock()
+
+	if sharedAnsible.dir != "" {
+		return sharedAnsible.dir, nil
+	}
+
+	source, err := filepath.EvalSymlinks(filepath.Join(InstallDir(), "ansible"))
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(os.TempDir(), fmt.Sprintf("mid-%d-%s", os.Getuid(), filepath.Base(source)))
+	if !isSharedCopy(dir, source) {
+		err = makeSharedCopy(dir, source)
+		if err != nil {
+			return "", err
+		}
+	}
+	sharedAnsible.dir = dir
+	return dir, nil
+}
+
+// isSharedCopy reports whether dir is a complete copy of the bundle in source
+// that only the agent user can change. The name of dir is predictable, so
+// anything else that is there might have been prepared by another user.
+func isSharedCopy(dir string, source string) bool {
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() || !ownedByAgentUser(info) || info.Mode().Perm()&0o022 != 0 {
+		return false
+	}
+	// bundles are stamped with their checksum once they are complete.
+	want, err := os.ReadFile(filepath.Join(source, AnsibleHashFile))
+	if err != nil {
+		return false
+	}
+	got, err := os.ReadFile(filepath.Join(dir, AnsibleHashFile))
+	return err == nil && bytes.Equal(want, got)
+}
+
+// makeSharedCopy copies source to dir. The copy is made in a directory with an
+// unpredictable name first and renamed into place once it is complete.
+func makeSharedCopy(dir string, source string) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), ".mid-ansible-shared-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = copyTreeReadable(source, tmpDir)
+	if err == nil {
+		err = os.Chmod(tmpDir, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+
+	// a copy of our own that was tampered with can be replaced, anything else
+	// is left alone.
+	info, err := os.Lstat(dir)
+	if err == nil && (!info.IsDir() || !ownedByAgentUser(info)) {
+		return fmt.Errorf("%w: %s exists and is not owned by the agent user", ErrRunAs, dir)
+	}
+	if err == nil {
+		err = os.RemoveAll(dir)
+		if err != nil {
+			return err
+		}
+	}
+	err = os.Rename(tmpDir, dir)
+	if err != nil && isSharedCopy(dir, source) {
+		// another agent won the race
+		return nil
+	}
+	return err
+}
+
+// copyTreeReadable copies the regular files and directories in source to
+// target, making them readable, but not writable, by everyone.
+func copyTreeReadable(source string, target string) error {
+	return filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, p)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(target, rel)
+		switch {
+		case rel == ".":
+			return nil
+		case d.IsDir():
+			return os.Mkdir(dest, 0o755)
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		mode := fs.FileMode(0o644)
+		if info.Mode()&0o100 != 0 {
+			mode = 0o755
+		}
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return errors.Join(err, dst.Close())
+	})
+}
@@ -0,0 +1,324 @@
+package rpc
+
+import (
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// currentGroups returns the supplementary groups of the test process, nil if
+// there are none like Resolve.
+func currentGroups(t *testing.T) []uint32 {
+	t.Helper()
+
+	groups, err := os.Getgroups()
+	require.NoError(t, err)
+	var result []uint32
+	for _, gid := range groups {
+		result = append(result, uint32(gid))
+	}
+	return result
+}
+
+func TestRunAsResolve(t *testing.T) {
+	t.Parallel()
+
+	current, err := user.Current()
+	require.NoError(t, err)
+	group, err := user.LookupGroupId(current.Gid)
+	require.NoError(t, err)
+	uid := uint32(os.Getuid())
+	gid := uint32(os.Getgid())
+
+	var memberOf []uint32
+	groupIDs, err := current.GroupIds()
+	require.NoError(t, err)
+	for _, id := range groupIDs {
+		n, err := strconv.ParseUint(id, 10, 32)
+		require.NoError(t, err)
+		memberOf = append(memberOf, uint32(n))
+	}
+
+	tests := map[string]struct {
+		runAs  RunAs
+		gid    uint32
+		groups []uint32
+		err    error
+	}{
+		"agent user": {
+			runAs:  RunAs{},
+			gid:    gid,
+			groups: currentGroups(t),
+		},
+
+		"by name": {
+			runAs:  RunAs{User: current.Username},
+			gid:    gid,
+			groups: memberOf,
+		},
+
+		"by id": {
+			runAs:  RunAs{User: current.Uid},
+			gid:    gid,
+			groups: memberOf,
+		},
+
+		"group by name": {
+			runAs:  RunAs{User: current.Username, Group: group.Name},
+			gid:    gid,
+			groups: memberOf,
+		},
+
+		"group by id": {
+			runAs:  RunAs{Group: "4242"},
+			gid:    4242,
+			groups: currentGroups(t),
+		},
+
+		"groups": {
+			runAs:  RunAs{User: current.Username, Groups: []string{group.Name, "4243"}},
+			gid:    gid,
+			groups: []uint32{gid, 4243},
+		},
+
+		"unknown user": {
+			runAs: RunAs{User: "mid-no-such-user"},
+			err:   ErrUnknownUser,
+		},
+
+		"unknown group": {
+			runAs: RunAs{Group: "mid-no-such-group"},
+			err:   ErrUnknownGroup,
+		},
+
+		"unknown supplementary group": {
+			runAs: RunAs{Groups: []string{"mid-no-such-group"}},
+			err:   ErrUnknownGroup,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cred, err := tc.runAs.Resolve()
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, uid, cred.Uid)
+			assert.Equal(t, tc.gid, cred.Gid)
+			assert.Equal(t, tc.groups, cred.Groups)
+			assert.Equal(t, current.Username, cred.Username)
+			assert.Equal(t, current.HomeDir, cred.HomeDir)
+			assert.NotEmpty(t, cred.Shell)
+		})
+	}
+}
+
+func TestCredentialIsAgentUser(t *testing.T) {
+	t.Parallel()
+
+	cred, err := RunAs{}.Resolve()
+	require.NoError(t, err)
+	assert.True(t, cred.IsAgentUser())
+
+	other := cred
+	other.Gid++
+	assert.False(t, other.IsAgentUser())
+
+	other = cred
+	other.Groups = append(slices.Clone(cred.Groups), 4242)
+	assert.False(t, other.IsAgentUser())
+}
+
+func TestCredentialLoginEnv(t *testing.T) {
+	t.Parallel()
+
+	cred := Credential{
+		Uid:      4242,
+		Username: "app",
+		HomeDir:  "/home/app",
+		Shell:    "/bin/bash",
+	}
+	env := cred.LoginEnv()
+	assert.Equal(t, []string{
+		"HOME=/home/app",
+		"USER=app",
+		"LOGNAME=app",
+		"SHELL=/bin/bash",
+	}, env[:4])
+
+	vars := map[string]string{}
+	for _, kv := range env {
+		key, value, ok := strings.Cut(kv, "=")
+		require.True(t, ok, kv)
+		vars[key] = value
+	}
+	if path, ok := os.LookupEnv("PATH"); ok {
+		assert.Equal(t, path, vars["PATH"])
+	}
+	// nothing else is carried over from the agent's environment.
+	for key := range vars {
+		assert.Contains(t, []string{
+			"HOME", "USER", "LOGNAME", "SHELL", "PATH", "LANG", "LANGUAGE", "LC_ALL", "TZ", "TERM",
+			"XDG_RUNTIME_DIR", "DBUS_SESSION_BUS_ADDRESS",
+		}, key)
+	}
+	// a user without a running systemd instance has no runtime directory.
+	assert.NotContains(t, vars, "XDG_RUNTIME_DIR")
+}
+
+func TestLookupGroupID(t *testing.T) {
+	t.Parallel()
+
+	group, err := user.LookupGroupId(strconv.Itoa(os.Getgid()))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		"name":            {input: group.Name, expect: group.Gid},
+		"id":              {input: group.Gid, expect: group.Gid},
+		"id not in db":    {input: "4242", expect: "4242"},
+		"unknown name":    {input: "mid-no-such-group", err: true},
+		"negative id":     {input: "-1", err: true},
+		"id out of range": {input: "4294967296", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gid, err := lookupGroupID(tc.input)
+			if tc.err {
+				assert.ErrorIs(t, err, ErrUnknownGroup)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, gid)
+		})
+	}
+}
+
+func TestCopyTreeReadable(t *testing.T) {
+	t.Parallel()
+
+	source := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "lib", "private"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "lib", "module.py"), []byte("module"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "lib", "private", "data"), []byte("data"), 0o400))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "run"), []byte("#!/bin/sh\n"), 0o700))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(source, "link")))
+
+	target := filepath.Join(t.TempDir(), "copy")
+	require.NoError(t, os.Mkdir(target, 0o700))
+	require.NoError(t, copyTreeReadable(source, target))
+
+	modes := map[string]fs.FileMode{}
+	err := filepath.WalkDir(target, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == target {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(target, p)
+		modes[rel] = info.Mode()
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]fs.FileMode{
+		"lib":              fs.ModeDir | 0o755,
+		"lib/module.py":    0o644,
+		"lib/private":      fs.ModeDir | 0o755,
+		"lib/private/data": 0o644,
+		"run":              0o755,
+	}, modes)
+
+	data, err := os.ReadFile(filepath.Join(target, "lib", "module.py"))
+	require.NoError(t, err)
+	assert.Equal(t, "module", string(data))
+
+	// nothing is overwritten.
+	assert.Error(t, copyTreeReadable(source, target))
+}
+
+func TestSharedAnsibleDir(t *testing.T) {
+	// SharedAnsibleDir uses the agent directory and the temp directory of the
+	// process, so this can't run in parallel.
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	agentDir := AgentDir
+	AgentDir = t.TempDir()
+	t.Cleanup(func() {
+		AgentDir = agentDir
+	})
+	reset := func() {
+		sharedAnsible.Lock()
+		sharedAnsible.dir = ""
+		sharedAnsible.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+
+	bundle := filepath.Join(InstallDir(), "ansible-0123456789abcdef")
+	require.NoError(t, os.MkdirAll(filepath.Join(bundle, "lib"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(bundle, "lib", "module.py"), []byte("module"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(bundle, AnsibleHashFile), []byte("abc\n"), 0o600))
+	require.NoError(t, os.Symlink("ansible-0123456789abcdef", filepath.Join(InstallDir(), "ansible")))
+
+	dir, err := SharedAnsibleDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, "mid-"+strconv.Itoa(os.Getuid())+"-ansible-0123456789abcdef"), dir)
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o755), info.Mode().Perm())
+	data, err := os.ReadFile(filepath.Join(dir, "lib", "module.py"))
+	require.NoError(t, err)
+	assert.Equal(t, "module", string(data))
+
+	// another agent process with the same bundle uses the same copy.
+	marker := filepath.Join(dir, "marker")
+	require.NoError(t, os.WriteFile(marker, nil, 0o644))
+	reset()
+	again, err := SharedAnsibleDir()
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+	assert.FileExists(t, marker)
+
+	// a copy others can change is replaced.
+	require.NoError(t, os.Chmod(dir, 0o777))
+	reset()
+	again, err = SharedAnsibleDir()
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+	assert.NoFileExists(t, marker)
+	info, err = os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o755), info.Mode().Perm())
+
+	// so is an incomplete one.
+	require.NoError(t, os.Remove(filepath.Join(dir, AnsibleHashFile)))
+	reset()
+	_, err = SharedAnsibleDir()
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, AnsibleHashFile))
+
+	// nothing is left behind in the temp directory.
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// DefaultLogForwarderBuffer is how many log records are buffered before new
+// ones are dropped.
+const DefaultLogForwarderBuffer = 1024
+
+// LogForwarder collects log records so that the Server can send them to the
+// provider over the RPC channel. Records are buffered instead of being written
+// directly because logging happens while the Server is holding its encoder
+// lock. When the buffer is full records are dropped rather than blocking the
+// caller, and a warning with the number of dropped records is sent once there
+// is room again.
+type LogForwarder struct {
+	records chan logEntry
+	dropped atomic.Int64
+}
+
+type logEntry struct {
+	uuid   string
+	record rpc.RPCLogRecord
+}
+
+func NewLogForwarder() *LogForwarder {
+	return &LogForwarder{
+		records: make(chan logEntry, DefaultLogForwarderBuffer),
+	}
+}
+
+// Handler returns a slog.Handler that forwards records at or above level. If
+// next is not nil every record is passed on to it as well. The value of a
+// top-level "uuid" attribute is used as the UUID of the call that produced the
+// record.
+func (f *LogForwarder) Handler(level slog.Leveler, next slog.Handler) slog.Handler {
+	return &logForwarderHandler{
+		forwarder: f,
+		level:     level,
+		next:      next,
+	}
+}
+
+func (f *LogForwarder) send(entry logEntry) {
+	select {
+	case f.records <- entry:
+	default:
+		f.dropped.Add(1)
+	}
+}
+
+// pending returns the next buffered record without blocking.
+func (f *LogForwarder) pending() (logEntry, bool) {
+	if dropped := f.dropped.Swap(0); dropped > 0 {
+		return logEntry{
+			record: rpc.RPCLogRecord{
+				Time:    time.Now(),
+				Level:   slog.LevelWarn,
+				Message: fmt.Sprintf("log forwarding buffer full, dropped %d records", dropped),
+			},
+		}, true
+	}
+	select {
+	case entry := <-f.records:
+		return entry, true
+	default:
+		return logEntry{}, false
+	}
+}
+
+type logForwarderHandler struct {
+	forwarder *LogForwarder
+	level     slog.Leveler
+	next      slog.Handler
+	uuid      string
+	group     string
+	attrs     []rpc.RPCLogAttr
+}
+
+func (h *logForwarderHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.level.Level() {
+		return true
+	}
+	return h.next != nil && h.next.Enabled(ctx, level)
+}
+
+func (h *logForwarderHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.level.Level() {
+		entry := logEntry{
+			uuid: h.uuid,
+			record: rpc.RPCLogRecord{
+				Time:    record.Time,
+				Level:   record.Level,
+				Message: record.Message,
+				Attrs:   append([]rpc.RPCLogAttr{}, h.attrs...),
+			},
+		}
+		if record.PC != 0 {
+			frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+			entry.record.Source = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			if h.group == "" && attr.Key == "uuid" {
+				entry.uuid = attr.Value.String()
+			}
+			entry.record.Attrs = flattenAttr(entry.record.Attrs, h.group, attr)
+			return true
+		})
+		h.forwarder.send(entry)
+	}
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+	return nil
+}
+
+func (h *logForwarderHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]rpc.RPCLogAttr{}, h.attrs...)
+	for _, attr := range attrs {
+		if h.group == "" && attr.Key == "uuid" {
+			h2.uuid = attr.Value.String()
+		}
+		h2.attrs = flattenAttr(h2.attrs, h.group, attr)
+	}
+	if h.next != nil {
+		h2.next = h.next.WithAttrs(attrs)
+	}
+	return &h2
+}
+
+func (h *logForwarderHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group = h.group + name + "."
+	if h.next != nil {
+		h2.next = h.next.WithGroup(name)
+	}
+	return &h2
+}
+
+// flattenAttr appends attr to attrs, prefixing keys with their group names.
+func flattenAttr(attrs []rpc.RPCLogAttr, group string, attr slog.Attr) []rpc.RPCLogAttr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return attrs
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, child := range attr.Value.Group() {
+			attrs = flattenAttr(attrs, prefix, child)
+		}
+		return attrs
+	}
+	return append(attrs, rpc.RPCLogAttr{
+		Key:   group + attr.Key,
+		Value: attr.Value.String(),
+	})
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// lazyValue is resolved when the record is forwarded.
+type lazyValue string
+
+func (v lazyValue) LogValue() slog.Value {
+	return slog.StringValue("resolved " + string(v))
+}
+
+func TestFlattenAttr(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		group  string
+		attr   slog.Attr
+		expect []rpc.RPCLogAttr
+	}{
+		"string": {
+			attr:   slog.String("key", "value"),
+			expect: []rpc.RPCLogAttr{{Key: "key", Value: "value"}},
+		},
+
+		"int": {
+			attr:   slog.Int("n", 42),
+			expect: []rpc.RPCLogAttr{{Key: "n", Value: "42"}},
+		},
+
+		"duration": {
+			attr:   slog.Duration("wait", 2*time.Second),
+			expect: []rpc.RPCLogAttr{{Key: "wait", Value: "2s"}},
+		},
+
+		"in a group": {
+			group:  "outer.",
+			attr:   slog.Bool("ok", true),
+			expect: []rpc.RPCLogAttr{{Key: "outer.ok", Value: "true"}},
+		},
+
+		"group": {
+			attr: slog.Group("outer",
+				slog.String("a", "1"),
+				slog.Group("inner", slog.String("b", "2")),
+			),
+			expect: []rpc.RPCLogAttr{
+				{Key: "outer.a", Value: "1"},
+				{Key: "outer.inner.b", Value: "2"},
+			},
+		},
+
+		"group without a key is inlined": {
+			group: "outer.",
+			attr:  slog.Group("", slog.String("a", "1")),
+			expect: []rpc.RPCLogAttr{
+				{Key: "outer.a", Value: "1"},
+			},
+		},
+
+		"empty group": {
+			attr: slog.Group("empty"),
+		},
+
+		"empty attr": {
+			attr: slog.Attr{},
+		},
+
+		"LogValuer": {
+			attr:   slog.Any("lazy", lazyValue("value")),
+			expect: []rpc.RPCLogAttr{{Key: "lazy", Value: "resolved value"}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			existing := rpc.RPCLogAttr{Key: "existing", Value: "kept"}
+			attrs := flattenAttr([]rpc.RPCLogAttr{existing}, tc.group, tc.attr)
+			assert.Equal(t, append([]rpc.RPCLogAttr{existing}, tc.expect...), attrs)
+		})
+	}
+}
+
+// pendingEntries drains every record buffered by f.
+func pendingEntries(f *LogForwarder) []logEntry {
+	entries := []logEntry{}
+	for {
+		entry, ok := f.pending()
+		if !ok {
+			return entries
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func TestLogForwarderHandler(t *testing.T) {
+	t.Parallel()
+
+	f := NewLogForwarder()
+	logger := slog.New(f.Handler(slog.LevelInfo, nil))
+
+	logger.Debug("below the level")
+	logger.Info("no call")
+	logger.With(slog.String("uuid", "call-1")).Warn("from With", slog.Int("n", 1))
+	logger.Error("from the record", slog.String("uuid", "call-2"))
+	logger.With(slog.String("uuid", "call-3")).WithGroup("group").Info(
+		"grouped",
+		slog.String("uuid", "not a call"),
+		slog.String("key", "value"),
+	)
+	logger.WithGroup("group").With(slog.String("uuid", "not a call either")).Info("uuid in a group")
+
+	entries := pendingEntries(f)
+	require.Len(t, entries, 5)
+
+	assert.Equal(t, "", entries[0].uuid)
+	assert.Equal(t, "no call", entries[0].record.Message)
+	assert.Equal(t, slog.LevelInfo, entries[0].record.Level)
+	assert.Empty(t, entries[0].record.Attrs)
+	assert.False(t, entries[0].record.Time.IsZero())
+	assert.Contains(t, entries[0].record.Source, "log_forwarder_test.go:")
+
+	assert.Equal(t, "call-1", entries[1].uuid)
+	assert.Equal(t, slog.LevelWarn, entries[1].record.Level)
+	assert.Equal(t, []rpc.RPCLogAttr{
+		{Key: "uuid", Value: "call-1"},
+		{Key: "n", Value: "1"},
+	}, entries[1].record.Attrs)
+
+	assert.Equal(t, "call-2", entries[2].uuid)
+	assert.Equal(t, slog.LevelError, entries[2].record.Level)
+
+	// only a top-level uuid attribute is taken as the call's UUID.
+	assert.Equal(t, "call-3", entries[3].uuid)
+	assert.Equal(t, []rpc.RPCLogAttr{
+		{Key: "uuid", Value: "call-3"},
+		{Key: "group.uuid", Value: "not a call"},
+		{Key: "group.key", Value: "value"},
+	}, entries[3].record.Attrs)
+
+	assert.Equal(t, "", entries[4].uuid)
+	assert.Equal(t, []rpc.RPCLogAttr{
+		{Key: "group.uuid", Value: "not a call either"},
+	}, entries[4].record.Attrs)
+}
+
+func TestLogForwarderHandlerWithAttrsDoesNotShare(t *testing.T) {
+	t.Parallel()
+
+	f := NewLogForwarder()
+	logger := slog.New(f.Handler(slog.LevelInfo, nil)).With(slog.String("base", "1"))
+
+	// loggers derived from the same one each keep their own attributes.
+	logger.With(slog.String("uuid", "call-1")).Info("one")
+	logger.With(slog.String("uuid", "call-2")).Info("two")
+	logger.Info("base")
+
+	entries := pendingEntries(f)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "call-1", entries[0].uuid)
+	assert.Equal(t, []rpc.RPCLogAttr{{Key: "base", Value: "1"}, {Key: "uuid", Value: "call-1"}}, entries[0].record.Attrs)
+	assert.Equal(t, "call-2", entries[1].uuid)
+	assert.Equal(t, []rpc.RPCLogAttr{{Key: "base", Value: "1"}, {Key: "uuid", Value: "call-2"}}, entries[1].record.Attrs)
+	assert.Equal(t, "", entries[2].uuid)
+	assert.Equal(t, []rpc.RPCLogAttr{{Key: "base", Value: "1"}}, entries[2].record.Attrs)
+}
+
+func TestLogForwarderHandlerNext(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	next := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if len(groups) == 0 && attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+	f := NewLogForwarder()
+	logger := slog.New(f.Handler(slog.LevelWarn, next))
+
+	assert.True(t, logger.Enabled(t.Context(), slog.LevelDebug))
+	logger.With(slog.String("uuid", "call")).WithGroup("g").Debug("debug", slog.Int("n", 1))
+	logger.Warn("warn")
+
+	// records below the forwarder's level still go to next.
+	assert.Equal(t, "level=DEBUG msg=debug uuid=call g.n=1\nlevel=WARN msg=warn\n", buf.String())
+
+	entries := pendingEntries(f)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "warn", entries[0].record.Message)
+
+	assert.False(t, slog.New(f.Handler(slog.LevelWarn, nil)).Enabled(t.Context(), slog.LevelInfo))
+}
+
+func TestLogForwarderDropped(t *testing.T) {
+	t.Parallel()
+
+	f := NewLogForwarder()
+	logger := slog.New(f.Handler(slog.LevelInfo, nil))
+
+	// logging never blocks, even once the buffer is full.
+	for i := range DefaultLogForwarderBuffer + 3 {
+		logger.Info(fmt.Sprintf("record %d", i), slog.String("uuid", "call"))
+	}
+
+	// the warning comes first so that it goes out as soon as there's room.
+	entry, ok := f.pending()
+	require.True(t, ok)
+	assert.Equal(t, "", entry.uuid)
+	assert.Equal(t, slog.LevelWarn, entry.record.Level)
+	assert.Equal(t, "log forwarding buffer full, dropped 3 records", entry.record.Message)
+
+	entries := pendingEntries(f)
+	require.Len(t, entries, DefaultLogForwarderBuffer)
+	for i, entry := range entries {
+		assert.Equal(t, "call", entry.uuid)
+		assert.Equal(t, fmt.Sprintf("record %d", i), entry.record.Message)
+	}
+
+	// the count starts over once it has been reported.
+	logger.Info("after")
+	entries = pendingEntries(f)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "after", entries[0].record.Message)
+}
@@ -1,53 +1,186 @@
 package server
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/propagation"
+
 	"github.com/sapslaj/mid/agent/rpc"
 	"github.com/sapslaj/mid/pkg/log"
+	"github.com/sapslaj/mid/pkg/syncmap"
 )
 
+// DefaultHeartbeatTimeout is how long the server waits for a call before
+// assuming the provider has gone away.
+const DefaultHeartbeatTimeout = 2 * time.Minute
+
+var ErrHeartbeatTimeout = errors.New("heartbeat timeout")
+
 type Server struct {
 	Logger *slog.Logger
+	// HeartbeatTimeout is how long the server waits without receiving any call
+	// (the provider sends periodic AgentPing heartbeats) before it stops
+	// accepting calls, waits for in-flight ones to finish and returns
+	// ErrHeartbeatTimeout. Zero means DefaultHeartbeatTimeout and a negative
+	// value disables the timeout.
+	HeartbeatTimeout time.Duration
+	// LogForwarder, if set, has its records sent to the provider as RPCResults
+	// with Log set. Logger should use one of its handlers.
+	LogForwarder *LogForwarder
+	// SpanForwarder, if set, has its spans sent to the provider as RPCResults
+	// with Spans set. It should be registered with the global tracer provider.
+	SpanForwarder *SpanForwarder
+}
+
+type decodedCall struct {
+	call rpc.RPCCall[any]
+	err  error
 }
 
 func (s *Server) Start() error {
-	encoder := json.NewEncoder(os.Stdout)
-	decoder := json.NewDecoder(os.Stdin)
+	encoder, err := rpc.NewEncoder(rpc.EncodingJSON, os.Stdout)
+	if err != nil {
+		return err
+	}
+	decoder, err := rpc.NewDecoder(rpc.EncodingJSON, os.Stdin)
+	if err != nil {
+		return err
+	}
 	mutex := sync.Mutex{}
 	wg := sync.WaitGroup{}
+	inFlight := syncmap.Map[string, context.CancelCauseFunc]{}
+
+	heartbeatTimeout := s.HeartbeatTimeout
+	if heartbeatTimeout == 0 {
+		heartbeatTimeout = DefaultHeartbeatTimeout
+	}
+	var heartbeatCheck <-chan time.Time
+	if heartbeatTimeout > 0 {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		heartbeatCheck = ticker.C
+	}
 	lastHeartbeat := time.Now()
-	heartbeatCheckClose := make(chan struct{})
 
+	// forwardTelemetry sends any buffered log records and spans, including the
+	// given spans. The mutex must be held.
+	forwardTelemetry := func(spans ...rpc.RPCSpan) {
+		if s.LogForwarder != nil {
+			for {
+				entry, ok := s.LogForwarder.pending()
+				if !ok {
+					break
+				}
+				// errors aren't logged since that would just produce another record
+				// to forward.
+				encoder.EncodeResult(rpc.RPCResult[any]{
+					UUID: entry.uuid,
+					Log:  &entry.record,
+				})
+			}
+		}
+		if s.SpanForwarder != nil {
+			pending, dropped := s.SpanForwarder.pending()
+			spans = append(spans, pending...)
+			if dropped > 0 {
+				s.Logger.Warn("span forwarding buffer full, dropped spans", slog.Int64("dropped", dropped))
+			}
+		}
+		if len(spans) > 0 {
+			encoder.EncodeResult(rpc.RPCResult[any]{
+				Spans: spans,
+			})
+		}
+	}
+	if s.LogForwarder != nil || s.SpanForwarder != nil {
+		var logRecords <-chan logEntry
+		if s.LogForwarder != nil {
+			logRecords = s.LogForwarder.records
+		}
+		var spans <-chan rpc.RPCSpan
+		if s.SpanForwarder != nil {
+			spans = s.SpanForwarder.spans
+		}
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case entry := <-logRecords:
+					mutex.Lock()
+					encoder.EncodeResult(rpc.RPCResult[any]{
+						UUID: entry.uuid,
+						Log:  &entry.record,
+					})
+					forwardTelemetry()
+					mutex.Unlock()
+				case span := <-spans:
+					mutex.Lock()
+					forwardTelemetry(span)
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	// decoding happens in its own goroutine so that the heartbeat can be checked
+	// while waiting for a call. It only decodes a call once asked to so that
+	// SetEncoding can swap out the decoder in between.
+	next := make(chan struct{})
+	decoded := make(chan decodedCall)
 	go func() {
+		for range next {
+			call, err := decoder.DecodeCall()
+			decoded <- decodedCall{call: call, err: err}
+		}
+	}()
+
+	waitForCall := func() (decodedCall, bool) {
+		next <- struct{}{}
 		for {
 			select {
-			case <-time.Tick(100 * time.Millisecond):
-				if time.Now().Add(-2 * time.Minute).After(lastHeartbeat) {
-					panic("heartbeat timeout")
+			case d := <-decoded:
+				return d, true
+			case <-heartbeatCheck:
+				if time.Since(lastHeartbeat) > heartbeatTimeout {
+					return decodedCall{}, false
 				}
-			case <-heartbeatCheckClose:
-				return
 			}
 		}
-	}()
+	}
 
 	for {
 		s.Logger.Info("waiting for next call")
 
-		var err error
-		var call rpc.RPCCall[any]
-
-		err = decoder.Decode(&call)
+		d, ok := waitForCall()
+		if !ok {
+			s.Logger.Error(
+				"no heartbeat received in time, shutting down once in-flight calls finish",
+				slog.Duration("heartbeat_timeout", heartbeatTimeout),
+				slog.Time("last_heartbeat", lastHeartbeat),
+				slog.Int("in_flight", inFlight.Length()),
+			)
+			wg.Wait()
+			s.Logger.Info("in-flight calls finished, closing")
+			mutex.Lock()
+			forwardTelemetry()
+			mutex.Unlock()
+			return fmt.Errorf("%w: no call received in %s", ErrHeartbeatTimeout, heartbeatTimeout)
+		}
+		call, err := d.call, d.err
 
 		if err != nil {
 			s.Logger.Error("error while decoding call", slog.Any("error", err))
 			mutex.Lock()
-			encoder.Encode(rpc.RPCResult[any]{
+			encoder.EncodeResult(rpc.RPCResult[any]{
 				UUID:  call.UUID,
 				Error: err.Error(),
 			})
@@ -55,6 +188,9 @@ func (s *Server) Start() error {
 			continue
 		}
 
+		// any call at all shows that the provider is still there
+		lastHeartbeat = time.Now()
+
 		if call.UUID == "" {
 			s.Logger.Error(
 				"UUID is empty",
@@ -62,7 +198,7 @@ func (s *Server) Start() error {
 				log.SlogJSON("args", call.Args),
 			)
 			mutex.Lock()
-			encoder.Encode(rpc.RPCResult[any]{
+			encoder.EncodeResult(rpc.RPCResult[any]{
 				UUID:  call.UUID,
 				Error: "UUID is empty",
 			})
@@ -72,19 +208,85 @@ func (s *Server) Start() error {
 
 		if call.RPCFunction == rpc.RPCClose {
 			s.Logger.Info("received close, waiting for inflight to finish")
-			heartbeatCheckClose <- struct{}{}
 			wg.Wait()
 			s.Logger.Info("closing")
+			mutex.Lock()
+			forwardTelemetry()
+			mutex.Unlock()
 			return nil
 		}
 
-		if call.RPCFunction == rpc.RPCAgentPing {
-			lastHeartbeat = time.Now()
+		if call.RPCFunction == rpc.RPCCancel {
+			result := rpc.RPCResult[any]{
+				UUID:        call.UUID,
+				RPCFunction: call.RPCFunction,
+			}
+			targs, err := rpc.Decode[rpc.CancelArgs](call.Args)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				cancel, found := inFlight.Load(targs.UUID)
+				if found && cancel != nil {
+					s.Logger.Info("cancelling call", slog.String("uuid", targs.UUID))
+					cancel(rpc.ErrCancelled)
+				} else {
+					s.Logger.Warn("cancel requested for unknown call", slog.String("uuid", targs.UUID))
+				}
+				result.Result = rpc.CancelResult{
+					UUID:      targs.UUID,
+					Cancelled: found,
+				}
+			}
+			mutex.Lock()
+			encoder.EncodeResult(result)
+			mutex.Unlock()
+			continue
+		}
+
+		if call.RPCFunction == rpc.RPCSetEncoding {
+			result := rpc.RPCResult[any]{
+				UUID:        call.UUID,
+				RPCFunction: call.RPCFunction,
+			}
+			var newEncoder *rpc.Encoder
+			var newDecoder *rpc.Decoder
+			targs, err := rpc.Decode[rpc.SetEncodingArgs](call.Args)
+			if err == nil {
+				newEncoder, err = rpc.NewEncoder(targs.Encoding, os.Stdout)
+			}
+			if err == nil {
+				newDecoder, err = decoder.Switch(targs.Encoding, os.Stdin)
+			}
+			if err != nil {
+				s.Logger.Error("error setting encoding", slog.Any("error", err))
+				result.Error = err.Error()
+			} else {
+				result.Result = rpc.SetEncodingResult{
+					Encoding: targs.Encoding,
+				}
+			}
+			mutex.Lock()
+			encoder.EncodeResult(result)
+			if err == nil {
+				s.Logger.Info("switching encoding", slog.String("encoding", string(targs.Encoding)))
+				encoder = newEncoder
+				decoder = newDecoder
+			}
+			mutex.Unlock()
+			continue
+		}
+
+		ctx, cancel := context.WithCancelCause(context.Background())
+		if len(call.TraceContext) > 0 {
+			ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier(call.TraceContext))
 		}
+		inFlight.Store(call.UUID, cancel)
 
 		wg.Add(1)
 		go func(call rpc.RPCCall[any]) {
 			defer wg.Done()
+			defer inFlight.Delete(call.UUID)
+			defer cancel(nil)
 
 			logger := s.Logger.With(
 				slog.String("uuid", call.UUID),
@@ -92,9 +294,25 @@ func (s *Server) Start() error {
 				log.SlogJSON("args", call.Args),
 			)
 
+			var stream rpc.StreamFunc
+			if call.Stream {
+				stream = func(chunk rpc.RPCStreamChunk) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					err := encoder.EncodeResult(rpc.RPCResult[any]{
+						UUID:        call.UUID,
+						RPCFunction: call.RPCFunction,
+						Chunk:       &chunk,
+					})
+					if err != nil {
+						logger.Error("error while encoding stream chunk", slog.Any("error", err))
+					}
+				}
+			}
+
 			logger.Info("routing call")
 
-			res, err := rpc.ServerRoute(call.RPCFunction, call.Args)
+			res, err := rpc.ServerRoute(ctx, call.RPCFunction, call.Args, stream)
 
 			mutex.Lock()
 			defer mutex.Unlock()
@@ -115,10 +333,18 @@ func (s *Server) Start() error {
 			)
 
 			logger.Info("sending result")
-			err = encoder.Encode(result)
+			// logs and spans from the call go out before its result so that the
+			// provider still knows which call they belong to.
+			forwardTelemetry()
+			err = encoder.EncodeResult(result)
 
 			if err != nil {
 				logger.Error("error while encoding result", slog.Any("error", err))
+				encoder.EncodeResult(rpc.RPCResult[any]{
+					UUID:        call.UUID,
+					RPCFunction: call.RPCFunction,
+					Error:       "error while encoding result: " + err.Error(),
+				})
 			}
 		}(call)
 	}
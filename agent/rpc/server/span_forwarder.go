@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"sync/atomic"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+// DefaultSpanForwarderBuffer is how many finished spans are buffered before new
+// ones are dropped.
+const DefaultSpanForwarderBuffer = 1024
+
+// SpanForwarder is an OTel span exporter that collects finished spans so that
+// the Server can send them to the provider over the RPC channel. Like
+// LogForwarder it never blocks and drops spans when its buffer is full.
+type SpanForwarder struct {
+	spans   chan rpc.RPCSpan
+	dropped atomic.Int64
+}
+
+var _ sdktrace.SpanExporter = (*SpanForwarder)(nil)
+
+func NewSpanForwarder() *SpanForwarder {
+	return &SpanForwarder{
+		spans: make(chan rpc.RPCSpan, DefaultSpanForwarderBuffer),
+	}
+}
+
+func (f *SpanForwarder) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	for _, span := range spans {
+		select {
+		case f.spans <- rpc.NewRPCSpan(span):
+		default:
+			f.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+func (f *SpanForwarder) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// pending returns all buffered spans without blocking, along with the number of
+// spans dropped since the last call.
+func (f *SpanForwarder) pending() ([]rpc.RPCSpan, int64) {
+	var spans []rpc.RPCSpan
+	for {
+		select {
+		case span := <-f.spans:
+			spans = append(spans, span)
+		default:
+			return spans, f.dropped.Swap(0)
+		}
+	}
+}
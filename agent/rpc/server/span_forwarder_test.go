@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSpanForwarder(t *testing.T) {
+	t.Parallel()
+
+	f := NewSpanForwarder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(f))
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+	tracer := provider.Tracer("mid/test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	spans, dropped := f.pending()
+	assert.Zero(t, dropped)
+	require.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, "mid/test", spans[0].Scope)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+
+	spans, dropped = f.pending()
+	assert.Empty(t, spans)
+	assert.Zero(t, dropped)
+}
+
+func TestSpanForwarderDropped(t *testing.T) {
+	t.Parallel()
+
+	f := NewSpanForwarder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(f))
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+	tracer := provider.Tracer("mid/test")
+
+	// exporting never blocks, even once the buffer is full.
+	for i := range DefaultSpanForwarderBuffer + 3 {
+		_, span := tracer.Start(context.Background(), fmt.Sprintf("span %d", i))
+		span.End()
+	}
+
+	spans, dropped := f.pending()
+	assert.Equal(t, int64(3), dropped)
+	require.Len(t, spans, DefaultSpanForwarderBuffer)
+	for i, span := range spans {
+		assert.Equal(t, fmt.Sprintf("span %d", i), span.Name)
+	}
+
+	// the count starts over once it has been reported.
+	_, span := tracer.Start(context.Background(), "after")
+	span.End()
+	spans, dropped = f.pending()
+	assert.Zero(t, dropped)
+	require.Len(t, spans, 1)
+	assert.Equal(t, "after", spans[0].Name)
+}
@@ -0,0 +1,12 @@
+package rpc
+
+// SetEncodingArgs asks the server to switch the channel to another Encoding.
+// The result is sent using the old encoding and everything after it, in both
+// directions, uses the new one.
+type SetEncodingArgs struct {
+	Encoding Encoding
+}
+
+type SetEncodingResult struct {
+	Encoding Encoding
+}
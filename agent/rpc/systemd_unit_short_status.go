@@ -1,7 +1,12 @@
 package rpc
 
 import (
+	"context"
 	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type SystemdUnitShortStatusArgs struct {
@@ -17,18 +22,33 @@ type SystemdUnitShortStatusResult struct {
 }
 
 func SystemdUnitShortStatus(args SystemdUnitShortStatusArgs) (SystemdUnitShortStatusResult, error) {
+	return SystemdUnitShortStatusContext(context.Background(), args)
+}
+
+// SystemdUnitShortStatusContext is the same as SystemdUnitShortStatus except
+// that the systemctl commands it runs are tied to ctx.
+func SystemdUnitShortStatusContext(
+	ctx context.Context,
+	args SystemdUnitShortStatusArgs,
+) (SystemdUnitShortStatusResult, error) {
+	ctx, span := Tracer.Start(ctx, "mid/agent/rpc.SystemdUnitShortStatus", trace.WithAttributes(
+		attribute.String("systemd.unit", args.Name),
+	))
+	defer span.End()
+
 	result := SystemdUnitShortStatusResult{
 		Name: args.Name,
 	}
 
-	catResult, err := Exec(ExecArgs{
+	catResult, err := ExecStream(ctx, ExecArgs{
 		Command: []string{
 			"systemctl",
 			"cat",
 			args.Name,
 		},
-	})
+	}, nil)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return result, err
 	}
 
@@ -38,7 +58,7 @@ func SystemdUnitShortStatus(args SystemdUnitShortStatusArgs) (SystemdUnitShortSt
 		return result, nil
 	}
 
-	listUnits, err := Exec(ExecArgs{
+	listUnits, err := ExecStream(ctx, ExecArgs{
 		Command: []string{
 			"systemctl",
 			"list-units",
@@ -46,8 +66,9 @@ func SystemdUnitShortStatus(args SystemdUnitShortStatusArgs) (SystemdUnitShortSt
 			"--plain",
 			"--no-legend",
 		},
-	})
+	}, nil)
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		return result, err
 	}
 
@@ -0,0 +1,245 @@
+package rpc
+
+import (
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var Tracer = otel.Tracer("mid/agent/rpc")
+
+// RPCSpan is a finished span recorded by the agent, sent back to the provider
+// in an RPCResult so that it can be exported along with the provider's own
+// spans. IDs are hex encoded.
+type RPCSpan struct {
+	Name              string
+	Scope             string
+	TraceID           string
+	SpanID            string
+	ParentSpanID      string `json:",omitempty"`
+	Kind              trace.SpanKind
+	StartTime         time.Time
+	EndTime           time.Time
+	Attributes        []RPCSpanAttr  `json:",omitempty"`
+	Events            []RPCSpanEvent `json:",omitempty"`
+	StatusCode        codes.Code
+	StatusDescription string `json:",omitempty"`
+}
+
+type RPCSpanEvent struct {
+	Name       string
+	Time       time.Time
+	Attributes []RPCSpanAttr `json:",omitempty"`
+}
+
+// RPCSpanAttr is an attribute.KeyValue. Type is the name of the
+// attribute.Type. Slice values are sent in their string form and come back as
+// strings.
+type RPCSpanAttr struct {
+	Key   string
+	Type  string
+	Value string
+}
+
+// NewRPCSpan converts a span recorded by the OTel SDK.
+func NewRPCSpan(span sdktrace.ReadOnlySpan) RPCSpan {
+	result := RPCSpan{
+		Name:              span.Name(),
+		Scope:             span.InstrumentationScope().Name,
+		TraceID:           span.SpanContext().TraceID().String(),
+		SpanID:            span.SpanContext().SpanID().String(),
+		Kind:              span.SpanKind(),
+		StartTime:         span.StartTime(),
+		EndTime:           span.EndTime(),
+		Attributes:        newRPCSpanAttrs(span.Attributes()),
+		StatusCode:        span.Status().Code,
+		StatusDescription: span.Status().Description,
+	}
+	if span.Parent().HasSpanID() {
+		result.ParentSpanID = span.Parent().SpanID().String()
+	}
+	for _, event := range span.Events() {
+		result.Events = append(result.Events, RPCSpanEvent{
+			Name:       event.Name,
+			Time:       event.Time,
+			Attributes: newRPCSpanAttrs(event.Attributes),
+		})
+	}
+	return result
+}
+
+// ReadOnlySpan converts the span back into a form that can be handed to an OTel
+// SDK span processor. Spans with invalid IDs return false.
+func (span RPCSpan) ReadOnlySpan(res *resource.Resource) (sdktrace.ReadOnlySpan, bool) {
+	traceID, err := trace.TraceIDFromHex(span.TraceID)
+	if err != nil {
+		return nil, false
+	}
+	spanID, err := trace.SpanIDFromHex(span.SpanID)
+	if err != nil {
+		return nil, false
+	}
+	remote := &remoteSpan{
+		span: span,
+		spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+			Remote:     true,
+		}),
+		attributes: rpcSpanAttrsToKeyValues(span.Attributes),
+		resource:   res,
+	}
+	if span.ParentSpanID != "" {
+		parentID, err := trace.SpanIDFromHex(span.ParentSpanID)
+		if err == nil {
+			remote.parent = trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    traceID,
+				SpanID:     parentID,
+				TraceFlags: trace.FlagsSampled,
+			})
+		}
+	}
+	for _, event := range span.Events {
+		remote.events = append(remote.events, sdktrace.Event{
+			Name:       event.Name,
+			Time:       event.Time,
+			Attributes: rpcSpanAttrsToKeyValues(event.Attributes),
+		})
+	}
+	return remote, true
+}
+
+// remoteSpan is an RPCSpan as an sdktrace.ReadOnlySpan.
+type remoteSpan struct {
+	// ReadOnlySpan can only be implemented by embedding it. It is always nil,
+	// every method is implemented below.
+	sdktrace.ReadOnlySpan
+
+	span        RPCSpan
+	spanContext trace.SpanContext
+	parent      trace.SpanContext
+	attributes  []attribute.KeyValue
+	events      []sdktrace.Event
+	resource    *resource.Resource
+}
+
+func (s *remoteSpan) Name() string {
+	return s.span.Name
+}
+
+func (s *remoteSpan) SpanContext() trace.SpanContext {
+	return s.spanContext
+}
+
+func (s *remoteSpan) Parent() trace.SpanContext {
+	return s.parent
+}
+
+func (s *remoteSpan) SpanKind() trace.SpanKind {
+	return s.span.Kind
+}
+
+func (s *remoteSpan) StartTime() time.Time {
+	return s.span.StartTime
+}
+
+func (s *remoteSpan) EndTime() time.Time {
+	return s.span.EndTime
+}
+
+func (s *remoteSpan) Attributes() []attribute.KeyValue {
+	return s.attributes
+}
+
+func (s *remoteSpan) Links() []sdktrace.Link {
+	return nil
+}
+
+func (s *remoteSpan) Events() []sdktrace.Event {
+	return s.events
+}
+
+func (s *remoteSpan) Status() sdktrace.Status {
+	return sdktrace.Status{
+		Code:        s.span.StatusCode,
+		Description: s.span.StatusDescription,
+	}
+}
+
+func (s *remoteSpan) InstrumentationScope() instrumentation.Scope {
+	return instrumentation.Scope{Name: s.span.Scope}
+}
+
+//nolint:staticcheck // part of sdktrace.ReadOnlySpan
+func (s *remoteSpan) InstrumentationLibrary() instrumentation.Library {
+	return instrumentation.Library{Name: s.span.Scope}
+}
+
+func (s *remoteSpan) Resource() *resource.Resource {
+	return s.resource
+}
+
+func (s *remoteSpan) DroppedAttributes() int {
+	return 0
+}
+
+func (s *remoteSpan) DroppedLinks() int {
+	return 0
+}
+
+func (s *remoteSpan) DroppedEvents() int {
+	return 0
+}
+
+func (s *remoteSpan) ChildSpanCount() int {
+	return 0
+}
+
+func newRPCSpanAttrs(kvs []attribute.KeyValue) []RPCSpanAttr {
+	if len(kvs) == 0 {
+		return nil
+	}
+	attrs := make([]RPCSpanAttr, 0, len(kvs))
+	for _, kv := range kvs {
+		attrs = append(attrs, RPCSpanAttr{
+			Key:   string(kv.Key),
+			Type:  kv.Value.Type().String(),
+			Value: kv.Value.Emit(),
+		})
+	}
+	return attrs
+}
+
+func rpcSpanAttrsToKeyValues(attrs []RPCSpanAttr) []attribute.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		kv := attribute.String(attr.Key, attr.Value)
+		switch attr.Type {
+		case attribute.BOOL.String():
+			if v, err := strconv.ParseBool(attr.Value); err == nil {
+				kv = attribute.Bool(attr.Key, v)
+			}
+		case attribute.INT64.String():
+			if v, err := strconv.ParseInt(attr.Value, 10, 64); err == nil {
+				kv = attribute.Int64(attr.Key, v)
+			}
+		case attribute.FLOAT64.String():
+			if v, err := strconv.ParseFloat(attr.Value, 64); err == nil {
+				kv = attribute.Float64(attr.Key, v)
+			}
+		}
+		kvs = append(kvs, kv)
+	}
+	return kvs
+}
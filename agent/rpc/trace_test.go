@@ -0,0 +1,98 @@
+package rpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sapslaj/mid/agent/rpc"
+)
+
+func TestRPCSpanRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("mid/test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child", trace.WithSpanKind(trace.SpanKindServer))
+	child.SetAttributes(
+		attribute.String("string", "value"),
+		attribute.Bool("bool", true),
+		attribute.Int64("int", 42),
+		attribute.Float64("float", 1.5),
+		attribute.StringSlice("slice", []string{"a", "b"}),
+	)
+	child.AddEvent("event", trace.WithAttributes(attribute.Int64("n", 1)))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	original := spans[0]
+	require.Equal(t, "child", original.Name())
+
+	res := resource.NewSchemaless(attribute.String("service.name", "mid-agent"))
+	span, ok := rpc.NewRPCSpan(original).ReadOnlySpan(res)
+	require.True(t, ok)
+
+	assert.Equal(t, original.Name(), span.Name())
+	assert.Equal(t, original.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.Equal(t, original.SpanContext().SpanID(), span.SpanContext().SpanID())
+	assert.True(t, span.SpanContext().IsSampled())
+	assert.Equal(t, original.Parent().SpanID(), span.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.True(t, original.StartTime().Equal(span.StartTime()))
+	assert.True(t, original.EndTime().Equal(span.EndTime()))
+	assert.Equal(t, sdktrace.Status{Code: codes.Error, Description: "failed"}, span.Status())
+	assert.Equal(t, "mid/test", span.InstrumentationScope().Name)
+	assert.Equal(t, res, span.Resource())
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("string", "value"),
+		attribute.Bool("bool", true),
+		attribute.Int64("int", 42),
+		attribute.Float64("float", 1.5),
+		attribute.String("slice", `["a","b"]`),
+	}, span.Attributes())
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "event", span.Events()[0].Name)
+	assert.Equal(t, []attribute.KeyValue{attribute.Int64("n", 1)}, span.Events()[0].Attributes)
+	assert.Empty(t, span.Links())
+	assert.Zero(t, span.ChildSpanCount())
+
+	// the span can be handed to a span processor like any other.
+	exported := tracetest.NewInMemoryExporter()
+	processor := sdktrace.NewSimpleSpanProcessor(exported)
+	processor.OnEnd(span)
+	require.Len(t, exported.GetSpans(), 1)
+	assert.Equal(t, "child", exported.GetSpans()[0].Name)
+}
+
+func TestRPCSpanInvalidIDs(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]rpc.RPCSpan{
+		"empty":            {},
+		"invalid trace ID": {TraceID: "nope", SpanID: "0102030405060708"},
+		"invalid span ID":  {TraceID: "0102030405060708090a0b0c0d0e0f10", SpanID: "nope"},
+	}
+
+	for name, span := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, ok := span.ReadOnlySpan(resource.Empty())
+			assert.False(t, ok)
+		})
+	}
+}
@@ -1,8 +1,6 @@
 package rpc
 
 import (
-	"os"
-
 	"github.com/sapslaj/mid/agent/untar"
 )
 
@@ -11,16 +9,17 @@ type UntarArgs struct {
 	TargetDirectory string
 }
 
-type UntarResult struct{}
+type UntarResult struct {
+	// Format is the archive format detected from the source file.
+	Format untar.Format
+}
 
 func Untar(args UntarArgs) (UntarResult, error) {
-	reader, err := os.Open(args.SourceFilePath)
-	if err != nil {
-		return UntarResult{}, err
-	}
-	err = untar.Untar(reader, args.TargetDirectory)
+	format, err := untar.ExtractFile(args.SourceFilePath, args.TargetDirectory)
 	if err != nil {
 		return UntarResult{}, err
 	}
-	return UntarResult{}, nil
+	return UntarResult{
+		Format: format,
+	}, nil
 }
@@ -0,0 +1,218 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFileArgs writes one chunk of a file. Large files are written with
+// several calls: the first (Offset 0, no TempPath) creates a temporary file
+// next to Path, and every following call passes the TempPath it returned. The
+// call with Final set verifies the checksum, applies Mode, Owner and Group, and
+// atomically renames the temporary file to Path.
+type WriteFileArgs struct {
+	Path     string
+	TempPath string `json:",omitempty"`
+	Data     []byte `json:",omitempty"`
+	Offset   int64  `json:",omitempty"`
+	Final    bool   `json:",omitempty"`
+	// Abort removes TempPath without writing anything.
+	Abort bool `json:",omitempty"`
+
+	// the following are only used on the first call
+	CreateParents bool `json:",omitempty"`
+
+	// the following are only used on the final call
+	// Mode defaults to the mode of the file being replaced or 0600 for new
+	// files.
+	Mode           *fs.FileMode `json:",omitempty"`
+	Owner          string       `json:",omitempty"`
+	Group          string       `json:",omitempty"`
+	SHA256Checksum string       `json:",omitempty"`
+}
+
+type WriteFileResult struct {
+	// Path is always absolute.
+	Path     string
+	TempPath string `json:",omitempty"`
+	Size     int64
+	// SHA256Checksum is only set on the final call.
+	SHA256Checksum string `json:",omitempty"`
+}
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+func WriteFile(args WriteFileArgs) (WriteFileResult, error) {
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return WriteFileResult{}, err
+	}
+	result := WriteFileResult{
+		Path:     path,
+		TempPath: args.TempPath,
+	}
+
+	if args.Abort {
+		if args.TempPath != "" {
+			err = os.Remove(args.TempPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				err = nil
+			}
+		}
+		return result, err
+	}
+
+	var f *os.File
+	if args.TempPath == "" {
+		dir := filepath.Dir(path)
+		if args.CreateParents {
+			err = os.MkdirAll(dir, 0o755)
+			if err != nil {
+				return result, err
+			}
+		}
+		f, err = os.CreateTemp(dir, "."+filepath.Base(path)+".mid-*")
+		if err != nil {
+			return result, err
+		}
+		result.TempPath = f.Name()
+	} else {
+		f, err = os.OpenFile(args.TempPath, os.O_RDWR, 0)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// anything that fails from here on leaves the temp file in an unknown
+	// state, so get rid of it.
+	fail := func(err error) (WriteFileResult, error) {
+		f.Close()
+		os.Remove(result.TempPath)
+		return result, err
+	}
+
+	if len(args.Data) > 0 {
+		_, err = f.WriteAt(args.Data, args.Offset)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return fail(err)
+	}
+	result.Size = info.Size()
+
+	if !args.Final {
+		return result, f.Close()
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return fail(err)
+	}
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return fail(err)
+	}
+	result.SHA256Checksum = fmt.Sprintf("%x", h.Sum(nil))
+	if args.SHA256Checksum != "" && args.SHA256Checksum != result.SHA256Checksum {
+		return fail(fmt.Errorf(
+			"%w: expected %s, got %s",
+			ErrChecksumMismatch,
+			args.SHA256Checksum,
+			result.SHA256Checksum,
+		))
+	}
+
+	err = f.Sync()
+	if err != nil {
+		return fail(err)
+	}
+
+	mode := fs.FileMode(0o600)
+	existing, err := os.Stat(path)
+	if err == nil {
+		mode = existing.Mode().Perm()
+	}
+	if args.Mode != nil {
+		mode = *args.Mode
+	}
+	err = f.Chmod(mode)
+	if err != nil {
+		return fail(err)
+	}
+
+	if args.Owner != "" || args.Group != "" {
+		uid, gid, err := lookupOwner(args.Owner, args.Group)
+		if err != nil {
+			return fail(err)
+		}
+		err = f.Chown(uid, gid)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fail(err)
+	}
+
+	err = os.Rename(result.TempPath, path)
+	if err != nil {
+		os.Remove(result.TempPath)
+		return result, err
+	}
+	result.TempPath = ""
+
+	return result, nil
+}
+
+// lookupOwner resolves user and group names (or numeric IDs) to IDs suitable
+// for os.Chown. Empty names resolve to -1, which leaves them unchanged.
+func lookupOwner(owner string, group string) (int, int, error) {
+	uid := -1
+	gid := -1
+
+	if owner != "" {
+		id, err := strconv.Atoi(owner)
+		if err != nil {
+			usr, err := user.Lookup(owner)
+			if err != nil {
+				return uid, gid, err
+			}
+			id, err = strconv.Atoi(usr.Uid)
+			if err != nil {
+				return uid, gid, err
+			}
+		}
+		uid = id
+	}
+
+	if group != "" {
+		id, err := strconv.Atoi(group)
+		if err != nil {
+			grp, err := user.LookupGroup(group)
+			if err != nil {
+				return uid, gid, err
+			}
+			id, err = strconv.Atoi(grp.Gid)
+			if err != nil {
+				return uid, gid, err
+			}
+		}
+		gid = id
+	}
+
+	return uid, gid, nil
+}
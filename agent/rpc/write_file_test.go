@@ -0,0 +1,251 @@
+package rpc_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/pkg/ptr"
+)
+
+func checksum(data string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+// tempFiles returns the temporary files WriteFile left in dir.
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.mid-*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		chunks []string
+		args   rpc.WriteFileArgs
+		expect fs.FileMode
+	}{
+		"single chunk": {
+			chunks: []string{"hello\n"},
+			expect: 0o600,
+		},
+
+		"several chunks": {
+			chunks: []string{"one\n", "two\n", "three\n"},
+			expect: 0o600,
+		},
+
+		"empty": {
+			chunks: []string{""},
+			expect: 0o600,
+		},
+
+		"mode": {
+			chunks: []string{"#!/bin/sh\n"},
+			args:   rpc.WriteFileArgs{Mode: ptr.Of(fs.FileMode(0o755))},
+			expect: 0o755,
+		},
+
+		"checksum": {
+			chunks: []string{"abc", "def"},
+			args:   rpc.WriteFileArgs{SHA256Checksum: checksum("abcdef")},
+			expect: 0o600,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file")
+
+			result := rpc.WriteFileResult{}
+			offset := int64(0)
+			for i, chunk := range tc.chunks {
+				args := tc.args
+				args.Path = path
+				args.TempPath = result.TempPath
+				args.Data = []byte(chunk)
+				args.Offset = offset
+				args.Final = i == len(tc.chunks)-1
+				var err error
+				result, err = rpc.WriteFile(args)
+				require.NoError(t, err)
+				offset += int64(len(chunk))
+				assert.Equal(t, offset, result.Size)
+
+				if !args.Final {
+					assert.NotEmpty(t, result.TempPath)
+					assert.NoFileExists(t, path)
+				}
+			}
+
+			content := strings.Join(tc.chunks, "")
+			assert.Equal(t, path, result.Path)
+			assert.Empty(t, result.TempPath)
+			assert.Equal(t, checksum(content), result.SHA256Checksum)
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, info.Mode().Perm())
+			assert.Empty(t, tempFiles(t, dir))
+		})
+	}
+}
+
+func TestWriteFileKeepsMode(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o640))
+	require.NoError(t, os.Chmod(path, 0o640))
+
+	_, err := rpc.WriteFile(rpc.WriteFileArgs{Path: path, Data: []byte("new"), Final: true})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestWriteFileChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+
+	result, err := rpc.WriteFile(rpc.WriteFileArgs{Path: path, Data: []byte("abc")})
+	require.NoError(t, err)
+	_, err = rpc.WriteFile(rpc.WriteFileArgs{
+		Path:           path,
+		TempPath:       result.TempPath,
+		Data:           []byte("def"),
+		Offset:         3,
+		Final:          true,
+		SHA256Checksum: checksum("something else"),
+	})
+	assert.ErrorIs(t, err, rpc.ErrChecksumMismatch)
+
+	// the file being replaced is left alone and the temp file is removed.
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(data))
+	assert.Empty(t, tempFiles(t, dir))
+}
+
+func TestWriteFileAbort(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	result, err := rpc.WriteFile(rpc.WriteFileArgs{Path: path, Data: []byte("partial")})
+	require.NoError(t, err)
+	assert.Len(t, tempFiles(t, dir), 1)
+
+	_, err = rpc.WriteFile(rpc.WriteFileArgs{Path: path, TempPath: result.TempPath, Abort: true})
+	require.NoError(t, err)
+	assert.Empty(t, tempFiles(t, dir))
+	assert.NoFileExists(t, path)
+
+	// aborting twice is fine.
+	_, err = rpc.WriteFile(rpc.WriteFileArgs{Path: path, TempPath: result.TempPath, Abort: true})
+	require.NoError(t, err)
+}
+
+func TestWriteFileCreateParents(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "a", "b", "file")
+
+	_, err := rpc.WriteFile(rpc.WriteFileArgs{Path: path, Data: []byte("x"), Final: true})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, err = rpc.WriteFile(rpc.WriteFileArgs{Path: path, Data: []byte("x"), Final: true, CreateParents: true})
+	require.NoError(t, err)
+	assert.FileExists(t, path)
+}
+
+func TestWriteFileOwner(t *testing.T) {
+	t.Parallel()
+
+	current, err := user.Current()
+	require.NoError(t, err)
+	group, err := user.LookupGroupId(current.Gid)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		owner string
+		group string
+	}{
+		"names": {
+			owner: current.Username,
+			group: group.Name,
+		},
+
+		"ids": {
+			owner: current.Uid,
+			group: current.Gid,
+		},
+
+		"group only": {
+			group: group.Name,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "file")
+			_, err := rpc.WriteFile(rpc.WriteFileArgs{
+				Path:  path,
+				Data:  []byte("x"),
+				Final: true,
+				Owner: tc.owner,
+				Group: tc.group,
+			})
+			require.NoError(t, err)
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			stat := info.Sys().(*syscall.Stat_t)
+			assert.Equal(t, current.Uid, fmt.Sprint(stat.Uid))
+			assert.Equal(t, current.Gid, fmt.Sprint(stat.Gid))
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path := filepath.Join(dir, "file")
+		_, err := rpc.WriteFile(rpc.WriteFileArgs{
+			Path:  path,
+			Data:  []byte("x"),
+			Final: true,
+			Owner: "mid-no-such-user",
+		})
+		assert.Error(t, err)
+		assert.NoFileExists(t, path)
+		assert.Empty(t, tempFiles(t, dir))
+	})
+}
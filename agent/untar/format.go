@@ -0,0 +1,97 @@
+package untar
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Format is an archive format, detected from the magic bytes at the start of
+// the archive.
+type Format string
+
+const (
+	FormatTar      Format = "tar"
+	FormatTarGzip  Format = "tar.gz"
+	FormatTarBzip2 Format = "tar.bz2"
+	FormatTarXz    Format = "tar.xz"
+	FormatTarZstd  Format = "tar.zst"
+	FormatZip      Format = "zip"
+)
+
+var ErrUnknownFormat = errors.New("unknown archive format")
+
+// tarMagicOffset is where the "ustar" magic is in a tar header.
+const tarMagicOffset = 257
+
+var magics = []struct {
+	format Format
+	offset int
+	magic  []byte
+}{
+	{format: FormatTarGzip, magic: []byte{0x1f, 0x8b}},
+	{format: FormatTarBzip2, magic: []byte("BZh")},
+	{format: FormatTarXz, magic: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{format: FormatTarZstd, magic: []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{format: FormatZip, magic: []byte("PK\x03\x04")},
+	// empty zip archives are nothing but the end of central directory record
+	{format: FormatZip, magic: []byte("PK\x05\x06")},
+	// covers both POSIX ("ustar\x00") and GNU ("ustar ") tar
+	{format: FormatTar, offset: tarMagicOffset, magic: []byte("ustar")},
+}
+
+// DetectFormat detects the archive format from the start of an archive. At
+// least the first 262 bytes are needed to detect uncompressed tar.
+func DetectFormat(header []byte) (Format, error) {
+	for _, m := range magics {
+		if len(header) < m.offset+len(m.magic) {
+			continue
+		}
+		if bytes.Equal(header[m.offset:m.offset+len(m.magic)], m.magic) {
+			return m.format, nil
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// decompress detects the compression of a tarball and returns a reader for the
+// uncompressed tar stream.
+func decompress(reader io.Reader) (io.ReadCloser, Format, error) {
+	buffered := bufio.NewReaderSize(reader, 4096)
+	header, err := buffered.Peek(tarMagicOffset + len("ustar"))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, "", err
+	}
+
+	format, err := DetectFormat(header)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case FormatTar:
+		return io.NopCloser(buffered), format, nil
+	case FormatTarGzip:
+		r, err := gzip.NewReader(buffered)
+		return r, format, err
+	case FormatTarBzip2:
+		return io.NopCloser(bzip2.NewReader(buffered)), format, nil
+	case FormatTarXz:
+		r, err := xz.NewReader(buffered)
+		return io.NopCloser(r), format, err
+	case FormatTarZstd:
+		r, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, format, err
+		}
+		return r.IOReadCloser(), format, nil
+	}
+	return nil, format, fmt.Errorf("%w: %s is not a tarball", ErrUnknownFormat, format)
+}
@@ -0,0 +1,247 @@
+package untar_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+
+	"github.com/sapslaj/mid/agent/untar"
+)
+
+func compressed(t *testing.T, wrap func(io.Writer) (io.WriteCloser, error)) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := wrap(&buf)
+	require.NoError(t, err)
+	writeTar(t, w, tar.FormatUnknown, dir("a/"), file("a/b.txt", "b"))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+type zipEntry struct {
+	name string
+	mode fs.FileMode
+	body string
+}
+
+func zipArchive(t *testing.T, entries ...zipEntry) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		header.SetMode(e.mode)
+		w, err := zipWriter.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipWriter.Close())
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	var plain bytes.Buffer
+	writeTar(t, &plain, tar.FormatGNU, file("a.txt", "a"))
+
+	tests := map[string]struct {
+		header []byte
+		format untar.Format
+		err    error
+	}{
+		"tar": {
+			header: plain.Bytes(),
+			format: untar.FormatTar,
+		},
+		"gzip": {
+			header: []byte{0x1f, 0x8b, 0x08, 0x00},
+			format: untar.FormatTarGzip,
+		},
+		"bzip2": {
+			header: []byte("BZh91AY&SY"),
+			format: untar.FormatTarBzip2,
+		},
+		"xz": {
+			header: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00, 0x00},
+			format: untar.FormatTarXz,
+		},
+		"zstd": {
+			header: []byte{0x28, 0xb5, 0x2f, 0xfd, 0x04},
+			format: untar.FormatTarZstd,
+		},
+		"zip": {
+			header: []byte("PK\x03\x04\x14\x00"),
+			format: untar.FormatZip,
+		},
+		"empty zip": {
+			header: []byte("PK\x05\x06\x00\x00"),
+			format: untar.FormatZip,
+		},
+		"unknown": {
+			header: []byte("#!/bin/sh\n"),
+			err:    untar.ErrUnknownFormat,
+		},
+		"empty": {
+			header: []byte{},
+			err:    untar.ErrUnknownFormat,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := untar.DetectFormat(tc.header)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+		})
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	t.Parallel()
+
+	bzip2Fixture, err := os.ReadFile(filepath.Join("testdata", "archive.tar.bz2"))
+	require.NoError(t, err)
+
+	var plain bytes.Buffer
+	writeTar(t, &plain, tar.FormatUnknown, dir("a/"), file("a/b.txt", "b"))
+
+	tests := map[string]struct {
+		archive []byte
+		format  untar.Format
+		files   map[string]string
+		links   map[string]string
+		err     error
+	}{
+		"tar": {
+			archive: plain.Bytes(),
+			format:  untar.FormatTar,
+			files:   map[string]string{"a/b.txt": "b"},
+		},
+		"tar.gz": {
+			archive: compressed(t, func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			}),
+			format: untar.FormatTarGzip,
+			files:  map[string]string{"a/b.txt": "b"},
+		},
+		"tar.bz2": {
+			archive: bzip2Fixture,
+			format:  untar.FormatTarBzip2,
+			files:   map[string]string{"a/b.txt": "b"},
+		},
+		"tar.xz": {
+			archive: compressed(t, func(w io.Writer) (io.WriteCloser, error) {
+				return xz.NewWriter(w)
+			}),
+			format: untar.FormatTarXz,
+			files:  map[string]string{"a/b.txt": "b"},
+		},
+		"tar.zst": {
+			archive: compressed(t, func(w io.Writer) (io.WriteCloser, error) {
+				return zstd.NewWriter(w)
+			}),
+			format: untar.FormatTarZstd,
+			files:  map[string]string{"a/b.txt": "b"},
+		},
+		"zip": {
+			archive: zipArchive(
+				t,
+				zipEntry{name: "a/", mode: fs.ModeDir | 0o755},
+				zipEntry{name: "a/b.txt", mode: 0o644, body: "b"},
+				zipEntry{name: "link", mode: fs.ModeSymlink | 0o777, body: "a/b.txt"},
+			),
+			format: untar.FormatZip,
+			files: map[string]string{
+				"a/b.txt": "b",
+				"link":    "b",
+			},
+			links: map[string]string{"link": "a/b.txt"},
+		},
+		"empty zip": {
+			archive: zipArchive(t),
+			format:  untar.FormatZip,
+		},
+		"zip with parent directory": {
+			archive: zipArchive(t, zipEntry{name: "../escape.txt", mode: 0o644, body: "x"}),
+			err:     untar.ErrUnsafePath,
+		},
+		"zip with symlink out of target": {
+			archive: zipArchive(t, zipEntry{name: "link", mode: fs.ModeSymlink | 0o777, body: "../escape.txt"}),
+			err:     untar.ErrUnsafePath,
+		},
+		"unknown": {
+			archive: []byte("not an archive"),
+			err:     untar.ErrUnknownFormat,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parent := t.TempDir()
+			source := filepath.Join(parent, "archive")
+			require.NoError(t, os.WriteFile(source, tc.archive, 0o644))
+			target := filepath.Join(parent, "target")
+
+			format, err := untar.ExtractFile(source, target)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				_, err = os.Lstat(filepath.Join(parent, "escape.txt"))
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+
+			for name, body := range tc.files {
+				data, err := os.ReadFile(filepath.Join(target, name))
+				require.NoError(t, err, name)
+				assert.Equal(t, body, string(data), name)
+			}
+			for name, linkTarget := range tc.links {
+				got, err := os.Readlink(filepath.Join(target, name))
+				require.NoError(t, err, name)
+				assert.Equal(t, linkTarget, got, name)
+			}
+		})
+	}
+}
+
+func TestUntar_Formats(t *testing.T) {
+	t.Parallel()
+
+	// Untar works on streams, so it handles every tarball format but not zip.
+	var plain bytes.Buffer
+	writeTar(t, &plain, tar.FormatUnknown, file("a.txt", "a"))
+
+	target := t.TempDir()
+	require.NoError(t, untar.Untar(&plain, target))
+	data, err := os.ReadFile(filepath.Join(target, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a", string(data))
+
+	err = untar.Untar(bytes.NewReader(zipArchive(t, zipEntry{name: "a.txt", mode: 0o644})), t.TempDir())
+	require.ErrorIs(t, err, untar.ErrUnknownFormat)
+}
@@ -2,99 +2,360 @@ package untar
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
+	"os/user"
 	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
+var (
+	// ErrUnsafePath is returned for entries (or link targets) that would end up
+	// outside of the target directory.
+	ErrUnsafePath = errors.New("unsafe path in archive")
+
+	// ErrUnsupportedEntry is returned for entries that can't be extracted, such
+	// as device files.
+	ErrUnsupportedEntry = errors.New("unsupported archive entry")
+)
+
+// Untar extracts a tarball into target, creating it if needed. The tarball can
+// be uncompressed or compressed with gzip, bzip2, xz, or zstd, which is
+// detected from its magic bytes.
+//
+// Regular files, directories, symlinks, and hardlinks are supported. PAX and
+// GNU long name headers are handled by archive/tar. Every entry and every link
+// target must stay within target, and all filesystem operations go through an
+// os.Root so that a symlink in the archive can't be used to write outside of
+// it either. Permissions and modification times are preserved, as is
+// ownership when running as root.
 func Untar(reader io.Reader, target string) error {
+	_, err := untar(reader, target)
+	return err
+}
+
+func untar(reader io.Reader, target string) (Format, error) {
+	tarStream, format, err := decompress(reader)
+	if err != nil {
+		return format, err
+	}
+	defer tarStream.Close()
+
+	x, err := newExtractor(target)
+	if err != nil {
+		return format, err
+	}
+	defer x.root.Close()
+
+	tarReader := tar.NewReader(tarStream)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return format, err
+		}
+
+		err = x.extract(header, tarReader)
+		if err != nil {
+			return format, fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+
+	return format, x.finishDirs()
+}
+
+// ExtractFile extracts the archive at path into target, detecting its format
+// from its magic bytes. Both tarballs and zip archives are supported.
+func ExtractFile(path string, target string) (Format, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, tarMagicOffset+len("ustar"))
+	n, err := io.ReadFull(f, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	format, err := DetectFormat(header[:n])
+	if err != nil {
+		return "", err
+	}
+
+	if format == FormatZip {
+		info, err := f.Stat()
+		if err != nil {
+			return format, err
+		}
+		return format, Unzip(f, info.Size(), target)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return format, err
+	}
+	return untar(f, target)
+}
+
+func newExtractor(target string) (*extractor, error) {
 	err := os.MkdirAll(target, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extractor{
+		root:       root,
+		preserveID: os.Geteuid() == 0,
+		uids:       map[string]int{},
+		gids:       map[string]int{},
+	}, nil
+}
+
+type extractor struct {
+	root       *os.Root
+	preserveID bool
+	uids       map[string]int
+	gids       map[string]int
+	// dirs get their mode and times applied once everything else has been
+	// extracted, since a read-only directory can't have anything extracted into
+	// it and extracting into a directory updates its mtime.
+	dirs []*tar.Header
+}
+
+func (x *extractor) extract(header *tar.Header, reader io.Reader) error {
+	if header.Typeflag == tar.TypeXGlobalHeader {
+		return nil
+	}
+
+	name, err := localName(header.Name)
 	if err != nil {
 		return err
 	}
+	if name == "." {
+		if header.Typeflag == tar.TypeDir {
+			x.dirs = append(x.dirs, header)
+			return nil
+		}
+		return fmt.Errorf("%w: entry replaces the target directory", ErrUnsafePath)
+	}
 
-	gzReader, err := gzip.NewReader(reader)
+	err = x.root.MkdirAll(path.Dir(name), 0o755)
 	if err != nil {
 		return err
 	}
 
-	tarReader := tar.NewReader(gzReader)
+	switch header.Typeflag {
+	case tar.TypeReg, tar.TypeRegA, tar.TypeGNUSparse:
+		err = x.removeExisting(name, false)
+		if err != nil {
+			return err
+		}
+		f, err := x.root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, reader)
+		closeErr := f.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+		return x.applyMetadata(name, header)
 
-	for {
-		header, err := tarReader.Next()
+	case tar.TypeDir:
+		err = x.removeExisting(name, true)
+		if err != nil {
+			return err
+		}
+		err = x.root.Mkdir(name, 0o700)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		header.Name = name
+		x.dirs = append(x.dirs, header)
+		return nil
 
-		if err == io.EOF {
-			break
+	case tar.TypeSymlink:
+		err = checkSymlinkTarget(name, header.Linkname)
+		if err != nil {
+			return err
 		}
+		err = x.removeExisting(name, false)
+		if err != nil {
+			return err
+		}
+		err = x.root.Symlink(header.Linkname, name)
+		if err != nil {
+			return err
+		}
+		if x.preserveID {
+			uid, gid := x.owner(header)
+			return x.root.Lchown(name, uid, gid)
+		}
+		return nil
 
+	case tar.TypeLink:
+		linkname, err := localName(header.Linkname)
+		if err != nil {
+			return fmt.Errorf("hardlink target %q: %w", header.Linkname, err)
+		}
+		err = x.removeExisting(name, false)
 		if err != nil {
 			return err
 		}
+		return x.root.Link(linkname, name)
+
+	case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+		return fmt.Errorf("%w: device or FIFO", ErrUnsupportedEntry)
+	}
 
-		targetFilepath := path.Join(target, header.Name)
+	return fmt.Errorf("%w: type %q", ErrUnsupportedEntry, header.Typeflag)
+}
 
-		switch header.Typeflag {
-		case tar.TypeRegA:
-			fallthrough
-		case tar.TypeReg:
-			err = os.MkdirAll(path.Dir(targetFilepath), os.FileMode(header.Mode)|fs.ModeDir)
-			if err != nil && !errors.Is(err, os.ErrExist) {
-				return err
-			}
-			f, err := os.OpenFile(targetFilepath, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return err
-			}
-			_, err = io.Copy(f, tarReader)
-			if err != nil {
-				return err
-			}
-			f.Close()
-		case tar.TypeDir:
-			fileinfo, err := os.Stat(targetFilepath)
-			if err == nil && !fileinfo.IsDir() {
-				err = os.RemoveAll(targetFilepath)
+// removeExisting removes whatever is at name so that it can be replaced. Only
+// a directory that is being replaced by another directory is kept.
+func (x *extractor) removeExisting(name string, keepDir bool) error {
+	info, err := x.root.Lstat(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if keepDir {
+			return nil
+		}
+		return x.root.RemoveAll(name)
+	}
+	return x.root.Remove(name)
+}
+
+func (x *extractor) applyMetadata(name string, header *tar.Header) error {
+	if x.preserveID {
+		uid, gid := x.owner(header)
+		err := x.root.Lchown(name, uid, gid)
+		if err != nil {
+			return err
+		}
+	}
+	// chmod comes after chown since chown clears the setuid and setgid bits.
+	mode := header.FileInfo().Mode() & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+	err := x.root.Chmod(name, mode)
+	if err != nil {
+		return err
+	}
+	if header.ModTime.IsZero() {
+		return nil
+	}
+	atime := header.AccessTime
+	if atime.IsZero() {
+		atime = time.Now()
+	}
+	return x.root.Chtimes(name, atime, header.ModTime)
+}
+
+func (x *extractor) finishDirs() error {
+	// deepest first so that a parent's mtime isn't touched again afterwards.
+	slices.SortStableFunc(x.dirs, func(a, b *tar.Header) int {
+		return strings.Count(b.Name, "/") - strings.Count(a.Name, "/")
+	})
+	for _, header := range x.dirs {
+		name, err := localName(header.Name)
+		if err != nil {
+			return err
+		}
+		err = x.applyMetadata(name, header)
+		if err != nil {
+			return fmt.Errorf("%s: %w", header.Name, err)
+		}
+	}
+	return nil
+}
+
+// owner resolves the owner of an entry. Like GNU tar, names are preferred over
+// the numeric IDs if they exist on this system.
+func (x *extractor) owner(header *tar.Header) (int, int) {
+	uid := header.Uid
+	if header.Uname != "" {
+		id, cached := x.uids[header.Uname]
+		if !cached {
+			id = -1
+			usr, err := user.Lookup(header.Uname)
+			if err == nil {
+				id, err = strconv.Atoi(usr.Uid)
 				if err != nil {
-					return err
+					id = -1
 				}
 			}
-			if err != nil && !errors.Is(err, os.ErrNotExist) {
-				return err
-			}
-			err = os.MkdirAll(targetFilepath, os.FileMode(header.Mode))
-			if err != nil {
-				return err
+			x.uids[header.Uname] = id
+		}
+		if id >= 0 {
+			uid = id
+		}
+	}
+
+	gid := header.Gid
+	if header.Gname != "" {
+		id, cached := x.gids[header.Gname]
+		if !cached {
+			id = -1
+			grp, err := user.LookupGroup(header.Gname)
+			if err == nil {
+				id, err = strconv.Atoi(grp.Gid)
+				if err != nil {
+					id = -1
+				}
 			}
-		case tar.TypeLink:
-			fallthrough
-		case tar.TypeSymlink:
-			fallthrough
-		case tar.TypeChar:
-			fallthrough
-		case tar.TypeBlock:
-			fallthrough
-		case tar.TypeFifo:
-			fallthrough
-		case tar.TypeCont:
-			fallthrough
-		case tar.TypeXHeader:
-			fallthrough
-		case tar.TypeXGlobalHeader:
-			fallthrough
-		case tar.TypeGNUSparse:
-			fallthrough
-		case tar.TypeGNULongName:
-			fallthrough
-		case tar.TypeGNULongLink:
-			fallthrough
-		default:
-			return fmt.Errorf("unsupported tar header: '%v'", header.Typeflag)
+			x.gids[header.Gname] = id
 		}
+		if id >= 0 {
+			gid = id
+		}
+	}
+
+	return uid, gid
+}
+
+// localName cleans an entry name and makes sure it is relative and doesn't
+// climb out of the target directory.
+func localName(name string) (string, error) {
+	cleaned := path.Clean(strings.TrimSuffix(name, "/"))
+	if cleaned == "." {
+		return cleaned, nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(cleaned)) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
 	}
+	return cleaned, nil
+}
 
+// checkSymlinkTarget makes sure a symlink at name pointing to target resolves
+// to somewhere within the target directory. Absolute targets are rejected.
+func checkSymlinkTarget(name string, target string) error {
+	if target == "" || path.IsAbs(target) {
+		return fmt.Errorf("%w: symlink target %q", ErrUnsafePath, target)
+	}
+	_, err := localName(path.Join(path.Dir(name), target))
+	if err != nil {
+		return fmt.Errorf("%w: symlink target %q", ErrUnsafePath, target)
+	}
 	return nil
 }
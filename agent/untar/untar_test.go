@@ -0,0 +1,299 @@
+package untar_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/agent/untar"
+)
+
+type entry struct {
+	header tar.Header
+	body   string
+}
+
+func tarball(t *testing.T, format tar.Format, entries ...entry) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzWriter := gzip.NewWriter(&buf)
+	writeTar(t, gzWriter, format, entries...)
+	require.NoError(t, gzWriter.Close())
+	return &buf
+}
+
+func writeTar(t *testing.T, w io.Writer, format tar.Format, entries ...entry) {
+	t.Helper()
+
+	tarWriter := tar.NewWriter(w)
+	for _, e := range entries {
+		header := e.header
+		header.Format = format
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(e.body))
+		}
+		if header.Mode == 0 {
+			header.Mode = 0o644
+		}
+		require.NoError(t, tarWriter.WriteHeader(&header))
+		if e.body != "" {
+			_, err := tarWriter.Write([]byte(e.body))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tarWriter.Close())
+}
+
+func file(name string, body string) entry {
+	return entry{
+		header: tar.Header{Typeflag: tar.TypeReg, Name: name},
+		body:   body,
+	}
+}
+
+func dir(name string) entry {
+	return entry{
+		header: tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0o755},
+	}
+}
+
+func symlink(name string, target string) entry {
+	return entry{
+		header: tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: target, Mode: 0o777},
+	}
+}
+
+func hardlink(name string, target string) entry {
+	return entry{
+		header: tar.Header{Typeflag: tar.TypeLink, Name: name, Linkname: target},
+	}
+}
+
+func TestUntar(t *testing.T) {
+	t.Parallel()
+
+	longName := strings.Repeat("d", 120) + "/" + strings.Repeat("f", 120)
+
+	tests := map[string]struct {
+		format  tar.Format
+		entries []entry
+		files   map[string]string
+		links   map[string]string
+	}{
+		"files and directories": {
+			entries: []entry{
+				dir("a/"),
+				file("a/b.txt", "b"),
+				file("c/d.txt", "d"),
+			},
+			files: map[string]string{
+				"a/b.txt": "b",
+				"c/d.txt": "d",
+			},
+		},
+
+		"leading dot slash": {
+			entries: []entry{
+				dir("./"),
+				file("./a.txt", "a"),
+			},
+			files: map[string]string{
+				"a.txt": "a",
+			},
+		},
+
+		"symlinks": {
+			entries: []entry{
+				file("a/b.txt", "b"),
+				symlink("a/link", "b.txt"),
+				symlink("c", "a"),
+			},
+			files: map[string]string{
+				"a/link":  "b",
+				"c/b.txt": "b",
+			},
+			links: map[string]string{
+				"a/link": "b.txt",
+				"c":      "a",
+			},
+		},
+
+		"hardlinks": {
+			entries: []entry{
+				file("a/b.txt", "b"),
+				hardlink("c.txt", "a/b.txt"),
+			},
+			files: map[string]string{
+				"c.txt": "b",
+			},
+		},
+
+		"PAX long names": {
+			format: tar.FormatPAX,
+			entries: []entry{
+				file(longName, "long"),
+			},
+			files: map[string]string{
+				longName: "long",
+			},
+		},
+
+		"GNU long names": {
+			format: tar.FormatGNU,
+			entries: []entry{
+				file(longName, "long"),
+				symlink("link", longName),
+			},
+			files: map[string]string{
+				longName: "long",
+				"link":   "long",
+			},
+		},
+
+		"later entries replace earlier ones": {
+			entries: []entry{
+				file("a.txt", "first, and longer"),
+				file("a.txt", "second"),
+				file("b", "file"),
+				symlink("b", "a.txt"),
+			},
+			files: map[string]string{
+				"a.txt": "second",
+				"b":     "second",
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			target := t.TempDir()
+			err := untar.Untar(tarball(t, tc.format, tc.entries...), target)
+			require.NoError(t, err)
+
+			for name, body := range tc.files {
+				data, err := os.ReadFile(filepath.Join(target, name))
+				require.NoError(t, err, name)
+				assert.Equal(t, body, string(data), name)
+			}
+			for name, linkTarget := range tc.links {
+				got, err := os.Readlink(filepath.Join(target, name))
+				require.NoError(t, err, name)
+				assert.Equal(t, linkTarget, got, name)
+			}
+		})
+	}
+}
+
+func TestUntar_Unsafe(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		entries []entry
+		err     error
+	}{
+		"parent directory": {
+			entries: []entry{file("../escape.txt", "x")},
+			err:     untar.ErrUnsafePath,
+		},
+		"nested parent directory": {
+			entries: []entry{file("a/../../escape.txt", "x")},
+			err:     untar.ErrUnsafePath,
+		},
+		"absolute path": {
+			entries: []entry{file("/escape.txt", "x")},
+			err:     untar.ErrUnsafePath,
+		},
+		"absolute symlink": {
+			entries: []entry{symlink("link", "/etc")},
+			err:     untar.ErrUnsafePath,
+		},
+		"relative symlink out of target": {
+			entries: []entry{symlink("a/link", "../../escape")},
+			err:     untar.ErrUnsafePath,
+		},
+		"hardlink out of target": {
+			entries: []entry{hardlink("link", "../escape.txt")},
+			err:     untar.ErrUnsafePath,
+		},
+		"device": {
+			entries: []entry{{header: tar.Header{Typeflag: tar.TypeChar, Name: "null"}}},
+			err:     untar.ErrUnsupportedEntry,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parent := t.TempDir()
+			target := filepath.Join(parent, "target")
+			err := untar.Untar(tarball(t, tar.FormatUnknown, tc.entries...), target)
+			require.ErrorIs(t, err, tc.err)
+
+			_, err = os.Lstat(filepath.Join(parent, "escape.txt"))
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+		})
+	}
+}
+
+func TestUntar_WriteThroughExistingSymlink(t *testing.T) {
+	t.Parallel()
+
+	// a symlink that already exists in the target can't be used to write
+	// outside of it either.
+	parent := t.TempDir()
+	target := filepath.Join(parent, "target")
+	require.NoError(t, os.MkdirAll(target, 0o755))
+	require.NoError(t, os.Symlink(parent, filepath.Join(target, "link")))
+
+	err := untar.Untar(tarball(t, tar.FormatUnknown, file("link/escape.txt", "x")), target)
+	require.Error(t, err)
+
+	_, err = os.Lstat(filepath.Join(parent, "escape.txt"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestUntar_Metadata(t *testing.T) {
+	t.Parallel()
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	target := t.TempDir()
+	err := untar.Untar(tarball(
+		t,
+		tar.FormatPAX,
+		entry{
+			header: tar.Header{Typeflag: tar.TypeDir, Name: "ro/", Mode: 0o555, ModTime: mtime},
+		},
+		entry{
+			header: tar.Header{Typeflag: tar.TypeReg, Name: "ro/script.sh", Mode: 0o750, ModTime: mtime},
+			body:   "#!/bin/sh\n",
+		},
+	), target)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.Chmod(filepath.Join(target, "ro"), 0o755)
+	})
+
+	info, err := os.Stat(filepath.Join(target, "ro", "script.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o750), info.Mode().Perm())
+	assert.True(t, mtime.Equal(info.ModTime()), info.ModTime())
+
+	info, err = os.Stat(filepath.Join(target, "ro"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0o555), info.Mode().Perm())
+	assert.True(t, mtime.Equal(info.ModTime()), info.ModTime())
+}
@@ -0,0 +1,67 @@
+package untar
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// maxZipSymlinkTarget limits how much of a zip symlink entry's body is read as
+// its target.
+const maxZipSymlinkTarget = 4096
+
+// Unzip extracts a zip archive into target, creating it if needed. Entries are
+// extracted with the same rules as Untar: everything must stay within target,
+// and permissions, modification times, and symlinks (stored as Unix external
+// attributes) are preserved.
+func Unzip(reader io.ReaderAt, size int64, target string) error {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return err
+	}
+
+	x, err := newExtractor(target)
+	if err != nil {
+		return err
+	}
+	defer x.root.Close()
+
+	for _, f := range zipReader.File {
+		err = x.extractZipFile(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+	}
+
+	return x.finishDirs()
+}
+
+func (x *extractor) extractZipFile(f *zip.File) error {
+	body, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	info := f.FileInfo()
+	linkname := ""
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(body, maxZipSymlinkTarget))
+		if err != nil {
+			return err
+		}
+		linkname = string(target)
+	}
+
+	header, err := tar.FileInfoHeader(info, linkname)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnsupportedEntry, err)
+	}
+	// FileInfoHeader only uses the base name.
+	header.Name = f.Name
+	header.ModTime = f.Modified
+
+	return x.extract(header, body)
+}
@@ -6,12 +6,43 @@ import (
 	"fmt"
 )
 
-//go:embed mid-agent-linux-amd64
+//go:embed mid-agent-linux-amd64.gz
 var AgentBinary_linux_amd64_ []byte
 
-//go:embed mid-agent-linux-arm64
+//go:embed mid-agent-linux-amd64.sha256
+var AgentBinaryChecksum_linux_amd64_ string
+
+//go:embed mid-agent-linux-arm64.gz
 var AgentBinary_linux_arm64_ []byte
 
+//go:embed mid-agent-linux-arm64.sha256
+var AgentBinaryChecksum_linux_arm64_ string
+
+//go:embed mid-agent-linux-arm.gz
+var AgentBinary_linux_arm_ []byte
+
+//go:embed mid-agent-linux-arm.sha256
+var AgentBinaryChecksum_linux_arm_ string
+
+//go:embed mid-agent-linux-riscv64.gz
+var AgentBinary_linux_riscv64_ []byte
+
+//go:embed mid-agent-linux-riscv64.sha256
+var AgentBinaryChecksum_linux_riscv64_ string
+
+//go:embed mid-agent-linux-ppc64le.gz
+var AgentBinary_linux_ppc64le_ []byte
+
+//go:embed mid-agent-linux-ppc64le.sha256
+var AgentBinaryChecksum_linux_ppc64le_ string
+
+//go:embed mid-agent-linux-s390x.gz
+var AgentBinary_linux_s390x_ []byte
+
+//go:embed mid-agent-linux-s390x.sha256
+var AgentBinaryChecksum_linux_s390x_ string
+
+// GetAgentBinary returns the gzipped agent binary for goos and goarch.
 func GetAgentBinary(goos string, goarch string) ([]byte, error) {
 	switch goos {
 	case "linux":
@@ -20,6 +51,14 @@ func GetAgentBinary(goos string, goarch string) ([]byte, error) {
 			return AgentBinary_linux_amd64_, nil
 		case "arm64":
 			return AgentBinary_linux_arm64_, nil
+		case "arm":
+			return AgentBinary_linux_arm_, nil
+		case "riscv64":
+			return AgentBinary_linux_riscv64_, nil
+		case "ppc64le":
+			return AgentBinary_linux_ppc64le_, nil
+		case "s390x":
+			return AgentBinary_linux_s390x_, nil
 		default:
 			return nil, fmt.Errorf("unsupported GOARCH=%s", goarch)
 		}
@@ -27,3 +66,29 @@ func GetAgentBinary(goos string, goarch string) ([]byte, error) {
 		return nil, fmt.Errorf("unsupported GOOS=%s", goos)
 	}
 }
+
+// GetAgentBinaryChecksum returns the hex encoded SHA-256 of the uncompressed
+// agent binary for goos and goarch.
+func GetAgentBinaryChecksum(goos string, goarch string) (string, error) {
+	switch goos {
+	case "linux":
+		switch goarch {
+		case "amd64":
+			return AgentBinaryChecksum_linux_amd64_, nil
+		case "arm64":
+			return AgentBinaryChecksum_linux_arm64_, nil
+		case "arm":
+			return AgentBinaryChecksum_linux_arm_, nil
+		case "riscv64":
+			return AgentBinaryChecksum_linux_riscv64_, nil
+		case "ppc64le":
+			return AgentBinaryChecksum_linux_ppc64le_, nil
+		case "s390x":
+			return AgentBinaryChecksum_linux_s390x_, nil
+		default:
+			return "", fmt.Errorf("unsupported GOARCH=%s", goarch)
+		}
+	default:
+		return "", fmt.Errorf("unsupported GOOS=%s", goos)
+	}
+}
@@ -1,5 +1,7 @@
 #!/usr/bin/env python3
 
+import gzip
+import hashlib
 import multiprocessing
 import os
 import pathlib
@@ -14,19 +16,27 @@ GOOS = [
 GOARCH = [
     "amd64",
     "arm64",
+    "arm",
+    "riscv64",
+    "ppc64le",
+    "s390x",
 ]
 
+# ARMv6 binaries run on both ARMv6 (Raspberry Pi Zero/1) and ARMv7 hosts.
+GOARM = "6"
+
 agent_dir = pathlib.Path(__file__).parent / ".." / "agent"
 
 
 def build_agent(goos: str, goarch: str):
+    binary = agent_dir / f"mid-agent-{goos}-{goarch}"
     command = [
         "go",
         "build",
         "-ldflags",
         "-s -w",
         "-o",
-        str(agent_dir / f"mid-agent-{goos}-{goarch}"),
+        str(binary),
         str(agent_dir / "cmd" / "mid-agent"),
     ]
     print(f"GOOS={goos}", f"GOARCH={goarch}", " ".join(command))
@@ -38,8 +48,17 @@ def build_agent(goos: str, goarch: str):
             "CGO_ENABLED": "0",
             "GOOS": goos,
             "GOARCH": goarch,
+            "GOARM": GOARM,
         },
     )
+    # the binaries are embedded gzipped to cut down on upload time.
+    content = binary.read_bytes()
+    with open(f"{binary}.gz", "wb") as f:
+        f.write(gzip.compress(content, compresslevel=9, mtime=0))
+    # the checksum of the uncompressed binary is embedded as well so that it
+    # doesn't have to be decompressed to verify an install.
+    with open(f"{binary}.sha256", "w") as f:
+        f.write(hashlib.sha256(content).hexdigest())
 
 
 def main():
@@ -68,47 +87,69 @@ def main():
             for goarch in GOARCH:
                 f.writelines(
                     [
-                        f"//go:embed mid-agent-{goos}-{goarch}\n",
+                        f"//go:embed mid-agent-{goos}-{goarch}.gz\n",
                         f"var AgentBinary_{goos}_{goarch}_ []byte\n",
                         "\n",
+                        f"//go:embed mid-agent-{goos}-{goarch}.sha256\n",
+                        f"var AgentBinaryChecksum_{goos}_{goarch}_ string\n",
+                        "\n",
                     ]
                 )
+        write_getter(
+            f,
+            "GetAgentBinary",
+            "returns the gzipped agent binary for goos and goarch.",
+            "[]byte",
+            "AgentBinary",
+        )
+        f.write("\n")
+        write_getter(
+            f,
+            "GetAgentBinaryChecksum",
+            "returns the hex encoded SHA-256 of the uncompressed\n// agent binary for goos and goarch.",
+            "string",
+            "AgentBinaryChecksum",
+        )
+
+
+def write_getter(f, name: str, doc: str, result: str, var: str):
+    zero = "nil" if result == "[]byte" else '""'
+    f.writelines(
+        [
+            f"// {name} {doc}\n",
+            f"func {name}(goos string, goarch string) ({result}, error) {{\n",
+            "\tswitch goos {\n",
+        ]
+    )
+    for goos in GOOS:
         f.writelines(
             [
-                "func GetAgentBinary(goos string, goarch string) ([]byte, error) {\n",
-                "\tswitch goos {\n",
+                f'\tcase "{goos}":\n',
+                "\t\tswitch goarch {\n",
             ]
         )
-        for goos in GOOS:
-            f.writelines(
-                [
-                    f'\tcase "{goos}":\n',
-                    "\t\tswitch goarch {\n",
-                ]
-            )
-            for goarch in GOARCH:
-                f.writelines(
-                    [
-                        f'\t\tcase "{goarch}":\n',
-                        f"\t\t\treturn AgentBinary_{goos}_{goarch}_, nil\n",
-                    ]
-                )
+        for goarch in GOARCH:
             f.writelines(
                 [
-                    "\t\tdefault:\n",
-                    '\t\t\treturn nil, fmt.Errorf("unsupported GOARCH=%s", goarch)\n',
-                    "\t\t}\n",
+                    f'\t\tcase "{goarch}":\n',
+                    f"\t\t\treturn {var}_{goos}_{goarch}_, nil\n",
                 ]
             )
-
         f.writelines(
             [
-                "\tdefault:\n",
-                '\t\treturn nil, fmt.Errorf("unsupported GOOS=%s", goos)\n',
-                "\t}\n",
-                "}\n",
+                "\t\tdefault:\n",
+                f'\t\t\treturn {zero}, fmt.Errorf("unsupported GOARCH=%s", goarch)\n',
+                "\t\t}\n",
             ]
         )
+    f.writelines(
+        [
+            "\tdefault:\n",
+            f'\t\treturn {zero}, fmt.Errorf("unsupported GOOS=%s", goos)\n',
+            "\t}\n",
+            "}\n",
+        ]
+    )
 
 
 if __name__ == "__main__":
@@ -0,0 +1,320 @@
+// Package sshconfig looks hosts up in OpenSSH client config files.
+//
+// Only the subset of ssh_config(5) that is needed to connect to a host is
+// supported: Host, Match, Include, HostName, User, Port, IdentityFile,
+// ProxyJump, and UserKnownHostsFile. Everything else is ignored.
+package sshconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrSyntax = errors.New("ssh config syntax error")
+
+	ErrIncludeDepth = errors.New("too many nested Include directives")
+)
+
+// maxIncludeDepth is how deep Include directives can be nested, the same as in
+// OpenSSH.
+const maxIncludeDepth = 16
+
+// Settings are the settings of a host. Values that aren't set are empty.
+type Settings struct {
+	// HostName is the real host name, with "%h" already expanded.
+	HostName            string
+	User                string
+	Port                string
+	IdentityFiles       []string
+	ProxyJump           string
+	UserKnownHostsFiles []string
+}
+
+// Resolve looks up the settings of host in the config file at path. Like with
+// ssh(1), the first value found for each setting is used, except that
+// IdentityFiles accumulate.
+func Resolve(path string, host string) (Settings, error) {
+	r := &resolver{
+		alias:    strings.ToLower(host),
+		hostname: strings.ToLower(host),
+		seen:     map[string]bool{},
+	}
+	err := r.file(path, 0)
+	if err != nil {
+		return Settings{}, err
+	}
+	return r.settings, nil
+}
+
+type resolver struct {
+	alias    string
+	hostname string
+	settings Settings
+	seen     map[string]bool
+}
+
+func (r *resolver) file(path string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("%w in %s", ErrIncludeDepth, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// the top of the file applies to every host.
+	active := true
+	for i, line := range strings.Split(string(content), "\n") {
+		keyword, args, err := parseLine(line)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%d: %w", ErrSyntax, path, i+1, err)
+		}
+		if keyword == "" {
+			continue
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("%w: %s:%d: %s has no value", ErrSyntax, path, i+1, keyword)
+		}
+
+		switch keyword {
+		case "host":
+			active = matchPatternList(r.alias, args)
+		case "match":
+			active, err = r.match(args)
+			if err != nil {
+				return fmt.Errorf("%w: %s:%d: %w", ErrSyntax, path, i+1, err)
+			}
+		case "include":
+			if !active {
+				continue
+			}
+			for _, pattern := range args {
+				files, err := filepath.Glob(includePath(pattern))
+				if err != nil {
+					return fmt.Errorf("%w: %s:%d: %w", ErrSyntax, path, i+1, err)
+				}
+				for _, file := range files {
+					err := r.file(file, depth+1)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		default:
+			if active {
+				r.set(keyword, args)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *resolver) set(keyword string, args []string) {
+	if keyword == "identityfile" {
+		r.settings.IdentityFiles = append(r.settings.IdentityFiles, args[0])
+		return
+	}
+	if r.seen[keyword] {
+		return
+	}
+	switch keyword {
+	case "hostname":
+		r.settings.HostName = ExpandTokens(args[0], map[byte]string{'h': r.alias})
+		// Match host is checked against the real host name from here on.
+		r.hostname = strings.ToLower(r.settings.HostName)
+	case "user":
+		r.settings.User = args[0]
+	case "port":
+		r.settings.Port = args[0]
+	case "proxyjump":
+		r.settings.ProxyJump = args[0]
+	case "userknownhostsfile":
+		r.settings.UserKnownHostsFiles = args
+	default:
+		return
+	}
+	r.seen[keyword] = true
+}
+
+// match reports whether the criteria of a Match line match. As there is only
+// one pass over the config, "final" always matches and "canonical" never does.
+// Criteria that aren't supported (e.g. exec) never match either.
+func (r *resolver) match(args []string) (bool, error) {
+	matched := true
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negated := false
+		if rest, ok := strings.CutPrefix(criterion, "!"); ok {
+			criterion = rest
+			negated = true
+		}
+
+		var ok bool
+		switch criterion {
+		case "all", "final":
+			ok = true
+		case "canonical":
+			ok = false
+		default:
+			if i+1 == len(args) {
+				return false, fmt.Errorf("match criterion %q has no argument", criterion)
+			}
+			i++
+			switch criterion {
+			case "host":
+				ok = matchPatternList(r.hostname, strings.Split(args[i], ","))
+			case "originalhost":
+				ok = matchPatternList(r.alias, strings.Split(args[i], ","))
+			}
+		}
+		if ok == negated {
+			matched = false
+		}
+	}
+	return matched, nil
+}
+
+// includePath returns where the file or glob pattern of an Include directive
+// is. Relative paths are relative to ~/.ssh.
+func includePath(pattern string) string {
+	home, _ := os.UserHomeDir()
+	if rest, ok := strings.CutPrefix(pattern, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	if filepath.IsAbs(pattern) {
+		return pattern
+	}
+	return filepath.Join(home, ".ssh", pattern)
+}
+
+// parseLine splits line into its lowercase keyword and its arguments. The
+// keyword is empty for blank lines and comments.
+func parseLine(line string) (string, []string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil, nil
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), nil, nil
+	}
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimSpace(line[end:])
+	// the keyword and the arguments can be separated by one "=" as well.
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "="))
+	args, err := splitArgs(rest)
+	return keyword, args, err
+}
+
+// splitArgs splits s on whitespace, except within double quotes.
+func splitArgs(s string) ([]string, error) {
+	args := []string{}
+	var arg strings.Builder
+	inArg := false
+	quoted := false
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (c == ' ' || c == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case !quoted && c == '#' && !inArg:
+			// the rest of the line is a comment.
+			return args, nil
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// matchPatternList reports whether host matches patterns, none of the negated
+// ones and at least one of the others.
+func matchPatternList(host string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if rest, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchPattern(host, rest) {
+				return false
+			}
+			continue
+		}
+		if matchPattern(host, pattern) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern reports whether s matches pattern, where "*" matches any
+// number of characters and "?" exactly one.
+func matchPattern(s string, pattern string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			pattern = pattern[1:]
+			if pattern == "" {
+				return true
+			}
+			for i := range len(s) + 1 {
+				if matchPattern(s[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		s = s[1:]
+		pattern = pattern[1:]
+	}
+	return s == ""
+}
+
+// ExpandTokens replaces the "%" tokens (e.g. "%h") of a setting with the
+// values in tokens. "%%" is a literal "%", and unknown tokens are left as they
+// are.
+func ExpandTokens(value string, tokens map[byte]string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		token, ok := tokens[value[i]]
+		switch {
+		case value[i] == '%':
+			b.WriteByte('%')
+		case ok:
+			b.WriteString(token)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,310 @@
+package sshconfig_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/pkg/sshconfig"
+)
+
+// writeConfigs writes files to a temporary directory, replacing "$DIR" in
+// their contents with its path, and returns the path of the "config" file.
+func writeConfigs(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		content = strings.ReplaceAll(content, "$DIR", dir)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return filepath.Join(dir, "config")
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		files  map[string]string
+		host   string
+		expect sshconfig.Settings
+	}{
+		"empty": {
+			files:  map[string]string{"config": ""},
+			host:   "web",
+			expect: sshconfig.Settings{},
+		},
+
+		"host block": {
+			files: map[string]string{
+				"config": `
+# comment
+Host web
+  HostName web.example.com
+  User deploy
+  Port 2222
+  IdentityFile ~/.ssh/web
+
+Host db
+  HostName db.example.com
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				HostName:      "web.example.com",
+				User:          "deploy",
+				Port:          "2222",
+				IdentityFiles: []string{"~/.ssh/web"},
+			},
+		},
+
+		"first value wins and identity files accumulate": {
+			files: map[string]string{
+				"config": `
+Host web
+  User deploy
+  IdentityFile ~/.ssh/web
+Host *
+  User root
+  Port 22
+  IdentityFile ~/.ssh/id_ed25519
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				User:          "deploy",
+				Port:          "22",
+				IdentityFiles: []string{"~/.ssh/web", "~/.ssh/id_ed25519"},
+			},
+		},
+
+		"wildcards and negation": {
+			files: map[string]string{
+				"config": `
+Host *.example.com !bastion.example.com
+  ProxyJump bastion.example.com
+Host web-?.example.com
+  User deploy
+`,
+			},
+			host: "web-1.example.com",
+			expect: sshconfig.Settings{
+				User:      "deploy",
+				ProxyJump: "bastion.example.com",
+			},
+		},
+
+		"negated host": {
+			files: map[string]string{
+				"config": `
+Host *.example.com !bastion.example.com
+  ProxyJump bastion.example.com
+`,
+			},
+			host:   "bastion.example.com",
+			expect: sshconfig.Settings{},
+		},
+
+		"hostname token and case insensitivity": {
+			files: map[string]string{
+				"config": `
+HOST Web
+  hostname = %h.internal.example.com
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				HostName: "web.internal.example.com",
+			},
+		},
+
+		"match host against host name": {
+			files: map[string]string{
+				"config": `
+Host web
+  HostName 10.0.0.5
+Match host 10.0.0.*,192.168.*
+  User ops
+Match originalhost web
+  Port 2200
+Match host web
+  UserKnownHostsFile /nonexistent
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				HostName: "10.0.0.5",
+				User:     "ops",
+				Port:     "2200",
+			},
+		},
+
+		"match all, final, and unsupported criteria": {
+			files: map[string]string{
+				"config": `
+Match exec "true"
+  User exec
+Match canonical
+  User canonical
+Match final all
+  User final
+Match !host web
+  Port 1
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				User: "final",
+			},
+		},
+
+		"include": {
+			files: map[string]string{
+				"config": `
+Include $DIR/config.d/*.conf
+Host *
+  User fallback
+`,
+				"config.d/10-web.conf": `
+Host web
+  User deploy
+`,
+				"config.d/20-all.conf": `
+Host *
+  Port 2222
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				User: "deploy",
+				Port: "2222",
+			},
+		},
+
+		"include within host block": {
+			files: map[string]string{
+				"config": `
+Host db
+  Include $DIR/db.conf
+Host web
+  Include $DIR/web.conf
+`,
+				"db.conf":  "User db\n",
+				"web.conf": "User web\n",
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				User: "web",
+			},
+		},
+
+		"known hosts files and quoting": {
+			files: map[string]string{
+				"config": `
+Host web
+  UserKnownHostsFile ~/.ssh/known_hosts "/path with spaces/known_hosts" # comment
+`,
+			},
+			host: "web",
+			expect: sshconfig.Settings{
+				UserKnownHostsFiles: []string{"~/.ssh/known_hosts", "/path with spaces/known_hosts"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			settings, err := sshconfig.Resolve(writeConfigs(t, tc.files), tc.host)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, settings)
+		})
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		files  map[string]string
+		expect error
+	}{
+		"missing value": {
+			files:  map[string]string{"config": "Host web\n  User\n"},
+			expect: sshconfig.ErrSyntax,
+		},
+
+		"unterminated quote": {
+			files:  map[string]string{"config": "IdentityFile \"~/.ssh/id\n"},
+			expect: sshconfig.ErrSyntax,
+		},
+
+		"match without argument": {
+			files:  map[string]string{"config": "Match host\n"},
+			expect: sshconfig.ErrSyntax,
+		},
+
+		"include loop": {
+			files:  map[string]string{"config": "Include $DIR/config\n"},
+			expect: sshconfig.ErrIncludeDepth,
+		},
+
+		"missing file": {
+			files:  map[string]string{},
+			expect: os.ErrNotExist,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := sshconfig.Resolve(writeConfigs(t, tc.files), "web")
+			assert.ErrorIs(t, err, tc.expect)
+		})
+	}
+}
+
+func TestResolveRelativeInclude(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".ssh", "hosts"), []byte("Host web\n  User deploy\n"), 0o600))
+	config := filepath.Join(home, ".ssh", "config")
+	require.NoError(t, os.WriteFile(config, []byte("Include hosts\n"), 0o600))
+
+	settings, err := sshconfig.Resolve(config, "web")
+	require.NoError(t, err)
+	assert.Equal(t, sshconfig.Settings{User: "deploy"}, settings)
+}
+
+func TestExpandTokens(t *testing.T) {
+	t.Parallel()
+
+	tokens := map[byte]string{'h': "web", 'u': "alice"}
+	tests := map[string]struct {
+		value  string
+		expect string
+	}{
+		"no tokens":     {value: "~/.ssh/id_ed25519", expect: "~/.ssh/id_ed25519"},
+		"tokens":        {value: "~/.ssh/%u@%h", expect: "~/.ssh/alice@web"},
+		"literal":       {value: "100%%", expect: "100%"},
+		"unknown token": {value: "%C", expect: "%C"},
+		"trailing":      {value: "web%", expect: "web%"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, sshconfig.ExpandTokens(tc.value, tokens))
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-slog/otelslog"
@@ -197,10 +198,12 @@ otel:
 			goto end
 		}
 
+		spanProcessor := sdktrace.NewBatchSpanProcessor(ts.OtlpExporter)
+		remoteSpanProcessor.Store(&spanProcessor)
 		otel.SetTracerProvider(
 			sdktrace.NewTracerProvider(
 				sdktrace.WithSampler(sdktrace.AlwaysSample()),
-				sdktrace.WithBatcher(ts.OtlpExporter),
+				sdktrace.WithSpanProcessor(spanProcessor),
 				sdktrace.WithResource(res),
 			),
 		)
@@ -211,6 +214,23 @@ end:
 	return ts
 }
 
+// remoteSpanProcessor is the span processor set up by StartTelemetry, kept
+// around for ExportSpans.
+var remoteSpanProcessor atomic.Pointer[sdktrace.SpanProcessor]
+
+// ExportSpans exports spans that were recorded outside of this process (e.g.
+// by the agent) through the same pipeline as the provider's own spans. It
+// does nothing if telemetry is disabled.
+func ExportSpans(spans ...sdktrace.ReadOnlySpan) {
+	spanProcessor := remoteSpanProcessor.Load()
+	if spanProcessor == nil {
+		return
+	}
+	for _, span := range spans {
+		(*spanProcessor).OnEnd(span)
+	}
+}
+
 // OtelJSON JSON marshals any value into an otel attribute.KeyValue
 func OtelJSON(key string, value any) attribute.KeyValue {
 	data, err := json.Marshal(value)
@@ -10,6 +10,7 @@ import (
 	"github.com/sapslaj/mid/agent/rpc"
 	"github.com/sapslaj/mid/pkg/providerfw/infer"
 	"github.com/sapslaj/mid/pkg/telemetry"
+	"github.com/sapslaj/mid/provider/executor"
 	"github.com/sapslaj/mid/provider/midtypes"
 )
 
@@ -22,8 +23,14 @@ type AgentPingInput struct {
 }
 
 type AgentPingOutput struct {
-	Ping string `pulumi:"ping"`
-	Pong string `pulumi:"pong"`
+	Ping                  string   `pulumi:"ping"`
+	Pong                  string   `pulumi:"pong"`
+	AgentVersion          string   `pulumi:"agentVersion"`
+	ProtocolVersion       int      `pulumi:"protocolVersion"`
+	RPCFunctions          []string `pulumi:"rpcFunctions"`
+	AnsibleInstallCached  *bool    `pulumi:"ansibleInstallCached,optional"`
+	AnsibleInstallSeconds *float64 `pulumi:"ansibleInstallSeconds,optional"`
+	HostKey               *string  `pulumi:"hostKey,optional"`
 }
 
 func (f AgentPing) Invoke(
@@ -52,8 +59,22 @@ func (f AgentPing) Invoke(
 	}
 
 	output := AgentPingOutput{
-		Ping: out.Ping,
-		Pong: out.Pong,
+		Ping:            out.Ping,
+		Pong:            out.Pong,
+		AgentVersion:    out.AgentVersion,
+		ProtocolVersion: out.ProtocolVersion,
+		RPCFunctions:    []string{},
+	}
+	for _, rpcFunction := range out.RPCFunctions {
+		output.RPCFunctions = append(output.RPCFunctions, string(rpcFunction))
+	}
+	if out.AnsibleInstall != nil {
+		output.AnsibleInstallCached = &out.AnsibleInstall.Cached
+		seconds := out.AnsibleInstall.Duration.Seconds()
+		output.AnsibleInstallSeconds = &seconds
+	}
+	if hostKey := executor.ObservedHostKey(midtypes.GetConnection(ctx, req.Input.Connection)); hostKey != "" {
+		output.HostKey = &hostKey
 	}
 	span.SetAttributes(telemetry.OtelJSON("pulumi.output", output))
 
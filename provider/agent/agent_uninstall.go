@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/pkg/providerfw/infer"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/pkg/telemetry"
+	"github.com/sapslaj/mid/provider/executor"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+// AgentUninstall removes the agent directory, and with it the agent, its
+// staging area, and the Ansible modules, from the remote host. A running agent
+// for the connection is disconnected first; anything using the connection
+// afterwards installs the agent again.
+type AgentUninstall struct{}
+
+type AgentUninstallInput struct {
+	Connection *midtypes.Connection     `pulumi:"connection,optional"`
+	Config     *midtypes.ResourceConfig `pulumi:"config,optional"`
+}
+
+type AgentUninstallOutput struct {
+	AgentDir string `pulumi:"agentDir"`
+	Removed  bool   `pulumi:"removed"`
+}
+
+func (f AgentUninstall) Invoke(
+	ctx context.Context,
+	req infer.FunctionRequest[AgentUninstallInput],
+) (infer.FunctionResponse[AgentUninstallOutput], error) {
+	ctx, span := Tracer.Start(ctx, "mid/provider/agent/agentUninstall.Call", trace.WithAttributes(
+		attribute.String("pulumi.function", "mid:agent:agentUninstall"),
+		telemetry.OtelJSON("pulumi.input", req.Input),
+	))
+	defer span.End()
+
+	connection := midtypes.GetConnection(ctx, req.Input.Connection)
+	config := midtypes.GetResourceConfig(ctx, req.Input.Config)
+
+	output := AgentUninstallOutput{}
+	agentDir, err := rpc.CleanAgentDir(ptr.FromDefault(connection.AgentDir, ""))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return infer.FunctionResponse[AgentUninstallOutput]{Output: output}, err
+	}
+	output.AgentDir = agentDir
+
+	output.Removed, err = executor.UninstallAgent(ctx, connection, config)
+	if err == nil {
+		span.SetStatus(codes.Ok, "")
+	} else {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.SetAttributes(telemetry.OtelJSON("pulumi.output", output))
+
+	return infer.FunctionResponse[AgentUninstallOutput]{
+		Output: output,
+	}, err
+}
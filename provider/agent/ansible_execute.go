@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -21,17 +22,20 @@ type AnsibleExecuteInput struct {
 	Environment        map[string]string        `pulumi:"environment,optional"`
 	Check              bool                     `pulumi:"check,optional"`
 	DebugKeepTempFiles bool                     `pulumi:"debugKeepTempFiles,optional"`
+	Timeout            int                      `pulumi:"timeout,optional"`
+	RunAs              *midtypes.RunAs          `pulumi:"runAs,optional"`
 	Connection         *midtypes.Connection     `pulumi:"connection,optional"`
 	Config             *midtypes.ResourceConfig `pulumi:"config,optional"`
 }
 
 type AnsibleExecuteOutput struct {
 	AnsibleExecuteInput
-	Stderr       string         `pulumi:"stderr"`
-	Stdout       string         `pulumi:"stdout"`
-	ExitCode     int            `pulumi:"exitCode"`
-	Result       map[string]any `pulumi:"result"`
-	DebugTempDir *string        `pulumi:"debugTempDir,optional"`
+	Stderr            string         `pulumi:"stderr"`
+	Stdout            string         `pulumi:"stdout"`
+	ExitCode          int            `pulumi:"exitCode"`
+	Result            map[string]any `pulumi:"result"`
+	DebugTempDir      *string        `pulumi:"debugTempDir,optional"`
+	PythonInterpreter *string        `pulumi:"pythonInterpreter,optional"`
 }
 
 func (f AnsibleExecute) Invoke(
@@ -55,6 +59,8 @@ func (f AnsibleExecute) Invoke(
 			Environment:        req.Input.Environment,
 			Check:              req.Input.Check,
 			DebugKeepTempFiles: req.Input.DebugKeepTempFiles,
+			Timeout:            time.Duration(req.Input.Timeout) * time.Second,
+			RunAs:              req.Input.RunAs.ToRPC(),
 		},
 	)
 
@@ -71,6 +77,7 @@ func (f AnsibleExecute) Invoke(
 		ExitCode:            out.ExitCode,
 		Result:              out.Result,
 		DebugTempDir:        ToOptional(out.DebugTempDir),
+		PythonInterpreter:   ToOptional(out.PythonInterpreter),
 	}
 	span.SetAttributes(telemetry.OtelJSON("pulumi.output", output))
 
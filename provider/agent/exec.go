@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -21,6 +22,8 @@ type ExecInput struct {
 	Environment        map[string]string        `pulumi:"environment,optional"`
 	Stdin              string                   `pulumi:"stdin,optional"`
 	ExpandArgumentVars bool                     `pulumi:"expandArgumentVars,optional"`
+	Timeout            int                      `pulumi:"timeout,optional"`
+	RunAs              *midtypes.RunAs          `pulumi:"runAs,optional"`
 	Connection         *midtypes.Connection     `pulumi:"connection,optional"`
 	Config             *midtypes.ResourceConfig `pulumi:"config,optional"`
 }
@@ -54,6 +57,8 @@ func (f Exec) Invoke(
 			Environment:        req.Input.Environment,
 			Stdin:              []byte(req.Input.Stdin),
 			ExpandArgumentVars: req.Input.ExpandArgumentVars,
+			Timeout:            time.Duration(req.Input.Timeout) * time.Second,
+			RunAs:              req.Input.RunAs.ToRPC(),
 		},
 	)
 
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/retry"
@@ -36,13 +37,25 @@ var (
 	ErrUnreachable = errors.New("host is unreachable")
 
 	ErrHostUnset = errors.New("host is not set in the connection configuration")
+
+	ErrCallNotRecovered = errors.New("call could not be recovered after losing the connection to the agent")
 )
 
+// MaxReconnectAttempts is how many times CallAgent reconnects to the agent and
+// retries a call after the connection to it was lost.
+const MaxReconnectAttempts = 3
+
 type ConnectionState struct {
-	ID              uint64
-	Reachable       bool
-	Unreachable     bool
-	MaxParallel     int
+	ID                uint64
+	Reachable         bool
+	Unreachable       bool
+	MaxParallel       int
+	HeartbeatInterval time.Duration
+	HeartbeatTimeout  time.Duration
+	AgentGCAfter      time.Duration
+	// HostKey is the key the host presented the last time it was connected
+	// to, in authorized_keys format.
+	HostKey         atomic.Pointer[string]
 	TaskCount       int
 	SetupAgentMutex sync.Mutex
 	CanConnectMutex sync.Mutex
@@ -53,6 +66,59 @@ type ConnectionState struct {
 
 var AgentPool = syncmap.Map[uint64, *ConnectionState]{}
 
+// remoteLogDiagnostic surfaces warnings and errors logged by the agent as
+// diagnostics on the resource whose call produced them. Errors are reported as
+// warnings as well: if they cause the call to fail that is reported on its
+// own, and plenty of them are handled (e.g. a failed check that leads to a
+// change) and shouldn't fail the deployment.
+func (cs *ConnectionState) remoteLogDiagnostic(ctx context.Context, record rpc.RPCLogRecord) {
+	if record.Level < slog.LevelWarn {
+		return
+	}
+	msg := record.Message
+	for _, attr := range record.Attrs {
+		if attr.Key == "error" {
+			msg += ": " + attr.Value
+		}
+	}
+	p.GetLogger(ctx).Warningf("agent on %s: %s", ptr.FromDefault(cs.Connection.Host, "unknown host"), msg)
+}
+
+// recordHostKey wraps callback to remember the key the host presents once
+// callback accepts it.
+func (cs *ConnectionState) recordHostKey(callback ssh.HostKeyCallback) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		if err != nil {
+			return err
+		}
+		hostKey := FormatHostKey(key)
+		cs.HostKey.Store(&hostKey)
+		return nil
+	}
+}
+
+// ObservedHostKey returns the key the host of connection presented and was
+// accepted the last time it was connected to, in authorized_keys format. It is empty if it hasn't
+// been connected to yet.
+func ObservedHostKey(connection midtypes.Connection) string {
+	id, err := hashstructure.Hash(connection, hashstructure.FormatV2, nil)
+	if err != nil {
+		return ""
+	}
+	cs, ok := AgentPool.Load(id)
+	if !ok {
+		return ""
+	}
+	return ptr.FromDefault(cs.HostKey.Load(), "")
+}
+
+// agentStatus shows what the agent is held up by, e.g. another install of the
+// agent, on the info line of the resource being connected for.
+func agentStatus(ctx context.Context, msg string) {
+	p.GetLogger(ctx).InfoStatus(msg)
+}
+
 func (cs *ConnectionState) SetupAgent(ctx context.Context) error {
 	ctx, span := Tracer.Start(ctx, "mid/provider/executor.ConnectionState.SetupAgent", trace.WithAttributes(
 		attribute.String("exec.strategy", "rpc"),
@@ -102,20 +168,49 @@ func (cs *ConnectionState) SetupAgent(ctx context.Context) error {
 		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
+	sshConfig.HostKeyCallback = cs.recordHostKey(sshConfig.HostKeyCallback)
+
+	become, err := ConnectionToBecome(cs.Connection)
+	if err != nil {
+		logger.ErrorContext(ctx, "SetupAgent: error building become settings", slog.Any("error", err))
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
 
 	sshClient, err := DialWithRetry(ctx, "Dial", 10, func() (*ssh.Client, error) {
-		return ssh.Dial("tcp", endpoint, sshConfig)
+		return DialConnection(ctx, cs.Connection, sshConfig, endpoint)
 	})
 	if err != nil {
 		logger.ErrorContext(ctx, "SetupAgent: error dialing", slog.Any("error", err))
-		cs.Reachable = false
-		cs.Unreachable = true
 		span.SetStatus(codes.Error, err.Error())
+		if cs.Reachable {
+			// the host was reachable before, so it is likely only gone for now,
+			// e.g. while it reboots. Giving up on it for good is left to the
+			// caller.
+			return errors.Join(midagent.ErrConnectionLost, err)
+		}
+		cs.Unreachable = true
 		return errors.Join(ErrUnreachable, err)
 	}
 
+	if cs.HeartbeatTimeout > 0 && cs.HeartbeatTimeout <= cs.HeartbeatInterval {
+		logger.WarnContext(
+			ctx,
+			"SetupAgent: heartbeat timeout is not longer than the heartbeat interval, the agent will likely time out",
+			slog.Duration("agent.heartbeat_interval", cs.HeartbeatInterval),
+			slog.Duration("agent.heartbeat_timeout", cs.HeartbeatTimeout),
+		)
+	}
+
 	cs.Agent = &midagent.Agent{
-		Client: sshClient,
+		Client:            sshClient,
+		HeartbeatInterval: cs.HeartbeatInterval,
+		HeartbeatTimeout:  cs.HeartbeatTimeout,
+		OnRemoteLog:       cs.remoteLogDiagnostic,
+		OnStatus:          agentStatus,
+		Dir:               ptr.FromDefault(cs.Connection.AgentDir, ""),
+		GCAfter:           cs.AgentGCAfter,
+		Become:            become,
 	}
 
 	err = midagent.Connect(ctx, cs.Agent)
@@ -201,6 +296,11 @@ func Acquire(
 	if !loaded && cs.MaxParallel == 0 {
 		cs.MaxParallel = resourceConfig.GetParallel()
 	}
+	if !loaded {
+		cs.HeartbeatInterval = resourceConfig.GetHeartbeatInterval()
+		cs.HeartbeatTimeout = resourceConfig.GetHeartbeatTimeout()
+		cs.AgentGCAfter = resourceConfig.GetAgentGCAfter()
+	}
 
 	logger = logger.With(slog.Bool("agent.loaded", loaded))
 	span.SetAttributes(attribute.Bool("agent.loaded", loaded))
@@ -327,8 +427,9 @@ func CanConnect(
 		cs.Unreachable = true
 		return false, err
 	}
+	sshConfig.HostKeyCallback = cs.recordHostKey(sshConfig.HostKeyCallback)
 	sshClient, err := DialWithRetry(ctx, "Dial", maxAttempts, func() (*ssh.Client, error) {
-		return ssh.Dial("tcp", endpoint, sshConfig)
+		return DialConnection(ctx, cs.Connection, sshConfig, endpoint)
 	})
 	if err != nil {
 		logger.With(
@@ -435,10 +536,34 @@ func CallAgent[I any, O any](
 	resourceConfig midtypes.ResourceConfig,
 	call rpc.RPCCall[I],
 ) (rpc.RPCResult[O], error) {
+	return CallAgentStream[I, O](ctx, connection, resourceConfig, call, nil)
+}
+
+// CallAgentStream is the same as CallAgent except that output chunks from
+// streaming RPC functions are passed to stream as they arrive.
+//
+// If the connection to the agent is lost, the agent is reconnected and the
+// call is retried up to MaxReconnectAttempts times, as long as the call had
+// not been sent yet or is safe to retry (see RetrySafe).
+func CallAgentStream[I any, O any](
+	ctx context.Context,
+	connection midtypes.Connection,
+	resourceConfig midtypes.ResourceConfig,
+	call rpc.RPCCall[I],
+	stream rpc.StreamFunc,
+) (rpc.RPCResult[O], error) {
+	// Ansible modules are called from all over the place, so the configured
+	// Python interpreter is filled in here rather than at every call site.
+	if args, ok := any(call.Args).(rpc.AnsibleExecuteArgs); ok && args.PythonInterpreter == "" {
+		args.PythonInterpreter = resourceConfig.GetPythonInterpreter()
+		call.Args = any(args).(I)
+	}
+
 	ctx, span := Tracer.Start(ctx, "mid/provider/executor.CallAgent", trace.WithAttributes(
 		attribute.String("exec.strategy", "rpc"),
 		attribute.String("rpc.function", string(call.RPCFunction)),
 		telemetry.OtelJSON("rpc.args", call.Args),
+		attribute.Bool("rpc.stream", stream != nil),
 	))
 	defer span.End()
 	logger := telemetry.LoggerFromContext(ctx).With(
@@ -460,19 +585,10 @@ func CallAgent[I any, O any](
 	}
 	defer cs.FinishedTask()
 
-	if cs.Unreachable {
-		err = ErrUnreachable
-		span.SetStatus(codes.Error, err.Error())
-		return zero, err
-	}
-
-	err = cs.SetupAgent(ctx)
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		return zero, err
-	}
+	res, err := retryOnConnectionLoss(ctx, *cs.Connection.Host, call, time.Second, func() (rpc.RPCResult[O], error) {
+		return callAgentOnce[I, O](ctx, cs, call, stream)
+	})
 
-	res, err := midagent.Call[I, O](ctx, cs.Agent, call)
 	if err == nil {
 		span.SetStatus(codes.Ok, "")
 	} else {
@@ -507,6 +623,127 @@ func CallAgent[I any, O any](
 	return res, err
 }
 
+// retryOnConnectionLoss makes a call through attempt, retrying it up to
+// MaxReconnectAttempts times if the connection to the agent is lost, as long as
+// the call had not been sent yet or is safe to retry. The wait before each
+// retry grows by backoff every time.
+func retryOnConnectionLoss[I any, O any](
+	ctx context.Context,
+	host string,
+	call rpc.RPCCall[I],
+	backoff time.Duration,
+	attempt func() (rpc.RPCResult[O], error),
+) (rpc.RPCResult[O], error) {
+	span := trace.SpanFromContext(ctx)
+	logger := telemetry.LoggerFromContext(ctx).With(
+		slog.String("rpc.function", string(call.RPCFunction)),
+	)
+
+	for reconnect := 1; ; reconnect++ {
+		res, err := attempt()
+		if !errors.Is(err, midagent.ErrConnectionLost) || ctx.Err() != nil {
+			return res, err
+		}
+
+		span.SetAttributes(attribute.Int("rpc.reconnect_attempts", reconnect))
+		logger = logger.With(slog.Int("rpc.reconnect_attempt", reconnect))
+
+		if !errors.Is(err, midagent.ErrCallNotSent) && !RetrySafe(call) {
+			err = fmt.Errorf(
+				"%w: %s was running on %s when the connection was lost and is not safe to retry, "+
+					"so it may or may not have completed: %w",
+				ErrCallNotRecovered,
+				call.RPCFunction,
+				host,
+				err,
+			)
+			logger.ErrorContext(ctx, "CallAgent: not retrying call after connection loss", slog.Any("error", err))
+			return res, err
+		}
+
+		if reconnect > MaxReconnectAttempts {
+			err = fmt.Errorf(
+				"%w: %s on %s still failing after %d reconnect attempts: %w",
+				ErrCallNotRecovered,
+				call.RPCFunction,
+				host,
+				MaxReconnectAttempts,
+				err,
+			)
+			logger.ErrorContext(ctx, "CallAgent: giving up after connection loss", slog.Any("error", err))
+			return res, err
+		}
+
+		wait := time.Duration(reconnect) * backoff
+		p.GetLogger(ctx).Warningf(
+			"connection to agent on %s lost during %s, reconnecting and retrying in %s (%d/%d)",
+			host,
+			call.RPCFunction,
+			wait,
+			reconnect,
+			MaxReconnectAttempts,
+		)
+		logger.WarnContext(ctx, "CallAgent: connection lost, retrying", slog.Duration("wait", wait))
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return res, err
+		}
+	}
+}
+
+// callAgentOnce makes a single attempt at a call, connecting to the agent
+// first if it isn't already running.
+func callAgentOnce[I any, O any](
+	ctx context.Context,
+	cs *ConnectionState,
+	call rpc.RPCCall[I],
+	stream rpc.StreamFunc,
+) (rpc.RPCResult[O], error) {
+	var zero rpc.RPCResult[O]
+
+	if cs.Unreachable {
+		return zero, ErrUnreachable
+	}
+
+	err := cs.SetupAgent(ctx)
+	if err != nil {
+		// nothing has been sent if the agent couldn't be set up.
+		return zero, errors.Join(midagent.ErrCallNotSent, err)
+	}
+
+	agent := cs.Agent
+	if !agent.Supports(call.RPCFunction) {
+		err = agent.UnsupportedError(call.RPCFunction)
+		telemetry.LoggerFromContext(ctx).ErrorContext(
+			ctx,
+			"CallAgent: RPC function not supported by agent",
+			slog.Any("error", err),
+		)
+		return zero, err
+	}
+
+	res, err := midagent.CallStream[I, O](ctx, agent, call, stream)
+	if err == nil && strings.HasPrefix(res.Error, rpc.ErrUnsupportedRPCFunction.Error()) {
+		err = agent.UnsupportedError(call.RPCFunction)
+	}
+	return res, err
+}
+
+// RetrySafe reports whether call can be made again after the connection to the
+// agent was lost while it was running, i.e. whether it has no side effects.
+func RetrySafe[I any](call rpc.RPCCall[I]) bool {
+	switch call.RPCFunction {
+	case rpc.RPCAgentPing, rpc.RPCFileStat, rpc.RPCReadFile, rpc.RPCSystemdUnitShortStatus:
+		return true
+	case rpc.RPCAnsibleExecute:
+		args, ok := any(call.Args).(rpc.AnsibleExecuteArgs)
+		return ok && args.Check
+	}
+	return false
+}
+
 type AnsibleExecuteArgs interface {
 	ToRPCCall() (rpc.RPCCall[rpc.AnsibleExecuteArgs], error)
 }
@@ -686,10 +923,89 @@ func DisconnectAll(ctx context.Context) error {
 		logger.DebugContext(ctx, fmt.Sprintf("DisconnectAll: disconnected %d", id), slog.Any("error", err))
 	}
 
+	// proxies are only closed once nothing is connected through them anymore.
+	multierr = errors.Join(multierr, DisconnectBastions(ctx))
+
 	logger.DebugContext(ctx, "DisconnectAll: finished disconnecting all", slog.Any("error", multierr))
 	return multierr
 }
 
+// UninstallAgent removes the agent directory from the host of connection. A
+// running agent for the connection is disconnected first, which fails any of
+// its in-flight calls. It reports whether there was an agent directory to
+// remove.
+func UninstallAgent(
+	ctx context.Context,
+	connection midtypes.Connection,
+	resourceConfig midtypes.ResourceConfig,
+) (bool, error) {
+	ctx, span := Tracer.Start(ctx, "mid/provider/executor.UninstallAgent", trace.WithAttributes(
+		attribute.String("exec.strategy", "rpc"),
+	))
+	defer span.End()
+	logger := telemetry.LoggerFromContext(ctx).With()
+
+	cs, err := Acquire(ctx, connection, resourceConfig)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+	defer cs.FinishedTask()
+
+	span.SetAttributes(attribute.String("connection.host", *connection.Host))
+	logger = logger.With(slog.String("connection.host", *connection.Host))
+
+	cs.SetupAgentMutex.Lock()
+	defer cs.SetupAgentMutex.Unlock()
+
+	if cs.Agent != nil && cs.Agent.Running.Load() {
+		logger.DebugContext(ctx, "UninstallAgent: disconnecting running agent")
+		err = cs.Agent.Disconnect(ctx, true)
+		if err != nil {
+			logger.WarnContext(ctx, "UninstallAgent: error disconnecting agent", slog.Any("error", err))
+		}
+	}
+	cs.Agent = nil
+
+	sshConfig, endpoint, err := ConnectionToSSHClientConfig(connection)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+
+	become, err := ConnectionToBecome(connection)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+
+	sshClient, err := DialWithRetry(ctx, "Dial", 10, func() (*ssh.Client, error) {
+		return DialConnection(ctx, connection, sshConfig, endpoint)
+	})
+	if err != nil {
+		err = errors.Join(ErrUnreachable, err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+	defer sshClient.Close()
+
+	removed, err := midagent.UninstallAgent(ctx, &midagent.Agent{
+		Client: sshClient,
+		Dir:    ptr.FromDefault(connection.AgentDir, ""),
+		Become: become,
+	})
+	span.SetAttributes(attribute.Bool("agent.removed", removed))
+	if err != nil {
+		logger.ErrorContext(ctx, "UninstallAgent: error removing agent", slog.Any("error", err))
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+
+	logger.DebugContext(ctx, "UninstallAgent: finished", slog.Bool("agent.removed", removed))
+	span.SetStatus(codes.Ok, "")
+	return removed, nil
+}
+
 func StageFile(
 	ctx context.Context,
 	connection midtypes.Connection,
@@ -739,6 +1055,11 @@ func StageFile(
 func ConnectionToSSHClientConfig(connection midtypes.Connection) (*ssh.ClientConfig, string, error) {
 	sshConfig := &ssh.ClientConfig{}
 
+	connection, err := resolveSSHConfig(connection)
+	if err != nil {
+		return nil, "", err
+	}
+
 	port := midtypes.DefaultConnectionPort
 	if connection.Port != nil {
 		port = int(*connection.Port)
@@ -765,15 +1086,17 @@ func ConnectionToSSHClientConfig(connection midtypes.Connection) (*ssh.ClientCon
 		sshConfig.Timeout = time.Second * time.Duration(*connection.PerDialTimeout)
 	}
 
-	if connection.HostKey != nil {
-		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(*connection.HostKey))
+	err = setHostKeyCallback(sshConfig, connection.ConnectionBase, endpoint)
+	if err != nil {
+		return sshConfig, endpoint, err
+	}
+
+	var cert *ssh.Certificate
+	if connection.Certificate != nil {
+		cert, err = parseUserCertificate(*connection.Certificate)
 		if err != nil {
-			return sshConfig, endpoint, fmt.Errorf("failed to parse host key: %w", err)
+			return sshConfig, endpoint, err
 		}
-		sshConfig.HostKeyCallback = ssh.FixedHostKey(publicKey)
-		sshConfig.HostKeyAlgorithms = []string{publicKey.Type()}
-	} else {
-		sshConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
 	}
 
 	if connection.PrivateKey != nil {
@@ -790,7 +1113,17 @@ func ConnectionToSSHClientConfig(connection midtypes.Connection) (*ssh.ClientCon
 		if err != nil {
 			return sshConfig, endpoint, err
 		}
-		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signer))
+		signers := []ssh.Signer{signer}
+		if cert != nil {
+			certSigner, err := ssh.NewCertSigner(cert, signer)
+			if err != nil {
+				return sshConfig, endpoint, fmt.Errorf("certificate does not match privateKey: %w", err)
+			}
+			// like OpenSSH, the bare key is offered after the certificate.
+			signers = []ssh.Signer{certSigner, signer}
+			cert = nil
+		}
+		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeys(signers...))
 	}
 
 	if connection.Password != nil {
@@ -820,12 +1153,33 @@ func ConnectionToSSHClientConfig(connection midtypes.Connection) (*ssh.ClientCon
 		if err != nil {
 			return sshConfig, endpoint, err
 		}
-		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		sshConfig.Auth = append(sshConfig.Auth, ssh.PublicKeysCallback(agentSigners(agent.NewClient(agentConn), cert)))
+		cert = nil
+	}
+	if cert != nil {
+		return sshConfig, endpoint, ErrCertificateWithoutKey
 	}
 
 	return sshConfig, endpoint, nil
 }
 
+// ConnectionToBecome returns the become settings of the agent for connection.
+func ConnectionToBecome(connection midtypes.Connection) (midagent.Become, error) {
+	if connection.Become == nil {
+		return midagent.Become{}, nil
+	}
+	method, err := midagent.BecomeMethodFor(ptr.FromDefault(connection.Become.Method, ""))
+	if err != nil {
+		return midagent.Become{}, err
+	}
+	return midagent.Become{
+		Method:   method,
+		User:     ptr.FromDefault(connection.Become.User, ""),
+		Password: ptr.FromDefault(connection.Become.Password, ""),
+		Flags:    ptr.FromDefault(connection.Become.Flags, nil),
+	}, nil
+}
+
 func DialWithRetry[T any](ctx context.Context, msg string, maxAttempts int, f func() (T, error)) (T, error) {
 	ctx, span := Tracer.Start(ctx, "mid/provider/executor.DialWithRetry", trace.WithAttributes(
 		attribute.String("exec.strategy", "rpc"),
@@ -857,7 +1211,8 @@ func DialWithRetry[T any](ctx context.Context, msg string, maxAttempts int, f fu
 				return true, result, nil
 			}
 			dials := try + 1
-			if maxAttempts > -1 && dials > maxAttempts {
+			// a host that presents the wrong key keeps presenting it.
+			if (maxAttempts > -1 && dials > maxAttempts) || isHostKeyError(userError) {
 				err := fmt.Errorf(
 					"after %d failed attempts: %w",
 					try,
@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	midagent "github.com/sapslaj/mid/agent"
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+func TestRetrySafe(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		call   rpc.RPCCall[any]
+		expect bool
+	}{
+		"AgentPing":              {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCAgentPing}, expect: true},
+		"FileStat":               {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCFileStat}, expect: true},
+		"ReadFile":               {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCReadFile}, expect: true},
+		"SystemdUnitShortStatus": {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCSystemdUnitShortStatus}, expect: true},
+		"Exec":                   {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCExec}, expect: false},
+		"WriteFile":              {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCWriteFile}, expect: false},
+		"Untar":                  {call: rpc.RPCCall[any]{RPCFunction: rpc.RPCUntar}, expect: false},
+
+		"AnsibleExecute in check mode": {
+			call: rpc.RPCCall[any]{
+				RPCFunction: rpc.RPCAnsibleExecute,
+				Args:        rpc.AnsibleExecuteArgs{Name: "ping", Check: true},
+			},
+			expect: true,
+		},
+
+		"AnsibleExecute": {
+			call: rpc.RPCCall[any]{
+				RPCFunction: rpc.RPCAnsibleExecute,
+				Args:        rpc.AnsibleExecuteArgs{Name: "ping"},
+			},
+			expect: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, RetrySafe(tc.call))
+		})
+	}
+}
+
+func TestRetryOnConnectionLoss(t *testing.T) {
+	t.Parallel()
+
+	errNotSent := errors.Join(midagent.ErrCallNotSent, midagent.ErrConnectionLost)
+	errLost := midagent.ErrConnectionLost
+	errOther := errors.New("permission denied")
+
+	// lost is returned by every attempt, more than the retry budget allows for.
+	lost := make([]error, MaxReconnectAttempts+2)
+	for i := range lost {
+		lost[i] = errLost
+	}
+
+	tests := map[string]struct {
+		function rpc.RPCFunction
+		// errs is what each attempt returns in turn, with nil for success.
+		errs     []error
+		attempts int
+		err      error
+	}{
+		"success": {
+			function: rpc.RPCExec,
+			errs:     []error{nil},
+			attempts: 1,
+		},
+
+		"not sent then success": {
+			function: rpc.RPCExec,
+			errs:     []error{errNotSent, errNotSent, nil},
+			attempts: 3,
+		},
+
+		"lost during a call that isn't safe to retry": {
+			function: rpc.RPCExec,
+			errs:     []error{errLost, nil},
+			attempts: 1,
+			err:      ErrCallNotRecovered,
+		},
+
+		"lost during a call that is safe to retry": {
+			function: rpc.RPCFileStat,
+			errs:     []error{errLost, errLost, nil},
+			attempts: 3,
+		},
+
+		"always lost": {
+			function: rpc.RPCFileStat,
+			errs:     lost,
+			attempts: MaxReconnectAttempts + 1,
+			err:      ErrCallNotRecovered,
+		},
+
+		"never sent": {
+			function: rpc.RPCExec,
+			errs:     []error{errNotSent, errNotSent, errNotSent, errNotSent, errNotSent},
+			attempts: MaxReconnectAttempts + 1,
+			err:      ErrCallNotRecovered,
+		},
+
+		"other error": {
+			function: rpc.RPCFileStat,
+			errs:     []error{errOther, nil},
+			attempts: 1,
+			err:      errOther,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			attempts := 0
+			res, err := retryOnConnectionLoss(
+				context.Background(),
+				"host",
+				rpc.RPCCall[any]{RPCFunction: tc.function},
+				time.Millisecond,
+				func() (rpc.RPCResult[any], error) {
+					err := tc.errs[attempts]
+					attempts++
+					if err != nil {
+						return rpc.RPCResult[any]{}, err
+					}
+					return rpc.RPCResult[any]{UUID: "done"}, nil
+				},
+			)
+			assert.Equal(t, tc.attempts, attempts)
+			if tc.err == nil {
+				require.NoError(t, err)
+				assert.Equal(t, "done", res.UUID)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRetryOnConnectionLossCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	attempts := 0
+	start := time.Now()
+	_, err := retryOnConnectionLoss(
+		ctx,
+		"host",
+		rpc.RPCCall[any]{RPCFunction: rpc.RPCFileStat},
+		time.Hour,
+		func() (rpc.RPCResult[any], error) {
+			attempts++
+			time.AfterFunc(50*time.Millisecond, cancel)
+			return rpc.RPCResult[any]{}, midagent.ErrConnectionLost
+		},
+	)
+	assert.ErrorIs(t, err, midagent.ErrConnectionLost)
+	// cancelling cuts the wait before the retry short, and the call isn't made
+	// again.
+	assert.Equal(t, 1, attempts)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestSetupAgentDialFailure(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		reachable   bool
+		err         error
+		unreachable bool
+	}{
+		"never reached": {
+			reachable:   false,
+			err:         ErrUnreachable,
+			unreachable: true,
+		},
+
+		"reached before": {
+			reachable:   true,
+			err:         midagent.ErrConnectionLost,
+			unreachable: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// nothing listens on the port once the listener is closed.
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			port := listener.Addr().(*net.TCPAddr).Port
+			require.NoError(t, listener.Close())
+
+			cs := &ConnectionState{
+				Reachable: tc.reachable,
+				Connection: midtypes.Connection{
+					ConnectionBase: midtypes.ConnectionBase{
+						Host:     ptr.Of("127.0.0.1"),
+						Port:     ptr.Of(float64(port)),
+						User:     ptr.Of("root"),
+						Password: ptr.Of("password"),
+					},
+				},
+			}
+
+			// short enough to give up before the first retry.
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			err = cs.SetupAgent(ctx)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.unreachable, cs.Unreachable)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	ErrNotUserCertificate = errors.New("not an SSH user certificate")
+
+	ErrCertificateWithoutKey = errors.New("certificate requires privateKey or the SSH agent to be set")
+
+	ErrCertificateKeyNotInAgent = errors.New("the key of the certificate is not in the SSH agent")
+)
+
+// parseUserCertificate parses an OpenSSH user certificate in authorized_keys
+// format.
+func parseUserCertificate(content string) (*ssh.Certificate, error) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok || cert.CertType != ssh.UserCert {
+		return nil, fmt.Errorf("%w: %s", ErrNotUserCertificate, key.Type())
+	}
+	return cert, nil
+}
+
+// isCertificate reports whether key is a certificate. Keys from the SSH agent
+// aren't *ssh.Certificate even if they are one.
+func isCertificate(key ssh.PublicKey) bool {
+	return strings.HasSuffix(key.Type(), "-cert-v01@openssh.com")
+}
+
+// agentSigners returns the signers of the SSH agent, certificates first so
+// that servers limiting the number of attempts see them before the bare keys.
+// If cert is set, it is paired with the agent's key for it.
+func agentSigners(client agent.ExtendedAgent, cert *ssh.Certificate) func() ([]ssh.Signer, error) {
+	return func() ([]ssh.Signer, error) {
+		signers, err := client.Signers()
+		if err != nil {
+			return nil, err
+		}
+
+		unpaired := cert
+		certs := []ssh.Signer{}
+		keys := []ssh.Signer{}
+		for _, signer := range signers {
+			if unpaired != nil && bytes.Equal(signer.PublicKey().Marshal(), unpaired.Key.Marshal()) {
+				certSigner, err := ssh.NewCertSigner(unpaired, signer)
+				if err != nil {
+					return nil, err
+				}
+				certs = append(certs, certSigner)
+				unpaired = nil
+			}
+			if isCertificate(signer.PublicKey()) {
+				certs = append(certs, signer)
+			} else {
+				keys = append(keys, signer)
+			}
+		}
+		if unpaired != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCertificateKeyNotInAgent, ssh.FingerprintSHA256(unpaired.Key))
+		}
+		return append(certs, keys...), nil
+	}
+}
@@ -0,0 +1,156 @@
+package executor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestParseUserCertificate(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestSigner(t)
+	key := newTestSigner(t).PublicKey()
+	userCert := newTestCertificate(t, ca, key, ssh.UserCert, []string{"mid"}, ssh.CertTimeInfinity)
+	hostCert := newTestCertificate(t, ca, key, ssh.HostCert, []string{"host.example.com"}, ssh.CertTimeInfinity)
+
+	tests := map[string]struct {
+		input       string
+		err         error
+		errContains string
+	}{
+		"user certificate": {
+			input: FormatHostKey(userCert) + " mid@example.com",
+		},
+
+		"host certificate": {
+			input: FormatHostKey(hostCert),
+			err:   ErrNotUserCertificate,
+		},
+
+		"plain key": {
+			input: FormatHostKey(key),
+			err:   ErrNotUserCertificate,
+		},
+
+		"garbage": {
+			input:       "not a certificate",
+			errContains: "failed to parse certificate",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cert, err := parseUserCertificate(tc.input)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			if tc.errContains != "" {
+				assert.ErrorContains(t, err, tc.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, userCert.Marshal(), cert.Marshal())
+		})
+	}
+}
+
+// newTestAgent returns an SSH agent holding a new key for each of certified,
+// along with the keys in order. The keys that are certified are added with a
+// user certificate signed by ca.
+func newTestAgent(t *testing.T, ca ssh.Signer, certified ...bool) (agent.ExtendedAgent, []ssh.PublicKey) {
+	t.Helper()
+
+	keyring, ok := agent.NewKeyring().(agent.ExtendedAgent)
+	require.True(t, ok)
+	keys := []ssh.PublicKey{}
+	for _, withCert := range certified {
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		signer, err := ssh.NewSignerFromKey(private)
+		require.NoError(t, err)
+		added := agent.AddedKey{PrivateKey: private}
+		if withCert {
+			added.Certificate = newTestCertificate(t, ca, signer.PublicKey(), ssh.UserCert, []string{"mid"}, ssh.CertTimeInfinity)
+		}
+		require.NoError(t, keyring.Add(added))
+		keys = append(keys, signer.PublicKey())
+	}
+	return keyring, keys
+}
+
+func TestAgentSigners(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestSigner(t)
+
+	t.Run("bare keys", func(t *testing.T) {
+		t.Parallel()
+
+		client, keys := newTestAgent(t, ca, false, false)
+		signers, err := agentSigners(client, nil)()
+		require.NoError(t, err)
+		assert.Equal(t, []string{FormatHostKey(keys[0]), FormatHostKey(keys[1])}, signerKeys(signers))
+	})
+
+	t.Run("certificates before bare keys", func(t *testing.T) {
+		t.Parallel()
+
+		// the agent lists the bare key first as it was added first.
+		client, keys := newTestAgent(t, ca, false, true)
+		signers, err := agentSigners(client, nil)()
+		require.NoError(t, err)
+		require.Len(t, signers, 2)
+		assert.True(t, isCertificate(signers[0].PublicKey()))
+		cert, err := parseUserCertificate(FormatHostKey(signers[0].PublicKey()))
+		require.NoError(t, err)
+		assert.Equal(t, keys[1].Marshal(), cert.Key.Marshal())
+		assert.Equal(t, FormatHostKey(keys[0]), FormatHostKey(signers[1].PublicKey()))
+	})
+
+	t.Run("certificate paired with agent key", func(t *testing.T) {
+		t.Parallel()
+
+		client, keys := newTestAgent(t, ca, false, false)
+		cert := newTestCertificate(t, ca, keys[1], ssh.UserCert, []string{"mid"}, ssh.CertTimeInfinity)
+		signers, err := agentSigners(client, cert)()
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			[]string{FormatHostKey(cert), FormatHostKey(keys[0]), FormatHostKey(keys[1])},
+			signerKeys(signers),
+		)
+
+		// the paired signer signs with the agent's key.
+		data := []byte("data")
+		signature, err := signers[0].Sign(rand.Reader, data)
+		require.NoError(t, err)
+		assert.NoError(t, keys[1].Verify(data, signature))
+	})
+
+	t.Run("certificate key not in agent", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := newTestAgent(t, ca, false)
+		cert := newTestCertificate(t, ca, newTestSigner(t).PublicKey(), ssh.UserCert, []string{"mid"}, ssh.CertTimeInfinity)
+		_, err := agentSigners(client, cert)()
+		assert.ErrorIs(t, err, ErrCertificateKeyNotInAgent)
+		assert.ErrorContains(t, err, ssh.FingerprintSHA256(cert.Key))
+	})
+}
+
+func signerKeys(signers []ssh.Signer) []string {
+	keys := []string{}
+	for _, signer := range signers {
+		keys = append(keys, FormatHostKey(signer.PublicKey()))
+	}
+	return keys
+}
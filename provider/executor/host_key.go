@@ -0,0 +1,307 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+var (
+	ErrUnknownHostKeyChecking = errors.New("unknown host key checking mode")
+
+	ErrNoKnownHostKeys = errors.New("strict host key checking requires hostKey, knownHosts, knownHostsFile, or hostCAKeys to be set")
+
+	ErrHostKeyMismatch = errors.New("host key does not match the known keys for the host")
+
+	ErrHostKeyUnknown = errors.New("host is not in the known hosts")
+
+	ErrHostKeyRevoked = errors.New("host key is revoked")
+)
+
+// knownHosts is the database of host keys a connection is checked against,
+// made up of its hostKey, knownHosts, and knownHostsFile.
+type knownHosts struct {
+	callback ssh.HostKeyCallback
+	// lines are the lines of each known hosts file by name, to tell
+	// "@cert-authority" lines apart when looking up a host.
+	lines map[string][]string
+}
+
+// loadKnownHosts reads the known host keys of connection, which is about to
+// connect to endpoint. It returns nil if none are set.
+func loadKnownHosts(connection midtypes.ConnectionBase, endpoint string) (*knownHosts, error) {
+	if connection.HostKey == nil && connection.KnownHosts == nil && connection.KnownHostsFile == nil {
+		return nil, nil
+	}
+
+	// knownhosts only reads files, so the host keys given inline are written to
+	// a temporary one.
+	dir, err := os.MkdirTemp("", "mid-known-hosts-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{}
+	lines := map[string][]string{}
+	addFile := func(name string, content string) {
+		lines[name] = strings.Split(content, "\n")
+		files = append(files, name)
+	}
+	writeFile := func(name string, content string) error {
+		path := filepath.Join(dir, name)
+		err := os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			return err
+		}
+		addFile(path, content)
+		return nil
+	}
+
+	if connection.KnownHostsFile != nil {
+		path, err := expandHome(*connection.KnownHostsFile)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read known hosts file: %w", err)
+		}
+		addFile(path, string(content))
+	}
+	if connection.KnownHosts != nil {
+		err = writeFile("knownHosts", *connection.KnownHosts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if connection.HostKey != nil {
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(*connection.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse host key: %w", err)
+		}
+		err = writeFile("hostKey", knownhosts.Line([]string{endpoint}, publicKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	callback, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse known hosts: %w", err)
+	}
+	return &knownHosts{callback: callback, lines: lines}, nil
+}
+
+// lookup returns the known keys for hostname, and whether they are
+// certificate authorities.
+func (kh *knownHosts) lookup(hostname string) ([]knownhosts.KnownKey, []bool) {
+	// the only way to get at the known keys is to check a key that doesn't
+	// match any of them.
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil
+	}
+	probe, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		return nil, nil
+	}
+	err = kh.callback(hostname, &net.TCPAddr{}, probe.PublicKey())
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) {
+		return nil, nil
+	}
+
+	authorities := []bool{}
+	for _, want := range keyErr.Want {
+		line := ""
+		if want.Line > 0 && want.Line <= len(kh.lines[want.Filename]) {
+			line = kh.lines[want.Filename][want.Line-1]
+		}
+		authorities = append(authorities, strings.HasPrefix(strings.TrimSpace(line), "@cert-authority"))
+	}
+	return keyErr.Want, authorities
+}
+
+// parseHostCAKeys parses the hostCAKeys of connection.
+func parseHostCAKeys(connection midtypes.ConnectionBase) ([]ssh.PublicKey, error) {
+	keys := []ssh.PublicKey{}
+	for _, content := range ptr.FromDefault(connection.HostCAKeys, nil) {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse host CA key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// certHostKeyAlgorithms returns the supported host certificate algorithms.
+func certHostKeyAlgorithms() []string {
+	algorithms := []string{}
+	for _, algorithm := range ssh.SupportedAlgorithms().HostKeys {
+		if strings.Contains(algorithm, "-cert-") {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+	return algorithms
+}
+
+// hostKeyAlgorithms returns the host key algorithms to offer to a host with the
+// given known keys, so that it presents one of those instead of a key of
+// another type that would be rejected.
+func hostKeyAlgorithms(keys []knownhosts.KnownKey, authorities []bool) []string {
+	algorithms := []string{}
+	for i, key := range keys {
+		var add []string
+		switch {
+		case authorities[i]:
+			add = certHostKeyAlgorithms()
+		case key.Key.Type() == ssh.KeyAlgoRSA:
+			add = []string{ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA}
+		default:
+			add = []string{key.Key.Type()}
+		}
+		for _, algorithm := range add {
+			if !slices.Contains(algorithms, algorithm) {
+				algorithms = append(algorithms, algorithm)
+			}
+		}
+	}
+	return algorithms
+}
+
+// checkHostCertificate returns a callback that checks host certificates signed
+// by one of authorities itself, and leaves any other key to fallback.
+func checkHostCertificate(authorities []ssh.PublicKey, fallback ssh.HostKeyCallback) ssh.HostKeyCallback {
+	checker := &ssh.CertChecker{
+		IsHostAuthority: func(auth ssh.PublicKey, address string) bool {
+			return slices.ContainsFunc(authorities, func(authority ssh.PublicKey) bool {
+				return bytes.Equal(authority.Marshal(), auth.Marshal())
+			})
+		},
+	}
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		cert, ok := key.(*ssh.Certificate)
+		if !ok || !checker.IsHostAuthority(cert.SignatureKey, hostname) {
+			return fallback(hostname, remote, key)
+		}
+		err := checker.CheckHostKey(hostname, remote, key)
+		if err != nil {
+			return fmt.Errorf("%w: %s presented %s: %w", ErrHostKeyMismatch, hostname, ssh.FingerprintSHA256(key), err)
+		}
+		return nil
+	}
+}
+
+// setHostKeyCallback sets up sshConfig to check the key of the host at
+// endpoint as configured in connection.
+func setHostKeyCallback(sshConfig *ssh.ClientConfig, connection midtypes.ConnectionBase, endpoint string) error {
+	known, err := loadKnownHosts(connection, endpoint)
+	if err != nil {
+		return err
+	}
+	hostCAKeys, err := parseHostCAKeys(connection)
+	if err != nil {
+		return err
+	}
+
+	mode := midtypes.HostKeyCheckingOff
+	if known != nil || len(hostCAKeys) > 0 {
+		mode = midtypes.HostKeyCheckingStrict
+	}
+	mode = ptr.FromDefault(connection.HostKeyChecking, mode)
+
+	switch mode {
+	case midtypes.HostKeyCheckingOff:
+		sshConfig.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return nil
+	case midtypes.HostKeyCheckingStrict:
+		if known == nil && len(hostCAKeys) == 0 {
+			return ErrNoKnownHostKeys
+		}
+	case midtypes.HostKeyCheckingTOFU:
+	default:
+		return fmt.Errorf("%w %q", ErrUnknownHostKeyChecking, mode)
+	}
+
+	var keys []knownhosts.KnownKey
+	var authorities []bool
+	if known != nil {
+		keys, authorities = known.lookup(endpoint)
+	}
+
+	var callback ssh.HostKeyCallback
+	switch {
+	case len(keys) > 0:
+		sshConfig.HostKeyAlgorithms = hostKeyAlgorithms(keys, authorities)
+		callback = known.check
+	case mode == midtypes.HostKeyCheckingTOFU:
+		callback = ssh.InsecureIgnoreHostKey()
+	default:
+		callback = func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			return fmt.Errorf("%w: %s", ErrHostKeyUnknown, hostname)
+		}
+	}
+
+	if len(hostCAKeys) > 0 {
+		switch {
+		case len(keys) > 0:
+			for _, algorithm := range certHostKeyAlgorithms() {
+				if !slices.Contains(sshConfig.HostKeyAlgorithms, algorithm) {
+					sshConfig.HostKeyAlgorithms = append(sshConfig.HostKeyAlgorithms, algorithm)
+				}
+			}
+		case mode == midtypes.HostKeyCheckingStrict:
+			// nothing but a certificate would be accepted.
+			sshConfig.HostKeyAlgorithms = certHostKeyAlgorithms()
+		}
+		callback = checkHostCertificate(hostCAKeys, callback)
+	}
+
+	sshConfig.HostKeyCallback = callback
+	return nil
+}
+
+// check checks key against the known hosts, with errors that say which key the
+// host presented.
+func (kh *knownHosts) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	err := kh.callback(hostname, remote, key)
+	var keyErr *knownhosts.KeyError
+	var revokedErr *knownhosts.RevokedError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &revokedErr):
+		return fmt.Errorf("%w: %s presented %s", ErrHostKeyRevoked, hostname, ssh.FingerprintSHA256(key))
+	case errors.As(err, &keyErr):
+		return fmt.Errorf("%w: %s presented %s", ErrHostKeyMismatch, hostname, ssh.FingerprintSHA256(key))
+	default:
+		// certificates that are expired, for another host, or not signed by a
+		// known authority.
+		return fmt.Errorf("%w: %s presented %s: %w", ErrHostKeyMismatch, hostname, ssh.FingerprintSHA256(key), err)
+	}
+}
+
+// isHostKeyError reports whether err is from the host key being rejected.
+func isHostKeyError(err error) bool {
+	return errors.Is(err, ErrHostKeyMismatch) || errors.Is(err, ErrHostKeyUnknown) || errors.Is(err, ErrHostKeyRevoked)
+}
+
+// FormatHostKey formats key the way hostKey expects it.
+func FormatHostKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
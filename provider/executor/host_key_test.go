@@ -0,0 +1,470 @@
+package executor
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
+
+// newTestCertificate returns a certificate for key signed by ca.
+func newTestCertificate(
+	t *testing.T,
+	ca ssh.Signer,
+	key ssh.PublicKey,
+	certType uint32,
+	principals []string,
+	validBefore uint64,
+) *ssh.Certificate {
+	t.Helper()
+
+	cert := &ssh.Certificate{
+		Key:             key,
+		CertType:        certType,
+		KeyId:           "test",
+		ValidPrincipals: principals,
+		ValidBefore:     validBefore,
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+	return cert
+}
+
+func TestSetHostKeyCallback(t *testing.T) {
+	t.Parallel()
+
+	const endpoint = "host.example.com:22"
+	hostKey := newTestSigner(t).PublicKey()
+	otherKey := newTestSigner(t).PublicKey()
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSigner, err := ssh.NewSignerFromKey(rsaPrivate)
+	require.NoError(t, err)
+	rsaKey := rsaSigner.PublicKey()
+	ca := newTestSigner(t)
+	otherCA := newTestSigner(t)
+	hostCert := newTestCertificate(t, ca, hostKey, ssh.HostCert, []string{"host.example.com"}, ssh.CertTimeInfinity)
+	otherCACert := newTestCertificate(t, otherCA, hostKey, ssh.HostCert, []string{"host.example.com"}, ssh.CertTimeInfinity)
+	expiredCert := newTestCertificate(t, ca, hostKey, ssh.HostCert, []string{"host.example.com"}, 1)
+	wrongHostCert := newTestCertificate(t, ca, hostKey, ssh.HostCert, []string{"other.example.com"}, ssh.CertTimeInfinity)
+
+	knownHostsFile := filepath.Join(t.TempDir(), "known_hosts")
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(knownhosts.Line([]string{"host.example.com"}, hostKey)+"\n"), 0o600))
+
+	certAlgorithms := certHostKeyAlgorithms()
+	withCertAlgorithms := func(algorithms ...string) []string {
+		return append(algorithms, certAlgorithms...)
+	}
+
+	tests := map[string]struct {
+		connection midtypes.ConnectionBase
+		presented  ssh.PublicKey
+		// setupErr is returned by setHostKeyCallback, err by the callback.
+		setupErr   error
+		err        error
+		algorithms []string
+	}{
+		"off by default": {
+			presented: otherKey,
+		},
+
+		"off with known hosts": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts:      ptr.Of("host.example.com " + FormatHostKey(hostKey)),
+				HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingOff),
+			},
+			presented: otherKey,
+		},
+
+		"strict without known hosts": {
+			connection: midtypes.ConnectionBase{HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingStrict)},
+			setupErr:   ErrNoKnownHostKeys,
+		},
+
+		"unknown mode": {
+			connection: midtypes.ConnectionBase{HostKeyChecking: ptr.Of("yolo")},
+			setupErr:   ErrUnknownHostKeyChecking,
+		},
+
+		"invalid host key": {
+			connection: midtypes.ConnectionBase{HostKey: ptr.Of("not a key")},
+			setupErr:   assert.AnError,
+		},
+
+		"invalid host CA key": {
+			connection: midtypes.ConnectionBase{HostCAKeys: ptr.Of([]string{"not a key"})},
+			setupErr:   assert.AnError,
+		},
+
+		"strict by default with known hosts": {
+			connection: midtypes.ConnectionBase{KnownHosts: ptr.Of("host.example.com " + FormatHostKey(hostKey))},
+			presented:  hostKey,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"known hosts mismatch": {
+			connection: midtypes.ConnectionBase{KnownHosts: ptr.Of("host.example.com " + FormatHostKey(hostKey))},
+			presented:  otherKey,
+			err:        ErrHostKeyMismatch,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"known hosts for another host": {
+			connection: midtypes.ConnectionBase{KnownHosts: ptr.Of("other.example.com " + FormatHostKey(hostKey))},
+			presented:  hostKey,
+			err:        ErrHostKeyUnknown,
+		},
+
+		"known hosts for another port": {
+			connection: midtypes.ConnectionBase{KnownHosts: ptr.Of("[host.example.com]:2222 " + FormatHostKey(hostKey))},
+			presented:  hostKey,
+			err:        ErrHostKeyUnknown,
+		},
+
+		"hashed entry": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of(knownhosts.HashHostname("host.example.com") + " " + FormatHostKey(hostKey)),
+			},
+			presented:  hostKey,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"hashed entry mismatch": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of(knownhosts.HashHostname("host.example.com") + " " + FormatHostKey(hostKey)),
+			},
+			presented:  otherKey,
+			err:        ErrHostKeyMismatch,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"host key": {
+			connection: midtypes.ConnectionBase{HostKey: ptr.Of(FormatHostKey(hostKey))},
+			presented:  hostKey,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"host key mismatch": {
+			connection: midtypes.ConnectionBase{HostKey: ptr.Of(FormatHostKey(hostKey))},
+			presented:  otherKey,
+			err:        ErrHostKeyMismatch,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"known hosts file": {
+			connection: midtypes.ConnectionBase{KnownHostsFile: ptr.Of(knownHostsFile)},
+			presented:  hostKey,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"missing known hosts file": {
+			connection: midtypes.ConnectionBase{KnownHostsFile: ptr.Of(knownHostsFile + ".missing")},
+			setupErr:   os.ErrNotExist,
+		},
+
+		"several sources": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of("host.example.com " + FormatHostKey(rsaKey)),
+				HostKey:    ptr.Of(FormatHostKey(hostKey)),
+			},
+			presented: hostKey,
+			algorithms: []string{
+				ssh.KeyAlgoRSASHA512,
+				ssh.KeyAlgoRSASHA256,
+				ssh.KeyAlgoRSA,
+				ssh.KeyAlgoED25519,
+			},
+		},
+
+		"tofu unknown host": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts:      ptr.Of("other.example.com " + FormatHostKey(hostKey)),
+				HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingTOFU),
+			},
+			presented: otherKey,
+		},
+
+		"tofu without known hosts": {
+			connection: midtypes.ConnectionBase{HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingTOFU)},
+			presented:  otherKey,
+		},
+
+		"tofu known host mismatch": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts:      ptr.Of("host.example.com " + FormatHostKey(hostKey)),
+				HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingTOFU),
+			},
+			presented:  otherKey,
+			err:        ErrHostKeyMismatch,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"cert authority": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of("@cert-authority *.example.com " + FormatHostKey(ca.PublicKey())),
+			},
+			presented:  hostCert,
+			algorithms: certAlgorithms,
+		},
+
+		"cert authority plain key": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of("@cert-authority *.example.com " + FormatHostKey(ca.PublicKey())),
+			},
+			presented:  hostKey,
+			err:        ErrHostKeyMismatch,
+			algorithms: certAlgorithms,
+		},
+
+		"cert authority wrong principal": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of("@cert-authority *.example.com " + FormatHostKey(ca.PublicKey())),
+			},
+			presented:  wrongHostCert,
+			err:        ErrHostKeyMismatch,
+			algorithms: certAlgorithms,
+		},
+
+		"revoked": {
+			connection: midtypes.ConnectionBase{
+				KnownHosts: ptr.Of(
+					"host.example.com " + FormatHostKey(hostKey) + "\n" +
+						"@revoked * " + FormatHostKey(hostKey),
+				),
+			},
+			presented:  hostKey,
+			err:        ErrHostKeyRevoked,
+			algorithms: []string{ssh.KeyAlgoED25519},
+		},
+
+		"host CA keys": {
+			connection: midtypes.ConnectionBase{HostCAKeys: ptr.Of([]string{FormatHostKey(ca.PublicKey())})},
+			presented:  hostCert,
+			algorithms: certAlgorithms,
+		},
+
+		"host CA keys plain key": {
+			connection: midtypes.ConnectionBase{HostCAKeys: ptr.Of([]string{FormatHostKey(ca.PublicKey())})},
+			presented:  hostKey,
+			err:        ErrHostKeyUnknown,
+			algorithms: certAlgorithms,
+		},
+
+		"host CA keys other CA": {
+			connection: midtypes.ConnectionBase{HostCAKeys: ptr.Of([]string{FormatHostKey(ca.PublicKey())})},
+			presented:  otherCACert,
+			err:        ErrHostKeyUnknown,
+			algorithms: certAlgorithms,
+		},
+
+		"host CA keys expired": {
+			connection: midtypes.ConnectionBase{HostCAKeys: ptr.Of([]string{FormatHostKey(ca.PublicKey())})},
+			presented:  expiredCert,
+			err:        ErrHostKeyMismatch,
+			algorithms: certAlgorithms,
+		},
+
+		"host CA keys with known hosts": {
+			connection: midtypes.ConnectionBase{
+				HostCAKeys: ptr.Of([]string{FormatHostKey(ca.PublicKey())}),
+				HostKey:    ptr.Of(FormatHostKey(otherKey)),
+			},
+			presented:  otherKey,
+			algorithms: withCertAlgorithms(ssh.KeyAlgoED25519),
+		},
+
+		"host CA keys tofu": {
+			connection: midtypes.ConnectionBase{
+				HostCAKeys:      ptr.Of([]string{FormatHostKey(ca.PublicKey())}),
+				HostKeyChecking: ptr.Of(midtypes.HostKeyCheckingTOFU),
+			},
+			presented: otherKey,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sshConfig := &ssh.ClientConfig{}
+			err := setHostKeyCallback(sshConfig, tc.connection, endpoint)
+			if tc.setupErr == assert.AnError {
+				assert.Error(t, err)
+				return
+			}
+			if tc.setupErr != nil {
+				assert.ErrorIs(t, err, tc.setupErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.algorithms, sshConfig.HostKeyAlgorithms)
+
+			remote := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}
+			err = sshConfig.HostKeyCallback(endpoint, remote, tc.presented)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.err)
+			assert.True(t, isHostKeyError(err))
+			// the error says which key was presented.
+			if tc.err != ErrHostKeyUnknown {
+				assert.ErrorContains(t, err, ssh.FingerprintSHA256(tc.presented))
+			}
+		})
+	}
+}
+
+func TestKnownHostsLookup(t *testing.T) {
+	t.Parallel()
+
+	hostKey := newTestSigner(t).PublicKey()
+	otherKey := newTestSigner(t).PublicKey()
+	ca := newTestSigner(t).PublicKey()
+
+	known, err := loadKnownHosts(midtypes.ConnectionBase{
+		KnownHosts: ptr.Of(
+			"# comment\n" +
+				"host.example.com " + FormatHostKey(hostKey) + "\n" +
+				knownhosts.HashHostname("hashed.example.com") + " " + FormatHostKey(otherKey) + "\n" +
+				"@cert-authority *.example.com " + FormatHostKey(ca) + "\n",
+		),
+		HostKey: ptr.Of(FormatHostKey(otherKey)),
+	}, "host.example.com:22")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		hostname    string
+		keys        []ssh.PublicKey
+		authorities []bool
+	}{
+		"host": {
+			hostname:    "host.example.com:22",
+			keys:        []ssh.PublicKey{hostKey, ca, otherKey},
+			authorities: []bool{false, true, false},
+		},
+
+		"hashed": {
+			hostname:    "hashed.example.com:22",
+			keys:        []ssh.PublicKey{otherKey, ca},
+			authorities: []bool{false, true},
+		},
+
+		"wildcard only": {
+			hostname:    "other.example.com:22",
+			keys:        []ssh.PublicKey{ca},
+			authorities: []bool{true},
+		},
+
+		"unknown": {
+			hostname: "example.org:22",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, authorities := known.lookup(tc.hostname)
+			assert.ElementsMatch(t, tc.keys, keysOf(keys))
+			require.Len(t, authorities, len(keys))
+			for i, key := range keys {
+				index := -1
+				for j, want := range tc.keys {
+					if FormatHostKey(want) == FormatHostKey(key.Key) {
+						index = j
+					}
+				}
+				require.NotEqual(t, -1, index)
+				assert.Equal(t, tc.authorities[index], authorities[i], FormatHostKey(key.Key))
+			}
+		})
+	}
+}
+
+func keysOf(known []knownhosts.KnownKey) []ssh.PublicKey {
+	keys := []ssh.PublicKey{}
+	for _, key := range known {
+		keys = append(keys, key.Key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return keys
+}
+
+func TestLoadKnownHostsNone(t *testing.T) {
+	t.Parallel()
+
+	known, err := loadKnownHosts(midtypes.ConnectionBase{}, "host.example.com:22")
+	require.NoError(t, err)
+	assert.Nil(t, known)
+}
+
+func TestRecordHostKey(t *testing.T) {
+	t.Parallel()
+
+	hostKey := newTestSigner(t).PublicKey()
+	otherKey := newTestSigner(t).PublicKey()
+	sshConfig := &ssh.ClientConfig{}
+	require.NoError(t, setHostKeyCallback(sshConfig, midtypes.ConnectionBase{
+		HostKey: ptr.Of(FormatHostKey(hostKey)),
+	}, "host.example.com:22"))
+
+	cs := &ConnectionState{}
+	callback := cs.recordHostKey(sshConfig.HostKeyCallback)
+
+	// a rejected key isn't recorded.
+	err := callback("host.example.com:22", &net.TCPAddr{}, otherKey)
+	assert.ErrorIs(t, err, ErrHostKeyMismatch)
+	assert.Nil(t, cs.HostKey.Load())
+
+	require.NoError(t, callback("host.example.com:22", &net.TCPAddr{}, hostKey))
+	require.NotNil(t, cs.HostKey.Load())
+	assert.Equal(t, FormatHostKey(hostKey), *cs.HostKey.Load())
+
+	// nor does it replace the one that was accepted before.
+	err = callback("host.example.com:22", &net.TCPAddr{}, otherKey)
+	assert.Error(t, err)
+	assert.Equal(t, FormatHostKey(hostKey), *cs.HostKey.Load())
+}
+
+func TestCheckHostCertificateTime(t *testing.T) {
+	t.Parallel()
+
+	ca := newTestSigner(t)
+	hostKey := newTestSigner(t).PublicKey()
+	future := newTestCertificate(t, ca, hostKey, ssh.HostCert, []string{"host.example.com"}, ssh.CertTimeInfinity)
+	future.ValidAfter = uint64(time.Now().Add(time.Hour).Unix())
+	require.NoError(t, future.SignCert(rand.Reader, ca))
+
+	callback := checkHostCertificate([]ssh.PublicKey{ca.PublicKey()}, ssh.InsecureIgnoreHostKey())
+	err := callback("host.example.com:22", &net.TCPAddr{}, future)
+	assert.ErrorIs(t, err, ErrHostKeyMismatch)
+
+	// user certificates aren't host certificates.
+	userCert := newTestCertificate(t, ca, hostKey, ssh.UserCert, []string{"host.example.com"}, ssh.CertTimeInfinity)
+	err = callback("host.example.com:22", &net.TCPAddr{}, userCert)
+	assert.ErrorIs(t, err, ErrHostKeyMismatch)
+}
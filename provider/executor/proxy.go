@@ -0,0 +1,256 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sapslaj/mid/pkg/hashstructure"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/pkg/syncmap"
+	"github.com/sapslaj/mid/pkg/telemetry"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+var ErrProxy = errors.New("error connecting to proxy")
+
+// errForwarding is returned by dialThrough if the connection couldn't be
+// forwarded, as opposed to the SSH handshake over it failing.
+var errForwarding = errors.New("error forwarding connection")
+
+// BastionState is the connection to a proxy host. It is shared by every
+// connection that goes through the same chain of proxies.
+type BastionState struct {
+	ID     uint64
+	Mutex  sync.Mutex
+	Client *ssh.Client
+}
+
+// BastionPool holds the connections to proxy hosts, keyed by the hash of the
+// chain of proxies leading up to and including the host.
+var BastionPool = syncmap.Map[uint64, *BastionState]{}
+
+// DialConnection connects to endpoint with sshConfig, through the proxies of
+// connection if it has any.
+func DialConnection(
+	ctx context.Context,
+	connection midtypes.Connection,
+	sshConfig *ssh.ClientConfig,
+	endpoint string,
+) (*ssh.Client, error) {
+	connection, err := resolveSSHConfig(connection)
+	if err != nil {
+		return nil, err
+	}
+	if connection.Proxies == nil || len(*connection.Proxies) == 0 {
+		return ssh.Dial("tcp", endpoint, sshConfig)
+	}
+
+	return dialThroughBastion(ctx, *connection.Proxies, endpoint, sshConfig)
+}
+
+// dialThroughBastion connects to endpoint with sshConfig through the last of
+// proxies. A shared connection to the proxy can be lost without it being
+// noticed yet, so if forwarding a connection over one fails for any reason
+// other than the proxy refusing to, it is evicted and the proxy is dialed
+// again once.
+func dialThroughBastion(
+	ctx context.Context,
+	proxies []midtypes.ProxyConnection,
+	endpoint string,
+	sshConfig *ssh.ClientConfig,
+) (*ssh.Client, error) {
+	bastion, shared, err := bastionClient(ctx, proxies)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dialThrough(ctx, bastion, endpoint, sshConfig)
+	var openChannelErr *ssh.OpenChannelError
+	if err == nil || !shared || !errors.Is(err, errForwarding) || errors.As(err, &openChannelErr) {
+		return client, err
+	}
+
+	telemetry.LoggerFromContext(ctx).WarnContext(
+		ctx,
+		"dialThroughBastion: error dialing through shared proxy connection, reconnecting to proxy",
+		slog.String("proxy.host", ptr.FromDefault(proxies[len(proxies)-1].Host, "")),
+		slog.Any("error", err),
+	)
+	err = evictBastion(proxies, bastion)
+	if err != nil {
+		return nil, err
+	}
+	bastion, _, err = bastionClient(ctx, proxies)
+	if err != nil {
+		return nil, err
+	}
+	return dialThrough(ctx, bastion, endpoint, sshConfig)
+}
+
+// evictBastion closes client and removes it from BastionPool if it still is
+// the shared connection to the last of proxies.
+func evictBastion(proxies []midtypes.ProxyConnection, client *ssh.Client) error {
+	id, err := hashstructure.Hash(proxies, hashstructure.FormatV2, nil)
+	if err != nil {
+		return err
+	}
+	state, ok := BastionPool.Load(id)
+	if !ok {
+		return nil
+	}
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+	if state.Client == client {
+		state.Client = nil
+		client.Close()
+	}
+	return nil
+}
+
+// bastionClient returns the shared connection to the last of proxies, dialing
+// it (and the proxies before it) if there isn't one yet or it was lost. It
+// reports whether the connection was already there.
+func bastionClient(ctx context.Context, proxies []midtypes.ProxyConnection) (*ssh.Client, bool, error) {
+	proxy := proxies[len(proxies)-1]
+	host := ptr.FromDefault(proxy.Host, "")
+	ctx, span := Tracer.Start(ctx, "mid/provider/executor.bastionClient", trace.WithAttributes(
+		attribute.String("proxy.host", host),
+		attribute.Int("proxy.hop", len(proxies)),
+	))
+	defer span.End()
+	logger := telemetry.LoggerFromContext(ctx).With(
+		slog.String("proxy.host", host),
+		slog.Int("proxy.hop", len(proxies)),
+	)
+
+	id, err := hashstructure.Hash(proxies, hashstructure.FormatV2, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, false, err
+	}
+	state, _ := BastionPool.LoadOrStore(id, &BastionState{ID: id})
+
+	state.Mutex.Lock()
+	defer state.Mutex.Unlock()
+
+	if state.Client != nil {
+		span.SetAttributes(attribute.Bool("proxy.shared", true))
+		span.SetStatus(codes.Ok, "")
+		return state.Client, true, nil
+	}
+	span.SetAttributes(attribute.Bool("proxy.shared", false))
+
+	sshConfig, endpoint, err := ConnectionToSSHClientConfig(midtypes.Connection{
+		ConnectionBase: proxy.ConnectionBase,
+	})
+	if err != nil {
+		err = fmt.Errorf("%w %q: %w", ErrProxy, host, err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, false, err
+	}
+
+	logger.DebugContext(ctx, "bastionClient: dialing proxy")
+	var client *ssh.Client
+	if len(proxies) == 1 {
+		client, err = ssh.Dial("tcp", endpoint, sshConfig)
+	} else {
+		client, err = dialThroughBastion(ctx, proxies[:len(proxies)-1], endpoint, sshConfig)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w %s: %w", ErrProxy, endpoint, err)
+		logger.ErrorContext(ctx, "bastionClient: error dialing proxy", slog.Any("error", err))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, false, err
+	}
+
+	state.Client = client
+	// the next connection through the proxy redials it once it is lost.
+	go func() {
+		client.Wait()
+		state.Mutex.Lock()
+		if state.Client == client {
+			state.Client = nil
+		}
+		state.Mutex.Unlock()
+	}()
+
+	span.SetStatus(codes.Ok, "")
+	return client, false, nil
+}
+
+// dialThrough connects to endpoint with sshConfig through a connection
+// forwarded by via. sshConfig.Timeout applies to both the forwarded connection
+// and the SSH handshake over it.
+func dialThrough(
+	ctx context.Context,
+	via *ssh.Client,
+	endpoint string,
+	sshConfig *ssh.ClientConfig,
+) (*ssh.Client, error) {
+	if sshConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sshConfig.Timeout)
+		defer cancel()
+	}
+
+	conn, err := via.DialContext(ctx, "tcp", endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%w to %s: %w", errForwarding, endpoint, err)
+	}
+
+	type handshake struct {
+		conn  ssh.Conn
+		chans <-chan ssh.NewChannel
+		reqs  <-chan *ssh.Request
+		err   error
+	}
+	done := make(chan handshake, 1)
+	go func() {
+		c, chans, reqs, err := ssh.NewClientConn(conn, endpoint, sshConfig)
+		done <- handshake{c, chans, reqs, err}
+	}()
+
+	// forwarded connections don't support deadlines, so a handshake that takes
+	// too long is stopped by closing the connection instead.
+	select {
+	case result := <-done:
+		if result.err != nil {
+			conn.Close()
+			return nil, result.err
+		}
+		return ssh.NewClient(result.conn, result.chans, result.reqs), nil
+	case <-ctx.Done():
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s: %w", endpoint, ctx.Err())
+	}
+}
+
+// DisconnectBastions closes all connections to proxy hosts.
+func DisconnectBastions(ctx context.Context) error {
+	logger := telemetry.LoggerFromContext(ctx)
+
+	var multierr error
+	for id, state := range BastionPool.Items() {
+		state.Mutex.Lock()
+		if state.Client != nil {
+			err := state.Client.Close()
+			if err != nil && !errors.Is(err, net.ErrClosed) {
+				multierr = errors.Join(multierr, err)
+			}
+			state.Client = nil
+		}
+		state.Mutex.Unlock()
+		BastionPool.Delete(id)
+		logger.DebugContext(ctx, fmt.Sprintf("DisconnectBastions: disconnected %d", id))
+	}
+	return multierr
+}
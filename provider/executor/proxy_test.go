@@ -0,0 +1,303 @@
+package executor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sapslaj/mid/pkg/hashstructure"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+// testSSHServer is an in-process SSH server that accepts the password
+// "hunter2" and forwards connections like a bastion would.
+type testSSHServer struct {
+	Addr string
+	// Conns is how many SSH connections were made to the server.
+	Conns atomic.Int32
+	// Forwards is how many connections the server forwarded.
+	Forwards atomic.Int32
+
+	listener net.Listener
+	mutex    sync.Mutex
+	open     []ssh.Conn
+}
+
+func newTestSSHServer(t *testing.T) *testSSHServer {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) != "hunter2" {
+				return nil, ssh.ErrNoAuth
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &testSSHServer{Addr: listener.Addr().String(), listener: listener}
+	t.Cleanup(server.Close)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn, config)
+		}
+	}()
+	return server
+}
+
+func (server *testSSHServer) serve(conn net.Conn, config *ssh.ServerConfig) {
+	sshConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	server.Conns.Add(1)
+	server.mutex.Lock()
+	server.open = append(server.open, sshConn)
+	server.mutex.Unlock()
+
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "only forwarding is supported")
+			continue
+		}
+		var payload struct {
+			Host     string
+			Port     uint32
+			OrigHost string
+			OrigPort uint32
+		}
+		err := ssh.Unmarshal(newChannel.ExtraData(), &payload)
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, channelReqs, err := newChannel.Accept()
+		if err != nil {
+			target.Close()
+			continue
+		}
+		server.Forwards.Add(1)
+		go ssh.DiscardRequests(channelReqs)
+		go func() {
+			io.Copy(channel, target)
+			channel.Close()
+		}()
+		go func() {
+			io.Copy(target, channel)
+			target.Close()
+		}()
+	}
+}
+
+// Close stops the server and drops every connection to it.
+func (server *testSSHServer) Close() {
+	server.listener.Close()
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for _, conn := range server.open {
+		conn.Close()
+	}
+	server.open = nil
+}
+
+// base returns the connection settings for the server.
+func (server *testSSHServer) base(t *testing.T) midtypes.ConnectionBase {
+	t.Helper()
+
+	host, port, err := net.SplitHostPort(server.Addr)
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return midtypes.ConnectionBase{
+		Host:     ptr.Of(host),
+		Port:     ptr.Of(float64(portNumber)),
+		User:     ptr.Of("mid"),
+		Password: ptr.Of("hunter2"),
+	}
+}
+
+func (server *testSSHServer) proxy(t *testing.T) midtypes.ProxyConnection {
+	return midtypes.ProxyConnection{ConnectionBase: server.base(t)}
+}
+
+// dialTestConnection connects to target through proxies like SetupAgent does.
+func dialTestConnection(
+	t *testing.T,
+	target *testSSHServer,
+	proxies ...midtypes.ProxyConnection,
+) (*ssh.Client, error) {
+	t.Helper()
+
+	connection := midtypes.Connection{
+		ConnectionBase: target.base(t),
+		Proxies:        &proxies,
+	}
+	sshConfig, endpoint, err := ConnectionToSSHClientConfig(connection)
+	require.NoError(t, err)
+	client, err := DialConnection(context.Background(), connection, sshConfig, endpoint)
+	if client != nil {
+		t.Cleanup(func() {
+			client.Close()
+		})
+	}
+	return client, err
+}
+
+// sharedBastion returns the shared connection to the last of proxies.
+func sharedBastion(t *testing.T, proxies ...midtypes.ProxyConnection) *BastionState {
+	t.Helper()
+
+	id, err := hashstructure.Hash(proxies, hashstructure.FormatV2, nil)
+	require.NoError(t, err)
+	state, ok := BastionPool.Load(id)
+	require.True(t, ok)
+	return state
+}
+
+func TestDialConnectionChainedProxies(t *testing.T) {
+	t.Parallel()
+
+	first := newTestSSHServer(t)
+	second := newTestSSHServer(t)
+	target := newTestSSHServer(t)
+	other := newTestSSHServer(t)
+	proxies := []midtypes.ProxyConnection{first.proxy(t), second.proxy(t)}
+
+	client, err := dialTestConnection(t, target, proxies...)
+	require.NoError(t, err)
+	_, _, err = client.SendRequest("ping@mid", true, nil)
+	require.NoError(t, err)
+
+	// the first proxy forwarded the connection to the second one, which
+	// forwarded the connection to the target.
+	assert.Equal(t, int32(1), first.Conns.Load())
+	assert.Equal(t, int32(1), first.Forwards.Load())
+	assert.Equal(t, int32(1), second.Conns.Load())
+	assert.Equal(t, int32(1), second.Forwards.Load())
+	assert.Equal(t, int32(1), target.Conns.Load())
+
+	// connections through the same proxies share the connections to them.
+	_, err = dialTestConnection(t, target, proxies...)
+	require.NoError(t, err)
+	_, err = dialTestConnection(t, other, proxies...)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), first.Conns.Load())
+	assert.Equal(t, int32(1), first.Forwards.Load())
+	assert.Equal(t, int32(1), second.Conns.Load())
+	assert.Equal(t, int32(3), second.Forwards.Load())
+	assert.Equal(t, int32(2), target.Conns.Load())
+	assert.Equal(t, int32(1), other.Conns.Load())
+
+	// a connection through part of the chain shares the connection to it.
+	_, err = dialTestConnection(t, other, proxies[0])
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), first.Conns.Load())
+	assert.Equal(t, int32(2), first.Forwards.Load())
+	assert.Equal(t, int32(2), other.Conns.Load())
+}
+
+func TestDialConnectionLostProxy(t *testing.T) {
+	t.Parallel()
+
+	bastion := newTestSSHServer(t)
+	target := newTestSSHServer(t)
+	proxy := bastion.proxy(t)
+
+	_, err := dialTestConnection(t, target, proxy)
+	require.NoError(t, err)
+	state := sharedBastion(t, proxy)
+
+	// a shared connection that was lost without it being noticed yet is
+	// replaced.
+	state.Mutex.Lock()
+	lost := state.Client
+	state.Mutex.Unlock()
+	sshConfig, endpoint, err := ConnectionToSSHClientConfig(midtypes.Connection{ConnectionBase: proxy.ConnectionBase})
+	require.NoError(t, err)
+	stale, err := ssh.Dial("tcp", endpoint, sshConfig)
+	require.NoError(t, err)
+	require.NoError(t, stale.Close())
+	state.Mutex.Lock()
+	state.Client = stale
+	state.Mutex.Unlock()
+
+	_, err = dialTestConnection(t, target, proxy)
+	require.NoError(t, err)
+	state.Mutex.Lock()
+	assert.NotSame(t, stale, state.Client)
+	assert.NotSame(t, lost, state.Client)
+	assert.NotNil(t, state.Client)
+	state.Mutex.Unlock()
+	assert.Equal(t, int32(3), bastion.Conns.Load())
+	assert.Equal(t, int32(2), target.Conns.Load())
+	lost.Close()
+}
+
+func TestDialConnectionProxyRefuses(t *testing.T) {
+	t.Parallel()
+
+	bastion := newTestSSHServer(t)
+	target := newTestSSHServer(t)
+	proxy := bastion.proxy(t)
+
+	_, err := dialTestConnection(t, target, proxy)
+	require.NoError(t, err)
+	state := sharedBastion(t, proxy)
+	state.Mutex.Lock()
+	shared := state.Client
+	state.Mutex.Unlock()
+
+	// the proxy refusing to forward a connection says nothing about the
+	// connection to it, so it is kept.
+	target.Close()
+	_, err = dialTestConnection(t, target, proxy)
+	var openChannelErr *ssh.OpenChannelError
+	assert.ErrorAs(t, err, &openChannelErr)
+	state.Mutex.Lock()
+	assert.Same(t, shared, state.Client)
+	state.Mutex.Unlock()
+	assert.Equal(t, int32(1), bastion.Conns.Load())
+}
+
+func TestDialConnectionProxyDown(t *testing.T) {
+	t.Parallel()
+
+	bastion := newTestSSHServer(t)
+	target := newTestSSHServer(t)
+	proxy := bastion.proxy(t)
+	bastion.Close()
+
+	_, err := dialTestConnection(t, target, proxy)
+	assert.ErrorIs(t, err, ErrProxy)
+	assert.Equal(t, int32(0), target.Conns.Load())
+}
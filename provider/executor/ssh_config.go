@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/pkg/sshconfig"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+var (
+	ErrSSHConfig = errors.New("error reading SSH config")
+
+	ErrProxyJumpLoop = errors.New("too many nested ProxyJump hosts")
+)
+
+// maxProxyJumps is how deep jump hosts can be nested through their own
+// ProxyJump settings, which stops hosts that jump through each other.
+const maxProxyJumps = 10
+
+// expandHome expands a leading "~/" in path to the home directory.
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// resolveSSHConfig fills in the settings connection doesn't set from the
+// OpenSSH client config file given by its sshConfig, if any.
+func resolveSSHConfig(connection midtypes.Connection) (midtypes.Connection, error) {
+	if connection.SSHConfig == nil || connection.Host == nil {
+		return connection, nil
+	}
+
+	path, err := expandHome(*connection.SSHConfig)
+	if err != nil {
+		return connection, errors.Join(ErrSSHConfig, err)
+	}
+
+	base, proxies, err := resolveSSHConfigHost(path, connection.ConnectionBase, 0)
+	if err != nil {
+		return connection, err
+	}
+	connection.ConnectionBase = base
+	if connection.Proxies == nil && len(proxies) > 0 {
+		// jump hosts use the same client side settings as the host.
+		for i := range proxies {
+			inheritClientSettings(&proxies[i].ConnectionBase, base)
+		}
+		connection.Proxies = &proxies
+	}
+	return connection, nil
+}
+
+// inheritClientSettings sets the settings of the SSH client itself, rather
+// than of the host it connects to, that proxy doesn't set to those of base.
+func inheritClientSettings(proxy *midtypes.ConnectionBase, base midtypes.ConnectionBase) {
+	if proxy.SSHAgent == nil {
+		proxy.SSHAgent = base.SSHAgent
+	}
+	if proxy.SSHAgentSocketPath == nil {
+		proxy.SSHAgentSocketPath = base.SSHAgentSocketPath
+	}
+	if proxy.PerDialTimeout == nil {
+		proxy.PerDialTimeout = base.PerDialTimeout
+	}
+	if proxy.KnownHosts == nil {
+		proxy.KnownHosts = base.KnownHosts
+	}
+	if proxy.KnownHostsFile == nil {
+		proxy.KnownHostsFile = base.KnownHostsFile
+	}
+	if proxy.HostKeyChecking == nil {
+		proxy.HostKeyChecking = base.HostKeyChecking
+	}
+	if proxy.HostCAKeys == nil {
+		proxy.HostCAKeys = base.HostCAKeys
+	}
+}
+
+// resolveSSHConfigHost fills in the settings base doesn't set from the
+// settings of its host in the config file at path, and returns the proxies
+// its ProxyJump goes through. depth is the number of jump hosts base itself is
+// nested in.
+func resolveSSHConfigHost(
+	path string,
+	base midtypes.ConnectionBase,
+	depth int,
+) (midtypes.ConnectionBase, []midtypes.ProxyConnection, error) {
+	if depth > maxProxyJumps {
+		return base, nil, fmt.Errorf("%w in %s", ErrProxyJumpLoop, path)
+	}
+
+	settings, err := sshconfig.Resolve(path, *base.Host)
+	if err != nil {
+		return base, nil, errors.Join(ErrSSHConfig, err)
+	}
+
+	if settings.HostName != "" {
+		base.Host = &settings.HostName
+	}
+	if base.User == nil && settings.User != "" {
+		base.User = &settings.User
+	}
+	if base.Port == nil && settings.Port != "" {
+		port, err := strconv.ParseUint(settings.Port, 10, 16)
+		if err != nil {
+			return base, nil, fmt.Errorf("%w %s: Port: %w", ErrSSHConfig, path, err)
+		}
+		base.Port = ptr.Of(float64(port))
+	}
+	if base.PrivateKey == nil {
+		setIdentity(&base, settings.IdentityFiles)
+	}
+	if base.KnownHostsFile == nil {
+		// like OpenSSH, files that don't exist are skipped. Only the first one
+		// that does is used.
+		for _, file := range settings.UserKnownHostsFiles {
+			knownHostsFile, err := expandHome(file)
+			if err != nil || knownHostsFile == os.DevNull {
+				continue
+			}
+			if _, err := os.Stat(knownHostsFile); err == nil {
+				base.KnownHostsFile = &knownHostsFile
+				break
+			}
+		}
+	}
+
+	if settings.ProxyJump == "" || strings.EqualFold(settings.ProxyJump, "none") {
+		return base, nil, nil
+	}
+	proxies := []midtypes.ProxyConnection{}
+	for _, spec := range strings.Split(settings.ProxyJump, ",") {
+		jump, err := parseJumpHost(strings.TrimSpace(spec))
+		if err != nil {
+			return base, nil, fmt.Errorf("%w %s: ProxyJump: %w", ErrSSHConfig, path, err)
+		}
+		jump, jumpProxies, err := resolveSSHConfigHost(path, jump, depth+1)
+		if err != nil {
+			return base, nil, err
+		}
+		// a jump host that has a ProxyJump of its own is connected to through
+		// those first.
+		proxies = append(proxies, jumpProxies...)
+		proxies = append(proxies, midtypes.ProxyConnection{ConnectionBase: jump})
+	}
+	return base, proxies, nil
+}
+
+// setIdentity sets the private key of base to the first of identityFiles that
+// can be used, along with the certificate next to it if there is one.
+func setIdentity(base *midtypes.ConnectionBase, identityFiles []string) {
+	home, _ := os.UserHomeDir()
+	localUser := ""
+	if current, err := user.Current(); err == nil {
+		localUser = current.Username
+	}
+	tokens := map[byte]string{
+		'd': home,
+		'h': ptr.FromDefault(base.Host, ""),
+		'r': ptr.FromDefault(base.User, localUser),
+		'u': localUser,
+	}
+
+	for _, file := range identityFiles {
+		path, err := expandHome(sshconfig.ExpandTokens(file, tokens))
+		if err != nil {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		// a key that needs a passphrase that isn't given is left to the SSH
+		// agent.
+		if base.PrivateKeyPassword == nil {
+			_, err = ssh.ParsePrivateKey(content)
+			var passphraseErr *ssh.PassphraseMissingError
+			if errors.As(err, &passphraseErr) {
+				continue
+			}
+		}
+		base.PrivateKey = ptr.Of(string(content))
+
+		if base.Certificate == nil {
+			certificate, err := os.ReadFile(path + "-cert.pub")
+			if err == nil {
+				base.Certificate = ptr.Of(string(certificate))
+			}
+		}
+		return
+	}
+}
+
+// parseJumpHost parses a ProxyJump host of the form "[user@]host[:port]".
+func parseJumpHost(spec string) (midtypes.ConnectionBase, error) {
+	base := midtypes.ConnectionBase{}
+	spec = strings.TrimPrefix(spec, "ssh://")
+	if at := strings.LastIndex(spec, "@"); at >= 0 {
+		base.User = ptr.Of(spec[:at])
+		spec = spec[at+1:]
+	}
+	host := spec
+	if strings.HasPrefix(spec, "[") || strings.Count(spec, ":") == 1 {
+		var port string
+		var err error
+		host, port, err = splitHostPort(spec)
+		if err != nil {
+			return base, err
+		}
+		if port != "" {
+			value, err := strconv.ParseUint(port, 10, 16)
+			if err != nil {
+				return base, fmt.Errorf("invalid port %q: %w", port, err)
+			}
+			base.Port = ptr.Of(float64(value))
+		}
+	}
+	if host == "" {
+		return base, fmt.Errorf("no host in %q", spec)
+	}
+	base.Host = &host
+	return base, nil
+}
+
+// splitHostPort splits "host:port" or "[host]:port", where the port is
+// optional.
+func splitHostPort(spec string) (string, string, error) {
+	if rest, ok := strings.CutPrefix(spec, "["); ok {
+		host, port, ok := strings.Cut(rest, "]")
+		if !ok {
+			return "", "", fmt.Errorf("missing ] in %q", spec)
+		}
+		if port == "" {
+			return host, "", nil
+		}
+		port, ok = strings.CutPrefix(port, ":")
+		if !ok {
+			return "", "", fmt.Errorf("unexpected %q after ] in %q", port, spec)
+		}
+		return host, port, nil
+	}
+	host, port, _ := strings.Cut(spec, ":")
+	return host, port, nil
+}
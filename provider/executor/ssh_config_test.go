@@ -0,0 +1,524 @@
+package executor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sapslaj/mid/pkg/providerfw/infer"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+// newTestPrivateKey returns a new private key in OpenSSH format, encrypted if
+// passphrase isn't empty.
+func newTestPrivateKey(t *testing.T, passphrase string) string {
+	t.Helper()
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(block))
+}
+
+// writeTestFiles writes files to a temporary directory, replacing "$DIR" in
+// their contents with its path, and returns the directory.
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		content = strings.ReplaceAll(content, "$DIR", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestSplitHostPort(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		host  string
+		port  string
+		err   bool
+	}{
+		"host":                  {input: "bastion", host: "bastion"},
+		"host and port":         {input: "bastion:2222", host: "bastion", port: "2222"},
+		"empty port":            {input: "bastion:", host: "bastion"},
+		"bracketed":             {input: "[bastion]", host: "bastion"},
+		"bracketed and port":    {input: "[bastion]:2222", host: "bastion", port: "2222"},
+		"bracketed IPv6":        {input: "[2001:db8::1]:2222", host: "2001:db8::1", port: "2222"},
+		"missing bracket":       {input: "[2001:db8::1:2222", err: true},
+		"garbage after bracket": {input: "[bastion]2222", err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			host, port, err := splitHostPort(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.host, host)
+			assert.Equal(t, tc.port, port)
+		})
+	}
+}
+
+func TestParseJumpHost(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		expect midtypes.ConnectionBase
+		err    bool
+	}{
+		"host": {
+			input:  "bastion",
+			expect: midtypes.ConnectionBase{Host: ptr.Of("bastion")},
+		},
+
+		"user, host, and port": {
+			input: "jump@bastion:2222",
+			expect: midtypes.ConnectionBase{
+				User: ptr.Of("jump"),
+				Host: ptr.Of("bastion"),
+				Port: ptr.Of(2222.0),
+			},
+		},
+
+		"ssh URI": {
+			input: "ssh://jump@bastion:2222",
+			expect: midtypes.ConnectionBase{
+				User: ptr.Of("jump"),
+				Host: ptr.Of("bastion"),
+				Port: ptr.Of(2222.0),
+			},
+		},
+
+		"user with @": {
+			input: "jump@example.com@bastion",
+			expect: midtypes.ConnectionBase{
+				User: ptr.Of("jump@example.com"),
+				Host: ptr.Of("bastion"),
+			},
+		},
+
+		"bare IPv6": {
+			input:  "2001:db8::1",
+			expect: midtypes.ConnectionBase{Host: ptr.Of("2001:db8::1")},
+		},
+
+		"bracketed IPv6 and port": {
+			input: "[2001:db8::1]:2222",
+			expect: midtypes.ConnectionBase{
+				Host: ptr.Of("2001:db8::1"),
+				Port: ptr.Of(2222.0),
+			},
+		},
+
+		"invalid port": {
+			input: "bastion:ssh",
+			err:   true,
+		},
+
+		"port out of range": {
+			input: "bastion:65536",
+			err:   true,
+		},
+
+		"no host": {
+			input: "jump@",
+			err:   true,
+		},
+
+		"no host with port": {
+			input: ":2222",
+			err:   true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := parseJumpHost(tc.input)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+		})
+	}
+}
+
+func TestSetIdentity(t *testing.T) {
+	t.Parallel()
+
+	key := newTestPrivateKey(t, "")
+	otherKey := newTestPrivateKey(t, "")
+	encryptedKey := newTestPrivateKey(t, "hunter2")
+	dir := writeTestFiles(t, map[string]string{
+		"id_plain":              key,
+		"id_certified":          otherKey,
+		"id_certified-cert.pub": "ssh-ed25519-cert-v01@openssh.com AAAA...",
+		"id_encrypted":          encryptedKey,
+		"id_web.example.com":    key,
+		"id_deploy":             otherKey,
+	})
+
+	tests := map[string]struct {
+		base          midtypes.ConnectionBase
+		identityFiles []string
+		expect        midtypes.ConnectionBase
+	}{
+		"none": {
+			expect: midtypes.ConnectionBase{},
+		},
+
+		"first usable": {
+			identityFiles: []string{
+				filepath.Join(dir, "id_missing"),
+				filepath.Join(dir, "id_plain"),
+				filepath.Join(dir, "id_certified"),
+			},
+			expect: midtypes.ConnectionBase{PrivateKey: ptr.Of(key)},
+		},
+
+		"none usable": {
+			identityFiles: []string{
+				filepath.Join(dir, "id_missing"),
+				filepath.Join(dir, "id_encrypted"),
+			},
+			expect: midtypes.ConnectionBase{},
+		},
+
+		"certificate next to key": {
+			identityFiles: []string{filepath.Join(dir, "id_certified")},
+			expect: midtypes.ConnectionBase{
+				PrivateKey:  ptr.Of(otherKey),
+				Certificate: ptr.Of("ssh-ed25519-cert-v01@openssh.com AAAA..."),
+			},
+		},
+
+		"certificate already set": {
+			base:          midtypes.ConnectionBase{Certificate: ptr.Of("given")},
+			identityFiles: []string{filepath.Join(dir, "id_certified")},
+			expect: midtypes.ConnectionBase{
+				PrivateKey:  ptr.Of(otherKey),
+				Certificate: ptr.Of("given"),
+			},
+		},
+
+		"encrypted key without passphrase": {
+			identityFiles: []string{
+				filepath.Join(dir, "id_encrypted"),
+				filepath.Join(dir, "id_plain"),
+			},
+			expect: midtypes.ConnectionBase{PrivateKey: ptr.Of(key)},
+		},
+
+		"encrypted key with passphrase": {
+			base: midtypes.ConnectionBase{PrivateKeyPassword: ptr.Of("hunter2")},
+			identityFiles: []string{
+				filepath.Join(dir, "id_encrypted"),
+				filepath.Join(dir, "id_plain"),
+			},
+			expect: midtypes.ConnectionBase{
+				PrivateKey:         ptr.Of(encryptedKey),
+				PrivateKeyPassword: ptr.Of("hunter2"),
+			},
+		},
+
+		"tokens": {
+			base: midtypes.ConnectionBase{
+				Host: ptr.Of("web.example.com"),
+				User: ptr.Of("deploy"),
+			},
+			identityFiles: []string{
+				filepath.Join(dir, "id_%h_%%"),
+				filepath.Join(dir, "id_%h"),
+			},
+			expect: midtypes.ConnectionBase{
+				Host:       ptr.Of("web.example.com"),
+				User:       ptr.Of("deploy"),
+				PrivateKey: ptr.Of(key),
+			},
+		},
+
+		"remote user token": {
+			base: midtypes.ConnectionBase{User: ptr.Of("deploy")},
+			identityFiles: []string{
+				filepath.Join(dir, "id_%r"),
+			},
+			expect: midtypes.ConnectionBase{
+				User:       ptr.Of("deploy"),
+				PrivateKey: ptr.Of(otherKey),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			base := tc.base
+			setIdentity(&base, tc.identityFiles)
+			assert.Equal(t, tc.expect, base)
+		})
+	}
+}
+
+func TestResolveSSHConfig(t *testing.T) {
+	t.Parallel()
+
+	key := newTestPrivateKey(t, "")
+	dir := writeTestFiles(t, map[string]string{
+		"id_web":      key,
+		"known_hosts": "",
+		"config": `
+Host web
+  HostName 192.0.2.10
+  User deploy
+  Port 2222
+  IdentityFile $DIR/id_web
+  UserKnownHostsFile $DIR/known_hosts_missing $DIR/known_hosts
+  ProxyJump bastion
+
+Host bastion
+  HostName bastion.example.com
+  User jump
+  ProxyJump gateway:2200
+
+Host direct
+  HostName 192.0.2.11
+  ProxyJump none
+
+Host loop-a
+  ProxyJump loop-b
+
+Host loop-b
+  ProxyJump loop-a
+
+Host bad-port
+  Port ssh
+
+Host bad-jump
+  ProxyJump jump@
+`,
+	})
+	config := filepath.Join(dir, "config")
+
+	tests := map[string]struct {
+		connection midtypes.Connection
+		expect     midtypes.Connection
+		err        error
+	}{
+		"no config": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("web")},
+			},
+			expect: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("web")},
+			},
+		},
+
+		"no host": {
+			connection: midtypes.Connection{SSHConfig: ptr.Of(config)},
+			expect:     midtypes.Connection{SSHConfig: ptr.Of(config)},
+		},
+
+		"host from config": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{
+					Host:       ptr.Of("web"),
+					SSHAgent:   ptr.Of(true),
+					KnownHosts: ptr.Of("@cert-authority * ssh-ed25519 ..."),
+				},
+				SSHConfig: ptr.Of(config),
+			},
+			expect: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{
+					Host:           ptr.Of("192.0.2.10"),
+					User:           ptr.Of("deploy"),
+					Port:           ptr.Of(2222.0),
+					PrivateKey:     ptr.Of(key),
+					SSHAgent:       ptr.Of(true),
+					KnownHosts:     ptr.Of("@cert-authority * ssh-ed25519 ..."),
+					KnownHostsFile: ptr.Of(filepath.Join(dir, "known_hosts")),
+				},
+				SSHConfig: ptr.Of(config),
+				// the jump host's own jump host comes first, and both use the
+				// client settings of the host.
+				Proxies: &[]midtypes.ProxyConnection{
+					{
+						ConnectionBase: midtypes.ConnectionBase{
+							Host:           ptr.Of("gateway"),
+							Port:           ptr.Of(2200.0),
+							SSHAgent:       ptr.Of(true),
+							KnownHosts:     ptr.Of("@cert-authority * ssh-ed25519 ..."),
+							KnownHostsFile: ptr.Of(filepath.Join(dir, "known_hosts")),
+						},
+					},
+					{
+						ConnectionBase: midtypes.ConnectionBase{
+							Host:           ptr.Of("bastion.example.com"),
+							User:           ptr.Of("jump"),
+							SSHAgent:       ptr.Of(true),
+							KnownHosts:     ptr.Of("@cert-authority * ssh-ed25519 ..."),
+							KnownHostsFile: ptr.Of(filepath.Join(dir, "known_hosts")),
+						},
+					},
+				},
+			},
+		},
+
+		"connection settings win": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{
+					Host:       ptr.Of("web"),
+					User:       ptr.Of("admin"),
+					Port:       ptr.Of(22.0),
+					PrivateKey: ptr.Of("given"),
+				},
+				SSHConfig: ptr.Of(config),
+				Proxies:   &[]midtypes.ProxyConnection{},
+			},
+			expect: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{
+					Host:           ptr.Of("192.0.2.10"),
+					User:           ptr.Of("admin"),
+					Port:           ptr.Of(22.0),
+					PrivateKey:     ptr.Of("given"),
+					KnownHostsFile: ptr.Of(filepath.Join(dir, "known_hosts")),
+				},
+				SSHConfig: ptr.Of(config),
+				Proxies:   &[]midtypes.ProxyConnection{},
+			},
+		},
+
+		"ProxyJump none": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("direct")},
+				SSHConfig:      ptr.Of(config),
+			},
+			expect: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("192.0.2.11")},
+				SSHConfig:      ptr.Of(config),
+			},
+		},
+
+		"host not in config": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("other")},
+				SSHConfig:      ptr.Of(config),
+			},
+			expect: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("other")},
+				SSHConfig:      ptr.Of(config),
+			},
+		},
+
+		"ProxyJump loop": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("loop-a")},
+				SSHConfig:      ptr.Of(config),
+			},
+			err: ErrProxyJumpLoop,
+		},
+
+		"invalid port": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("bad-port")},
+				SSHConfig:      ptr.Of(config),
+			},
+			err: ErrSSHConfig,
+		},
+
+		"invalid ProxyJump": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("bad-jump")},
+				SSHConfig:      ptr.Of(config),
+			},
+			err: ErrSSHConfig,
+		},
+
+		"missing config": {
+			connection: midtypes.Connection{
+				ConnectionBase: midtypes.ConnectionBase{Host: ptr.Of("web")},
+				SSHConfig:      ptr.Of(filepath.Join(dir, "missing")),
+			},
+			err: ErrSSHConfig,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := resolveSSHConfig(tc.connection)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expect, result)
+		})
+	}
+}
+
+func TestResolveSSHConfigDefaultedConnection(t *testing.T) {
+	t.Parallel()
+
+	dir := writeTestFiles(t, map[string]string{
+		"config": `
+Host web
+  HostName 192.0.2.10
+  User deploy
+  Port 2222
+`,
+	})
+
+	// the connection as a resource gets it, with its defaults filled in.
+	type input struct {
+		Connection midtypes.Connection `pulumi:"connection"`
+	}
+	inputs := property.NewMap(map[string]property.Value{
+		"connection": property.New(property.NewMap(map[string]property.Value{
+			"host":      property.New("web"),
+			"sshConfig": property.New(filepath.Join(dir, "config")),
+		})),
+	})
+	defaulted, failures, err := infer.DefaultCheck[input](context.Background(), inputs)
+	require.NoError(t, err)
+	require.Empty(t, failures)
+	assert.Nil(t, defaulted.Connection.User)
+	assert.Nil(t, defaulted.Connection.Port)
+
+	result, err := resolveSSHConfig(defaulted.Connection)
+	require.NoError(t, err)
+	assert.Equal(t, ptr.Of("192.0.2.10"), result.Host)
+	assert.Equal(t, ptr.Of("deploy"), result.User)
+	assert.Equal(t, ptr.Of(2222.0), result.Port)
+}
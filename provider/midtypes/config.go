@@ -2,6 +2,7 @@ package midtypes
 
 import (
 	"context"
+	"time"
 
 	"github.com/sapslaj/mid/pkg/env"
 	"github.com/sapslaj/mid/pkg/providerfw/infer"
@@ -22,6 +23,33 @@ type ResourceConfig struct {
 	// enabled by default. Disabling it will speed up preview at the cost of
 	// potentially running into unexpected errors during apply.
 	DryRunCheck *bool `pulumi:"check,optional"`
+
+	// HeartbeatInterval is how often, in seconds, the provider pings the agent
+	// to make sure it is still alive. Defaults to 60.
+	HeartbeatInterval *int `pulumi:"heartbeatInterval,optional"`
+
+	// HeartbeatTimeout is how long, in seconds, the agent waits without hearing
+	// from the provider before it finishes any in-flight work and shuts itself
+	// down. Defaults to 120. If set to `-1` the agent never times out.
+	HeartbeatTimeout *int `pulumi:"heartbeatTimeout,optional"`
+
+	// PythonInterpreter is the Python interpreter used to run Ansible modules on
+	// the remote host, either a path or a command name to look up in `PATH`. If
+	// not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter *string `pulumi:"pythonInterpreter,optional"`
+
+	// AgentGCDays is how many days an agent version can go unused on a remote
+	// host before it is removed by the agent of another version. Defaults to 14.
+	// If set to `-1` unused agent versions are never removed.
+	AgentGCDays *int `pulumi:"agentGCDays,optional"`
+}
+
+func (config *ResourceConfig) Annotate(a infer.Annotator) {
+	a.Describe(&config.PythonInterpreter, `The Python interpreter used to run Ansible
+modules on the remote host, either a path or a command name to look up in PATH.
+It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+/usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+installed. If not set, the agent picks the first suitable interpreter it finds.`)
 }
 
 // GetDeleteUnreachable determines if the environment should delete unreachable
@@ -48,6 +76,64 @@ func (config ResourceConfig) GetDryRunCheck() bool {
 	return env.MustGetDefault("PULUMI_MID_DRY_RUN_CHECK", true)
 }
 
+func (config ResourceConfig) GetHeartbeatInterval() time.Duration {
+	var seconds int
+	if config.HeartbeatInterval != nil {
+		seconds = *config.HeartbeatInterval
+	} else {
+		seconds = env.MustGetDefault("PULUMI_MID_HEARTBEAT_INTERVAL", 60)
+	}
+	if seconds <= 0 {
+		seconds = 60
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetHeartbeatTimeout returns the agent-side heartbeat timeout. A negative
+// duration means the timeout is disabled.
+func (config ResourceConfig) GetHeartbeatTimeout() time.Duration {
+	var seconds int
+	if config.HeartbeatTimeout != nil {
+		seconds = *config.HeartbeatTimeout
+	} else {
+		seconds = env.MustGetDefault("PULUMI_MID_HEARTBEAT_TIMEOUT", 120)
+	}
+	if seconds < 0 {
+		return -1
+	}
+	if seconds == 0 {
+		seconds = 120
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetPythonInterpreter returns the configured Python interpreter, or an empty
+// string if the agent should discover one.
+func (config ResourceConfig) GetPythonInterpreter() string {
+	if config.PythonInterpreter != nil {
+		return *config.PythonInterpreter
+	}
+	return env.MustGetDefault("PULUMI_MID_PYTHON_INTERPRETER", "")
+}
+
+// GetAgentGCAfter returns how long an agent version can go unused before it is
+// removed. A negative duration means unused agent versions are never removed.
+func (config ResourceConfig) GetAgentGCAfter() time.Duration {
+	var days int
+	if config.AgentGCDays != nil {
+		days = *config.AgentGCDays
+	} else {
+		days = env.MustGetDefault("PULUMI_MID_AGENT_GC_DAYS", 14)
+	}
+	if days < 0 {
+		return -1
+	}
+	if days == 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // provider configuration
 type ProviderConfig struct {
 	ResourceConfig
@@ -67,6 +153,18 @@ func GetResourceConfig(ctx context.Context, config *ResourceConfig) ResourceConf
 	if providerConfig.DryRunCheck != nil {
 		result.DryRunCheck = providerConfig.DryRunCheck
 	}
+	if providerConfig.HeartbeatInterval != nil {
+		result.HeartbeatInterval = providerConfig.HeartbeatInterval
+	}
+	if providerConfig.HeartbeatTimeout != nil {
+		result.HeartbeatTimeout = providerConfig.HeartbeatTimeout
+	}
+	if providerConfig.PythonInterpreter != nil {
+		result.PythonInterpreter = providerConfig.PythonInterpreter
+	}
+	if providerConfig.AgentGCDays != nil {
+		result.AgentGCDays = providerConfig.AgentGCDays
+	}
 	if config != nil {
 		if config.DeleteUnreachable != nil {
 			result.DeleteUnreachable = config.DeleteUnreachable
@@ -77,6 +175,18 @@ func GetResourceConfig(ctx context.Context, config *ResourceConfig) ResourceConf
 		if config.DryRunCheck != nil {
 			result.DryRunCheck = config.DryRunCheck
 		}
+		if config.HeartbeatInterval != nil {
+			result.HeartbeatInterval = config.HeartbeatInterval
+		}
+		if config.HeartbeatTimeout != nil {
+			result.HeartbeatTimeout = config.HeartbeatTimeout
+		}
+		if config.PythonInterpreter != nil {
+			result.PythonInterpreter = config.PythonInterpreter
+		}
+		if config.AgentGCDays != nil {
+			result.AgentGCDays = config.AgentGCDays
+		}
 	}
 	return result
 }
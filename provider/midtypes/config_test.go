@@ -3,6 +3,7 @@ package midtypes_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -100,6 +101,50 @@ func TestGetResourceConfig(t *testing.T) {
 			},
 		},
 
+		"heartbeat config from both provider and resource config": {
+			providerConfig: &midtypes.ProviderConfig{
+				ResourceConfig: midtypes.ResourceConfig{
+					HeartbeatInterval: ptr.Of(30),
+					HeartbeatTimeout:  ptr.Of(600),
+				},
+			},
+			resourceConfig: &midtypes.ResourceConfig{
+				HeartbeatTimeout: ptr.Of(-1),
+			},
+			expect: midtypes.ResourceConfig{
+				HeartbeatInterval: ptr.Of(30),
+				HeartbeatTimeout:  ptr.Of(-1),
+			},
+		},
+
+		"python interpreter from resource config overrides provider config": {
+			providerConfig: &midtypes.ProviderConfig{
+				ResourceConfig: midtypes.ResourceConfig{
+					PythonInterpreter: ptr.Of("/usr/bin/python3"),
+				},
+			},
+			resourceConfig: &midtypes.ResourceConfig{
+				PythonInterpreter: ptr.Of("/usr/libexec/platform-python"),
+			},
+			expect: midtypes.ResourceConfig{
+				PythonInterpreter: ptr.Of("/usr/libexec/platform-python"),
+			},
+		},
+
+		"agent GC days from resource config overrides provider config": {
+			providerConfig: &midtypes.ProviderConfig{
+				ResourceConfig: midtypes.ResourceConfig{
+					AgentGCDays: ptr.Of(30),
+				},
+			},
+			resourceConfig: &midtypes.ResourceConfig{
+				AgentGCDays: ptr.Of(-1),
+			},
+			expect: midtypes.ResourceConfig{
+				AgentGCDays: ptr.Of(-1),
+			},
+		},
+
 		"resource config overrides provider config": {
 			providerConfig: &midtypes.ProviderConfig{
 				ResourceConfig: midtypes.ResourceConfig{
@@ -130,3 +175,77 @@ func TestGetResourceConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceConfig_GetHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config         midtypes.ResourceConfig
+		expectInterval time.Duration
+		expectTimeout  time.Duration
+	}{
+		"explicit": {
+			config: midtypes.ResourceConfig{
+				HeartbeatInterval: ptr.Of(30),
+				HeartbeatTimeout:  ptr.Of(600),
+			},
+			expectInterval: 30 * time.Second,
+			expectTimeout:  10 * time.Minute,
+		},
+		"zero uses defaults": {
+			config: midtypes.ResourceConfig{
+				HeartbeatInterval: ptr.Of(0),
+				HeartbeatTimeout:  ptr.Of(0),
+			},
+			expectInterval: time.Minute,
+			expectTimeout:  2 * time.Minute,
+		},
+		"negative timeout disables it": {
+			config: midtypes.ResourceConfig{
+				HeartbeatInterval: ptr.Of(-1),
+				HeartbeatTimeout:  ptr.Of(-1),
+			},
+			expectInterval: time.Minute,
+			expectTimeout:  -1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expectInterval, tc.config.GetHeartbeatInterval())
+			assert.Equal(t, tc.expectTimeout, tc.config.GetHeartbeatTimeout())
+		})
+	}
+}
+
+func TestResourceConfig_GetAgentGCAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config midtypes.ResourceConfig
+		expect time.Duration
+	}{
+		"explicit": {
+			config: midtypes.ResourceConfig{AgentGCDays: ptr.Of(3)},
+			expect: 3 * 24 * time.Hour,
+		},
+		"zero uses default": {
+			config: midtypes.ResourceConfig{AgentGCDays: ptr.Of(0)},
+			expect: 14 * 24 * time.Hour,
+		},
+		"negative disables it": {
+			config: midtypes.ResourceConfig{AgentGCDays: ptr.Of(-1)},
+			expect: -1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, tc.config.GetAgentGCAfter())
+		})
+	}
+}
@@ -13,40 +13,149 @@ const (
 )
 
 type ConnectionBase struct {
-	User               *string  `pulumi:"user,optional"`
-	Password           *string  `pulumi:"password,optional" provider:"secret"`
-	Host               *string  `pulumi:"host,optional"`
-	Port               *float64 `pulumi:"port,optional"`
-	PrivateKey         *string  `pulumi:"privateKey,optional" provider:"secret"`
-	PrivateKeyPassword *string  `pulumi:"privateKeyPassword,optional" provider:"secret"`
-	SSHAgent           *bool    `pulumi:"sshAgent,optional"`
-	SSHAgentSocketPath *string  `pulumi:"sshAgentSocketPath,optional"`
-	PerDialTimeout     *int     `pulumi:"perDialTimeout,optional"`
-	HostKey            *string  `pulumi:"hostKey,optional"`
+	User               *string   `pulumi:"user,optional"`
+	Password           *string   `pulumi:"password,optional" provider:"secret"`
+	Host               *string   `pulumi:"host,optional"`
+	Port               *float64  `pulumi:"port,optional"`
+	PrivateKey         *string   `pulumi:"privateKey,optional" provider:"secret"`
+	PrivateKeyPassword *string   `pulumi:"privateKeyPassword,optional" provider:"secret"`
+	Certificate        *string   `pulumi:"certificate,optional"`
+	SSHAgent           *bool     `pulumi:"sshAgent,optional"`
+	SSHAgentSocketPath *string   `pulumi:"sshAgentSocketPath,optional"`
+	PerDialTimeout     *int      `pulumi:"perDialTimeout,optional"`
+	HostKey            *string   `pulumi:"hostKey,optional"`
+	KnownHosts         *string   `pulumi:"knownHosts,optional"`
+	KnownHostsFile     *string   `pulumi:"knownHostsFile,optional"`
+	HostKeyChecking    *string   `pulumi:"hostKeyChecking,optional"`
+	HostCAKeys         *[]string `pulumi:"hostCAKeys,optional"`
 	// TODO: add support for below
 	// DialErrorLimit     *int     `pulumi:"dialErrorLimit,optional"`
 }
 
+// Host key checking modes.
+const (
+	HostKeyCheckingStrict = "strict"
+	HostKeyCheckingTOFU   = "tofu"
+	HostKeyCheckingOff    = "off"
+)
+
+// ProxyConnection is a host, usually a bastion, that connections are tunneled
+// through with SSH port forwarding.
 type ProxyConnection struct {
 	ConnectionBase
 }
 
+func (i *ProxyConnection) Annotate(a infer.Annotator) {
+	a.Describe(&i, "Instructions for how to connect to a proxy (or bastion) host.")
+	a.Describe(&i.User, `The user that we should use for the connection to the
+proxy. Defaults to the current local user.`)
+	a.Describe(&i.Password, "The password we should use for the connection to the proxy.")
+	a.Describe(&i.Host, "The address of the proxy to connect to.")
+	a.Describe(&i.Port, "The port to connect to. Defaults to 22.")
+	a.SetDefault(&i.Port, DefaultConnectionPort)
+	a.Describe(&i.PrivateKey, `The contents of an SSH key to use for the
+connection to the proxy. This takes preference over the password if provided.`)
+	annotateCertificate(a, &i.ConnectionBase)
+	annotateHostKeyChecking(a, &i.ConnectionBase)
+}
+
+func annotateCertificate(a infer.Annotator, i *ConnectionBase) {
+	a.Describe(&i.Certificate, `The contents of an OpenSSH certificate (usually a
+"-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+key. It is for privateKey if that is set, and otherwise for the key of the same
+name in the SSH agent. Certificates held by the SSH agent itself are used
+without setting this.`)
+}
+
+func annotateHostKeyChecking(a infer.Annotator, i *ConnectionBase) {
+	a.Describe(&i.HostKey, `The public key the host is expected to have, in
+authorized_keys format.`)
+	a.Describe(&i.KnownHosts, `The contents of an OpenSSH known_hosts file to
+check the host's key against. Hashed hostnames, "@cert-authority", and
+"@revoked" lines are supported.`)
+	a.Describe(&i.HostCAKeys, `The public keys, in authorized_keys format, of the
+CAs that sign host certificates. A host presenting a certificate signed by one
+of them is trusted if the certificate is valid and names the host as a
+principal.`)
+	a.Describe(&i.KnownHostsFile, `The path of an OpenSSH known_hosts file on the
+machine running Pulumi to check the host's key against, e.g.
+"~/.ssh/known_hosts".`)
+	a.Describe(&i.HostKeyChecking, `How the host's key is checked. "strict" only
+connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+hostCAKeys.
+"tofu" (trust on first use) does the same for hosts that are listed there, but
+connects to any other host. The key it presents is the hostKey output of Exec
+and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+to any host. Defaults to "strict" if any of hostKey, knownHosts,
+knownHostsFile, or hostCAKeys are set and "off" otherwise.`)
+}
+
+// Become methods.
+const (
+	BecomeMethodAuto = "auto"
+	BecomeMethodSudo = "sudo"
+	BecomeMethodDoas = "doas"
+	BecomeMethodSu   = "su"
+	BecomeMethodNone = "none"
+)
+
+// Become configures how the agent gets the privileges of another user, usually
+// root, on the remote host.
+type Become struct {
+	Method   *string   `pulumi:"method,optional"`
+	User     *string   `pulumi:"user,optional"`
+	Password *string   `pulumi:"password,optional" provider:"secret"`
+	Flags    *[]string `pulumi:"flags,optional"`
+}
+
+func (i *Become) Annotate(a infer.Annotator) {
+	a.Describe(&i, "Instructions for how the agent gets the privileges of another user.")
+	a.Describe(&i.Method, `How to switch users. One of "auto", "sudo", "doas", "su",
+or "none". "auto" uses sudo unless the connection user already is the become
+user, and "none" runs the agent as the connection user. Defaults to "auto".`)
+	a.Describe(&i.User, "The user the agent runs as. Defaults to \"root\".")
+	a.Describe(&i.Password, `The password to give the become method when it asks
+for one. It is only ever written to the terminal of the become command.`)
+	a.Describe(&i.Flags, "Extra flags passed to the become method.")
+}
+
 type Connection struct {
 	ConnectionBase
-	// TODO: add support for below
-	// Proxy *ProxyConnection `pulumi:"proxy,optional"`
+	SSHConfig *string            `pulumi:"sshConfig,optional"`
+	AgentDir  *string            `pulumi:"agentDir,optional"`
+	Become    *Become            `pulumi:"become,optional"`
+	Proxies   *[]ProxyConnection `pulumi:"proxies,optional"`
 }
 
 func (i *Connection) Annotate(a infer.Annotator) {
 	a.Describe(&i, "Instructions for how to connect to a remote endpoint.")
-	a.Describe(&i.User, "The user that we should use for the connection.")
-	a.SetDefault(&i.User, DefaultConnectionUser)
+	a.Describe(&i.User, `The user that we should use for the connection.
+Defaults to the User set for the host in sshConfig, or else the current local
+user.`)
 	a.Describe(&i.Password, "The password we should use for the connection.")
 	a.Describe(&i.Host, "The address of the resource to connect to.")
 	a.Describe(&i.Port, "The port to connect to. Defaults to 22.")
-	a.SetDefault(&i.Port, DefaultConnectionPort)
 	a.Describe(&i.PrivateKey, `The contents of an SSH key to use for the
 connection. This takes preference over the password if provided.`)
+	annotateCertificate(a, &i.ConnectionBase)
+	a.Describe(&i.SSHConfig, `The path of an OpenSSH client config file on the
+machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+for the host are used for anything the connection doesn't set itself. Include
+and "Match host" are supported. Defaults to not reading any config file.`)
+	a.Describe(&i.AgentDir, `The directory the agent, its staging area, and the
+Ansible modules are installed in on the remote host. Relative paths are
+relative to the home directory of the user and can't leave it. Since the
+directory is removed when uninstalling the agent, absolute paths need at least
+two levels (e.g. "/opt/mid"). Defaults to ".mid".`)
+	annotateHostKeyChecking(a, &i.ConnectionBase)
+	a.Describe(&i.Become, `How the agent gets root (or another user's)
+privileges. Each setting given for a resource overrides the same setting of the
+provider.`)
+	a.Describe(&i.Proxies, `Proxy (or bastion) hosts to connect through, in order.
+The first proxy is connected to directly, each following one through the one
+before it, and the host itself through the last one. Connections to proxies are
+shared between all hosts that go through them.`)
 }
 
 func GetConnection(ctx context.Context, connection *Connection) Connection {
@@ -74,6 +183,9 @@ func GetConnection(ctx context.Context, connection *Connection) Connection {
 		if connection.PrivateKeyPassword != nil {
 			result.PrivateKeyPassword = connection.PrivateKeyPassword
 		}
+		if connection.Certificate != nil {
+			result.Certificate = connection.Certificate
+		}
 		if connection.SSHAgent != nil {
 			result.SSHAgent = connection.SSHAgent
 		}
@@ -86,6 +198,51 @@ func GetConnection(ctx context.Context, connection *Connection) Connection {
 		if connection.HostKey != nil {
 			result.HostKey = connection.HostKey
 		}
+		if connection.KnownHosts != nil {
+			result.KnownHosts = connection.KnownHosts
+		}
+		if connection.KnownHostsFile != nil {
+			result.KnownHostsFile = connection.KnownHostsFile
+		}
+		if connection.HostKeyChecking != nil {
+			result.HostKeyChecking = connection.HostKeyChecking
+		}
+		if connection.HostCAKeys != nil {
+			result.HostCAKeys = connection.HostCAKeys
+		}
+		if connection.SSHConfig != nil {
+			result.SSHConfig = connection.SSHConfig
+		}
+		if connection.AgentDir != nil {
+			result.AgentDir = connection.AgentDir
+		}
+		if connection.Become != nil {
+			result.Become = mergeBecome(result.Become, connection.Become)
+		}
+		if connection.Proxies != nil {
+			result.Proxies = connection.Proxies
+		}
 	}
 	return result
 }
+
+// mergeBecome returns base with the settings given in override applied on top.
+func mergeBecome(base *Become, override *Become) *Become {
+	result := Become{}
+	if base != nil {
+		result = *base
+	}
+	if override.Method != nil {
+		result.Method = override.Method
+	}
+	if override.User != nil {
+		result.User = override.User
+	}
+	if override.Password != nil {
+		result.Password = override.Password
+	}
+	if override.Flags != nil {
+		result.Flags = override.Flags
+	}
+	return &result
+}
@@ -1,12 +1,21 @@
 package midtypes
 
-import "github.com/sapslaj/mid/pkg/providerfw/infer"
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	p "github.com/sapslaj/mid/pkg/providerfw"
+	"github.com/sapslaj/mid/pkg/providerfw/infer"
+)
 
 type ExecCommand struct {
 	Command     []string           `pulumi:"command"`
 	Environment *map[string]string `pulumi:"environment,optional"`
 	Dir         *string            `pulumi:"dir,optional"`
 	Stdin       *string            `pulumi:"stdin,optional"`
+	Timeout     *int               `pulumi:"timeout,optional"`
 }
 
 func (i *ExecCommand) Annotate(a infer.Annotator) {
@@ -30,6 +39,21 @@ home.`,
 		&i.Stdin,
 		`Pass a string to the command's process as standard in.`,
 	)
+	a.Describe(
+		&i.Timeout,
+		`Maximum number of seconds the command is allowed to run. When exceeded, the
+command's process group is sent SIGTERM, followed by SIGKILL if it has not
+exited 10 seconds later. Defaults to no timeout.`,
+	)
+}
+
+// TimeoutDuration converts an optional number of seconds into a
+// time.Duration, where nil means no timeout.
+func TimeoutDuration(seconds *int) time.Duration {
+	if seconds == nil {
+		return 0
+	}
+	return time.Duration(*seconds) * time.Second
 }
 
 type ExecLogging string
@@ -40,3 +64,32 @@ const (
 	ExecLoggingStdoutAndStderr ExecLogging = "stdoutAndStderr"
 	ExecLoggingNone            ExecLogging = "none"
 )
+
+// StreamFunc returns an rpc.StreamFunc which forwards process output to the
+// Pulumi diagnostics for the resource in ctx as it arrives. Only the streams
+// selected by the logging setting are forwarded. A nil StreamFunc is returned
+// for ExecLoggingNone.
+func (logging ExecLogging) StreamFunc(ctx context.Context) rpc.StreamFunc {
+	var stdout, stderr bool
+	switch logging {
+	case ExecLoggingStdout:
+		stdout = true
+	case ExecLoggingStderr:
+		stderr = true
+	case ExecLoggingStdoutAndStderr:
+		stdout = true
+		stderr = true
+	default:
+		return nil
+	}
+
+	logger := p.GetLogger(ctx)
+	return func(chunk rpc.RPCStreamChunk) {
+		if stdout && len(chunk.Stdout) > 0 {
+			logger.Info(strings.TrimRight(string(chunk.Stdout), "\r\n"))
+		}
+		if stderr && len(chunk.Stderr) > 0 {
+			logger.Info(strings.TrimRight(string(chunk.Stderr), "\r\n"))
+		}
+	}
+}
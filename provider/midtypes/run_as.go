@@ -0,0 +1,52 @@
+package midtypes
+
+import (
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/pkg/providerfw/infer"
+)
+
+// RunAs is the user, groups, and environment a command or Ansible module is
+// run with on the remote host.
+type RunAs struct {
+	User     *string   `pulumi:"user,optional"`
+	Group    *string   `pulumi:"group,optional"`
+	Groups   *[]string `pulumi:"groups,optional"`
+	LoginEnv *bool     `pulumi:"loginEnv,optional"`
+}
+
+func (i *RunAs) Annotate(a infer.Annotator) {
+	a.Describe(&i, `The user and groups to run as instead of the user the agent
+runs as. Switching users requires the agent to run as root.`)
+	a.Describe(&i.User, `The name or numeric ID of the user to run as. Defaults to
+the agent's user.`)
+	a.Describe(&i.Group, `The name or numeric ID of the primary group. Defaults to
+the primary group of the user.`)
+	a.Describe(&i.Groups, `The names or numeric IDs of the supplementary groups.
+Defaults to the groups the user is a member of.`)
+	a.Describe(&i.LoginEnv, `Start with the environment a login of the user would
+get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+unless another directory is given. Defaults to false.`)
+}
+
+// ToRPC converts i to the agent's rpc.RunAs. A nil RunAs is the zero value.
+func (i *RunAs) ToRPC() rpc.RunAs {
+	if i == nil {
+		return rpc.RunAs{}
+	}
+	runAs := rpc.RunAs{}
+	if i.User != nil {
+		runAs.User = *i.User
+	}
+	if i.Group != nil {
+		runAs.Group = *i.Group
+	}
+	if i.Groups != nil {
+		runAs.Groups = *i.Groups
+	}
+	if i.LoginEnv != nil {
+		runAs.LoginEnv = *i.LoginEnv
+	}
+	return runAs
+}
@@ -0,0 +1,62 @@
+package midtypes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sapslaj/mid/agent/rpc"
+	"github.com/sapslaj/mid/pkg/ptr"
+	"github.com/sapslaj/mid/provider/midtypes"
+)
+
+func TestRunAsToRPC(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  *midtypes.RunAs
+		expect rpc.RunAs
+	}{
+		"nil": {
+			input:  nil,
+			expect: rpc.RunAs{},
+		},
+
+		"empty": {
+			input:  &midtypes.RunAs{},
+			expect: rpc.RunAs{},
+		},
+
+		"user only": {
+			input: &midtypes.RunAs{
+				User: ptr.Of("nobody"),
+			},
+			expect: rpc.RunAs{
+				User: "nobody",
+			},
+		},
+
+		"everything": {
+			input: &midtypes.RunAs{
+				User:     ptr.Of("1000"),
+				Group:    ptr.Of("users"),
+				Groups:   ptr.Of([]string{"wheel", "100"}),
+				LoginEnv: ptr.Of(true),
+			},
+			expect: rpc.RunAs{
+				User:     "1000",
+				Group:    "users",
+				Groups:   []string{"wheel", "100"},
+				LoginEnv: true,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expect, tc.input.ToRPC())
+		})
+	}
+}
@@ -71,6 +71,7 @@ func Provider() (p.Provider, error) {
 		).
 		WithFunctions(
 			infer.Function(&agent.AgentPing{}),
+			infer.Function(&agent.AgentUninstall{}),
 			infer.Function(&agent.AnsibleExecute{}),
 			infer.Function(&agent.Exec{}),
 			infer.Function(&agent.FileStat{}),
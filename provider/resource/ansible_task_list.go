@@ -26,6 +26,8 @@ type AnsibleTaskListArgsTask struct {
 	Environment  *map[string]string `pulumi:"environment,optional"`
 	Check        *bool              `pulumi:"check,optional"`
 	IgnoreErrors *bool              `pulumi:"ignoreErrors,optional"`
+	Timeout      *int               `pulumi:"timeout,optional"`
+	RunAs        *midtypes.RunAs    `pulumi:"runAs,optional"`
 }
 
 type AnsibleTaskListArgsTasks struct {
@@ -36,6 +38,7 @@ type AnsibleTaskListArgsTasks struct {
 
 type AnsibleTaskListArgs struct {
 	Tasks      AnsibleTaskListArgsTasks `pulumi:"tasks"`
+	Logging    *midtypes.ExecLogging    `pulumi:"logging,optional"`
 	Connection *midtypes.Connection     `pulumi:"connection,optional"`
 	Config     *midtypes.ResourceConfig `pulumi:"config,optional"`
 	Triggers   *midtypes.TriggersInput  `pulumi:"triggers,optional"`
@@ -92,6 +95,7 @@ func (r AnsibleTaskList) Diff(
 	diff = pdiff.MergeDiffResponses(
 		diff,
 		pdiff.DiffAllAttributesExcept(req.Inputs, req.State, []string{
+			"logging",
 			"connection",
 			"config",
 			"triggers",
@@ -149,6 +153,13 @@ func (r AnsibleTaskList) run(
 
 	state.Results.Tasks = []AnsibleTaskListStateTaskResult{}
 
+	// module stdout is the JSON result, so only stream output when asked to.
+	logging := midtypes.ExecLoggingNone
+	if inputs.Logging != nil {
+		logging = *inputs.Logging
+	}
+	stream := logging.StreamFunc(ctx)
+
 	for _, task := range taskList {
 		ignoreErrors := false
 		if task.IgnoreErrors != nil {
@@ -158,8 +169,10 @@ func (r AnsibleTaskList) run(
 		call := rpc.RPCCall[rpc.AnsibleExecuteArgs]{
 			RPCFunction: rpc.RPCAnsibleExecute,
 			Args: rpc.AnsibleExecuteArgs{
-				Name: task.Module,
-				Args: task.Args,
+				Name:    task.Module,
+				Args:    task.Args,
+				Timeout: midtypes.TimeoutDuration(task.Timeout),
+				RunAs:   task.RunAs.ToRPC(),
 			},
 		}
 		if task.Environment != nil {
@@ -169,10 +182,10 @@ func (r AnsibleTaskList) run(
 			call.Args.Check = *task.Check
 		}
 
-		callResult, err := executor.CallAgent[
+		callResult, err := executor.CallAgentStream[
 			rpc.AnsibleExecuteArgs,
 			rpc.AnsibleExecuteResult,
-		](ctx, connection, config, call)
+		](ctx, connection, config, call, stream)
 		if err != nil && !ignoreErrors {
 			span.SetStatus(codes.Error, err.Error())
 			return state, err
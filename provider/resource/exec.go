@@ -29,6 +29,7 @@ type ExecArgs struct {
 	DeleteBeforeReplace *bool                    `pulumi:"deleteBeforeReplace,optional"`
 	Dir                 *string                  `pulumi:"dir,optional"`
 	Environment         *map[string]string       `pulumi:"environment,optional"`
+	RunAs               *midtypes.RunAs          `pulumi:"runAs,optional"`
 	Logging             *midtypes.ExecLogging    `pulumi:"logging,optional"`
 	Connection          *midtypes.Connection     `pulumi:"connection,optional"`
 	Config              *midtypes.ResourceConfig `pulumi:"config,optional"`
@@ -40,6 +41,9 @@ type ExecState struct {
 	Stdout   string                  `pulumi:"stdout"`
 	Stderr   string                  `pulumi:"stderr"`
 	Triggers midtypes.TriggersOutput `pulumi:"triggers"`
+	// HostKey is the key the host presented, so that it can be pinned as the
+	// connection's hostKey after trusting it on first use.
+	HostKey *string `pulumi:"hostKey,optional"`
 }
 
 func (r Exec) argsToRPCCall(input ExecArgs, lifecycle string) (rpc.RPCCall[rpc.ExecArgs], error) {
@@ -92,6 +96,8 @@ func (r Exec) argsToRPCCall(input ExecArgs, lifecycle string) (rpc.RPCCall[rpc.E
 			Environment:        environment,
 			Stdin:              stdin,
 			ExpandArgumentVars: input.ExpandArgumentVars != nil && *input.ExpandArgumentVars,
+			Timeout:            midtypes.TimeoutDuration(execCommand.Timeout),
+			RunAs:              input.RunAs.ToRPC(),
 		},
 	}, nil
 }
@@ -102,15 +108,19 @@ func (r Exec) updateState(inputs ExecArgs, state ExecState, changed bool) ExecSt
 	return state
 }
 
+func (r Exec) logging(inputs ExecArgs) midtypes.ExecLogging {
+	if inputs.Logging != nil {
+		return *inputs.Logging
+	}
+	return midtypes.ExecLoggingStdoutAndStderr
+}
+
 func (r Exec) updateStateFromRPCResult(
 	inputs ExecArgs,
 	state ExecState,
 	result rpc.RPCResult[rpc.ExecResult],
 ) ExecState {
-	logging := midtypes.ExecLoggingStdoutAndStderr
-	if inputs.Logging != nil {
-		logging = *inputs.Logging
-	}
+	logging := r.logging(inputs)
 	switch logging {
 	case midtypes.ExecLoggingNone:
 		state.Stderr = ""
@@ -157,7 +167,13 @@ func (r Exec) runRPCExec(
 		return state, err
 	}
 
-	result, err := executor.CallAgent[rpc.ExecArgs, rpc.ExecResult](ctx, connection, config, call)
+	result, err := executor.CallAgentStream[rpc.ExecArgs, rpc.ExecResult](
+		ctx,
+		connection,
+		config,
+		call,
+		r.logging(inputs).StreamFunc(ctx),
+	)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		return state, err
@@ -169,6 +185,15 @@ func (r Exec) runRPCExec(
 			call.Args.Command,
 			result.Error,
 		)
+		// timeouts and cancellations still return whatever output was produced
+		if len(result.Result.Stderr) > 0 || len(result.Result.Stdout) > 0 {
+			err = fmt.Errorf(
+				"%w: stderr=%s stdout=%s",
+				err,
+				result.Result.Stderr,
+				result.Result.Stdout,
+			)
+		}
 		span.SetStatus(codes.Error, err.Error())
 		return state, err
 	}
@@ -186,6 +211,9 @@ func (r Exec) runRPCExec(
 	}
 
 	state = r.updateStateFromRPCResult(inputs, state, result)
+	if hostKey := executor.ObservedHostKey(connection); hostKey != "" {
+		state.HostKey = &hostKey
+	}
 	span.SetStatus(codes.Ok, "")
 	return state, nil
 }
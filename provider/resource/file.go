@@ -6,6 +6,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"reflect"
 	"slices"
@@ -915,24 +917,15 @@ func (r File) copyLocalSourceArchive(
 	} else if dryRun {
 		state = r.updateStateDrifted(inputs, state, []string{"source"})
 	} else {
-		// FIXME: the TarGZIPArchive format is broken because the gzip.Writer is
-		// never closed. This is an upstream Pulumi SDK bug.
-		bbuf := bytes.Buffer{}
-		zbuf := gzip.NewWriter(&bbuf)
-
-		err := archive.Archive(parchive.TarArchive, zbuf)
-		if err != nil {
-			span.SetStatus(codes.Error, err.Error())
-			return state, err
-		}
-
-		err = zbuf.Close()
+		source, repacked, err := openLocalArchive(archive)
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			return state, err
 		}
+		defer source.Close()
+		span.SetAttributes(attribute.Bool("archive.repacked", repacked))
 
-		stagedPath, err := executor.StageFile(ctx, connection, config, &bbuf)
+		stagedPath, err := executor.StageFile(ctx, connection, config, source)
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			return state, err
@@ -957,6 +950,7 @@ func (r File) copyLocalSourceArchive(
 			span.SetStatus(codes.Error, err.Error())
 			return state, err
 		}
+		span.SetAttributes(attribute.String("archive.format", string(untarResult.Result.Format)))
 
 		state = r.updateStateDrifted(inputs, state, []string{"source"})
 	}
@@ -965,6 +959,39 @@ func (r File) copyLocalSourceArchive(
 	return state, nil
 }
 
+// openLocalArchive opens an archive for uploading. Archive files (tarballs,
+// compressed or not, and zip files) are uploaded as-is since the agent detects
+// their format, everything else is packed into a gzipped tarball first.
+func openLocalArchive(archive *resource.Archive) (io.ReadCloser, bool, error) {
+	if archive.IsPath() {
+		info, err := os.Stat(archive.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		if !info.IsDir() {
+			f, err := os.Open(archive.Path)
+			return f, false, err
+		}
+	}
+
+	// FIXME: the TarGZIPArchive format is broken because the gzip.Writer is
+	// never closed. This is an upstream Pulumi SDK bug.
+	bbuf := bytes.Buffer{}
+	zbuf := gzip.NewWriter(&bbuf)
+
+	err := archive.Archive(parchive.TarArchive, zbuf)
+	if err != nil {
+		return nil, true, err
+	}
+
+	err = zbuf.Close()
+	if err != nil {
+		return nil, true, err
+	}
+
+	return io.NopCloser(&bbuf), true, nil
+}
+
 func (r File) copyLocalSourceAsset(
 	ctx context.Context,
 	inputs FileArgs,
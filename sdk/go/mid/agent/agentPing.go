@@ -15,7 +15,7 @@ import (
 func AgentPing(ctx *pulumi.Context, args *AgentPingArgs, opts ...pulumi.InvokeOption) (*AgentPingResult, error) {
 	opts = internal.PkgInvokeDefaultOpts(opts)
 	var rv AgentPingResult
-	err := ctx.Invoke("mid:agent:agentPing", args.Defaults(), &rv, opts...)
+	err := ctx.Invoke("mid:agent:agentPing", args, &rv, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,20 +28,15 @@ type AgentPingArgs struct {
 	Ping       *string             `pulumi:"ping"`
 }
 
-// Defaults sets the appropriate defaults for AgentPingArgs
-func (val *AgentPingArgs) Defaults() *AgentPingArgs {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
-}
-
 type AgentPingResult struct {
-	Ping string `pulumi:"ping"`
-	Pong string `pulumi:"pong"`
+	AgentVersion          string   `pulumi:"agentVersion"`
+	AnsibleInstallCached  *bool    `pulumi:"ansibleInstallCached"`
+	AnsibleInstallSeconds *float64 `pulumi:"ansibleInstallSeconds"`
+	HostKey               *string  `pulumi:"hostKey"`
+	Ping                  string   `pulumi:"ping"`
+	Pong                  string   `pulumi:"pong"`
+	ProtocolVersion       int      `pulumi:"protocolVersion"`
+	RpcFunctions          []string `pulumi:"rpcFunctions"`
 }
 
 func AgentPingOutput(ctx *pulumi.Context, args AgentPingOutputArgs, opts ...pulumi.InvokeOption) AgentPingResultOutput {
@@ -49,7 +44,7 @@ func AgentPingOutput(ctx *pulumi.Context, args AgentPingOutputArgs, opts ...pulu
 		ApplyT(func(v interface{}) (AgentPingResultOutput, error) {
 			args := v.(AgentPingArgs)
 			options := pulumi.InvokeOutputOptions{InvokeOptions: internal.PkgInvokeDefaultOpts(opts)}
-			return ctx.InvokeOutput("mid:agent:agentPing", args.Defaults(), AgentPingResultOutput{}, options).(AgentPingResultOutput), nil
+			return ctx.InvokeOutput("mid:agent:agentPing", args, AgentPingResultOutput{}, options).(AgentPingResultOutput), nil
 		}).(AgentPingResultOutput)
 }
 
@@ -77,6 +72,22 @@ func (o AgentPingResultOutput) ToAgentPingResultOutputWithContext(ctx context.Co
 	return o
 }
 
+func (o AgentPingResultOutput) AgentVersion() pulumi.StringOutput {
+	return o.ApplyT(func(v AgentPingResult) string { return v.AgentVersion }).(pulumi.StringOutput)
+}
+
+func (o AgentPingResultOutput) AnsibleInstallCached() pulumi.BoolPtrOutput {
+	return o.ApplyT(func(v AgentPingResult) *bool { return v.AnsibleInstallCached }).(pulumi.BoolPtrOutput)
+}
+
+func (o AgentPingResultOutput) AnsibleInstallSeconds() pulumi.Float64PtrOutput {
+	return o.ApplyT(func(v AgentPingResult) *float64 { return v.AnsibleInstallSeconds }).(pulumi.Float64PtrOutput)
+}
+
+func (o AgentPingResultOutput) HostKey() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v AgentPingResult) *string { return v.HostKey }).(pulumi.StringPtrOutput)
+}
+
 func (o AgentPingResultOutput) Ping() pulumi.StringOutput {
 	return o.ApplyT(func(v AgentPingResult) string { return v.Ping }).(pulumi.StringOutput)
 }
@@ -85,6 +96,14 @@ func (o AgentPingResultOutput) Pong() pulumi.StringOutput {
 	return o.ApplyT(func(v AgentPingResult) string { return v.Pong }).(pulumi.StringOutput)
 }
 
+func (o AgentPingResultOutput) ProtocolVersion() pulumi.IntOutput {
+	return o.ApplyT(func(v AgentPingResult) int { return v.ProtocolVersion }).(pulumi.IntOutput)
+}
+
+func (o AgentPingResultOutput) RpcFunctions() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v AgentPingResult) []string { return v.RpcFunctions }).(pulumi.StringArrayOutput)
+}
+
 func init() {
 	pulumi.RegisterOutputType(AgentPingResultOutput{})
 }
@@ -0,0 +1,77 @@
+// Code generated by pulumi-language-go DO NOT EDIT.
+// *** WARNING: Do not edit by hand unless you're certain you know what you are doing! ***
+
+package agent
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/sapslaj/mid/sdk/go/mid"
+	"github.com/sapslaj/mid/sdk/go/mid/internal"
+)
+
+func AgentUninstall(ctx *pulumi.Context, args *AgentUninstallArgs, opts ...pulumi.InvokeOption) (*AgentUninstallResult, error) {
+	opts = internal.PkgInvokeDefaultOpts(opts)
+	var rv AgentUninstallResult
+	err := ctx.Invoke("mid:agent:agentUninstall", args, &rv, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+type AgentUninstallArgs struct {
+	Config     *mid.ResourceConfig `pulumi:"config"`
+	Connection *mid.Connection     `pulumi:"connection"`
+}
+
+type AgentUninstallResult struct {
+	AgentDir string `pulumi:"agentDir"`
+	Removed  bool   `pulumi:"removed"`
+}
+
+func AgentUninstallOutput(ctx *pulumi.Context, args AgentUninstallOutputArgs, opts ...pulumi.InvokeOption) AgentUninstallResultOutput {
+	return pulumi.ToOutputWithContext(ctx.Context(), args).
+		ApplyT(func(v interface{}) (AgentUninstallResultOutput, error) {
+			args := v.(AgentUninstallArgs)
+			options := pulumi.InvokeOutputOptions{InvokeOptions: internal.PkgInvokeDefaultOpts(opts)}
+			return ctx.InvokeOutput("mid:agent:agentUninstall", args, AgentUninstallResultOutput{}, options).(AgentUninstallResultOutput), nil
+		}).(AgentUninstallResultOutput)
+}
+
+type AgentUninstallOutputArgs struct {
+	Config     mid.ResourceConfigPtrInput `pulumi:"config"`
+	Connection mid.ConnectionPtrInput     `pulumi:"connection"`
+}
+
+func (AgentUninstallOutputArgs) ElementType() reflect.Type {
+	return reflect.TypeOf((*AgentUninstallArgs)(nil)).Elem()
+}
+
+type AgentUninstallResultOutput struct{ *pulumi.OutputState }
+
+func (AgentUninstallResultOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((*AgentUninstallResult)(nil)).Elem()
+}
+
+func (o AgentUninstallResultOutput) ToAgentUninstallResultOutput() AgentUninstallResultOutput {
+	return o
+}
+
+func (o AgentUninstallResultOutput) ToAgentUninstallResultOutputWithContext(ctx context.Context) AgentUninstallResultOutput {
+	return o
+}
+
+func (o AgentUninstallResultOutput) AgentDir() pulumi.StringOutput {
+	return o.ApplyT(func(v AgentUninstallResult) string { return v.AgentDir }).(pulumi.StringOutput)
+}
+
+func (o AgentUninstallResultOutput) Removed() pulumi.BoolOutput {
+	return o.ApplyT(func(v AgentUninstallResult) bool { return v.Removed }).(pulumi.BoolOutput)
+}
+
+func init() {
+	pulumi.RegisterOutputType(AgentUninstallResultOutput{})
+}
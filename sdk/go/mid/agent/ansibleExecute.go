@@ -15,11 +15,11 @@ import (
 func AnsibleExecute(ctx *pulumi.Context, args *AnsibleExecuteArgs, opts ...pulumi.InvokeOption) (*AnsibleExecuteResult, error) {
 	opts = internal.PkgInvokeDefaultOpts(opts)
 	var rv AnsibleExecuteResult
-	err := ctx.Invoke("mid:agent:ansibleExecute", args.Defaults(), &rv, opts...)
+	err := ctx.Invoke("mid:agent:ansibleExecute", args, &rv, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return rv.Defaults(), nil
+	return &rv, nil
 }
 
 type AnsibleExecuteArgs struct {
@@ -30,17 +30,8 @@ type AnsibleExecuteArgs struct {
 	DebugKeepTempFiles *bool                  `pulumi:"debugKeepTempFiles"`
 	Environment        map[string]string      `pulumi:"environment"`
 	Name               string                 `pulumi:"name"`
-}
-
-// Defaults sets the appropriate defaults for AnsibleExecuteArgs
-func (val *AnsibleExecuteArgs) Defaults() *AnsibleExecuteArgs {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
+	RunAs              *mid.RunAs             `pulumi:"runAs"`
+	Timeout            *int                   `pulumi:"timeout"`
 }
 
 type AnsibleExecuteResult struct {
@@ -53,27 +44,20 @@ type AnsibleExecuteResult struct {
 	Environment        map[string]string      `pulumi:"environment"`
 	ExitCode           int                    `pulumi:"exitCode"`
 	Name               string                 `pulumi:"name"`
+	PythonInterpreter  *string                `pulumi:"pythonInterpreter"`
 	Result             map[string]interface{} `pulumi:"result"`
+	RunAs              *mid.RunAs             `pulumi:"runAs"`
 	Stderr             string                 `pulumi:"stderr"`
 	Stdout             string                 `pulumi:"stdout"`
+	Timeout            *int                   `pulumi:"timeout"`
 }
 
-// Defaults sets the appropriate defaults for AnsibleExecuteResult
-func (val *AnsibleExecuteResult) Defaults() *AnsibleExecuteResult {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
-}
 func AnsibleExecuteOutput(ctx *pulumi.Context, args AnsibleExecuteOutputArgs, opts ...pulumi.InvokeOption) AnsibleExecuteResultOutput {
 	return pulumi.ToOutputWithContext(ctx.Context(), args).
 		ApplyT(func(v interface{}) (AnsibleExecuteResultOutput, error) {
 			args := v.(AnsibleExecuteArgs)
 			options := pulumi.InvokeOutputOptions{InvokeOptions: internal.PkgInvokeDefaultOpts(opts)}
-			return ctx.InvokeOutput("mid:agent:ansibleExecute", args.Defaults(), AnsibleExecuteResultOutput{}, options).(AnsibleExecuteResultOutput), nil
+			return ctx.InvokeOutput("mid:agent:ansibleExecute", args, AnsibleExecuteResultOutput{}, options).(AnsibleExecuteResultOutput), nil
 		}).(AnsibleExecuteResultOutput)
 }
 
@@ -85,6 +69,8 @@ type AnsibleExecuteOutputArgs struct {
 	DebugKeepTempFiles pulumi.BoolPtrInput        `pulumi:"debugKeepTempFiles"`
 	Environment        pulumi.StringMapInput      `pulumi:"environment"`
 	Name               pulumi.StringInput         `pulumi:"name"`
+	RunAs              mid.RunAsPtrInput          `pulumi:"runAs"`
+	Timeout            pulumi.IntPtrInput         `pulumi:"timeout"`
 }
 
 func (AnsibleExecuteOutputArgs) ElementType() reflect.Type {
@@ -141,10 +127,18 @@ func (o AnsibleExecuteResultOutput) Name() pulumi.StringOutput {
 	return o.ApplyT(func(v AnsibleExecuteResult) string { return v.Name }).(pulumi.StringOutput)
 }
 
+func (o AnsibleExecuteResultOutput) PythonInterpreter() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v AnsibleExecuteResult) *string { return v.PythonInterpreter }).(pulumi.StringPtrOutput)
+}
+
 func (o AnsibleExecuteResultOutput) Result() pulumi.MapOutput {
 	return o.ApplyT(func(v AnsibleExecuteResult) map[string]interface{} { return v.Result }).(pulumi.MapOutput)
 }
 
+func (o AnsibleExecuteResultOutput) RunAs() mid.RunAsPtrOutput {
+	return o.ApplyT(func(v AnsibleExecuteResult) *mid.RunAs { return v.RunAs }).(mid.RunAsPtrOutput)
+}
+
 func (o AnsibleExecuteResultOutput) Stderr() pulumi.StringOutput {
 	return o.ApplyT(func(v AnsibleExecuteResult) string { return v.Stderr }).(pulumi.StringOutput)
 }
@@ -153,6 +147,10 @@ func (o AnsibleExecuteResultOutput) Stdout() pulumi.StringOutput {
 	return o.ApplyT(func(v AnsibleExecuteResult) string { return v.Stdout }).(pulumi.StringOutput)
 }
 
+func (o AnsibleExecuteResultOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v AnsibleExecuteResult) *int { return v.Timeout }).(pulumi.IntPtrOutput)
+}
+
 func init() {
 	pulumi.RegisterOutputType(AnsibleExecuteResultOutput{})
 }
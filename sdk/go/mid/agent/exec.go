@@ -15,11 +15,11 @@ import (
 func Exec(ctx *pulumi.Context, args *ExecArgs, opts ...pulumi.InvokeOption) (*ExecResult, error) {
 	opts = internal.PkgInvokeDefaultOpts(opts)
 	var rv ExecResult
-	err := ctx.Invoke("mid:agent:exec", args.Defaults(), &rv, opts...)
+	err := ctx.Invoke("mid:agent:exec", args, &rv, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return rv.Defaults(), nil
+	return &rv, nil
 }
 
 type ExecArgs struct {
@@ -29,18 +29,9 @@ type ExecArgs struct {
 	Dir                *string             `pulumi:"dir"`
 	Environment        map[string]string   `pulumi:"environment"`
 	ExpandArgumentVars *bool               `pulumi:"expandArgumentVars"`
+	RunAs              *mid.RunAs          `pulumi:"runAs"`
 	Stdin              *string             `pulumi:"stdin"`
-}
-
-// Defaults sets the appropriate defaults for ExecArgs
-func (val *ExecArgs) Defaults() *ExecArgs {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
+	Timeout            *int                `pulumi:"timeout"`
 }
 
 type ExecResult struct {
@@ -52,27 +43,19 @@ type ExecResult struct {
 	ExitCode           int                 `pulumi:"exitCode"`
 	ExpandArgumentVars *bool               `pulumi:"expandArgumentVars"`
 	Pid                int                 `pulumi:"pid"`
+	RunAs              *mid.RunAs          `pulumi:"runAs"`
 	Stderr             string              `pulumi:"stderr"`
 	Stdin              *string             `pulumi:"stdin"`
 	Stdout             string              `pulumi:"stdout"`
+	Timeout            *int                `pulumi:"timeout"`
 }
 
-// Defaults sets the appropriate defaults for ExecResult
-func (val *ExecResult) Defaults() *ExecResult {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
-}
 func ExecOutput(ctx *pulumi.Context, args ExecOutputArgs, opts ...pulumi.InvokeOption) ExecResultOutput {
 	return pulumi.ToOutputWithContext(ctx.Context(), args).
 		ApplyT(func(v interface{}) (ExecResultOutput, error) {
 			args := v.(ExecArgs)
 			options := pulumi.InvokeOutputOptions{InvokeOptions: internal.PkgInvokeDefaultOpts(opts)}
-			return ctx.InvokeOutput("mid:agent:exec", args.Defaults(), ExecResultOutput{}, options).(ExecResultOutput), nil
+			return ctx.InvokeOutput("mid:agent:exec", args, ExecResultOutput{}, options).(ExecResultOutput), nil
 		}).(ExecResultOutput)
 }
 
@@ -83,7 +66,9 @@ type ExecOutputArgs struct {
 	Dir                pulumi.StringPtrInput      `pulumi:"dir"`
 	Environment        pulumi.StringMapInput      `pulumi:"environment"`
 	ExpandArgumentVars pulumi.BoolPtrInput        `pulumi:"expandArgumentVars"`
+	RunAs              mid.RunAsPtrInput          `pulumi:"runAs"`
 	Stdin              pulumi.StringPtrInput      `pulumi:"stdin"`
+	Timeout            pulumi.IntPtrInput         `pulumi:"timeout"`
 }
 
 func (ExecOutputArgs) ElementType() reflect.Type {
@@ -136,6 +121,10 @@ func (o ExecResultOutput) Pid() pulumi.IntOutput {
 	return o.ApplyT(func(v ExecResult) int { return v.Pid }).(pulumi.IntOutput)
 }
 
+func (o ExecResultOutput) RunAs() mid.RunAsPtrOutput {
+	return o.ApplyT(func(v ExecResult) *mid.RunAs { return v.RunAs }).(mid.RunAsPtrOutput)
+}
+
 func (o ExecResultOutput) Stderr() pulumi.StringOutput {
 	return o.ApplyT(func(v ExecResult) string { return v.Stderr }).(pulumi.StringOutput)
 }
@@ -148,6 +137,10 @@ func (o ExecResultOutput) Stdout() pulumi.StringOutput {
 	return o.ApplyT(func(v ExecResult) string { return v.Stdout }).(pulumi.StringOutput)
 }
 
+func (o ExecResultOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ExecResult) *int { return v.Timeout }).(pulumi.IntPtrOutput)
+}
+
 func init() {
 	pulumi.RegisterOutputType(ExecResultOutput{})
 }
@@ -15,11 +15,11 @@ import (
 func FileStat(ctx *pulumi.Context, args *FileStatArgs, opts ...pulumi.InvokeOption) (*FileStatResult, error) {
 	opts = internal.PkgInvokeDefaultOpts(opts)
 	var rv FileStatResult
-	err := ctx.Invoke("mid:agent:fileStat", args.Defaults(), &rv, opts...)
+	err := ctx.Invoke("mid:agent:fileStat", args, &rv, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return rv.Defaults(), nil
+	return &rv, nil
 }
 
 type FileStatArgs struct {
@@ -30,17 +30,6 @@ type FileStatArgs struct {
 	Path              string              `pulumi:"path"`
 }
 
-// Defaults sets the appropriate defaults for FileStatArgs
-func (val *FileStatArgs) Defaults() *FileStatArgs {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
-}
-
 type FileStatResult struct {
 	AccessTime        *string               `pulumi:"accessTime"`
 	BaseName          *string               `pulumi:"baseName"`
@@ -64,22 +53,12 @@ type FileStatResult struct {
 	UserName          *string               `pulumi:"userName"`
 }
 
-// Defaults sets the appropriate defaults for FileStatResult
-func (val *FileStatResult) Defaults() *FileStatResult {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	tmp.Connection = tmp.Connection.Defaults()
-
-	return &tmp
-}
 func FileStatOutput(ctx *pulumi.Context, args FileStatOutputArgs, opts ...pulumi.InvokeOption) FileStatResultOutput {
 	return pulumi.ToOutputWithContext(ctx.Context(), args).
 		ApplyT(func(v interface{}) (FileStatResultOutput, error) {
 			args := v.(FileStatArgs)
 			options := pulumi.InvokeOutputOptions{InvokeOptions: internal.PkgInvokeDefaultOpts(opts)}
-			return ctx.InvokeOutput("mid:agent:fileStat", args.Defaults(), FileStatResultOutput{}, options).(FileStatResultOutput), nil
+			return ctx.InvokeOutput("mid:agent:fileStat", args, FileStatResultOutput{}, options).(FileStatResultOutput), nil
 		}).(FileStatResultOutput)
 }
 
@@ -11,6 +11,9 @@ import (
 
 var _ = internal.GetEnvOrDefault
 
+func GetAgentGCDays(ctx *pulumi.Context) int {
+	return config.GetInt(ctx, "mid:agentGCDays")
+}
 func GetCheck(ctx *pulumi.Context) bool {
 	return config.GetBool(ctx, "mid:check")
 }
@@ -20,6 +23,21 @@ func GetConnection(ctx *pulumi.Context) string {
 func GetDeleteUnreachable(ctx *pulumi.Context) bool {
 	return config.GetBool(ctx, "mid:deleteUnreachable")
 }
+func GetHeartbeatInterval(ctx *pulumi.Context) int {
+	return config.GetInt(ctx, "mid:heartbeatInterval")
+}
+func GetHeartbeatTimeout(ctx *pulumi.Context) int {
+	return config.GetInt(ctx, "mid:heartbeatTimeout")
+}
 func GetParallel(ctx *pulumi.Context) int {
 	return config.GetInt(ctx, "mid:parallel")
 }
+
+// The Python interpreter used to run Ansible
+// modules on the remote host, either a path or a command name to look up in PATH.
+// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+// installed. If not set, the agent picks the first suitable interpreter it finds.
+func GetPythonInterpreter(ctx *pulumi.Context) string {
+	return config.Get(ctx, "mid:pythonInterpreter")
+}
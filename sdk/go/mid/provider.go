@@ -13,6 +13,13 @@ import (
 
 type Provider struct {
 	pulumi.ProviderResourceState
+
+	// The Python interpreter used to run Ansible
+	// modules on the remote host, either a path or a command name to look up in PATH.
+	// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+	// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+	// installed. If not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter pulumi.StringPtrOutput `pulumi:"pythonInterpreter"`
 }
 
 // NewProvider registers a new resource with the given unique name, arguments, and options.
@@ -22,9 +29,6 @@ func NewProvider(ctx *pulumi.Context,
 		args = &ProviderArgs{}
 	}
 
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *Connection) *Connection { return v.Defaults() }).(ConnectionPtrOutput)
-	}
 	if args.Connection != nil {
 		args.Connection = pulumi.ToSecret(args.Connection).(ConnectionPtrInput)
 	}
@@ -38,18 +42,36 @@ func NewProvider(ctx *pulumi.Context,
 }
 
 type providerArgs struct {
+	AgentGCDays       *int        `pulumi:"agentGCDays"`
 	Check             *bool       `pulumi:"check"`
 	Connection        *Connection `pulumi:"connection"`
 	DeleteUnreachable *bool       `pulumi:"deleteUnreachable"`
+	HeartbeatInterval *int        `pulumi:"heartbeatInterval"`
+	HeartbeatTimeout  *int        `pulumi:"heartbeatTimeout"`
 	Parallel          *int        `pulumi:"parallel"`
+	// The Python interpreter used to run Ansible
+	// modules on the remote host, either a path or a command name to look up in PATH.
+	// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+	// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+	// installed. If not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter *string `pulumi:"pythonInterpreter"`
 }
 
 // The set of arguments for constructing a Provider resource.
 type ProviderArgs struct {
+	AgentGCDays       pulumi.IntPtrInput
 	Check             pulumi.BoolPtrInput
 	Connection        ConnectionPtrInput
 	DeleteUnreachable pulumi.BoolPtrInput
+	HeartbeatInterval pulumi.IntPtrInput
+	HeartbeatTimeout  pulumi.IntPtrInput
 	Parallel          pulumi.IntPtrInput
+	// The Python interpreter used to run Ansible
+	// modules on the remote host, either a path or a command name to look up in PATH.
+	// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+	// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+	// installed. If not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter pulumi.StringPtrInput
 }
 
 func (ProviderArgs) ElementType() reflect.Type {
@@ -89,6 +111,15 @@ func (o ProviderOutput) ToProviderOutputWithContext(ctx context.Context) Provide
 	return o
 }
 
+// The Python interpreter used to run Ansible
+// modules on the remote host, either a path or a command name to look up in PATH.
+// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+// installed. If not set, the agent picks the first suitable interpreter it finds.
+func (o ProviderOutput) PythonInterpreter() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Provider) pulumi.StringPtrOutput { return v.PythonInterpreter }).(pulumi.StringPtrOutput)
+}
+
 func init() {
 	pulumi.RegisterInputType(reflect.TypeOf((*ProviderInput)(nil)).Elem(), &Provider{})
 	pulumi.RegisterOutputType(ProviderOutput{})
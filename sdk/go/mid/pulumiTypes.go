@@ -13,11 +13,260 @@ import (
 
 var _ = internal.GetEnvOrDefault
 
+// Instructions for how the agent gets the privileges of another user.
+type Become struct {
+	// Extra flags passed to the become method.
+	Flags []string `pulumi:"flags"`
+	// How to switch users. One of "auto", "sudo", "doas", "su",
+	// or "none". "auto" uses sudo unless the connection user already is the become
+	// user, and "none" runs the agent as the connection user. Defaults to "auto".
+	Method *string `pulumi:"method"`
+	// The password to give the become method when it asks
+	// for one. It is only ever written to the terminal of the become command.
+	Password *string `pulumi:"password"`
+	// The user the agent runs as. Defaults to "root".
+	User *string `pulumi:"user"`
+}
+
+// BecomeInput is an input type that accepts BecomeArgs and BecomeOutput values.
+// You can construct a concrete instance of `BecomeInput` via:
+//
+//	BecomeArgs{...}
+type BecomeInput interface {
+	pulumi.Input
+
+	ToBecomeOutput() BecomeOutput
+	ToBecomeOutputWithContext(context.Context) BecomeOutput
+}
+
+// Instructions for how the agent gets the privileges of another user.
+type BecomeArgs struct {
+	// Extra flags passed to the become method.
+	Flags pulumi.StringArrayInput `pulumi:"flags"`
+	// How to switch users. One of "auto", "sudo", "doas", "su",
+	// or "none". "auto" uses sudo unless the connection user already is the become
+	// user, and "none" runs the agent as the connection user. Defaults to "auto".
+	Method pulumi.StringPtrInput `pulumi:"method"`
+	// The password to give the become method when it asks
+	// for one. It is only ever written to the terminal of the become command.
+	Password pulumi.StringPtrInput `pulumi:"password"`
+	// The user the agent runs as. Defaults to "root".
+	User pulumi.StringPtrInput `pulumi:"user"`
+}
+
+func (BecomeArgs) ElementType() reflect.Type {
+	return reflect.TypeOf((*Become)(nil)).Elem()
+}
+
+func (i BecomeArgs) ToBecomeOutput() BecomeOutput {
+	return i.ToBecomeOutputWithContext(context.Background())
+}
+
+func (i BecomeArgs) ToBecomeOutputWithContext(ctx context.Context) BecomeOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(BecomeOutput)
+}
+
+func (i BecomeArgs) ToBecomePtrOutput() BecomePtrOutput {
+	return i.ToBecomePtrOutputWithContext(context.Background())
+}
+
+func (i BecomeArgs) ToBecomePtrOutputWithContext(ctx context.Context) BecomePtrOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(BecomeOutput).ToBecomePtrOutputWithContext(ctx)
+}
+
+// BecomePtrInput is an input type that accepts BecomeArgs, BecomePtr and BecomePtrOutput values.
+// You can construct a concrete instance of `BecomePtrInput` via:
+//
+//	        BecomeArgs{...}
+//
+//	or:
+//
+//	        nil
+type BecomePtrInput interface {
+	pulumi.Input
+
+	ToBecomePtrOutput() BecomePtrOutput
+	ToBecomePtrOutputWithContext(context.Context) BecomePtrOutput
+}
+
+type becomePtrType BecomeArgs
+
+func BecomePtr(v *BecomeArgs) BecomePtrInput {
+	return (*becomePtrType)(v)
+}
+
+func (*becomePtrType) ElementType() reflect.Type {
+	return reflect.TypeOf((**Become)(nil)).Elem()
+}
+
+func (i *becomePtrType) ToBecomePtrOutput() BecomePtrOutput {
+	return i.ToBecomePtrOutputWithContext(context.Background())
+}
+
+func (i *becomePtrType) ToBecomePtrOutputWithContext(ctx context.Context) BecomePtrOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(BecomePtrOutput)
+}
+
+// Instructions for how the agent gets the privileges of another user.
+type BecomeOutput struct{ *pulumi.OutputState }
+
+func (BecomeOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((*Become)(nil)).Elem()
+}
+
+func (o BecomeOutput) ToBecomeOutput() BecomeOutput {
+	return o
+}
+
+func (o BecomeOutput) ToBecomeOutputWithContext(ctx context.Context) BecomeOutput {
+	return o
+}
+
+func (o BecomeOutput) ToBecomePtrOutput() BecomePtrOutput {
+	return o.ToBecomePtrOutputWithContext(context.Background())
+}
+
+func (o BecomeOutput) ToBecomePtrOutputWithContext(ctx context.Context) BecomePtrOutput {
+	return o.ApplyTWithContext(ctx, func(_ context.Context, v Become) *Become {
+		return &v
+	}).(BecomePtrOutput)
+}
+
+// Extra flags passed to the become method.
+func (o BecomeOutput) Flags() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v Become) []string { return v.Flags }).(pulumi.StringArrayOutput)
+}
+
+// How to switch users. One of "auto", "sudo", "doas", "su",
+// or "none". "auto" uses sudo unless the connection user already is the become
+// user, and "none" runs the agent as the connection user. Defaults to "auto".
+func (o BecomeOutput) Method() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Become) *string { return v.Method }).(pulumi.StringPtrOutput)
+}
+
+// The password to give the become method when it asks
+// for one. It is only ever written to the terminal of the become command.
+func (o BecomeOutput) Password() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Become) *string { return v.Password }).(pulumi.StringPtrOutput)
+}
+
+// The user the agent runs as. Defaults to "root".
+func (o BecomeOutput) User() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Become) *string { return v.User }).(pulumi.StringPtrOutput)
+}
+
+type BecomePtrOutput struct{ *pulumi.OutputState }
+
+func (BecomePtrOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((**Become)(nil)).Elem()
+}
+
+func (o BecomePtrOutput) ToBecomePtrOutput() BecomePtrOutput {
+	return o
+}
+
+func (o BecomePtrOutput) ToBecomePtrOutputWithContext(ctx context.Context) BecomePtrOutput {
+	return o
+}
+
+func (o BecomePtrOutput) Elem() BecomeOutput {
+	return o.ApplyT(func(v *Become) Become {
+		if v != nil {
+			return *v
+		}
+		var ret Become
+		return ret
+	}).(BecomeOutput)
+}
+
+// Extra flags passed to the become method.
+func (o BecomePtrOutput) Flags() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v *Become) []string {
+		if v == nil {
+			return nil
+		}
+		return v.Flags
+	}).(pulumi.StringArrayOutput)
+}
+
+// How to switch users. One of "auto", "sudo", "doas", "su",
+// or "none". "auto" uses sudo unless the connection user already is the become
+// user, and "none" runs the agent as the connection user. Defaults to "auto".
+func (o BecomePtrOutput) Method() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Become) *string {
+		if v == nil {
+			return nil
+		}
+		return v.Method
+	}).(pulumi.StringPtrOutput)
+}
+
+// The password to give the become method when it asks
+// for one. It is only ever written to the terminal of the become command.
+func (o BecomePtrOutput) Password() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Become) *string {
+		if v == nil {
+			return nil
+		}
+		return v.Password
+	}).(pulumi.StringPtrOutput)
+}
+
+// The user the agent runs as. Defaults to "root".
+func (o BecomePtrOutput) User() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Become) *string {
+		if v == nil {
+			return nil
+		}
+		return v.User
+	}).(pulumi.StringPtrOutput)
+}
+
 // Instructions for how to connect to a remote endpoint.
 type Connection struct {
+	// The directory the agent, its staging area, and the
+	// Ansible modules are installed in on the remote host. Relative paths are
+	// relative to the home directory of the user and can't leave it. Since the
+	// directory is removed when uninstalling the agent, absolute paths need at least
+	// two levels (e.g. "/opt/mid"). Defaults to ".mid".
+	AgentDir *string `pulumi:"agentDir"`
+	// How the agent gets root (or another user's)
+	// privileges. Each setting given for a resource overrides the same setting of the
+	// provider.
+	Become *Become `pulumi:"become"`
+	// The contents of an OpenSSH certificate (usually a
+	// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+	// key. It is for privateKey if that is set, and otherwise for the key of the same
+	// name in the SSH agent. Certificates held by the SSH agent itself are used
+	// without setting this.
+	Certificate *string `pulumi:"certificate"`
 	// The address of the resource to connect to.
-	Host    *string `pulumi:"host"`
+	Host *string `pulumi:"host"`
+	// The public keys, in authorized_keys format, of the
+	// CAs that sign host certificates. A host presenting a certificate signed by one
+	// of them is trusted if the certificate is valid and names the host as a
+	// principal.
+	HostCAKeys []string `pulumi:"hostCAKeys"`
+	// The public key the host is expected to have, in
+	// authorized_keys format.
 	HostKey *string `pulumi:"hostKey"`
+	// How the host's key is checked. "strict" only
+	// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+	// hostCAKeys.
+	// "tofu" (trust on first use) does the same for hosts that are listed there, but
+	// connects to any other host. The key it presents is the hostKey output of Exec
+	// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+	// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+	// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+	HostKeyChecking *string `pulumi:"hostKeyChecking"`
+	// The contents of an OpenSSH known_hosts file to
+	// check the host's key against. Hashed hostnames, "@cert-authority", and
+	// "@revoked" lines are supported.
+	KnownHosts *string `pulumi:"knownHosts"`
+	// The path of an OpenSSH known_hosts file on the
+	// machine running Pulumi to check the host's key against, e.g.
+	// "~/.ssh/known_hosts".
+	KnownHostsFile *string `pulumi:"knownHostsFile"`
 	// The password we should use for the connection.
 	Password       *string `pulumi:"password"`
 	PerDialTimeout *int    `pulumi:"perDialTimeout"`
@@ -27,29 +276,25 @@ type Connection struct {
 	// connection. This takes preference over the password if provided.
 	PrivateKey         *string `pulumi:"privateKey"`
 	PrivateKeyPassword *string `pulumi:"privateKeyPassword"`
-	SshAgent           *bool   `pulumi:"sshAgent"`
-	SshAgentSocketPath *string `pulumi:"sshAgentSocketPath"`
+	// Proxy (or bastion) hosts to connect through, in order.
+	// The first proxy is connected to directly, each following one through the one
+	// before it, and the host itself through the last one. Connections to proxies are
+	// shared between all hosts that go through them.
+	Proxies            []ProxyConnection `pulumi:"proxies"`
+	SshAgent           *bool             `pulumi:"sshAgent"`
+	SshAgentSocketPath *string           `pulumi:"sshAgentSocketPath"`
+	// The path of an OpenSSH client config file on the
+	// machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+	// HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+	// for the host are used for anything the connection doesn't set itself. Include
+	// and "Match host" are supported. Defaults to not reading any config file.
+	SshConfig *string `pulumi:"sshConfig"`
 	// The user that we should use for the connection.
+	// Defaults to the User set for the host in sshConfig, or else the current local
+	// user.
 	User *string `pulumi:"user"`
 }
 
-// Defaults sets the appropriate defaults for Connection
-func (val *Connection) Defaults() *Connection {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	if tmp.Port == nil {
-		port_ := 22.0
-		tmp.Port = &port_
-	}
-	if tmp.User == nil {
-		user_ := "root"
-		tmp.User = &user_
-	}
-	return &tmp
-}
-
 // ConnectionInput is an input type that accepts ConnectionArgs and ConnectionOutput values.
 // You can construct a concrete instance of `ConnectionInput` via:
 //
@@ -63,9 +308,49 @@ type ConnectionInput interface {
 
 // Instructions for how to connect to a remote endpoint.
 type ConnectionArgs struct {
+	// The directory the agent, its staging area, and the
+	// Ansible modules are installed in on the remote host. Relative paths are
+	// relative to the home directory of the user and can't leave it. Since the
+	// directory is removed when uninstalling the agent, absolute paths need at least
+	// two levels (e.g. "/opt/mid"). Defaults to ".mid".
+	AgentDir pulumi.StringPtrInput `pulumi:"agentDir"`
+	// How the agent gets root (or another user's)
+	// privileges. Each setting given for a resource overrides the same setting of the
+	// provider.
+	Become BecomePtrInput `pulumi:"become"`
+	// The contents of an OpenSSH certificate (usually a
+	// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+	// key. It is for privateKey if that is set, and otherwise for the key of the same
+	// name in the SSH agent. Certificates held by the SSH agent itself are used
+	// without setting this.
+	Certificate pulumi.StringPtrInput `pulumi:"certificate"`
 	// The address of the resource to connect to.
-	Host    pulumi.StringPtrInput `pulumi:"host"`
+	Host pulumi.StringPtrInput `pulumi:"host"`
+	// The public keys, in authorized_keys format, of the
+	// CAs that sign host certificates. A host presenting a certificate signed by one
+	// of them is trusted if the certificate is valid and names the host as a
+	// principal.
+	HostCAKeys pulumi.StringArrayInput `pulumi:"hostCAKeys"`
+	// The public key the host is expected to have, in
+	// authorized_keys format.
 	HostKey pulumi.StringPtrInput `pulumi:"hostKey"`
+	// How the host's key is checked. "strict" only
+	// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+	// hostCAKeys.
+	// "tofu" (trust on first use) does the same for hosts that are listed there, but
+	// connects to any other host. The key it presents is the hostKey output of Exec
+	// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+	// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+	// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+	HostKeyChecking pulumi.StringPtrInput `pulumi:"hostKeyChecking"`
+	// The contents of an OpenSSH known_hosts file to
+	// check the host's key against. Hashed hostnames, "@cert-authority", and
+	// "@revoked" lines are supported.
+	KnownHosts pulumi.StringPtrInput `pulumi:"knownHosts"`
+	// The path of an OpenSSH known_hosts file on the
+	// machine running Pulumi to check the host's key against, e.g.
+	// "~/.ssh/known_hosts".
+	KnownHostsFile pulumi.StringPtrInput `pulumi:"knownHostsFile"`
 	// The password we should use for the connection.
 	Password       pulumi.StringPtrInput `pulumi:"password"`
 	PerDialTimeout pulumi.IntPtrInput    `pulumi:"perDialTimeout"`
@@ -75,26 +360,25 @@ type ConnectionArgs struct {
 	// connection. This takes preference over the password if provided.
 	PrivateKey         pulumi.StringPtrInput `pulumi:"privateKey"`
 	PrivateKeyPassword pulumi.StringPtrInput `pulumi:"privateKeyPassword"`
-	SshAgent           pulumi.BoolPtrInput   `pulumi:"sshAgent"`
-	SshAgentSocketPath pulumi.StringPtrInput `pulumi:"sshAgentSocketPath"`
+	// Proxy (or bastion) hosts to connect through, in order.
+	// The first proxy is connected to directly, each following one through the one
+	// before it, and the host itself through the last one. Connections to proxies are
+	// shared between all hosts that go through them.
+	Proxies            ProxyConnectionArrayInput `pulumi:"proxies"`
+	SshAgent           pulumi.BoolPtrInput       `pulumi:"sshAgent"`
+	SshAgentSocketPath pulumi.StringPtrInput     `pulumi:"sshAgentSocketPath"`
+	// The path of an OpenSSH client config file on the
+	// machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+	// HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+	// for the host are used for anything the connection doesn't set itself. Include
+	// and "Match host" are supported. Defaults to not reading any config file.
+	SshConfig pulumi.StringPtrInput `pulumi:"sshConfig"`
 	// The user that we should use for the connection.
+	// Defaults to the User set for the host in sshConfig, or else the current local
+	// user.
 	User pulumi.StringPtrInput `pulumi:"user"`
 }
 
-// Defaults sets the appropriate defaults for ConnectionArgs
-func (val *ConnectionArgs) Defaults() *ConnectionArgs {
-	if val == nil {
-		return nil
-	}
-	tmp := *val
-	if tmp.Port == nil {
-		tmp.Port = pulumi.Float64Ptr(22.0)
-	}
-	if tmp.User == nil {
-		tmp.User = pulumi.StringPtr("root")
-	}
-	return &tmp
-}
 func (ConnectionArgs) ElementType() reflect.Type {
 	return reflect.TypeOf((*Connection)(nil)).Elem()
 }
@@ -173,15 +457,76 @@ func (o ConnectionOutput) ToConnectionPtrOutputWithContext(ctx context.Context)
 	}).(ConnectionPtrOutput)
 }
 
+// The directory the agent, its staging area, and the
+// Ansible modules are installed in on the remote host. Relative paths are
+// relative to the home directory of the user and can't leave it. Since the
+// directory is removed when uninstalling the agent, absolute paths need at least
+// two levels (e.g. "/opt/mid"). Defaults to ".mid".
+func (o ConnectionOutput) AgentDir() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.AgentDir }).(pulumi.StringPtrOutput)
+}
+
+// How the agent gets root (or another user's)
+// privileges. Each setting given for a resource overrides the same setting of the
+// provider.
+func (o ConnectionOutput) Become() BecomePtrOutput {
+	return o.ApplyT(func(v Connection) *Become { return v.Become }).(BecomePtrOutput)
+}
+
+// The contents of an OpenSSH certificate (usually a
+// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+// key. It is for privateKey if that is set, and otherwise for the key of the same
+// name in the SSH agent. Certificates held by the SSH agent itself are used
+// without setting this.
+func (o ConnectionOutput) Certificate() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.Certificate }).(pulumi.StringPtrOutput)
+}
+
 // The address of the resource to connect to.
 func (o ConnectionOutput) Host() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.Host }).(pulumi.StringPtrOutput)
 }
 
+// The public keys, in authorized_keys format, of the
+// CAs that sign host certificates. A host presenting a certificate signed by one
+// of them is trusted if the certificate is valid and names the host as a
+// principal.
+func (o ConnectionOutput) HostCAKeys() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v Connection) []string { return v.HostCAKeys }).(pulumi.StringArrayOutput)
+}
+
+// The public key the host is expected to have, in
+// authorized_keys format.
 func (o ConnectionOutput) HostKey() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.HostKey }).(pulumi.StringPtrOutput)
 }
 
+// How the host's key is checked. "strict" only
+// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+// hostCAKeys.
+// "tofu" (trust on first use) does the same for hosts that are listed there, but
+// connects to any other host. The key it presents is the hostKey output of Exec
+// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+func (o ConnectionOutput) HostKeyChecking() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.HostKeyChecking }).(pulumi.StringPtrOutput)
+}
+
+// The contents of an OpenSSH known_hosts file to
+// check the host's key against. Hashed hostnames, "@cert-authority", and
+// "@revoked" lines are supported.
+func (o ConnectionOutput) KnownHosts() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.KnownHosts }).(pulumi.StringPtrOutput)
+}
+
+// The path of an OpenSSH known_hosts file on the
+// machine running Pulumi to check the host's key against, e.g.
+// "~/.ssh/known_hosts".
+func (o ConnectionOutput) KnownHostsFile() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.KnownHostsFile }).(pulumi.StringPtrOutput)
+}
+
 // The password we should use for the connection.
 func (o ConnectionOutput) Password() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.Password }).(pulumi.StringPtrOutput)
@@ -206,6 +551,14 @@ func (o ConnectionOutput) PrivateKeyPassword() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.PrivateKeyPassword }).(pulumi.StringPtrOutput)
 }
 
+// Proxy (or bastion) hosts to connect through, in order.
+// The first proxy is connected to directly, each following one through the one
+// before it, and the host itself through the last one. Connections to proxies are
+// shared between all hosts that go through them.
+func (o ConnectionOutput) Proxies() ProxyConnectionArrayOutput {
+	return o.ApplyT(func(v Connection) []ProxyConnection { return v.Proxies }).(ProxyConnectionArrayOutput)
+}
+
 func (o ConnectionOutput) SshAgent() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v Connection) *bool { return v.SshAgent }).(pulumi.BoolPtrOutput)
 }
@@ -214,7 +567,18 @@ func (o ConnectionOutput) SshAgentSocketPath() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.SshAgentSocketPath }).(pulumi.StringPtrOutput)
 }
 
+// The path of an OpenSSH client config file on the
+// machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+// HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+// for the host are used for anything the connection doesn't set itself. Include
+// and "Match host" are supported. Defaults to not reading any config file.
+func (o ConnectionOutput) SshConfig() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v Connection) *string { return v.SshConfig }).(pulumi.StringPtrOutput)
+}
+
 // The user that we should use for the connection.
+// Defaults to the User set for the host in sshConfig, or else the current local
+// user.
 func (o ConnectionOutput) User() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v Connection) *string { return v.User }).(pulumi.StringPtrOutput)
 }
@@ -243,6 +607,46 @@ func (o ConnectionPtrOutput) Elem() ConnectionOutput {
 	}).(ConnectionOutput)
 }
 
+// The directory the agent, its staging area, and the
+// Ansible modules are installed in on the remote host. Relative paths are
+// relative to the home directory of the user and can't leave it. Since the
+// directory is removed when uninstalling the agent, absolute paths need at least
+// two levels (e.g. "/opt/mid"). Defaults to ".mid".
+func (o ConnectionPtrOutput) AgentDir() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.AgentDir
+	}).(pulumi.StringPtrOutput)
+}
+
+// How the agent gets root (or another user's)
+// privileges. Each setting given for a resource overrides the same setting of the
+// provider.
+func (o ConnectionPtrOutput) Become() BecomePtrOutput {
+	return o.ApplyT(func(v *Connection) *Become {
+		if v == nil {
+			return nil
+		}
+		return v.Become
+	}).(BecomePtrOutput)
+}
+
+// The contents of an OpenSSH certificate (usually a
+// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+// key. It is for privateKey if that is set, and otherwise for the key of the same
+// name in the SSH agent. Certificates held by the SSH agent itself are used
+// without setting this.
+func (o ConnectionPtrOutput) Certificate() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.Certificate
+	}).(pulumi.StringPtrOutput)
+}
+
 // The address of the resource to connect to.
 func (o ConnectionPtrOutput) Host() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v *Connection) *string {
@@ -253,6 +657,21 @@ func (o ConnectionPtrOutput) Host() pulumi.StringPtrOutput {
 	}).(pulumi.StringPtrOutput)
 }
 
+// The public keys, in authorized_keys format, of the
+// CAs that sign host certificates. A host presenting a certificate signed by one
+// of them is trusted if the certificate is valid and names the host as a
+// principal.
+func (o ConnectionPtrOutput) HostCAKeys() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v *Connection) []string {
+		if v == nil {
+			return nil
+		}
+		return v.HostCAKeys
+	}).(pulumi.StringArrayOutput)
+}
+
+// The public key the host is expected to have, in
+// authorized_keys format.
 func (o ConnectionPtrOutput) HostKey() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v *Connection) *string {
 		if v == nil {
@@ -262,6 +681,47 @@ func (o ConnectionPtrOutput) HostKey() pulumi.StringPtrOutput {
 	}).(pulumi.StringPtrOutput)
 }
 
+// How the host's key is checked. "strict" only
+// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+// hostCAKeys.
+// "tofu" (trust on first use) does the same for hosts that are listed there, but
+// connects to any other host. The key it presents is the hostKey output of Exec
+// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+func (o ConnectionPtrOutput) HostKeyChecking() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.HostKeyChecking
+	}).(pulumi.StringPtrOutput)
+}
+
+// The contents of an OpenSSH known_hosts file to
+// check the host's key against. Hashed hostnames, "@cert-authority", and
+// "@revoked" lines are supported.
+func (o ConnectionPtrOutput) KnownHosts() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.KnownHosts
+	}).(pulumi.StringPtrOutput)
+}
+
+// The path of an OpenSSH known_hosts file on the
+// machine running Pulumi to check the host's key against, e.g.
+// "~/.ssh/known_hosts".
+func (o ConnectionPtrOutput) KnownHostsFile() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.KnownHostsFile
+	}).(pulumi.StringPtrOutput)
+}
+
 // The password we should use for the connection.
 func (o ConnectionPtrOutput) Password() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v *Connection) *string {
@@ -311,6 +771,19 @@ func (o ConnectionPtrOutput) PrivateKeyPassword() pulumi.StringPtrOutput {
 	}).(pulumi.StringPtrOutput)
 }
 
+// Proxy (or bastion) hosts to connect through, in order.
+// The first proxy is connected to directly, each following one through the one
+// before it, and the host itself through the last one. Connections to proxies are
+// shared between all hosts that go through them.
+func (o ConnectionPtrOutput) Proxies() ProxyConnectionArrayOutput {
+	return o.ApplyT(func(v *Connection) []ProxyConnection {
+		if v == nil {
+			return nil
+		}
+		return v.Proxies
+	}).(ProxyConnectionArrayOutput)
+}
+
 func (o ConnectionPtrOutput) SshAgent() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v *Connection) *bool {
 		if v == nil {
@@ -329,7 +802,23 @@ func (o ConnectionPtrOutput) SshAgentSocketPath() pulumi.StringPtrOutput {
 	}).(pulumi.StringPtrOutput)
 }
 
+// The path of an OpenSSH client config file on the
+// machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+// HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+// for the host are used for anything the connection doesn't set itself. Include
+// and "Match host" are supported. Defaults to not reading any config file.
+func (o ConnectionPtrOutput) SshConfig() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Connection) *string {
+		if v == nil {
+			return nil
+		}
+		return v.SshConfig
+	}).(pulumi.StringPtrOutput)
+}
+
 // The user that we should use for the connection.
+// Defaults to the User set for the host in sshConfig, or else the current local
+// user.
 func (o ConnectionPtrOutput) User() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v *Connection) *string {
 		if v == nil {
@@ -351,6 +840,10 @@ type ExecCommand struct {
 	Environment map[string]string `pulumi:"environment"`
 	// Pass a string to the command's process as standard in.
 	Stdin *string `pulumi:"stdin"`
+	// Maximum number of seconds the command is allowed to run. When exceeded, the
+	// command's process group is sent SIGTERM, followed by SIGKILL if it has not
+	// exited 10 seconds later. Defaults to no timeout.
+	Timeout *int `pulumi:"timeout"`
 }
 
 // ExecCommandInput is an input type that accepts ExecCommandArgs and ExecCommandOutput values.
@@ -376,6 +869,10 @@ type ExecCommandArgs struct {
 	Environment pulumi.StringMapInput `pulumi:"environment"`
 	// Pass a string to the command's process as standard in.
 	Stdin pulumi.StringPtrInput `pulumi:"stdin"`
+	// Maximum number of seconds the command is allowed to run. When exceeded, the
+	// command's process group is sent SIGTERM, followed by SIGKILL if it has not
+	// exited 10 seconds later. Defaults to no timeout.
+	Timeout pulumi.IntPtrInput `pulumi:"timeout"`
 }
 
 func (ExecCommandArgs) ElementType() reflect.Type {
@@ -478,6 +975,13 @@ func (o ExecCommandOutput) Stdin() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v ExecCommand) *string { return v.Stdin }).(pulumi.StringPtrOutput)
 }
 
+// Maximum number of seconds the command is allowed to run. When exceeded, the
+// command's process group is sent SIGTERM, followed by SIGKILL if it has not
+// exited 10 seconds later. Defaults to no timeout.
+func (o ExecCommandOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ExecCommand) *int { return v.Timeout }).(pulumi.IntPtrOutput)
+}
+
 type ExecCommandPtrOutput struct{ *pulumi.OutputState }
 
 func (ExecCommandPtrOutput) ElementType() reflect.Type {
@@ -545,6 +1049,18 @@ func (o ExecCommandPtrOutput) Stdin() pulumi.StringPtrOutput {
 	}).(pulumi.StringPtrOutput)
 }
 
+// Maximum number of seconds the command is allowed to run. When exceeded, the
+// command's process group is sent SIGTERM, followed by SIGKILL if it has not
+// exited 10 seconds later. Defaults to no timeout.
+func (o ExecCommandPtrOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v *ExecCommand) *int {
+		if v == nil {
+			return nil
+		}
+		return v.Timeout
+	}).(pulumi.IntPtrOutput)
+}
+
 type FileStatFileMode struct {
 	Int       int    `pulumi:"int"`
 	IsDir     bool   `pulumi:"isDir"`
@@ -748,10 +1264,320 @@ func (o FileStatStateOutput) UserName() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v FileStatState) *string { return v.UserName }).(pulumi.StringPtrOutput)
 }
 
+// Instructions for how to connect to a proxy (or bastion) host.
+type ProxyConnection struct {
+	// The contents of an OpenSSH certificate (usually a
+	// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+	// key. It is for privateKey if that is set, and otherwise for the key of the same
+	// name in the SSH agent. Certificates held by the SSH agent itself are used
+	// without setting this.
+	Certificate *string `pulumi:"certificate"`
+	// The address of the proxy to connect to.
+	Host *string `pulumi:"host"`
+	// The public keys, in authorized_keys format, of the
+	// CAs that sign host certificates. A host presenting a certificate signed by one
+	// of them is trusted if the certificate is valid and names the host as a
+	// principal.
+	HostCAKeys []string `pulumi:"hostCAKeys"`
+	// The public key the host is expected to have, in
+	// authorized_keys format.
+	HostKey *string `pulumi:"hostKey"`
+	// How the host's key is checked. "strict" only
+	// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+	// hostCAKeys.
+	// "tofu" (trust on first use) does the same for hosts that are listed there, but
+	// connects to any other host. The key it presents is the hostKey output of Exec
+	// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+	// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+	// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+	HostKeyChecking *string `pulumi:"hostKeyChecking"`
+	// The contents of an OpenSSH known_hosts file to
+	// check the host's key against. Hashed hostnames, "@cert-authority", and
+	// "@revoked" lines are supported.
+	KnownHosts *string `pulumi:"knownHosts"`
+	// The path of an OpenSSH known_hosts file on the
+	// machine running Pulumi to check the host's key against, e.g.
+	// "~/.ssh/known_hosts".
+	KnownHostsFile *string `pulumi:"knownHostsFile"`
+	// The password we should use for the connection to the proxy.
+	Password       *string `pulumi:"password"`
+	PerDialTimeout *int    `pulumi:"perDialTimeout"`
+	// The port to connect to. Defaults to 22.
+	Port *float64 `pulumi:"port"`
+	// The contents of an SSH key to use for the
+	// connection to the proxy. This takes preference over the password if provided.
+	PrivateKey         *string `pulumi:"privateKey"`
+	PrivateKeyPassword *string `pulumi:"privateKeyPassword"`
+	SshAgent           *bool   `pulumi:"sshAgent"`
+	SshAgentSocketPath *string `pulumi:"sshAgentSocketPath"`
+	// The user that we should use for the connection to the
+	// proxy. Defaults to the current local user.
+	User *string `pulumi:"user"`
+}
+
+// Defaults sets the appropriate defaults for ProxyConnection
+func (val *ProxyConnection) Defaults() *ProxyConnection {
+	if val == nil {
+		return nil
+	}
+	tmp := *val
+	if tmp.Port == nil {
+		port_ := 22.0
+		tmp.Port = &port_
+	}
+	return &tmp
+}
+
+// ProxyConnectionInput is an input type that accepts ProxyConnectionArgs and ProxyConnectionOutput values.
+// You can construct a concrete instance of `ProxyConnectionInput` via:
+//
+//	ProxyConnectionArgs{...}
+type ProxyConnectionInput interface {
+	pulumi.Input
+
+	ToProxyConnectionOutput() ProxyConnectionOutput
+	ToProxyConnectionOutputWithContext(context.Context) ProxyConnectionOutput
+}
+
+// Instructions for how to connect to a proxy (or bastion) host.
+type ProxyConnectionArgs struct {
+	// The contents of an OpenSSH certificate (usually a
+	// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+	// key. It is for privateKey if that is set, and otherwise for the key of the same
+	// name in the SSH agent. Certificates held by the SSH agent itself are used
+	// without setting this.
+	Certificate pulumi.StringPtrInput `pulumi:"certificate"`
+	// The address of the proxy to connect to.
+	Host pulumi.StringPtrInput `pulumi:"host"`
+	// The public keys, in authorized_keys format, of the
+	// CAs that sign host certificates. A host presenting a certificate signed by one
+	// of them is trusted if the certificate is valid and names the host as a
+	// principal.
+	HostCAKeys pulumi.StringArrayInput `pulumi:"hostCAKeys"`
+	// The public key the host is expected to have, in
+	// authorized_keys format.
+	HostKey pulumi.StringPtrInput `pulumi:"hostKey"`
+	// How the host's key is checked. "strict" only
+	// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+	// hostCAKeys.
+	// "tofu" (trust on first use) does the same for hosts that are listed there, but
+	// connects to any other host. The key it presents is the hostKey output of Exec
+	// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+	// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+	// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+	HostKeyChecking pulumi.StringPtrInput `pulumi:"hostKeyChecking"`
+	// The contents of an OpenSSH known_hosts file to
+	// check the host's key against. Hashed hostnames, "@cert-authority", and
+	// "@revoked" lines are supported.
+	KnownHosts pulumi.StringPtrInput `pulumi:"knownHosts"`
+	// The path of an OpenSSH known_hosts file on the
+	// machine running Pulumi to check the host's key against, e.g.
+	// "~/.ssh/known_hosts".
+	KnownHostsFile pulumi.StringPtrInput `pulumi:"knownHostsFile"`
+	// The password we should use for the connection to the proxy.
+	Password       pulumi.StringPtrInput `pulumi:"password"`
+	PerDialTimeout pulumi.IntPtrInput    `pulumi:"perDialTimeout"`
+	// The port to connect to. Defaults to 22.
+	Port pulumi.Float64PtrInput `pulumi:"port"`
+	// The contents of an SSH key to use for the
+	// connection to the proxy. This takes preference over the password if provided.
+	PrivateKey         pulumi.StringPtrInput `pulumi:"privateKey"`
+	PrivateKeyPassword pulumi.StringPtrInput `pulumi:"privateKeyPassword"`
+	SshAgent           pulumi.BoolPtrInput   `pulumi:"sshAgent"`
+	SshAgentSocketPath pulumi.StringPtrInput `pulumi:"sshAgentSocketPath"`
+	// The user that we should use for the connection to the
+	// proxy. Defaults to the current local user.
+	User pulumi.StringPtrInput `pulumi:"user"`
+}
+
+// Defaults sets the appropriate defaults for ProxyConnectionArgs
+func (val *ProxyConnectionArgs) Defaults() *ProxyConnectionArgs {
+	if val == nil {
+		return nil
+	}
+	tmp := *val
+	if tmp.Port == nil {
+		tmp.Port = pulumi.Float64Ptr(22.0)
+	}
+	return &tmp
+}
+func (ProxyConnectionArgs) ElementType() reflect.Type {
+	return reflect.TypeOf((*ProxyConnection)(nil)).Elem()
+}
+
+func (i ProxyConnectionArgs) ToProxyConnectionOutput() ProxyConnectionOutput {
+	return i.ToProxyConnectionOutputWithContext(context.Background())
+}
+
+func (i ProxyConnectionArgs) ToProxyConnectionOutputWithContext(ctx context.Context) ProxyConnectionOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(ProxyConnectionOutput)
+}
+
+// ProxyConnectionArrayInput is an input type that accepts ProxyConnectionArray and ProxyConnectionArrayOutput values.
+// You can construct a concrete instance of `ProxyConnectionArrayInput` via:
+//
+//	ProxyConnectionArray{ ProxyConnectionArgs{...} }
+type ProxyConnectionArrayInput interface {
+	pulumi.Input
+
+	ToProxyConnectionArrayOutput() ProxyConnectionArrayOutput
+	ToProxyConnectionArrayOutputWithContext(context.Context) ProxyConnectionArrayOutput
+}
+
+type ProxyConnectionArray []ProxyConnectionInput
+
+func (ProxyConnectionArray) ElementType() reflect.Type {
+	return reflect.TypeOf((*[]ProxyConnection)(nil)).Elem()
+}
+
+func (i ProxyConnectionArray) ToProxyConnectionArrayOutput() ProxyConnectionArrayOutput {
+	return i.ToProxyConnectionArrayOutputWithContext(context.Background())
+}
+
+func (i ProxyConnectionArray) ToProxyConnectionArrayOutputWithContext(ctx context.Context) ProxyConnectionArrayOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(ProxyConnectionArrayOutput)
+}
+
+// Instructions for how to connect to a proxy (or bastion) host.
+type ProxyConnectionOutput struct{ *pulumi.OutputState }
+
+func (ProxyConnectionOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((*ProxyConnection)(nil)).Elem()
+}
+
+func (o ProxyConnectionOutput) ToProxyConnectionOutput() ProxyConnectionOutput {
+	return o
+}
+
+func (o ProxyConnectionOutput) ToProxyConnectionOutputWithContext(ctx context.Context) ProxyConnectionOutput {
+	return o
+}
+
+// The contents of an OpenSSH certificate (usually a
+// "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+// key. It is for privateKey if that is set, and otherwise for the key of the same
+// name in the SSH agent. Certificates held by the SSH agent itself are used
+// without setting this.
+func (o ProxyConnectionOutput) Certificate() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.Certificate }).(pulumi.StringPtrOutput)
+}
+
+// The address of the proxy to connect to.
+func (o ProxyConnectionOutput) Host() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.Host }).(pulumi.StringPtrOutput)
+}
+
+// The public keys, in authorized_keys format, of the
+// CAs that sign host certificates. A host presenting a certificate signed by one
+// of them is trusted if the certificate is valid and names the host as a
+// principal.
+func (o ProxyConnectionOutput) HostCAKeys() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v ProxyConnection) []string { return v.HostCAKeys }).(pulumi.StringArrayOutput)
+}
+
+// The public key the host is expected to have, in
+// authorized_keys format.
+func (o ProxyConnectionOutput) HostKey() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.HostKey }).(pulumi.StringPtrOutput)
+}
+
+// How the host's key is checked. "strict" only
+// connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+// hostCAKeys.
+// "tofu" (trust on first use) does the same for hosts that are listed there, but
+// connects to any other host. The key it presents is the hostKey output of Exec
+// and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+// to any host. Defaults to "strict" if any of hostKey, knownHosts,
+// knownHostsFile, or hostCAKeys are set and "off" otherwise.
+func (o ProxyConnectionOutput) HostKeyChecking() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.HostKeyChecking }).(pulumi.StringPtrOutput)
+}
+
+// The contents of an OpenSSH known_hosts file to
+// check the host's key against. Hashed hostnames, "@cert-authority", and
+// "@revoked" lines are supported.
+func (o ProxyConnectionOutput) KnownHosts() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.KnownHosts }).(pulumi.StringPtrOutput)
+}
+
+// The path of an OpenSSH known_hosts file on the
+// machine running Pulumi to check the host's key against, e.g.
+// "~/.ssh/known_hosts".
+func (o ProxyConnectionOutput) KnownHostsFile() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.KnownHostsFile }).(pulumi.StringPtrOutput)
+}
+
+// The password we should use for the connection to the proxy.
+func (o ProxyConnectionOutput) Password() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.Password }).(pulumi.StringPtrOutput)
+}
+
+func (o ProxyConnectionOutput) PerDialTimeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *int { return v.PerDialTimeout }).(pulumi.IntPtrOutput)
+}
+
+// The port to connect to. Defaults to 22.
+func (o ProxyConnectionOutput) Port() pulumi.Float64PtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *float64 { return v.Port }).(pulumi.Float64PtrOutput)
+}
+
+// The contents of an SSH key to use for the
+// connection to the proxy. This takes preference over the password if provided.
+func (o ProxyConnectionOutput) PrivateKey() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.PrivateKey }).(pulumi.StringPtrOutput)
+}
+
+func (o ProxyConnectionOutput) PrivateKeyPassword() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.PrivateKeyPassword }).(pulumi.StringPtrOutput)
+}
+
+func (o ProxyConnectionOutput) SshAgent() pulumi.BoolPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *bool { return v.SshAgent }).(pulumi.BoolPtrOutput)
+}
+
+func (o ProxyConnectionOutput) SshAgentSocketPath() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.SshAgentSocketPath }).(pulumi.StringPtrOutput)
+}
+
+// The user that we should use for the connection to the
+// proxy. Defaults to the current local user.
+func (o ProxyConnectionOutput) User() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ProxyConnection) *string { return v.User }).(pulumi.StringPtrOutput)
+}
+
+type ProxyConnectionArrayOutput struct{ *pulumi.OutputState }
+
+func (ProxyConnectionArrayOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((*[]ProxyConnection)(nil)).Elem()
+}
+
+func (o ProxyConnectionArrayOutput) ToProxyConnectionArrayOutput() ProxyConnectionArrayOutput {
+	return o
+}
+
+func (o ProxyConnectionArrayOutput) ToProxyConnectionArrayOutputWithContext(ctx context.Context) ProxyConnectionArrayOutput {
+	return o
+}
+
+func (o ProxyConnectionArrayOutput) Index(i pulumi.IntInput) ProxyConnectionOutput {
+	return pulumi.All(o, i).ApplyT(func(vs []interface{}) ProxyConnection {
+		return vs[0].([]ProxyConnection)[vs[1].(int)]
+	}).(ProxyConnectionOutput)
+}
+
 type ResourceConfig struct {
+	AgentGCDays       *int  `pulumi:"agentGCDays"`
 	Check             *bool `pulumi:"check"`
 	DeleteUnreachable *bool `pulumi:"deleteUnreachable"`
+	HeartbeatInterval *int  `pulumi:"heartbeatInterval"`
+	HeartbeatTimeout  *int  `pulumi:"heartbeatTimeout"`
 	Parallel          *int  `pulumi:"parallel"`
+	// The Python interpreter used to run Ansible
+	// modules on the remote host, either a path or a command name to look up in PATH.
+	// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+	// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+	// installed. If not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter *string `pulumi:"pythonInterpreter"`
 }
 
 // ResourceConfigInput is an input type that accepts ResourceConfigArgs and ResourceConfigOutput values.
@@ -766,9 +1592,18 @@ type ResourceConfigInput interface {
 }
 
 type ResourceConfigArgs struct {
+	AgentGCDays       pulumi.IntPtrInput  `pulumi:"agentGCDays"`
 	Check             pulumi.BoolPtrInput `pulumi:"check"`
 	DeleteUnreachable pulumi.BoolPtrInput `pulumi:"deleteUnreachable"`
+	HeartbeatInterval pulumi.IntPtrInput  `pulumi:"heartbeatInterval"`
+	HeartbeatTimeout  pulumi.IntPtrInput  `pulumi:"heartbeatTimeout"`
 	Parallel          pulumi.IntPtrInput  `pulumi:"parallel"`
+	// The Python interpreter used to run Ansible
+	// modules on the remote host, either a path or a command name to look up in PATH.
+	// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+	// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+	// installed. If not set, the agent picks the first suitable interpreter it finds.
+	PythonInterpreter pulumi.StringPtrInput `pulumi:"pythonInterpreter"`
 }
 
 func (ResourceConfigArgs) ElementType() reflect.Type {
@@ -848,6 +1683,10 @@ func (o ResourceConfigOutput) ToResourceConfigPtrOutputWithContext(ctx context.C
 	}).(ResourceConfigPtrOutput)
 }
 
+func (o ResourceConfigOutput) AgentGCDays() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ResourceConfig) *int { return v.AgentGCDays }).(pulumi.IntPtrOutput)
+}
+
 func (o ResourceConfigOutput) Check() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v ResourceConfig) *bool { return v.Check }).(pulumi.BoolPtrOutput)
 }
@@ -856,10 +1695,27 @@ func (o ResourceConfigOutput) DeleteUnreachable() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v ResourceConfig) *bool { return v.DeleteUnreachable }).(pulumi.BoolPtrOutput)
 }
 
+func (o ResourceConfigOutput) HeartbeatInterval() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ResourceConfig) *int { return v.HeartbeatInterval }).(pulumi.IntPtrOutput)
+}
+
+func (o ResourceConfigOutput) HeartbeatTimeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v ResourceConfig) *int { return v.HeartbeatTimeout }).(pulumi.IntPtrOutput)
+}
+
 func (o ResourceConfigOutput) Parallel() pulumi.IntPtrOutput {
 	return o.ApplyT(func(v ResourceConfig) *int { return v.Parallel }).(pulumi.IntPtrOutput)
 }
 
+// The Python interpreter used to run Ansible
+// modules on the remote host, either a path or a command name to look up in PATH.
+// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+// installed. If not set, the agent picks the first suitable interpreter it finds.
+func (o ResourceConfigOutput) PythonInterpreter() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v ResourceConfig) *string { return v.PythonInterpreter }).(pulumi.StringPtrOutput)
+}
+
 type ResourceConfigPtrOutput struct{ *pulumi.OutputState }
 
 func (ResourceConfigPtrOutput) ElementType() reflect.Type {
@@ -884,6 +1740,15 @@ func (o ResourceConfigPtrOutput) Elem() ResourceConfigOutput {
 	}).(ResourceConfigOutput)
 }
 
+func (o ResourceConfigPtrOutput) AgentGCDays() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v *ResourceConfig) *int {
+		if v == nil {
+			return nil
+		}
+		return v.AgentGCDays
+	}).(pulumi.IntPtrOutput)
+}
+
 func (o ResourceConfigPtrOutput) Check() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v *ResourceConfig) *bool {
 		if v == nil {
@@ -902,6 +1767,24 @@ func (o ResourceConfigPtrOutput) DeleteUnreachable() pulumi.BoolPtrOutput {
 	}).(pulumi.BoolPtrOutput)
 }
 
+func (o ResourceConfigPtrOutput) HeartbeatInterval() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v *ResourceConfig) *int {
+		if v == nil {
+			return nil
+		}
+		return v.HeartbeatInterval
+	}).(pulumi.IntPtrOutput)
+}
+
+func (o ResourceConfigPtrOutput) HeartbeatTimeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v *ResourceConfig) *int {
+		if v == nil {
+			return nil
+		}
+		return v.HeartbeatTimeout
+	}).(pulumi.IntPtrOutput)
+}
+
 func (o ResourceConfigPtrOutput) Parallel() pulumi.IntPtrOutput {
 	return o.ApplyT(func(v *ResourceConfig) *int {
 		if v == nil {
@@ -911,6 +1794,248 @@ func (o ResourceConfigPtrOutput) Parallel() pulumi.IntPtrOutput {
 	}).(pulumi.IntPtrOutput)
 }
 
+// The Python interpreter used to run Ansible
+// modules on the remote host, either a path or a command name to look up in PATH.
+// It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+// /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+// installed. If not set, the agent picks the first suitable interpreter it finds.
+func (o ResourceConfigPtrOutput) PythonInterpreter() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *ResourceConfig) *string {
+		if v == nil {
+			return nil
+		}
+		return v.PythonInterpreter
+	}).(pulumi.StringPtrOutput)
+}
+
+// The user and groups to run as instead of the user the agent
+// runs as. Switching users requires the agent to run as root.
+type RunAs struct {
+	// The name or numeric ID of the primary group. Defaults to
+	// the primary group of the user.
+	Group *string `pulumi:"group"`
+	// The names or numeric IDs of the supplementary groups.
+	// Defaults to the groups the user is a member of.
+	Groups []string `pulumi:"groups"`
+	// Start with the environment a login of the user would
+	// get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+	// if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+	// DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+	// unless another directory is given. Defaults to false.
+	LoginEnv *bool `pulumi:"loginEnv"`
+	// The name or numeric ID of the user to run as. Defaults to
+	// the agent's user.
+	User *string `pulumi:"user"`
+}
+
+// RunAsInput is an input type that accepts RunAsArgs and RunAsOutput values.
+// You can construct a concrete instance of `RunAsInput` via:
+//
+//	RunAsArgs{...}
+type RunAsInput interface {
+	pulumi.Input
+
+	ToRunAsOutput() RunAsOutput
+	ToRunAsOutputWithContext(context.Context) RunAsOutput
+}
+
+// The user and groups to run as instead of the user the agent
+// runs as. Switching users requires the agent to run as root.
+type RunAsArgs struct {
+	// The name or numeric ID of the primary group. Defaults to
+	// the primary group of the user.
+	Group pulumi.StringPtrInput `pulumi:"group"`
+	// The names or numeric IDs of the supplementary groups.
+	// Defaults to the groups the user is a member of.
+	Groups pulumi.StringArrayInput `pulumi:"groups"`
+	// Start with the environment a login of the user would
+	// get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+	// if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+	// DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+	// unless another directory is given. Defaults to false.
+	LoginEnv pulumi.BoolPtrInput `pulumi:"loginEnv"`
+	// The name or numeric ID of the user to run as. Defaults to
+	// the agent's user.
+	User pulumi.StringPtrInput `pulumi:"user"`
+}
+
+func (RunAsArgs) ElementType() reflect.Type {
+	return reflect.TypeOf((*RunAs)(nil)).Elem()
+}
+
+func (i RunAsArgs) ToRunAsOutput() RunAsOutput {
+	return i.ToRunAsOutputWithContext(context.Background())
+}
+
+func (i RunAsArgs) ToRunAsOutputWithContext(ctx context.Context) RunAsOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(RunAsOutput)
+}
+
+func (i RunAsArgs) ToRunAsPtrOutput() RunAsPtrOutput {
+	return i.ToRunAsPtrOutputWithContext(context.Background())
+}
+
+func (i RunAsArgs) ToRunAsPtrOutputWithContext(ctx context.Context) RunAsPtrOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(RunAsOutput).ToRunAsPtrOutputWithContext(ctx)
+}
+
+// RunAsPtrInput is an input type that accepts RunAsArgs, RunAsPtr and RunAsPtrOutput values.
+// You can construct a concrete instance of `RunAsPtrInput` via:
+//
+//	        RunAsArgs{...}
+//
+//	or:
+//
+//	        nil
+type RunAsPtrInput interface {
+	pulumi.Input
+
+	ToRunAsPtrOutput() RunAsPtrOutput
+	ToRunAsPtrOutputWithContext(context.Context) RunAsPtrOutput
+}
+
+type runAsPtrType RunAsArgs
+
+func RunAsPtr(v *RunAsArgs) RunAsPtrInput {
+	return (*runAsPtrType)(v)
+}
+
+func (*runAsPtrType) ElementType() reflect.Type {
+	return reflect.TypeOf((**RunAs)(nil)).Elem()
+}
+
+func (i *runAsPtrType) ToRunAsPtrOutput() RunAsPtrOutput {
+	return i.ToRunAsPtrOutputWithContext(context.Background())
+}
+
+func (i *runAsPtrType) ToRunAsPtrOutputWithContext(ctx context.Context) RunAsPtrOutput {
+	return pulumi.ToOutputWithContext(ctx, i).(RunAsPtrOutput)
+}
+
+// The user and groups to run as instead of the user the agent
+// runs as. Switching users requires the agent to run as root.
+type RunAsOutput struct{ *pulumi.OutputState }
+
+func (RunAsOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((*RunAs)(nil)).Elem()
+}
+
+func (o RunAsOutput) ToRunAsOutput() RunAsOutput {
+	return o
+}
+
+func (o RunAsOutput) ToRunAsOutputWithContext(ctx context.Context) RunAsOutput {
+	return o
+}
+
+func (o RunAsOutput) ToRunAsPtrOutput() RunAsPtrOutput {
+	return o.ToRunAsPtrOutputWithContext(context.Background())
+}
+
+func (o RunAsOutput) ToRunAsPtrOutputWithContext(ctx context.Context) RunAsPtrOutput {
+	return o.ApplyTWithContext(ctx, func(_ context.Context, v RunAs) *RunAs {
+		return &v
+	}).(RunAsPtrOutput)
+}
+
+// The name or numeric ID of the primary group. Defaults to
+// the primary group of the user.
+func (o RunAsOutput) Group() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v RunAs) *string { return v.Group }).(pulumi.StringPtrOutput)
+}
+
+// The names or numeric IDs of the supplementary groups.
+// Defaults to the groups the user is a member of.
+func (o RunAsOutput) Groups() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v RunAs) []string { return v.Groups }).(pulumi.StringArrayOutput)
+}
+
+// Start with the environment a login of the user would
+// get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+// if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+// DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+// unless another directory is given. Defaults to false.
+func (o RunAsOutput) LoginEnv() pulumi.BoolPtrOutput {
+	return o.ApplyT(func(v RunAs) *bool { return v.LoginEnv }).(pulumi.BoolPtrOutput)
+}
+
+// The name or numeric ID of the user to run as. Defaults to
+// the agent's user.
+func (o RunAsOutput) User() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v RunAs) *string { return v.User }).(pulumi.StringPtrOutput)
+}
+
+type RunAsPtrOutput struct{ *pulumi.OutputState }
+
+func (RunAsPtrOutput) ElementType() reflect.Type {
+	return reflect.TypeOf((**RunAs)(nil)).Elem()
+}
+
+func (o RunAsPtrOutput) ToRunAsPtrOutput() RunAsPtrOutput {
+	return o
+}
+
+func (o RunAsPtrOutput) ToRunAsPtrOutputWithContext(ctx context.Context) RunAsPtrOutput {
+	return o
+}
+
+func (o RunAsPtrOutput) Elem() RunAsOutput {
+	return o.ApplyT(func(v *RunAs) RunAs {
+		if v != nil {
+			return *v
+		}
+		var ret RunAs
+		return ret
+	}).(RunAsOutput)
+}
+
+// The name or numeric ID of the primary group. Defaults to
+// the primary group of the user.
+func (o RunAsPtrOutput) Group() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *RunAs) *string {
+		if v == nil {
+			return nil
+		}
+		return v.Group
+	}).(pulumi.StringPtrOutput)
+}
+
+// The names or numeric IDs of the supplementary groups.
+// Defaults to the groups the user is a member of.
+func (o RunAsPtrOutput) Groups() pulumi.StringArrayOutput {
+	return o.ApplyT(func(v *RunAs) []string {
+		if v == nil {
+			return nil
+		}
+		return v.Groups
+	}).(pulumi.StringArrayOutput)
+}
+
+// Start with the environment a login of the user would
+// get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+// if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+// DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+// unless another directory is given. Defaults to false.
+func (o RunAsPtrOutput) LoginEnv() pulumi.BoolPtrOutput {
+	return o.ApplyT(func(v *RunAs) *bool {
+		if v == nil {
+			return nil
+		}
+		return v.LoginEnv
+	}).(pulumi.BoolPtrOutput)
+}
+
+// The name or numeric ID of the user to run as. Defaults to
+// the agent's user.
+func (o RunAsPtrOutput) User() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *RunAs) *string {
+		if v == nil {
+			return nil
+		}
+		return v.User
+	}).(pulumi.StringPtrOutput)
+}
+
 type TriggersInput struct {
 	// Run any "refresh" operations (e.g. service restarts, change diffs, etc) if
 	// any value in this list changes.
@@ -1120,14 +2245,22 @@ func (o TriggersOutputOutput) Replace() pulumi.ArrayOutput {
 }
 
 func init() {
+	pulumi.RegisterInputType(reflect.TypeOf((*BecomeInput)(nil)).Elem(), BecomeArgs{})
+	pulumi.RegisterInputType(reflect.TypeOf((*BecomePtrInput)(nil)).Elem(), BecomeArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ConnectionInput)(nil)).Elem(), ConnectionArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ConnectionPtrInput)(nil)).Elem(), ConnectionArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ExecCommandInput)(nil)).Elem(), ExecCommandArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ExecCommandPtrInput)(nil)).Elem(), ExecCommandArgs{})
+	pulumi.RegisterInputType(reflect.TypeOf((*ProxyConnectionInput)(nil)).Elem(), ProxyConnectionArgs{})
+	pulumi.RegisterInputType(reflect.TypeOf((*ProxyConnectionArrayInput)(nil)).Elem(), ProxyConnectionArray{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ResourceConfigInput)(nil)).Elem(), ResourceConfigArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*ResourceConfigPtrInput)(nil)).Elem(), ResourceConfigArgs{})
+	pulumi.RegisterInputType(reflect.TypeOf((*RunAsInput)(nil)).Elem(), RunAsArgs{})
+	pulumi.RegisterInputType(reflect.TypeOf((*RunAsPtrInput)(nil)).Elem(), RunAsArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*TriggersInputInput)(nil)).Elem(), TriggersInputArgs{})
 	pulumi.RegisterInputType(reflect.TypeOf((*TriggersInputPtrInput)(nil)).Elem(), TriggersInputArgs{})
+	pulumi.RegisterOutputType(BecomeOutput{})
+	pulumi.RegisterOutputType(BecomePtrOutput{})
 	pulumi.RegisterOutputType(ConnectionOutput{})
 	pulumi.RegisterOutputType(ConnectionPtrOutput{})
 	pulumi.RegisterOutputType(ExecCommandOutput{})
@@ -1135,8 +2268,12 @@ func init() {
 	pulumi.RegisterOutputType(FileStatFileModeOutput{})
 	pulumi.RegisterOutputType(FileStatFileModePtrOutput{})
 	pulumi.RegisterOutputType(FileStatStateOutput{})
+	pulumi.RegisterOutputType(ProxyConnectionOutput{})
+	pulumi.RegisterOutputType(ProxyConnectionArrayOutput{})
 	pulumi.RegisterOutputType(ResourceConfigOutput{})
 	pulumi.RegisterOutputType(ResourceConfigPtrOutput{})
+	pulumi.RegisterOutputType(RunAsOutput{})
+	pulumi.RegisterOutputType(RunAsPtrOutput{})
 	pulumi.RegisterOutputType(TriggersInputOutput{})
 	pulumi.RegisterOutputType(TriggersInputPtrOutput{})
 	pulumi.RegisterOutputType(TriggersOutputOutput{})
@@ -18,6 +18,7 @@ type AnsibleTaskList struct {
 
 	Config     mid.ResourceConfigPtrOutput       `pulumi:"config"`
 	Connection mid.ConnectionPtrOutput           `pulumi:"connection"`
+	Logging    pulumi.StringPtrOutput            `pulumi:"logging"`
 	Results    AnsibleTaskListStateResultsOutput `pulumi:"results"`
 	Tasks      AnsibleTaskListArgsTasksOutput    `pulumi:"tasks"`
 	Triggers   mid.TriggersOutputOutput          `pulumi:"triggers"`
@@ -33,9 +34,6 @@ func NewAnsibleTaskList(ctx *pulumi.Context,
 	if args.Tasks == nil {
 		return nil, errors.New("invalid value for required argument 'Tasks'")
 	}
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource AnsibleTaskList
 	err := ctx.RegisterResource("mid:resource:AnsibleTaskList", name, args, &resource, opts...)
@@ -71,6 +69,7 @@ func (AnsibleTaskListState) ElementType() reflect.Type {
 type ansibleTaskListArgs struct {
 	Config     *mid.ResourceConfig      `pulumi:"config"`
 	Connection *mid.Connection          `pulumi:"connection"`
+	Logging    *string                  `pulumi:"logging"`
 	Tasks      AnsibleTaskListArgsTasks `pulumi:"tasks"`
 	Triggers   *mid.TriggersInput       `pulumi:"triggers"`
 }
@@ -79,6 +78,7 @@ type ansibleTaskListArgs struct {
 type AnsibleTaskListArgs struct {
 	Config     mid.ResourceConfigPtrInput
 	Connection mid.ConnectionPtrInput
+	Logging    pulumi.StringPtrInput
 	Tasks      AnsibleTaskListArgsTasksInput
 	Triggers   mid.TriggersInputPtrInput
 }
@@ -178,6 +178,10 @@ func (o AnsibleTaskListOutput) Connection() mid.ConnectionPtrOutput {
 	return o.ApplyT(func(v *AnsibleTaskList) mid.ConnectionPtrOutput { return v.Connection }).(mid.ConnectionPtrOutput)
 }
 
+func (o AnsibleTaskListOutput) Logging() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *AnsibleTaskList) pulumi.StringPtrOutput { return v.Logging }).(pulumi.StringPtrOutput)
+}
+
 func (o AnsibleTaskListOutput) Results() AnsibleTaskListStateResultsOutput {
 	return o.ApplyT(func(v *AnsibleTaskList) AnsibleTaskListStateResultsOutput { return v.Results }).(AnsibleTaskListStateResultsOutput)
 }
@@ -53,9 +53,6 @@ func NewApt(ctx *pulumi.Context,
 		args = &AptArgs{}
 	}
 
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource Apt
 	err := ctx.RegisterResource("mid:resource:Apt", name, args, &resource, opts...)
@@ -24,7 +24,9 @@ type Exec struct {
 	Dir                 pulumi.StringPtrOutput      `pulumi:"dir"`
 	Environment         pulumi.StringMapOutput      `pulumi:"environment"`
 	ExpandArgumentVars  pulumi.BoolPtrOutput        `pulumi:"expandArgumentVars"`
+	HostKey             pulumi.StringPtrOutput      `pulumi:"hostKey"`
 	Logging             pulumi.StringPtrOutput      `pulumi:"logging"`
+	RunAs               mid.RunAsPtrOutput          `pulumi:"runAs"`
 	Stderr              pulumi.StringOutput         `pulumi:"stderr"`
 	Stdout              pulumi.StringOutput         `pulumi:"stdout"`
 	Triggers            mid.TriggersOutputOutput    `pulumi:"triggers"`
@@ -41,9 +43,6 @@ func NewExec(ctx *pulumi.Context,
 	if args.Create == nil {
 		return nil, errors.New("invalid value for required argument 'Create'")
 	}
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource Exec
 	err := ctx.RegisterResource("mid:resource:Exec", name, args, &resource, opts...)
@@ -86,6 +85,7 @@ type execArgs struct {
 	Environment         map[string]string   `pulumi:"environment"`
 	ExpandArgumentVars  *bool               `pulumi:"expandArgumentVars"`
 	Logging             *string             `pulumi:"logging"`
+	RunAs               *mid.RunAs          `pulumi:"runAs"`
 	Triggers            *mid.TriggersInput  `pulumi:"triggers"`
 	Update              *mid.ExecCommand    `pulumi:"update"`
 }
@@ -101,6 +101,7 @@ type ExecArgs struct {
 	Environment         pulumi.StringMapInput
 	ExpandArgumentVars  pulumi.BoolPtrInput
 	Logging             pulumi.StringPtrInput
+	RunAs               mid.RunAsPtrInput
 	Triggers            mid.TriggersInputPtrInput
 	Update              mid.ExecCommandPtrInput
 }
@@ -224,10 +225,18 @@ func (o ExecOutput) ExpandArgumentVars() pulumi.BoolPtrOutput {
 	return o.ApplyT(func(v *Exec) pulumi.BoolPtrOutput { return v.ExpandArgumentVars }).(pulumi.BoolPtrOutput)
 }
 
+func (o ExecOutput) HostKey() pulumi.StringPtrOutput {
+	return o.ApplyT(func(v *Exec) pulumi.StringPtrOutput { return v.HostKey }).(pulumi.StringPtrOutput)
+}
+
 func (o ExecOutput) Logging() pulumi.StringPtrOutput {
 	return o.ApplyT(func(v *Exec) pulumi.StringPtrOutput { return v.Logging }).(pulumi.StringPtrOutput)
 }
 
+func (o ExecOutput) RunAs() mid.RunAsPtrOutput {
+	return o.ApplyT(func(v *Exec) mid.RunAsPtrOutput { return v.RunAs }).(mid.RunAsPtrOutput)
+}
+
 func (o ExecOutput) Stderr() pulumi.StringOutput {
 	return o.ApplyT(func(v *Exec) pulumi.StringOutput { return v.Stderr }).(pulumi.StringOutput)
 }
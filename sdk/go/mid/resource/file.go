@@ -60,9 +60,6 @@ func NewFile(ctx *pulumi.Context,
 	if args.Path == nil {
 		return nil, errors.New("invalid value for required argument 'Path'")
 	}
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	replaceOnChanges := pulumi.ReplaceOnChanges([]string{
 		"path",
 	})
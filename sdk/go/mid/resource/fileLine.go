@@ -45,9 +45,6 @@ func NewFileLine(ctx *pulumi.Context,
 	if args.Path == nil {
 		return nil, errors.New("invalid value for required argument 'Path'")
 	}
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource FileLine
 	err := ctx.RegisterResource("mid:resource:FileLine", name, args, &resource, opts...)
@@ -30,9 +30,6 @@ func NewPackage(ctx *pulumi.Context,
 		args = &PackageArgs{}
 	}
 
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource Package
 	err := ctx.RegisterResource("mid:resource:Package", name, args, &resource, opts...)
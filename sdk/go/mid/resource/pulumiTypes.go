@@ -8,6 +8,7 @@ import (
 	"reflect"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+	"github.com/sapslaj/mid/sdk/go/mid"
 	"github.com/sapslaj/mid/sdk/go/mid/internal"
 )
 
@@ -19,6 +20,8 @@ type AnsibleTaskListArgsTask struct {
 	Environment  map[string]string      `pulumi:"environment"`
 	IgnoreErrors *bool                  `pulumi:"ignoreErrors"`
 	Module       string                 `pulumi:"module"`
+	RunAs        *mid.RunAs             `pulumi:"runAs"`
+	Timeout      *int                   `pulumi:"timeout"`
 }
 
 // AnsibleTaskListArgsTaskInput is an input type that accepts AnsibleTaskListArgsTaskArgs and AnsibleTaskListArgsTaskOutput values.
@@ -38,6 +41,8 @@ type AnsibleTaskListArgsTaskArgs struct {
 	Environment  pulumi.StringMapInput `pulumi:"environment"`
 	IgnoreErrors pulumi.BoolPtrInput   `pulumi:"ignoreErrors"`
 	Module       pulumi.StringInput    `pulumi:"module"`
+	RunAs        mid.RunAsPtrInput     `pulumi:"runAs"`
+	Timeout      pulumi.IntPtrInput    `pulumi:"timeout"`
 }
 
 func (AnsibleTaskListArgsTaskArgs) ElementType() reflect.Type {
@@ -111,6 +116,14 @@ func (o AnsibleTaskListArgsTaskOutput) Module() pulumi.StringOutput {
 	return o.ApplyT(func(v AnsibleTaskListArgsTask) string { return v.Module }).(pulumi.StringOutput)
 }
 
+func (o AnsibleTaskListArgsTaskOutput) RunAs() mid.RunAsPtrOutput {
+	return o.ApplyT(func(v AnsibleTaskListArgsTask) *mid.RunAs { return v.RunAs }).(mid.RunAsPtrOutput)
+}
+
+func (o AnsibleTaskListArgsTaskOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v AnsibleTaskListArgsTask) *int { return v.Timeout }).(pulumi.IntPtrOutput)
+}
+
 type AnsibleTaskListArgsTaskArrayOutput struct{ *pulumi.OutputState }
 
 func (AnsibleTaskListArgsTaskArrayOutput) ElementType() reflect.Type {
@@ -227,9 +240,11 @@ type AnsibleTaskListStateTaskResult struct {
 	IgnoreErrors *bool                  `pulumi:"ignoreErrors"`
 	Module       string                 `pulumi:"module"`
 	Result       map[string]interface{} `pulumi:"result"`
+	RunAs        *mid.RunAs             `pulumi:"runAs"`
 	Stderr       string                 `pulumi:"stderr"`
 	Stdout       string                 `pulumi:"stdout"`
 	Success      bool                   `pulumi:"success"`
+	Timeout      *int                   `pulumi:"timeout"`
 }
 
 type AnsibleTaskListStateTaskResultOutput struct{ *pulumi.OutputState }
@@ -274,6 +289,10 @@ func (o AnsibleTaskListStateTaskResultOutput) Result() pulumi.MapOutput {
 	return o.ApplyT(func(v AnsibleTaskListStateTaskResult) map[string]interface{} { return v.Result }).(pulumi.MapOutput)
 }
 
+func (o AnsibleTaskListStateTaskResultOutput) RunAs() mid.RunAsPtrOutput {
+	return o.ApplyT(func(v AnsibleTaskListStateTaskResult) *mid.RunAs { return v.RunAs }).(mid.RunAsPtrOutput)
+}
+
 func (o AnsibleTaskListStateTaskResultOutput) Stderr() pulumi.StringOutput {
 	return o.ApplyT(func(v AnsibleTaskListStateTaskResult) string { return v.Stderr }).(pulumi.StringOutput)
 }
@@ -286,6 +305,10 @@ func (o AnsibleTaskListStateTaskResultOutput) Success() pulumi.BoolOutput {
 	return o.ApplyT(func(v AnsibleTaskListStateTaskResult) bool { return v.Success }).(pulumi.BoolOutput)
 }
 
+func (o AnsibleTaskListStateTaskResultOutput) Timeout() pulumi.IntPtrOutput {
+	return o.ApplyT(func(v AnsibleTaskListStateTaskResult) *int { return v.Timeout }).(pulumi.IntPtrOutput)
+}
+
 type AnsibleTaskListStateTaskResultArrayOutput struct{ *pulumi.OutputState }
 
 func (AnsibleTaskListStateTaskResultArrayOutput) ElementType() reflect.Type {
@@ -37,9 +37,6 @@ func NewSystemdService(ctx *pulumi.Context,
 		args = &SystemdServiceArgs{}
 	}
 
-	if args.Connection != nil {
-		args.Connection = args.Connection.ToConnectionPtrOutput().ApplyT(func(v *mid.Connection) *mid.Connection { return v.Defaults() }).(mid.ConnectionPtrOutput)
-	}
 	opts = internal.PkgResourceDefaultOpts(opts)
 	var resource SystemdService
 	err := ctx.RegisterResource("mid:resource:SystemdService", name, args, &resource, opts...)
@@ -11,7 +11,7 @@ export function agentPing(args?: AgentPingArgs, opts?: pulumi.InvokeOptions): Pr
   opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts || {});
   return pulumi.runtime.invoke("mid:agent:agentPing", {
     "config": args.config,
-    "connection": args.connection ? inputs.connectionProvideDefaults(args.connection) : undefined,
+    "connection": args.connection,
     "ping": args.ping,
   }, opts);
 }
@@ -23,8 +23,14 @@ export interface AgentPingArgs {
 }
 
 export interface AgentPingResult {
+  readonly agentVersion: string;
+  readonly ansibleInstallCached?: boolean;
+  readonly ansibleInstallSeconds?: number;
+  readonly hostKey?: string;
   readonly ping: string;
   readonly pong: string;
+  readonly protocolVersion: number;
+  readonly rpcFunctions: string[];
 }
 export function agentPingOutput(
   args?: AgentPingOutputArgs,
@@ -34,9 +40,7 @@ export function agentPingOutput(
   opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts || {});
   return pulumi.runtime.invokeOutput("mid:agent:agentPing", {
     "config": args.config,
-    "connection": pulumi.output(args.connection).apply(v =>
-      v === undefined ? undefined : inputs.connectionProvideDefaults(v)
-    ),
+    "connection": args.connection,
     "ping": args.ping,
   }, opts);
 }
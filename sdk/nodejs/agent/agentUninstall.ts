@@ -0,0 +1,42 @@
+// *** WARNING: this file was generated by pulumi-language-nodejs. ***
+// *** Do not edit by hand unless you're certain you know what you are doing! ***
+
+import * as pulumi from "@pulumi/pulumi";
+import * as inputs from "../types/input";
+import * as outputs from "../types/output";
+import * as utilities from "../utilities";
+
+export function agentUninstall(args?: AgentUninstallArgs, opts?: pulumi.InvokeOptions): Promise<AgentUninstallResult> {
+  args = args || {};
+  opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts || {});
+  return pulumi.runtime.invoke("mid:agent:agentUninstall", {
+    "config": args.config,
+    "connection": args.connection,
+  }, opts);
+}
+
+export interface AgentUninstallArgs {
+  config?: inputs.ResourceConfig;
+  connection?: inputs.Connection;
+}
+
+export interface AgentUninstallResult {
+  readonly agentDir: string;
+  readonly removed: boolean;
+}
+export function agentUninstallOutput(
+  args?: AgentUninstallOutputArgs,
+  opts?: pulumi.InvokeOutputOptions,
+): pulumi.Output<AgentUninstallResult> {
+  args = args || {};
+  opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts || {});
+  return pulumi.runtime.invokeOutput("mid:agent:agentUninstall", {
+    "config": args.config,
+    "connection": args.connection,
+  }, opts);
+}
+
+export interface AgentUninstallOutputArgs {
+  config?: pulumi.Input<inputs.ResourceConfigArgs | undefined>;
+  connection?: pulumi.Input<inputs.ConnectionArgs | undefined>;
+}
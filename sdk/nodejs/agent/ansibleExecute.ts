@@ -12,10 +12,12 @@ export function ansibleExecute(args: AnsibleExecuteArgs, opts?: pulumi.InvokeOpt
     "args": args.args,
     "check": args.check,
     "config": args.config,
-    "connection": args.connection ? inputs.connectionProvideDefaults(args.connection) : undefined,
+    "connection": args.connection,
     "debugKeepTempFiles": args.debugKeepTempFiles,
     "environment": args.environment,
     "name": args.name,
+    "runAs": args.runAs,
+    "timeout": args.timeout,
   }, opts);
 }
 
@@ -27,6 +29,8 @@ export interface AnsibleExecuteArgs {
   debugKeepTempFiles?: boolean;
   environment?: { [key: string]: string };
   name: string;
+  runAs?: inputs.RunAs;
+  timeout?: number;
 }
 
 export interface AnsibleExecuteResult {
@@ -39,9 +43,12 @@ export interface AnsibleExecuteResult {
   readonly environment?: { [key: string]: string };
   readonly exitCode: number;
   readonly name: string;
+  readonly pythonInterpreter?: string;
   readonly result: { [key: string]: any };
+  readonly runAs?: outputs.RunAs;
   readonly stderr: string;
   readonly stdout: string;
+  readonly timeout?: number;
 }
 export function ansibleExecuteOutput(
   args: AnsibleExecuteOutputArgs,
@@ -52,12 +59,12 @@ export function ansibleExecuteOutput(
     "args": args.args,
     "check": args.check,
     "config": args.config,
-    "connection": pulumi.output(args.connection).apply(v =>
-      v === undefined ? undefined : inputs.connectionProvideDefaults(v)
-    ),
+    "connection": args.connection,
     "debugKeepTempFiles": args.debugKeepTempFiles,
     "environment": args.environment,
     "name": args.name,
+    "runAs": args.runAs,
+    "timeout": args.timeout,
   }, opts);
 }
 
@@ -69,4 +76,6 @@ export interface AnsibleExecuteOutputArgs {
   debugKeepTempFiles?: pulumi.Input<boolean | undefined>;
   environment?: pulumi.Input<{ [key: string]: pulumi.Input<string> } | undefined>;
   name: pulumi.Input<string>;
+  runAs?: pulumi.Input<inputs.RunAsArgs | undefined>;
+  timeout?: pulumi.Input<number | undefined>;
 }
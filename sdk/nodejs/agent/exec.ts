@@ -11,11 +11,13 @@ export function exec(args: ExecArgs, opts?: pulumi.InvokeOptions): Promise<ExecR
   return pulumi.runtime.invoke("mid:agent:exec", {
     "command": args.command,
     "config": args.config,
-    "connection": args.connection ? inputs.connectionProvideDefaults(args.connection) : undefined,
+    "connection": args.connection,
     "dir": args.dir,
     "environment": args.environment,
     "expandArgumentVars": args.expandArgumentVars,
+    "runAs": args.runAs,
     "stdin": args.stdin,
+    "timeout": args.timeout,
   }, opts);
 }
 
@@ -26,7 +28,9 @@ export interface ExecArgs {
   dir?: string;
   environment?: { [key: string]: string };
   expandArgumentVars?: boolean;
+  runAs?: inputs.RunAs;
   stdin?: string;
+  timeout?: number;
 }
 
 export interface ExecResult {
@@ -38,22 +42,24 @@ export interface ExecResult {
   readonly exitCode: number;
   readonly expandArgumentVars?: boolean;
   readonly pid: number;
+  readonly runAs?: outputs.RunAs;
   readonly stderr: string;
   readonly stdin?: string;
   readonly stdout: string;
+  readonly timeout?: number;
 }
 export function execOutput(args: ExecOutputArgs, opts?: pulumi.InvokeOutputOptions): pulumi.Output<ExecResult> {
   opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts || {});
   return pulumi.runtime.invokeOutput("mid:agent:exec", {
     "command": args.command,
     "config": args.config,
-    "connection": pulumi.output(args.connection).apply(v =>
-      v === undefined ? undefined : inputs.connectionProvideDefaults(v)
-    ),
+    "connection": args.connection,
     "dir": args.dir,
     "environment": args.environment,
     "expandArgumentVars": args.expandArgumentVars,
+    "runAs": args.runAs,
     "stdin": args.stdin,
+    "timeout": args.timeout,
   }, opts);
 }
 
@@ -64,5 +70,7 @@ export interface ExecOutputArgs {
   dir?: pulumi.Input<string | undefined>;
   environment?: pulumi.Input<{ [key: string]: pulumi.Input<string> } | undefined>;
   expandArgumentVars?: pulumi.Input<boolean | undefined>;
+  runAs?: pulumi.Input<inputs.RunAsArgs | undefined>;
   stdin?: pulumi.Input<string | undefined>;
+  timeout?: pulumi.Input<number | undefined>;
 }
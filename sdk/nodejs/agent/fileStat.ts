@@ -11,7 +11,7 @@ export function fileStat(args: FileStatArgs, opts?: pulumi.InvokeOptions): Promi
   return pulumi.runtime.invoke("mid:agent:fileStat", {
     "calculateChecksum": args.calculateChecksum,
     "config": args.config,
-    "connection": args.connection ? inputs.connectionProvideDefaults(args.connection) : undefined,
+    "connection": args.connection,
     "followSymlinks": args.followSymlinks,
     "path": args.path,
   }, opts);
@@ -55,9 +55,7 @@ export function fileStatOutput(
   return pulumi.runtime.invokeOutput("mid:agent:fileStat", {
     "calculateChecksum": args.calculateChecksum,
     "config": args.config,
-    "connection": pulumi.output(args.connection).apply(v =>
-      v === undefined ? undefined : inputs.connectionProvideDefaults(v)
-    ),
+    "connection": args.connection,
     "followSymlinks": args.followSymlinks,
     "path": args.path,
   }, opts);
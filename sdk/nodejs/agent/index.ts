@@ -9,6 +9,11 @@ export const agentPing: typeof import("./agentPing").agentPing = null as any;
 export const agentPingOutput: typeof import("./agentPing").agentPingOutput = null as any;
 utilities.lazyLoad(exports, ["agentPing", "agentPingOutput"], () => require("./agentPing"));
 
+export { AgentUninstallArgs, AgentUninstallOutputArgs, AgentUninstallResult } from "./agentUninstall";
+export const agentUninstall: typeof import("./agentUninstall").agentUninstall = null as any;
+export const agentUninstallOutput: typeof import("./agentUninstall").agentUninstallOutput = null as any;
+utilities.lazyLoad(exports, ["agentUninstall", "agentUninstallOutput"], () => require("./agentUninstall"));
+
 export { AnsibleExecuteArgs, AnsibleExecuteOutputArgs, AnsibleExecuteResult } from "./ansibleExecute";
 export const ansibleExecute: typeof import("./ansibleExecute").ansibleExecute = null as any;
 export const ansibleExecuteOutput: typeof import("./ansibleExecute").ansibleExecuteOutput = null as any;
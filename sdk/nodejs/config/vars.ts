@@ -9,6 +9,14 @@ import * as utilities from "../utilities";
 declare var exports: any;
 const __config = new pulumi.Config("mid");
 
+export declare const agentGCDays: number | undefined;
+Object.defineProperty(exports, "agentGCDays", {
+  get() {
+    return __config.getObject<number>("agentGCDays");
+  },
+  enumerable: true,
+});
+
 export declare const check: boolean | undefined;
 Object.defineProperty(exports, "check", {
   get() {
@@ -33,6 +41,22 @@ Object.defineProperty(exports, "deleteUnreachable", {
   enumerable: true,
 });
 
+export declare const heartbeatInterval: number | undefined;
+Object.defineProperty(exports, "heartbeatInterval", {
+  get() {
+    return __config.getObject<number>("heartbeatInterval");
+  },
+  enumerable: true,
+});
+
+export declare const heartbeatTimeout: number | undefined;
+Object.defineProperty(exports, "heartbeatTimeout", {
+  get() {
+    return __config.getObject<number>("heartbeatTimeout");
+  },
+  enumerable: true,
+});
+
 export declare const parallel: number | undefined;
 Object.defineProperty(exports, "parallel", {
   get() {
@@ -40,3 +64,18 @@ Object.defineProperty(exports, "parallel", {
   },
   enumerable: true,
 });
+
+/**
+ * The Python interpreter used to run Ansible
+ * modules on the remote host, either a path or a command name to look up in PATH.
+ * It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+ * /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+ * installed. If not set, the agent picks the first suitable interpreter it finds.
+ */
+export declare const pythonInterpreter: string | undefined;
+Object.defineProperty(exports, "pythonInterpreter", {
+  get() {
+    return __config.get("pythonInterpreter");
+  },
+  enumerable: true,
+});
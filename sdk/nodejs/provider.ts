@@ -21,6 +21,15 @@ export class Provider extends pulumi.ProviderResource {
     return obj["__pulumiType"] === "pulumi:providers:" + Provider.__pulumiType;
   }
 
+  /**
+   * The Python interpreter used to run Ansible
+   * modules on the remote host, either a path or a command name to look up in PATH.
+   * It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+   * /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+   * installed. If not set, the agent picks the first suitable interpreter it finds.
+   */
+  declare public readonly pythonInterpreter: pulumi.Output<string | undefined>;
+
   /**
    * Create a Provider resource with the given unique name, arguments, and options.
    *
@@ -32,18 +41,16 @@ export class Provider extends pulumi.ProviderResource {
     let resourceInputs: pulumi.Inputs = {};
     opts = opts || {};
     {
+      resourceInputs["agentGCDays"] = pulumi.output(args?.agentGCDays).apply(JSON.stringify);
       resourceInputs["check"] = pulumi.output(args?.check).apply(JSON.stringify);
       resourceInputs["connection"] = pulumi.output(
-        args?.connection
-          ? pulumi.secret(
-            pulumi.output(args.connection).apply(v =>
-              v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-            ),
-          )
-          : undefined,
+        args?.connection ? pulumi.secret(args.connection) : undefined,
       ).apply(JSON.stringify);
       resourceInputs["deleteUnreachable"] = pulumi.output(args?.deleteUnreachable).apply(JSON.stringify);
+      resourceInputs["heartbeatInterval"] = pulumi.output(args?.heartbeatInterval).apply(JSON.stringify);
+      resourceInputs["heartbeatTimeout"] = pulumi.output(args?.heartbeatTimeout).apply(JSON.stringify);
       resourceInputs["parallel"] = pulumi.output(args?.parallel).apply(JSON.stringify);
+      resourceInputs["pythonInterpreter"] = args?.pythonInterpreter;
     }
     opts = pulumi.mergeOptions(utilities.resourceOptsDefaults(), opts);
     super(Provider.__pulumiType, name, resourceInputs, opts);
@@ -54,8 +61,19 @@ export class Provider extends pulumi.ProviderResource {
  * The set of arguments for constructing a Provider resource.
  */
 export interface ProviderArgs {
+  agentGCDays?: pulumi.Input<number | undefined>;
   check?: pulumi.Input<boolean | undefined>;
   connection?: pulumi.Input<inputs.ConnectionArgs | undefined>;
   deleteUnreachable?: pulumi.Input<boolean | undefined>;
+  heartbeatInterval?: pulumi.Input<number | undefined>;
+  heartbeatTimeout?: pulumi.Input<number | undefined>;
   parallel?: pulumi.Input<number | undefined>;
+  /**
+   * The Python interpreter used to run Ansible
+   * modules on the remote host, either a path or a command name to look up in PATH.
+   * It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+   * /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+   * installed. If not set, the agent picks the first suitable interpreter it finds.
+   */
+  pythonInterpreter?: pulumi.Input<string | undefined>;
 }
@@ -35,6 +35,7 @@ export class AnsibleTaskList extends pulumi.CustomResource {
 
   declare public readonly config: pulumi.Output<outputs.ResourceConfig | undefined>;
   declare public readonly connection: pulumi.Output<outputs.Connection | undefined>;
+  declare public readonly logging: pulumi.Output<string | undefined>;
   declare public readonly /*out*/ results: pulumi.Output<outputs.resource.AnsibleTaskListStateResults>;
   declare public readonly tasks: pulumi.Output<outputs.resource.AnsibleTaskListArgsTasks>;
   declare public readonly triggers: pulumi.Output<outputs.TriggersOutput>;
@@ -54,17 +55,15 @@ export class AnsibleTaskList extends pulumi.CustomResource {
         throw new Error("Missing required property 'tasks'");
       }
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
+      resourceInputs["logging"] = args?.logging;
       resourceInputs["tasks"] = args?.tasks;
       resourceInputs["triggers"] = args?.triggers;
       resourceInputs["results"] = undefined /*out*/;
     } else {
       resourceInputs["config"] = undefined /*out*/;
       resourceInputs["connection"] = undefined /*out*/;
+      resourceInputs["logging"] = undefined /*out*/;
       resourceInputs["results"] = undefined /*out*/;
       resourceInputs["tasks"] = undefined /*out*/;
       resourceInputs["triggers"] = undefined /*out*/;
@@ -80,6 +79,7 @@ export class AnsibleTaskList extends pulumi.CustomResource {
 export interface AnsibleTaskListArgs {
   config?: pulumi.Input<inputs.ResourceConfigArgs | undefined>;
   connection?: pulumi.Input<inputs.ConnectionArgs | undefined>;
+  logging?: pulumi.Input<string | undefined>;
   tasks: pulumi.Input<inputs.resource.AnsibleTaskListArgsTasksArgs>;
   triggers?: pulumi.Input<inputs.TriggersInputArgs | undefined>;
 }
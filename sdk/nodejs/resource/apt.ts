@@ -82,11 +82,7 @@ export class Apt extends pulumi.CustomResource {
       resourceInputs["cacheValidTime"] = args?.cacheValidTime;
       resourceInputs["clean"] = args?.clean;
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["deb"] = args?.deb;
       resourceInputs["defaultRelease"] = args?.defaultRelease;
       resourceInputs["dpkgOptions"] = args?.dpkgOptions;
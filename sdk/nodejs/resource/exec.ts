@@ -41,7 +41,9 @@ export class Exec extends pulumi.CustomResource {
   declare public readonly dir: pulumi.Output<string | undefined>;
   declare public readonly environment: pulumi.Output<{ [key: string]: string } | undefined>;
   declare public readonly expandArgumentVars: pulumi.Output<boolean | undefined>;
+  declare public readonly /*out*/ hostKey: pulumi.Output<string | undefined>;
   declare public readonly logging: pulumi.Output<string | undefined>;
+  declare public readonly runAs: pulumi.Output<outputs.RunAs | undefined>;
   declare public readonly /*out*/ stderr: pulumi.Output<string>;
   declare public readonly /*out*/ stdout: pulumi.Output<string>;
   declare public readonly triggers: pulumi.Output<outputs.TriggersOutput>;
@@ -62,11 +64,7 @@ export class Exec extends pulumi.CustomResource {
         throw new Error("Missing required property 'create'");
       }
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["create"] = args?.create;
       resourceInputs["delete"] = args?.delete;
       resourceInputs["deleteBeforeReplace"] = args?.deleteBeforeReplace;
@@ -74,8 +72,10 @@ export class Exec extends pulumi.CustomResource {
       resourceInputs["environment"] = args?.environment;
       resourceInputs["expandArgumentVars"] = args?.expandArgumentVars;
       resourceInputs["logging"] = args?.logging;
+      resourceInputs["runAs"] = args?.runAs;
       resourceInputs["triggers"] = args?.triggers;
       resourceInputs["update"] = args?.update;
+      resourceInputs["hostKey"] = undefined /*out*/;
       resourceInputs["stderr"] = undefined /*out*/;
       resourceInputs["stdout"] = undefined /*out*/;
     } else {
@@ -87,7 +87,9 @@ export class Exec extends pulumi.CustomResource {
       resourceInputs["dir"] = undefined /*out*/;
       resourceInputs["environment"] = undefined /*out*/;
       resourceInputs["expandArgumentVars"] = undefined /*out*/;
+      resourceInputs["hostKey"] = undefined /*out*/;
       resourceInputs["logging"] = undefined /*out*/;
+      resourceInputs["runAs"] = undefined /*out*/;
       resourceInputs["stderr"] = undefined /*out*/;
       resourceInputs["stdout"] = undefined /*out*/;
       resourceInputs["triggers"] = undefined /*out*/;
@@ -111,6 +113,7 @@ export interface ExecArgs {
   environment?: pulumi.Input<{ [key: string]: pulumi.Input<string> } | undefined>;
   expandArgumentVars?: pulumi.Input<boolean | undefined>;
   logging?: pulumi.Input<string | undefined>;
+  runAs?: pulumi.Input<inputs.RunAsArgs | undefined>;
   triggers?: pulumi.Input<inputs.TriggersInputArgs | undefined>;
   update?: pulumi.Input<inputs.ExecCommandArgs | undefined>;
 }
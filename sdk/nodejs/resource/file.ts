@@ -86,11 +86,7 @@ export class File extends pulumi.CustomResource {
       resourceInputs["backup"] = args?.backup;
       resourceInputs["checksum"] = args?.checksum;
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["content"] = args?.content;
       resourceInputs["directoryMode"] = args?.directoryMode;
       resourceInputs["ensure"] = args?.ensure;
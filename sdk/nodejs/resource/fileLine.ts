@@ -68,11 +68,7 @@ export class FileLine extends pulumi.CustomResource {
       resourceInputs["backrefs"] = args?.backrefs;
       resourceInputs["backup"] = args?.backup;
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["create"] = args?.create;
       resourceInputs["ensure"] = args?.ensure;
       resourceInputs["firstMatch"] = args?.firstMatch;
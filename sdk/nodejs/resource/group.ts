@@ -61,11 +61,7 @@ export class Group extends pulumi.CustomResource {
         throw new Error("Missing required property 'name'");
       }
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["ensure"] = args?.ensure;
       resourceInputs["force"] = args?.force;
       resourceInputs["gid"] = args?.gid;
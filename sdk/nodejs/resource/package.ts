@@ -52,11 +52,7 @@ export class Package extends pulumi.CustomResource {
     opts = opts || {};
     if (!opts.id) {
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["ensure"] = args?.ensure;
       resourceInputs["name"] = args?.name;
       resourceInputs["names"] = args?.names;
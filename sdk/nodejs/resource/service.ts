@@ -61,11 +61,7 @@ export class Service extends pulumi.CustomResource {
       }
       resourceInputs["arguments"] = args?.arguments;
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["enabled"] = args?.enabled;
       resourceInputs["name"] = args?.name;
       resourceInputs["pattern"] = args?.pattern;
@@ -59,11 +59,7 @@ export class SystemdService extends pulumi.CustomResource {
     opts = opts || {};
     if (!opts.id) {
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["daemonReexec"] = args?.daemonReexec;
       resourceInputs["daemonReload"] = args?.daemonReload;
       resourceInputs["enabled"] = args?.enabled;
@@ -73,11 +73,7 @@ export class User extends pulumi.CustomResource {
       }
       resourceInputs["comment"] = args?.comment;
       resourceInputs["config"] = args?.config;
-      resourceInputs["connection"] = args
-        ? pulumi.output(args.connection).apply(v =>
-          v === undefined ? undefined : inputs.connectionArgsProvideDefaults(v)
-        )
-        : undefined;
+      resourceInputs["connection"] = args?.connection;
       resourceInputs["ensure"] = args?.ensure;
       resourceInputs["force"] = args?.force;
       resourceInputs["group"] = args?.group;
@@ -7,15 +7,96 @@ import * as outputs from "../types/output";
 
 import * as utilities from "../utilities";
 
+/**
+ * Instructions for how the agent gets the privileges of another user.
+ */
+export interface Become {
+  /**
+   * Extra flags passed to the become method.
+   */
+  flags?: string[];
+  /**
+   * How to switch users. One of "auto", "sudo", "doas", "su",
+   * or "none". "auto" uses sudo unless the connection user already is the become
+   * user, and "none" runs the agent as the connection user. Defaults to "auto".
+   */
+  method?: string;
+  /**
+   * The password to give the become method when it asks
+   * for one. It is only ever written to the terminal of the become command.
+   */
+  password?: string;
+  /**
+   * The user the agent runs as. Defaults to "root".
+   */
+  user?: string;
+}
+
 /**
  * Instructions for how to connect to a remote endpoint.
  */
 export interface Connection {
+  /**
+   * The directory the agent, its staging area, and the
+   * Ansible modules are installed in on the remote host. Relative paths are
+   * relative to the home directory of the user and can't leave it. Since the
+   * directory is removed when uninstalling the agent, absolute paths need at least
+   * two levels (e.g. "/opt/mid"). Defaults to ".mid".
+   */
+  agentDir?: string;
+  /**
+   * How the agent gets root (or another user's)
+   * privileges. Each setting given for a resource overrides the same setting of the
+   * provider.
+   */
+  become?: outputs.Become;
+  /**
+   * The contents of an OpenSSH certificate (usually a
+   * "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+   * key. It is for privateKey if that is set, and otherwise for the key of the same
+   * name in the SSH agent. Certificates held by the SSH agent itself are used
+   * without setting this.
+   */
+  certificate?: string;
   /**
    * The address of the resource to connect to.
    */
   host?: string;
+  /**
+   * The public keys, in authorized_keys format, of the
+   * CAs that sign host certificates. A host presenting a certificate signed by one
+   * of them is trusted if the certificate is valid and names the host as a
+   * principal.
+   */
+  hostCAKeys?: string[];
+  /**
+   * The public key the host is expected to have, in
+   * authorized_keys format.
+   */
   hostKey?: string;
+  /**
+   * How the host's key is checked. "strict" only
+   * connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+   * hostCAKeys.
+   * "tofu" (trust on first use) does the same for hosts that are listed there, but
+   * connects to any other host. The key it presents is the hostKey output of Exec
+   * and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+   * to any host. Defaults to "strict" if any of hostKey, knownHosts,
+   * knownHostsFile, or hostCAKeys are set and "off" otherwise.
+   */
+  hostKeyChecking?: string;
+  /**
+   * The contents of an OpenSSH known_hosts file to
+   * check the host's key against. Hashed hostnames, "@cert-authority", and
+   * "@revoked" lines are supported.
+   */
+  knownHosts?: string;
+  /**
+   * The path of an OpenSSH known_hosts file on the
+   * machine running Pulumi to check the host's key against, e.g.
+   * "~/.ssh/known_hosts".
+   */
+  knownHostsFile?: string;
   /**
    * The password we should use for the connection.
    */
@@ -31,23 +112,30 @@ export interface Connection {
    */
   privateKey?: string;
   privateKeyPassword?: string;
+  /**
+   * Proxy (or bastion) hosts to connect through, in order.
+   * The first proxy is connected to directly, each following one through the one
+   * before it, and the host itself through the last one. Connections to proxies are
+   * shared between all hosts that go through them.
+   */
+  proxies?: outputs.ProxyConnection[];
   sshAgent?: boolean;
   sshAgentSocketPath?: string;
+  /**
+   * The path of an OpenSSH client config file on the
+   * machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+   * HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+   * for the host are used for anything the connection doesn't set itself. Include
+   * and "Match host" are supported. Defaults to not reading any config file.
+   */
+  sshConfig?: string;
   /**
    * The user that we should use for the connection.
+   * Defaults to the User set for the host in sshConfig, or else the current local
+   * user.
    */
   user?: string;
 }
-/**
- * connectionProvideDefaults sets the appropriate defaults for Connection
- */
-export function connectionProvideDefaults(val: Connection): Connection {
-  return {
-    ...val,
-    port: (val.port) ?? 22,
-    user: (val.user) ?? "root",
-  };
-}
 
 export interface ExecCommand {
   /**
@@ -69,6 +157,12 @@ export interface ExecCommand {
    * Pass a string to the command's process as standard in.
    */
   stdin?: string;
+  /**
+   * Maximum number of seconds the command is allowed to run. When exceeded, the
+   * command's process group is sent SIGTERM, followed by SIGKILL if it has not
+   * exited 10 seconds later. Defaults to no timeout.
+   */
+  timeout?: number;
 }
 
 export interface FileStatFileMode {
@@ -97,10 +191,135 @@ export interface FileStatState {
   userName?: string;
 }
 
+/**
+ * Instructions for how to connect to a proxy (or bastion) host.
+ */
+export interface ProxyConnection {
+  /**
+   * The contents of an OpenSSH certificate (usually a
+   * "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+   * key. It is for privateKey if that is set, and otherwise for the key of the same
+   * name in the SSH agent. Certificates held by the SSH agent itself are used
+   * without setting this.
+   */
+  certificate?: string;
+  /**
+   * The address of the proxy to connect to.
+   */
+  host?: string;
+  /**
+   * The public keys, in authorized_keys format, of the
+   * CAs that sign host certificates. A host presenting a certificate signed by one
+   * of them is trusted if the certificate is valid and names the host as a
+   * principal.
+   */
+  hostCAKeys?: string[];
+  /**
+   * The public key the host is expected to have, in
+   * authorized_keys format.
+   */
+  hostKey?: string;
+  /**
+   * How the host's key is checked. "strict" only
+   * connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+   * hostCAKeys.
+   * "tofu" (trust on first use) does the same for hosts that are listed there, but
+   * connects to any other host. The key it presents is the hostKey output of Exec
+   * and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+   * to any host. Defaults to "strict" if any of hostKey, knownHosts,
+   * knownHostsFile, or hostCAKeys are set and "off" otherwise.
+   */
+  hostKeyChecking?: string;
+  /**
+   * The contents of an OpenSSH known_hosts file to
+   * check the host's key against. Hashed hostnames, "@cert-authority", and
+   * "@revoked" lines are supported.
+   */
+  knownHosts?: string;
+  /**
+   * The path of an OpenSSH known_hosts file on the
+   * machine running Pulumi to check the host's key against, e.g.
+   * "~/.ssh/known_hosts".
+   */
+  knownHostsFile?: string;
+  /**
+   * The password we should use for the connection to the proxy.
+   */
+  password?: string;
+  perDialTimeout?: number;
+  /**
+   * The port to connect to. Defaults to 22.
+   */
+  port?: number;
+  /**
+   * The contents of an SSH key to use for the
+   * connection to the proxy. This takes preference over the password if provided.
+   */
+  privateKey?: string;
+  privateKeyPassword?: string;
+  sshAgent?: boolean;
+  sshAgentSocketPath?: string;
+  /**
+   * The user that we should use for the connection to the
+   * proxy. Defaults to the current local user.
+   */
+  user?: string;
+}
+/**
+ * proxyConnectionProvideDefaults sets the appropriate defaults for ProxyConnection
+ */
+export function proxyConnectionProvideDefaults(val: ProxyConnection): ProxyConnection {
+  return {
+    ...val,
+    port: (val.port) ?? 22,
+  };
+}
+
 export interface ResourceConfig {
+  agentGCDays?: number;
   check?: boolean;
   deleteUnreachable?: boolean;
+  heartbeatInterval?: number;
+  heartbeatTimeout?: number;
   parallel?: number;
+  /**
+   * The Python interpreter used to run Ansible
+   * modules on the remote host, either a path or a command name to look up in PATH.
+   * It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+   * /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+   * installed. If not set, the agent picks the first suitable interpreter it finds.
+   */
+  pythonInterpreter?: string;
+}
+
+/**
+ * The user and groups to run as instead of the user the agent
+ * runs as. Switching users requires the agent to run as root.
+ */
+export interface RunAs {
+  /**
+   * The name or numeric ID of the primary group. Defaults to
+   * the primary group of the user.
+   */
+  group?: string;
+  /**
+   * The names or numeric IDs of the supplementary groups.
+   * Defaults to the groups the user is a member of.
+   */
+  groups?: string[];
+  /**
+   * Start with the environment a login of the user would
+   * get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+   * if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+   * DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+   * unless another directory is given. Defaults to false.
+   */
+  loginEnv?: boolean;
+  /**
+   * The name or numeric ID of the user to run as. Defaults to
+   * the agent's user.
+   */
+  user?: string;
 }
 
 export interface TriggersOutput {
@@ -128,6 +347,8 @@ export namespace resource {
     environment?: { [key: string]: string };
     ignoreErrors?: boolean;
     module: string;
+    runAs?: outputs.RunAs;
+    timeout?: number;
   }
 
   export interface AnsibleTaskListArgsTasks {
@@ -149,8 +370,10 @@ export namespace resource {
     ignoreErrors?: boolean;
     module: string;
     result: { [key: string]: any };
+    runAs?: outputs.RunAs;
     stderr: string;
     stdout: string;
     success: boolean;
+    timeout?: number;
   }
 }
@@ -16,31 +16,317 @@ else:
 from . import _utilities
 
 __all__ = [
+    "Become",
+    "BecomeDict",
+    "BecomeArgs",
+    "BecomeArgsDict",
     "Connection",
     "ConnectionDict",
     "ConnectionArgs",
     "ConnectionArgsDict",
     "ExecCommandArgs",
     "ExecCommandArgsDict",
+    "ProxyConnection",
+    "ProxyConnectionDict",
+    "ProxyConnectionArgs",
+    "ProxyConnectionArgsDict",
     "ResourceConfig",
     "ResourceConfigDict",
     "ResourceConfigArgs",
     "ResourceConfigArgsDict",
+    "RunAs",
+    "RunAsDict",
+    "RunAsArgs",
+    "RunAsArgsDict",
     "TriggersInputArgs",
     "TriggersInputArgsDict",
 ]
 
 
+class BecomeDict(TypedDict):
+    """
+    Instructions for how the agent gets the privileges of another user.
+    """
+
+    flags: NotRequired[Sequence[_builtins.str]]
+    """
+    Extra flags passed to the become method.
+    """
+    method: NotRequired[_builtins.str]
+    """
+    How to switch users. One of "auto", "sudo", "doas", "su",
+    or "none". "auto" uses sudo unless the connection user already is the become
+    user, and "none" runs the agent as the connection user. Defaults to "auto".
+    """
+    password: NotRequired[_builtins.str]
+    """
+    The password to give the become method when it asks
+    for one. It is only ever written to the terminal of the become command.
+    """
+    user: NotRequired[_builtins.str]
+    """
+    The user the agent runs as. Defaults to "root".
+    """
+
+
+@pulumi.input_type
+class Become:
+    def __init__(
+        __self__,
+        *,
+        flags: Optional[Sequence[_builtins.str]] = None,
+        method: Optional[_builtins.str] = None,
+        password: Optional[_builtins.str] = None,
+        user: Optional[_builtins.str] = None,
+    ):
+        """
+        Instructions for how the agent gets the privileges of another user.
+
+        :param Sequence[_builtins.str] flags: Extra flags passed to the become method.
+        :param _builtins.str method: How to switch users. One of "auto", "sudo", "doas", "su",
+               or "none". "auto" uses sudo unless the connection user already is the become
+               user, and "none" runs the agent as the connection user. Defaults to "auto".
+        :param _builtins.str password: The password to give the become method when it asks
+               for one. It is only ever written to the terminal of the become command.
+        :param _builtins.str user: The user the agent runs as. Defaults to "root".
+        """
+        if flags is not None:
+            pulumi.set(__self__, "flags", flags)
+        if method is not None:
+            pulumi.set(__self__, "method", method)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def flags(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        Extra flags passed to the become method.
+        """
+        return pulumi.get(self, "flags")
+
+    @flags.setter
+    def flags(self, value: Optional[Sequence[_builtins.str]]):
+        pulumi.set(self, "flags", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def method(self) -> Optional[_builtins.str]:
+        """
+        How to switch users. One of "auto", "sudo", "doas", "su",
+        or "none". "auto" uses sudo unless the connection user already is the become
+        user, and "none" runs the agent as the connection user. Defaults to "auto".
+        """
+        return pulumi.get(self, "method")
+
+    @method.setter
+    def method(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "method", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> Optional[_builtins.str]:
+        """
+        The password to give the become method when it asks
+        for one. It is only ever written to the terminal of the become command.
+        """
+        return pulumi.get(self, "password")
+
+    @password.setter
+    def password(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "password", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The user the agent runs as. Defaults to "root".
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "user", value)
+
+
+class BecomeArgsDict(TypedDict):
+    """
+    Instructions for how the agent gets the privileges of another user.
+    """
+
+    flags: NotRequired[pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]]
+    """
+    Extra flags passed to the become method.
+    """
+    method: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    How to switch users. One of "auto", "sudo", "doas", "su",
+    or "none". "auto" uses sudo unless the connection user already is the become
+    user, and "none" runs the agent as the connection user. Defaults to "auto".
+    """
+    password: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The password to give the become method when it asks
+    for one. It is only ever written to the terminal of the become command.
+    """
+    user: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The user the agent runs as. Defaults to "root".
+    """
+
+
+@pulumi.input_type
+class BecomeArgs:
+    def __init__(
+        __self__,
+        *,
+        flags: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]] = None,
+        method: pulumi.Input[Optional[_builtins.str]] = None,
+        password: pulumi.Input[Optional[_builtins.str]] = None,
+        user: pulumi.Input[Optional[_builtins.str]] = None,
+    ):
+        """
+        Instructions for how the agent gets the privileges of another user.
+
+        :param pulumi.Input[Sequence[pulumi.Input[_builtins.str]]] flags: Extra flags passed to the become method.
+        :param pulumi.Input[_builtins.str] method: How to switch users. One of "auto", "sudo", "doas", "su",
+               or "none". "auto" uses sudo unless the connection user already is the become
+               user, and "none" runs the agent as the connection user. Defaults to "auto".
+        :param pulumi.Input[_builtins.str] password: The password to give the become method when it asks
+               for one. It is only ever written to the terminal of the become command.
+        :param pulumi.Input[_builtins.str] user: The user the agent runs as. Defaults to "root".
+        """
+        if flags is not None:
+            pulumi.set(__self__, "flags", flags)
+        if method is not None:
+            pulumi.set(__self__, "method", method)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def flags(self) -> pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]:
+        """
+        Extra flags passed to the become method.
+        """
+        return pulumi.get(self, "flags")
+
+    @flags.setter
+    def flags(
+        self, value: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ):
+        pulumi.set(self, "flags", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def method(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        How to switch users. One of "auto", "sudo", "doas", "su",
+        or "none". "auto" uses sudo unless the connection user already is the become
+        user, and "none" runs the agent as the connection user. Defaults to "auto".
+        """
+        return pulumi.get(self, "method")
+
+    @method.setter
+    def method(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "method", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The password to give the become method when it asks
+        for one. It is only ever written to the terminal of the become command.
+        """
+        return pulumi.get(self, "password")
+
+    @password.setter
+    def password(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "password", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The user the agent runs as. Defaults to "root".
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "user", value)
+
+
 class ConnectionDict(TypedDict):
     """
     Instructions for how to connect to a remote endpoint.
     """
 
+    agent_dir: NotRequired[_builtins.str]
+    """
+    The directory the agent, its staging area, and the
+    Ansible modules are installed in on the remote host. Relative paths are
+    relative to the home directory of the user and can't leave it. Since the
+    directory is removed when uninstalling the agent, absolute paths need at least
+    two levels (e.g. "/opt/mid"). Defaults to ".mid".
+    """
+    become: NotRequired["BecomeDict"]
+    """
+    How the agent gets root (or another user's)
+    privileges. Each setting given for a resource overrides the same setting of the
+    provider.
+    """
+    certificate: NotRequired[_builtins.str]
+    """
+    The contents of an OpenSSH certificate (usually a
+    "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+    key. It is for privateKey if that is set, and otherwise for the key of the same
+    name in the SSH agent. Certificates held by the SSH agent itself are used
+    without setting this.
+    """
     host: NotRequired[_builtins.str]
     """
     The address of the resource to connect to.
     """
+    host_ca_keys: NotRequired[Sequence[_builtins.str]]
+    """
+    The public keys, in authorized_keys format, of the
+    CAs that sign host certificates. A host presenting a certificate signed by one
+    of them is trusted if the certificate is valid and names the host as a
+    principal.
+    """
     host_key: NotRequired[_builtins.str]
+    """
+    The public key the host is expected to have, in
+    authorized_keys format.
+    """
+    host_key_checking: NotRequired[_builtins.str]
+    """
+    How the host's key is checked. "strict" only
+    connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+    hostCAKeys.
+    "tofu" (trust on first use) does the same for hosts that are listed there, but
+    connects to any other host. The key it presents is the hostKey output of Exec
+    and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+    to any host. Defaults to "strict" if any of hostKey, knownHosts,
+    knownHostsFile, or hostCAKeys are set and "off" otherwise.
+    """
+    known_hosts: NotRequired[_builtins.str]
+    """
+    The contents of an OpenSSH known_hosts file to
+    check the host's key against. Hashed hostnames, "@cert-authority", and
+    "@revoked" lines are supported.
+    """
+    known_hosts_file: NotRequired[_builtins.str]
+    """
+    The path of an OpenSSH known_hosts file on the
+    machine running Pulumi to check the host's key against, e.g.
+    "~/.ssh/known_hosts".
+    """
     password: NotRequired[_builtins.str]
     """
     The password we should use for the connection.
@@ -56,11 +342,28 @@ class ConnectionDict(TypedDict):
     connection. This takes preference over the password if provided.
     """
     private_key_password: NotRequired[_builtins.str]
+    proxies: NotRequired[Sequence["ProxyConnectionDict"]]
+    """
+    Proxy (or bastion) hosts to connect through, in order.
+    The first proxy is connected to directly, each following one through the one
+    before it, and the host itself through the last one. Connections to proxies are
+    shared between all hosts that go through them.
+    """
     ssh_agent: NotRequired[_builtins.bool]
     ssh_agent_socket_path: NotRequired[_builtins.str]
+    ssh_config: NotRequired[_builtins.str]
+    """
+    The path of an OpenSSH client config file on the
+    machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+    HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+    for the host are used for anything the connection doesn't set itself. Include
+    and "Match host" are supported. Defaults to not reading any config file.
+    """
     user: NotRequired[_builtins.str]
     """
     The user that we should use for the connection.
+    Defaults to the User set for the host in sshConfig, or else the current local
+    user.
     """
 
 
@@ -69,52 +372,165 @@ class Connection:
     def __init__(
         __self__,
         *,
+        agent_dir: Optional[_builtins.str] = None,
+        become: Optional["Become"] = None,
+        certificate: Optional[_builtins.str] = None,
         host: Optional[_builtins.str] = None,
+        host_ca_keys: Optional[Sequence[_builtins.str]] = None,
         host_key: Optional[_builtins.str] = None,
+        host_key_checking: Optional[_builtins.str] = None,
+        known_hosts: Optional[_builtins.str] = None,
+        known_hosts_file: Optional[_builtins.str] = None,
         password: Optional[_builtins.str] = None,
         per_dial_timeout: Optional[_builtins.int] = None,
         port: Optional[_builtins.float] = None,
         private_key: Optional[_builtins.str] = None,
         private_key_password: Optional[_builtins.str] = None,
+        proxies: Optional[Sequence["ProxyConnection"]] = None,
         ssh_agent: Optional[_builtins.bool] = None,
         ssh_agent_socket_path: Optional[_builtins.str] = None,
+        ssh_config: Optional[_builtins.str] = None,
         user: Optional[_builtins.str] = None,
     ):
         """
         Instructions for how to connect to a remote endpoint.
 
+        :param _builtins.str agent_dir: The directory the agent, its staging area, and the
+               Ansible modules are installed in on the remote host. Relative paths are
+               relative to the home directory of the user and can't leave it. Since the
+               directory is removed when uninstalling the agent, absolute paths need at least
+               two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        :param 'Become' become: How the agent gets root (or another user's)
+               privileges. Each setting given for a resource overrides the same setting of the
+               provider.
+        :param _builtins.str certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
         :param _builtins.str host: The address of the resource to connect to.
+        :param Sequence[_builtins.str] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param _builtins.str host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param _builtins.str host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param _builtins.str known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param _builtins.str known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
         :param _builtins.str password: The password we should use for the connection.
         :param _builtins.float port: The port to connect to. Defaults to 22.
         :param _builtins.str private_key: The contents of an SSH key to use for the
                connection. This takes preference over the password if provided.
+        :param Sequence['ProxyConnection'] proxies: Proxy (or bastion) hosts to connect through, in order.
+               The first proxy is connected to directly, each following one through the one
+               before it, and the host itself through the last one. Connections to proxies are
+               shared between all hosts that go through them.
+        :param _builtins.str ssh_config: The path of an OpenSSH client config file on the
+               machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+               HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+               for the host are used for anything the connection doesn't set itself. Include
+               and "Match host" are supported. Defaults to not reading any config file.
         :param _builtins.str user: The user that we should use for the connection.
+               Defaults to the User set for the host in sshConfig, or else the current local
+               user.
         """
+        if agent_dir is not None:
+            pulumi.set(__self__, "agent_dir", agent_dir)
+        if become is not None:
+            pulumi.set(__self__, "become", become)
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
         if host is not None:
             pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
         if host_key is not None:
             pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
         if password is not None:
             pulumi.set(__self__, "password", password)
         if per_dial_timeout is not None:
             pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
-        if port is None:
-            port = 22
         if port is not None:
             pulumi.set(__self__, "port", port)
         if private_key is not None:
             pulumi.set(__self__, "private_key", private_key)
         if private_key_password is not None:
             pulumi.set(__self__, "private_key_password", private_key_password)
+        if proxies is not None:
+            pulumi.set(__self__, "proxies", proxies)
         if ssh_agent is not None:
             pulumi.set(__self__, "ssh_agent", ssh_agent)
         if ssh_agent_socket_path is not None:
             pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
-        if user is None:
-            user = "root"
+        if ssh_config is not None:
+            pulumi.set(__self__, "ssh_config", ssh_config)
         if user is not None:
             pulumi.set(__self__, "user", user)
 
+    @_builtins.property
+    @pulumi.getter(name="agentDir")
+    def agent_dir(self) -> Optional[_builtins.str]:
+        """
+        The directory the agent, its staging area, and the
+        Ansible modules are installed in on the remote host. Relative paths are
+        relative to the home directory of the user and can't leave it. Since the
+        directory is removed when uninstalling the agent, absolute paths need at least
+        two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        """
+        return pulumi.get(self, "agent_dir")
+
+    @agent_dir.setter
+    def agent_dir(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "agent_dir", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def become(self) -> Optional["Become"]:
+        """
+        How the agent gets root (or another user's)
+        privileges. Each setting given for a resource overrides the same setting of the
+        provider.
+        """
+        return pulumi.get(self, "become")
+
+    @become.setter
+    def become(self, value: Optional["Become"]):
+        pulumi.set(self, "become", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def certificate(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
+
+    @certificate.setter
+    def certificate(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "certificate", value)
+
     @_builtins.property
     @pulumi.getter
     def host(self) -> Optional[_builtins.str]:
@@ -127,15 +543,81 @@ class Connection:
     def host(self, value: Optional[_builtins.str]):
         pulumi.set(self, "host", value)
 
+    @_builtins.property
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
+
+    @host_ca_keys.setter
+    def host_ca_keys(self, value: Optional[Sequence[_builtins.str]]):
+        pulumi.set(self, "host_ca_keys", value)
+
     @_builtins.property
     @pulumi.getter(name="hostKey")
     def host_key(self) -> Optional[_builtins.str]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
         return pulumi.get(self, "host_key")
 
     @host_key.setter
     def host_key(self, value: Optional[_builtins.str]):
         pulumi.set(self, "host_key", value)
 
+    @_builtins.property
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> Optional[_builtins.str]:
+        """
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
+
+    @host_key_checking.setter
+    def host_key_checking(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "host_key_checking", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @known_hosts.setter
+    def known_hosts(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "known_hosts", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
+    @known_hosts_file.setter
+    def known_hosts_file(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "known_hosts_file", value)
+
     @_builtins.property
     @pulumi.getter
     def password(self) -> Optional[_builtins.str]:
@@ -191,6 +673,21 @@ class Connection:
     def private_key_password(self, value: Optional[_builtins.str]):
         pulumi.set(self, "private_key_password", value)
 
+    @_builtins.property
+    @pulumi.getter
+    def proxies(self) -> Optional[Sequence["ProxyConnection"]]:
+        """
+        Proxy (or bastion) hosts to connect through, in order.
+        The first proxy is connected to directly, each following one through the one
+        before it, and the host itself through the last one. Connections to proxies are
+        shared between all hosts that go through them.
+        """
+        return pulumi.get(self, "proxies")
+
+    @proxies.setter
+    def proxies(self, value: Optional[Sequence["ProxyConnection"]]):
+        pulumi.set(self, "proxies", value)
+
     @_builtins.property
     @pulumi.getter(name="sshAgent")
     def ssh_agent(self) -> Optional[_builtins.bool]:
@@ -209,11 +706,29 @@ class Connection:
     def ssh_agent_socket_path(self, value: Optional[_builtins.str]):
         pulumi.set(self, "ssh_agent_socket_path", value)
 
+    @_builtins.property
+    @pulumi.getter(name="sshConfig")
+    def ssh_config(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH client config file on the
+        machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+        HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+        for the host are used for anything the connection doesn't set itself. Include
+        and "Match host" are supported. Defaults to not reading any config file.
+        """
+        return pulumi.get(self, "ssh_config")
+
+    @ssh_config.setter
+    def ssh_config(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "ssh_config", value)
+
     @_builtins.property
     @pulumi.getter
     def user(self) -> Optional[_builtins.str]:
         """
         The user that we should use for the connection.
+        Defaults to the User set for the host in sshConfig, or else the current local
+        user.
         """
         return pulumi.get(self, "user")
 
@@ -227,11 +742,69 @@ class ConnectionArgsDict(TypedDict):
     Instructions for how to connect to a remote endpoint.
     """
 
+    agent_dir: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The directory the agent, its staging area, and the
+    Ansible modules are installed in on the remote host. Relative paths are
+    relative to the home directory of the user and can't leave it. Since the
+    directory is removed when uninstalling the agent, absolute paths need at least
+    two levels (e.g. "/opt/mid"). Defaults to ".mid".
+    """
+    become: NotRequired[pulumi.Input[Optional["BecomeArgsDict"]]]
+    """
+    How the agent gets root (or another user's)
+    privileges. Each setting given for a resource overrides the same setting of the
+    provider.
+    """
+    certificate: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The contents of an OpenSSH certificate (usually a
+    "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+    key. It is for privateKey if that is set, and otherwise for the key of the same
+    name in the SSH agent. Certificates held by the SSH agent itself are used
+    without setting this.
+    """
     host: NotRequired[pulumi.Input[Optional[_builtins.str]]]
     """
     The address of the resource to connect to.
     """
+    host_ca_keys: NotRequired[
+        pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ]
+    """
+    The public keys, in authorized_keys format, of the
+    CAs that sign host certificates. A host presenting a certificate signed by one
+    of them is trusted if the certificate is valid and names the host as a
+    principal.
+    """
     host_key: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The public key the host is expected to have, in
+    authorized_keys format.
+    """
+    host_key_checking: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    How the host's key is checked. "strict" only
+    connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+    hostCAKeys.
+    "tofu" (trust on first use) does the same for hosts that are listed there, but
+    connects to any other host. The key it presents is the hostKey output of Exec
+    and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+    to any host. Defaults to "strict" if any of hostKey, knownHosts,
+    knownHostsFile, or hostCAKeys are set and "off" otherwise.
+    """
+    known_hosts: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The contents of an OpenSSH known_hosts file to
+    check the host's key against. Hashed hostnames, "@cert-authority", and
+    "@revoked" lines are supported.
+    """
+    known_hosts_file: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The path of an OpenSSH known_hosts file on the
+    machine running Pulumi to check the host's key against, e.g.
+    "~/.ssh/known_hosts".
+    """
     password: NotRequired[pulumi.Input[Optional[_builtins.str]]]
     """
     The password we should use for the connection.
@@ -247,11 +820,30 @@ class ConnectionArgsDict(TypedDict):
     connection. This takes preference over the password if provided.
     """
     private_key_password: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    proxies: NotRequired[
+        pulumi.Input[Optional[Sequence[pulumi.Input["ProxyConnectionArgsDict"]]]]
+    ]
+    """
+    Proxy (or bastion) hosts to connect through, in order.
+    The first proxy is connected to directly, each following one through the one
+    before it, and the host itself through the last one. Connections to proxies are
+    shared between all hosts that go through them.
+    """
     ssh_agent: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
     ssh_agent_socket_path: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    ssh_config: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The path of an OpenSSH client config file on the
+    machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+    HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+    for the host are used for anything the connection doesn't set itself. Include
+    and "Match host" are supported. Defaults to not reading any config file.
+    """
     user: NotRequired[pulumi.Input[Optional[_builtins.str]]]
     """
     The user that we should use for the connection.
+    Defaults to the User set for the host in sshConfig, or else the current local
+    user.
     """
 
 
@@ -260,52 +852,169 @@ class ConnectionArgs:
     def __init__(
         __self__,
         *,
+        agent_dir: pulumi.Input[Optional[_builtins.str]] = None,
+        become: pulumi.Input[Optional["BecomeArgs"]] = None,
+        certificate: pulumi.Input[Optional[_builtins.str]] = None,
         host: pulumi.Input[Optional[_builtins.str]] = None,
+        host_ca_keys: pulumi.Input[
+            Optional[Sequence[pulumi.Input[_builtins.str]]]
+        ] = None,
         host_key: pulumi.Input[Optional[_builtins.str]] = None,
+        host_key_checking: pulumi.Input[Optional[_builtins.str]] = None,
+        known_hosts: pulumi.Input[Optional[_builtins.str]] = None,
+        known_hosts_file: pulumi.Input[Optional[_builtins.str]] = None,
         password: pulumi.Input[Optional[_builtins.str]] = None,
         per_dial_timeout: pulumi.Input[Optional[_builtins.int]] = None,
         port: pulumi.Input[Optional[_builtins.float]] = None,
         private_key: pulumi.Input[Optional[_builtins.str]] = None,
         private_key_password: pulumi.Input[Optional[_builtins.str]] = None,
+        proxies: pulumi.Input[
+            Optional[Sequence[pulumi.Input["ProxyConnectionArgs"]]]
+        ] = None,
         ssh_agent: pulumi.Input[Optional[_builtins.bool]] = None,
         ssh_agent_socket_path: pulumi.Input[Optional[_builtins.str]] = None,
+        ssh_config: pulumi.Input[Optional[_builtins.str]] = None,
         user: pulumi.Input[Optional[_builtins.str]] = None,
     ):
         """
         Instructions for how to connect to a remote endpoint.
 
+        :param pulumi.Input[_builtins.str] agent_dir: The directory the agent, its staging area, and the
+               Ansible modules are installed in on the remote host. Relative paths are
+               relative to the home directory of the user and can't leave it. Since the
+               directory is removed when uninstalling the agent, absolute paths need at least
+               two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        :param pulumi.Input['BecomeArgs'] become: How the agent gets root (or another user's)
+               privileges. Each setting given for a resource overrides the same setting of the
+               provider.
+        :param pulumi.Input[_builtins.str] certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
         :param pulumi.Input[_builtins.str] host: The address of the resource to connect to.
+        :param pulumi.Input[Sequence[pulumi.Input[_builtins.str]]] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param pulumi.Input[_builtins.str] host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param pulumi.Input[_builtins.str] host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param pulumi.Input[_builtins.str] known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param pulumi.Input[_builtins.str] known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
         :param pulumi.Input[_builtins.str] password: The password we should use for the connection.
         :param pulumi.Input[_builtins.float] port: The port to connect to. Defaults to 22.
         :param pulumi.Input[_builtins.str] private_key: The contents of an SSH key to use for the
                connection. This takes preference over the password if provided.
+        :param pulumi.Input[Sequence[pulumi.Input['ProxyConnectionArgs']]] proxies: Proxy (or bastion) hosts to connect through, in order.
+               The first proxy is connected to directly, each following one through the one
+               before it, and the host itself through the last one. Connections to proxies are
+               shared between all hosts that go through them.
+        :param pulumi.Input[_builtins.str] ssh_config: The path of an OpenSSH client config file on the
+               machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+               HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+               for the host are used for anything the connection doesn't set itself. Include
+               and "Match host" are supported. Defaults to not reading any config file.
         :param pulumi.Input[_builtins.str] user: The user that we should use for the connection.
+               Defaults to the User set for the host in sshConfig, or else the current local
+               user.
         """
+        if agent_dir is not None:
+            pulumi.set(__self__, "agent_dir", agent_dir)
+        if become is not None:
+            pulumi.set(__self__, "become", become)
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
         if host is not None:
             pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
         if host_key is not None:
             pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
         if password is not None:
             pulumi.set(__self__, "password", password)
         if per_dial_timeout is not None:
             pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
-        if port is None:
-            port = 22
         if port is not None:
             pulumi.set(__self__, "port", port)
         if private_key is not None:
             pulumi.set(__self__, "private_key", private_key)
         if private_key_password is not None:
             pulumi.set(__self__, "private_key_password", private_key_password)
+        if proxies is not None:
+            pulumi.set(__self__, "proxies", proxies)
         if ssh_agent is not None:
             pulumi.set(__self__, "ssh_agent", ssh_agent)
         if ssh_agent_socket_path is not None:
             pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
-        if user is None:
-            user = "root"
+        if ssh_config is not None:
+            pulumi.set(__self__, "ssh_config", ssh_config)
         if user is not None:
             pulumi.set(__self__, "user", user)
 
+    @_builtins.property
+    @pulumi.getter(name="agentDir")
+    def agent_dir(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The directory the agent, its staging area, and the
+        Ansible modules are installed in on the remote host. Relative paths are
+        relative to the home directory of the user and can't leave it. Since the
+        directory is removed when uninstalling the agent, absolute paths need at least
+        two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        """
+        return pulumi.get(self, "agent_dir")
+
+    @agent_dir.setter
+    def agent_dir(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "agent_dir", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def become(self) -> pulumi.Input[Optional["BecomeArgs"]]:
+        """
+        How the agent gets root (or another user's)
+        privileges. Each setting given for a resource overrides the same setting of the
+        provider.
+        """
+        return pulumi.get(self, "become")
+
+    @become.setter
+    def become(self, value: pulumi.Input[Optional["BecomeArgs"]]):
+        pulumi.set(self, "become", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def certificate(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
+
+    @certificate.setter
+    def certificate(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "certificate", value)
+
     @_builtins.property
     @pulumi.getter
     def host(self) -> pulumi.Input[Optional[_builtins.str]]:
@@ -318,9 +1027,32 @@ class ConnectionArgs:
     def host(self, value: pulumi.Input[Optional[_builtins.str]]):
         pulumi.set(self, "host", value)
 
+    @_builtins.property
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(
+        self,
+    ) -> pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
+
+    @host_ca_keys.setter
+    def host_ca_keys(
+        self, value: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ):
+        pulumi.set(self, "host_ca_keys", value)
+
     @_builtins.property
     @pulumi.getter(name="hostKey")
     def host_key(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
         return pulumi.get(self, "host_key")
 
     @host_key.setter
@@ -328,10 +1060,57 @@ class ConnectionArgs:
         pulumi.set(self, "host_key", value)
 
     @_builtins.property
-    @pulumi.getter
-    def password(self) -> pulumi.Input[Optional[_builtins.str]]:
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> pulumi.Input[Optional[_builtins.str]]:
         """
-        The password we should use for the connection.
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
+
+    @host_key_checking.setter
+    def host_key_checking(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "host_key_checking", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @known_hosts.setter
+    def known_hosts(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "known_hosts", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
+    @known_hosts_file.setter
+    def known_hosts_file(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "known_hosts_file", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The password we should use for the connection.
         """
         return pulumi.get(self, "password")
 
@@ -382,6 +1161,26 @@ class ConnectionArgs:
     def private_key_password(self, value: pulumi.Input[Optional[_builtins.str]]):
         pulumi.set(self, "private_key_password", value)
 
+    @_builtins.property
+    @pulumi.getter
+    def proxies(
+        self,
+    ) -> pulumi.Input[Optional[Sequence[pulumi.Input["ProxyConnectionArgs"]]]]:
+        """
+        Proxy (or bastion) hosts to connect through, in order.
+        The first proxy is connected to directly, each following one through the one
+        before it, and the host itself through the last one. Connections to proxies are
+        shared between all hosts that go through them.
+        """
+        return pulumi.get(self, "proxies")
+
+    @proxies.setter
+    def proxies(
+        self,
+        value: pulumi.Input[Optional[Sequence[pulumi.Input["ProxyConnectionArgs"]]]],
+    ):
+        pulumi.set(self, "proxies", value)
+
     @_builtins.property
     @pulumi.getter(name="sshAgent")
     def ssh_agent(self) -> pulumi.Input[Optional[_builtins.bool]]:
@@ -400,11 +1199,29 @@ class ConnectionArgs:
     def ssh_agent_socket_path(self, value: pulumi.Input[Optional[_builtins.str]]):
         pulumi.set(self, "ssh_agent_socket_path", value)
 
+    @_builtins.property
+    @pulumi.getter(name="sshConfig")
+    def ssh_config(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The path of an OpenSSH client config file on the
+        machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+        HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+        for the host are used for anything the connection doesn't set itself. Include
+        and "Match host" are supported. Defaults to not reading any config file.
+        """
+        return pulumi.get(self, "ssh_config")
+
+    @ssh_config.setter
+    def ssh_config(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "ssh_config", value)
+
     @_builtins.property
     @pulumi.getter
     def user(self) -> pulumi.Input[Optional[_builtins.str]]:
         """
         The user that we should use for the connection.
+        Defaults to the User set for the host in sshConfig, or else the current local
+        user.
         """
         return pulumi.get(self, "user")
 
@@ -435,6 +1252,12 @@ class ExecCommandArgsDict(TypedDict):
     """
     Pass a string to the command's process as standard in.
     """
+    timeout: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    """
+    Maximum number of seconds the command is allowed to run. When exceeded, the
+    command's process group is sent SIGTERM, followed by SIGKILL if it has not
+    exited 10 seconds later. Defaults to no timeout.
+    """
 
 
 @pulumi.input_type
@@ -448,6 +1271,7 @@ class ExecCommandArgs:
             Optional[Mapping[str, pulumi.Input[_builtins.str]]]
         ] = None,
         stdin: pulumi.Input[Optional[_builtins.str]] = None,
+        timeout: pulumi.Input[Optional[_builtins.int]] = None,
     ):
         """
         :param pulumi.Input[Sequence[pulumi.Input[_builtins.str]]] command: List of arguments to execute. Under the hood, these are passed to `execve`, bypassing any shell
@@ -457,6 +1281,9 @@ class ExecCommandArgs:
         :param pulumi.Input[Mapping[str, pulumi.Input[_builtins.str]]] environment: Key-value pairs of environment variables to pass to the process. These are
                merged with any system-wide environment variables.
         :param pulumi.Input[_builtins.str] stdin: Pass a string to the command's process as standard in.
+        :param pulumi.Input[_builtins.int] timeout: Maximum number of seconds the command is allowed to run. When exceeded, the
+               command's process group is sent SIGTERM, followed by SIGKILL if it has not
+               exited 10 seconds later. Defaults to no timeout.
         """
         pulumi.set(__self__, "command", command)
         if dir is not None:
@@ -465,6 +1292,8 @@ class ExecCommandArgs:
             pulumi.set(__self__, "environment", environment)
         if stdin is not None:
             pulumi.set(__self__, "stdin", stdin)
+        if timeout is not None:
+            pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -521,105 +1350,1231 @@ class ExecCommandArgs:
     def stdin(self, value: pulumi.Input[Optional[_builtins.str]]):
         pulumi.set(self, "stdin", value)
 
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> pulumi.Input[Optional[_builtins.int]]:
+        """
+        Maximum number of seconds the command is allowed to run. When exceeded, the
+        command's process group is sent SIGTERM, followed by SIGKILL if it has not
+        exited 10 seconds later. Defaults to no timeout.
+        """
+        return pulumi.get(self, "timeout")
 
-class ResourceConfigDict(TypedDict):
-    check: NotRequired[_builtins.bool]
-    delete_unreachable: NotRequired[_builtins.bool]
-    parallel: NotRequired[_builtins.int]
+    @timeout.setter
+    def timeout(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "timeout", value)
+
+
+class ProxyConnectionDict(TypedDict):
+    """
+    Instructions for how to connect to a proxy (or bastion) host.
+    """
+
+    certificate: NotRequired[_builtins.str]
+    """
+    The contents of an OpenSSH certificate (usually a
+    "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+    key. It is for privateKey if that is set, and otherwise for the key of the same
+    name in the SSH agent. Certificates held by the SSH agent itself are used
+    without setting this.
+    """
+    host: NotRequired[_builtins.str]
+    """
+    The address of the proxy to connect to.
+    """
+    host_ca_keys: NotRequired[Sequence[_builtins.str]]
+    """
+    The public keys, in authorized_keys format, of the
+    CAs that sign host certificates. A host presenting a certificate signed by one
+    of them is trusted if the certificate is valid and names the host as a
+    principal.
+    """
+    host_key: NotRequired[_builtins.str]
+    """
+    The public key the host is expected to have, in
+    authorized_keys format.
+    """
+    host_key_checking: NotRequired[_builtins.str]
+    """
+    How the host's key is checked. "strict" only
+    connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+    hostCAKeys.
+    "tofu" (trust on first use) does the same for hosts that are listed there, but
+    connects to any other host. The key it presents is the hostKey output of Exec
+    and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+    to any host. Defaults to "strict" if any of hostKey, knownHosts,
+    knownHostsFile, or hostCAKeys are set and "off" otherwise.
+    """
+    known_hosts: NotRequired[_builtins.str]
+    """
+    The contents of an OpenSSH known_hosts file to
+    check the host's key against. Hashed hostnames, "@cert-authority", and
+    "@revoked" lines are supported.
+    """
+    known_hosts_file: NotRequired[_builtins.str]
+    """
+    The path of an OpenSSH known_hosts file on the
+    machine running Pulumi to check the host's key against, e.g.
+    "~/.ssh/known_hosts".
+    """
+    password: NotRequired[_builtins.str]
+    """
+    The password we should use for the connection to the proxy.
+    """
+    per_dial_timeout: NotRequired[_builtins.int]
+    port: NotRequired[_builtins.float]
+    """
+    The port to connect to. Defaults to 22.
+    """
+    private_key: NotRequired[_builtins.str]
+    """
+    The contents of an SSH key to use for the
+    connection to the proxy. This takes preference over the password if provided.
+    """
+    private_key_password: NotRequired[_builtins.str]
+    ssh_agent: NotRequired[_builtins.bool]
+    ssh_agent_socket_path: NotRequired[_builtins.str]
+    user: NotRequired[_builtins.str]
+    """
+    The user that we should use for the connection to the
+    proxy. Defaults to the current local user.
+    """
 
 
 @pulumi.input_type
-class ResourceConfig:
+class ProxyConnection:
     def __init__(
         __self__,
         *,
-        check: Optional[_builtins.bool] = None,
-        delete_unreachable: Optional[_builtins.bool] = None,
-        parallel: Optional[_builtins.int] = None,
+        certificate: Optional[_builtins.str] = None,
+        host: Optional[_builtins.str] = None,
+        host_ca_keys: Optional[Sequence[_builtins.str]] = None,
+        host_key: Optional[_builtins.str] = None,
+        host_key_checking: Optional[_builtins.str] = None,
+        known_hosts: Optional[_builtins.str] = None,
+        known_hosts_file: Optional[_builtins.str] = None,
+        password: Optional[_builtins.str] = None,
+        per_dial_timeout: Optional[_builtins.int] = None,
+        port: Optional[_builtins.float] = None,
+        private_key: Optional[_builtins.str] = None,
+        private_key_password: Optional[_builtins.str] = None,
+        ssh_agent: Optional[_builtins.bool] = None,
+        ssh_agent_socket_path: Optional[_builtins.str] = None,
+        user: Optional[_builtins.str] = None,
     ):
-        if check is not None:
-            pulumi.set(__self__, "check", check)
-        if delete_unreachable is not None:
-            pulumi.set(__self__, "delete_unreachable", delete_unreachable)
-        if parallel is not None:
-            pulumi.set(__self__, "parallel", parallel)
+        """
+        Instructions for how to connect to a proxy (or bastion) host.
+
+        :param _builtins.str certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
+        :param _builtins.str host: The address of the proxy to connect to.
+        :param Sequence[_builtins.str] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param _builtins.str host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param _builtins.str host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param _builtins.str known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param _builtins.str known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
+        :param _builtins.str password: The password we should use for the connection to the proxy.
+        :param _builtins.float port: The port to connect to. Defaults to 22.
+        :param _builtins.str private_key: The contents of an SSH key to use for the
+               connection to the proxy. This takes preference over the password if provided.
+        :param _builtins.str user: The user that we should use for the connection to the
+               proxy. Defaults to the current local user.
+        """
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
+        if host is not None:
+            pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
+        if host_key is not None:
+            pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if per_dial_timeout is not None:
+            pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
+        if port is None:
+            port = 22
+        if port is not None:
+            pulumi.set(__self__, "port", port)
+        if private_key is not None:
+            pulumi.set(__self__, "private_key", private_key)
+        if private_key_password is not None:
+            pulumi.set(__self__, "private_key_password", private_key_password)
+        if ssh_agent is not None:
+            pulumi.set(__self__, "ssh_agent", ssh_agent)
+        if ssh_agent_socket_path is not None:
+            pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
 
     @_builtins.property
     @pulumi.getter
-    def check(self) -> Optional[_builtins.bool]:
-        return pulumi.get(self, "check")
+    def certificate(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
 
-    @check.setter
-    def check(self, value: Optional[_builtins.bool]):
-        pulumi.set(self, "check", value)
+    @certificate.setter
+    def certificate(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "certificate", value)
 
     @_builtins.property
-    @pulumi.getter(name="deleteUnreachable")
-    def delete_unreachable(self) -> Optional[_builtins.bool]:
-        return pulumi.get(self, "delete_unreachable")
+    @pulumi.getter
+    def host(self) -> Optional[_builtins.str]:
+        """
+        The address of the proxy to connect to.
+        """
+        return pulumi.get(self, "host")
 
-    @delete_unreachable.setter
-    def delete_unreachable(self, value: Optional[_builtins.bool]):
-        pulumi.set(self, "delete_unreachable", value)
+    @host.setter
+    def host(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "host", value)
 
     @_builtins.property
-    @pulumi.getter
-    def parallel(self) -> Optional[_builtins.int]:
-        return pulumi.get(self, "parallel")
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
 
-    @parallel.setter
-    def parallel(self, value: Optional[_builtins.int]):
-        pulumi.set(self, "parallel", value)
+    @host_ca_keys.setter
+    def host_ca_keys(self, value: Optional[Sequence[_builtins.str]]):
+        pulumi.set(self, "host_ca_keys", value)
+
+    @_builtins.property
+    @pulumi.getter(name="hostKey")
+    def host_key(self) -> Optional[_builtins.str]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
+        return pulumi.get(self, "host_key")
 
+    @host_key.setter
+    def host_key(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "host_key", value)
 
-class ResourceConfigArgsDict(TypedDict):
-    check: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
-    delete_unreachable: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
-    parallel: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    @_builtins.property
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> Optional[_builtins.str]:
+        """
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
 
+    @host_key_checking.setter
+    def host_key_checking(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "host_key_checking", value)
 
-@pulumi.input_type
-class ResourceConfigArgs:
-    def __init__(
-        __self__,
-        *,
-        check: pulumi.Input[Optional[_builtins.bool]] = None,
-        delete_unreachable: pulumi.Input[Optional[_builtins.bool]] = None,
-        parallel: pulumi.Input[Optional[_builtins.int]] = None,
-    ):
-        if check is not None:
-            pulumi.set(__self__, "check", check)
-        if delete_unreachable is not None:
-            pulumi.set(__self__, "delete_unreachable", delete_unreachable)
-        if parallel is not None:
-            pulumi.set(__self__, "parallel", parallel)
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @known_hosts.setter
+    def known_hosts(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "known_hosts", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
+    @known_hosts_file.setter
+    def known_hosts_file(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "known_hosts_file", value)
 
     @_builtins.property
     @pulumi.getter
-    def check(self) -> pulumi.Input[Optional[_builtins.bool]]:
-        return pulumi.get(self, "check")
+    def password(self) -> Optional[_builtins.str]:
+        """
+        The password we should use for the connection to the proxy.
+        """
+        return pulumi.get(self, "password")
 
-    @check.setter
-    def check(self, value: pulumi.Input[Optional[_builtins.bool]]):
-        pulumi.set(self, "check", value)
+    @password.setter
+    def password(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "password", value)
 
     @_builtins.property
-    @pulumi.getter(name="deleteUnreachable")
-    def delete_unreachable(self) -> pulumi.Input[Optional[_builtins.bool]]:
-        return pulumi.get(self, "delete_unreachable")
+    @pulumi.getter(name="perDialTimeout")
+    def per_dial_timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "per_dial_timeout")
 
-    @delete_unreachable.setter
-    def delete_unreachable(self, value: pulumi.Input[Optional[_builtins.bool]]):
-        pulumi.set(self, "delete_unreachable", value)
+    @per_dial_timeout.setter
+    def per_dial_timeout(self, value: Optional[_builtins.int]):
+        pulumi.set(self, "per_dial_timeout", value)
 
     @_builtins.property
     @pulumi.getter
-    def parallel(self) -> pulumi.Input[Optional[_builtins.int]]:
-        return pulumi.get(self, "parallel")
+    def port(self) -> Optional[_builtins.float]:
+        """
+        The port to connect to. Defaults to 22.
+        """
+        return pulumi.get(self, "port")
 
-    @parallel.setter
-    def parallel(self, value: pulumi.Input[Optional[_builtins.int]]):
-        pulumi.set(self, "parallel", value)
+    @port.setter
+    def port(self, value: Optional[_builtins.float]):
+        pulumi.set(self, "port", value)
+
+    @_builtins.property
+    @pulumi.getter(name="privateKey")
+    def private_key(self) -> Optional[_builtins.str]:
+        """
+        The contents of an SSH key to use for the
+        connection to the proxy. This takes preference over the password if provided.
+        """
+        return pulumi.get(self, "private_key")
+
+    @private_key.setter
+    def private_key(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "private_key", value)
+
+    @_builtins.property
+    @pulumi.getter(name="privateKeyPassword")
+    def private_key_password(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "private_key_password")
+
+    @private_key_password.setter
+    def private_key_password(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "private_key_password", value)
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgent")
+    def ssh_agent(self) -> Optional[_builtins.bool]:
+        return pulumi.get(self, "ssh_agent")
+
+    @ssh_agent.setter
+    def ssh_agent(self, value: Optional[_builtins.bool]):
+        pulumi.set(self, "ssh_agent", value)
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgentSocketPath")
+    def ssh_agent_socket_path(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "ssh_agent_socket_path")
+
+    @ssh_agent_socket_path.setter
+    def ssh_agent_socket_path(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "ssh_agent_socket_path", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The user that we should use for the connection to the
+        proxy. Defaults to the current local user.
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "user", value)
+
+
+class ProxyConnectionArgsDict(TypedDict):
+    """
+    Instructions for how to connect to a proxy (or bastion) host.
+    """
+
+    certificate: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The contents of an OpenSSH certificate (usually a
+    "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+    key. It is for privateKey if that is set, and otherwise for the key of the same
+    name in the SSH agent. Certificates held by the SSH agent itself are used
+    without setting this.
+    """
+    host: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The address of the proxy to connect to.
+    """
+    host_ca_keys: NotRequired[
+        pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ]
+    """
+    The public keys, in authorized_keys format, of the
+    CAs that sign host certificates. A host presenting a certificate signed by one
+    of them is trusted if the certificate is valid and names the host as a
+    principal.
+    """
+    host_key: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The public key the host is expected to have, in
+    authorized_keys format.
+    """
+    host_key_checking: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    How the host's key is checked. "strict" only
+    connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+    hostCAKeys.
+    "tofu" (trust on first use) does the same for hosts that are listed there, but
+    connects to any other host. The key it presents is the hostKey output of Exec
+    and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+    to any host. Defaults to "strict" if any of hostKey, knownHosts,
+    knownHostsFile, or hostCAKeys are set and "off" otherwise.
+    """
+    known_hosts: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The contents of an OpenSSH known_hosts file to
+    check the host's key against. Hashed hostnames, "@cert-authority", and
+    "@revoked" lines are supported.
+    """
+    known_hosts_file: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The path of an OpenSSH known_hosts file on the
+    machine running Pulumi to check the host's key against, e.g.
+    "~/.ssh/known_hosts".
+    """
+    password: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The password we should use for the connection to the proxy.
+    """
+    per_dial_timeout: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    port: NotRequired[pulumi.Input[Optional[_builtins.float]]]
+    """
+    The port to connect to. Defaults to 22.
+    """
+    private_key: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The contents of an SSH key to use for the
+    connection to the proxy. This takes preference over the password if provided.
+    """
+    private_key_password: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    ssh_agent: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
+    ssh_agent_socket_path: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    user: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The user that we should use for the connection to the
+    proxy. Defaults to the current local user.
+    """
+
+
+@pulumi.input_type
+class ProxyConnectionArgs:
+    def __init__(
+        __self__,
+        *,
+        certificate: pulumi.Input[Optional[_builtins.str]] = None,
+        host: pulumi.Input[Optional[_builtins.str]] = None,
+        host_ca_keys: pulumi.Input[
+            Optional[Sequence[pulumi.Input[_builtins.str]]]
+        ] = None,
+        host_key: pulumi.Input[Optional[_builtins.str]] = None,
+        host_key_checking: pulumi.Input[Optional[_builtins.str]] = None,
+        known_hosts: pulumi.Input[Optional[_builtins.str]] = None,
+        known_hosts_file: pulumi.Input[Optional[_builtins.str]] = None,
+        password: pulumi.Input[Optional[_builtins.str]] = None,
+        per_dial_timeout: pulumi.Input[Optional[_builtins.int]] = None,
+        port: pulumi.Input[Optional[_builtins.float]] = None,
+        private_key: pulumi.Input[Optional[_builtins.str]] = None,
+        private_key_password: pulumi.Input[Optional[_builtins.str]] = None,
+        ssh_agent: pulumi.Input[Optional[_builtins.bool]] = None,
+        ssh_agent_socket_path: pulumi.Input[Optional[_builtins.str]] = None,
+        user: pulumi.Input[Optional[_builtins.str]] = None,
+    ):
+        """
+        Instructions for how to connect to a proxy (or bastion) host.
+
+        :param pulumi.Input[_builtins.str] certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
+        :param pulumi.Input[_builtins.str] host: The address of the proxy to connect to.
+        :param pulumi.Input[Sequence[pulumi.Input[_builtins.str]]] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param pulumi.Input[_builtins.str] host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param pulumi.Input[_builtins.str] host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param pulumi.Input[_builtins.str] known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param pulumi.Input[_builtins.str] known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
+        :param pulumi.Input[_builtins.str] password: The password we should use for the connection to the proxy.
+        :param pulumi.Input[_builtins.float] port: The port to connect to. Defaults to 22.
+        :param pulumi.Input[_builtins.str] private_key: The contents of an SSH key to use for the
+               connection to the proxy. This takes preference over the password if provided.
+        :param pulumi.Input[_builtins.str] user: The user that we should use for the connection to the
+               proxy. Defaults to the current local user.
+        """
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
+        if host is not None:
+            pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
+        if host_key is not None:
+            pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if per_dial_timeout is not None:
+            pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
+        if port is None:
+            port = 22
+        if port is not None:
+            pulumi.set(__self__, "port", port)
+        if private_key is not None:
+            pulumi.set(__self__, "private_key", private_key)
+        if private_key_password is not None:
+            pulumi.set(__self__, "private_key_password", private_key_password)
+        if ssh_agent is not None:
+            pulumi.set(__self__, "ssh_agent", ssh_agent)
+        if ssh_agent_socket_path is not None:
+            pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def certificate(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
+
+    @certificate.setter
+    def certificate(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "certificate", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def host(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The address of the proxy to connect to.
+        """
+        return pulumi.get(self, "host")
+
+    @host.setter
+    def host(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "host", value)
+
+    @_builtins.property
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(
+        self,
+    ) -> pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
+
+    @host_ca_keys.setter
+    def host_ca_keys(
+        self, value: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ):
+        pulumi.set(self, "host_ca_keys", value)
+
+    @_builtins.property
+    @pulumi.getter(name="hostKey")
+    def host_key(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
+        return pulumi.get(self, "host_key")
+
+    @host_key.setter
+    def host_key(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "host_key", value)
+
+    @_builtins.property
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
+
+    @host_key_checking.setter
+    def host_key_checking(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "host_key_checking", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @known_hosts.setter
+    def known_hosts(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "known_hosts", value)
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
+    @known_hosts_file.setter
+    def known_hosts_file(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "known_hosts_file", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The password we should use for the connection to the proxy.
+        """
+        return pulumi.get(self, "password")
+
+    @password.setter
+    def password(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "password", value)
+
+    @_builtins.property
+    @pulumi.getter(name="perDialTimeout")
+    def per_dial_timeout(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "per_dial_timeout")
+
+    @per_dial_timeout.setter
+    def per_dial_timeout(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "per_dial_timeout", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def port(self) -> pulumi.Input[Optional[_builtins.float]]:
+        """
+        The port to connect to. Defaults to 22.
+        """
+        return pulumi.get(self, "port")
+
+    @port.setter
+    def port(self, value: pulumi.Input[Optional[_builtins.float]]):
+        pulumi.set(self, "port", value)
+
+    @_builtins.property
+    @pulumi.getter(name="privateKey")
+    def private_key(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The contents of an SSH key to use for the
+        connection to the proxy. This takes preference over the password if provided.
+        """
+        return pulumi.get(self, "private_key")
+
+    @private_key.setter
+    def private_key(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "private_key", value)
+
+    @_builtins.property
+    @pulumi.getter(name="privateKeyPassword")
+    def private_key_password(self) -> pulumi.Input[Optional[_builtins.str]]:
+        return pulumi.get(self, "private_key_password")
+
+    @private_key_password.setter
+    def private_key_password(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "private_key_password", value)
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgent")
+    def ssh_agent(self) -> pulumi.Input[Optional[_builtins.bool]]:
+        return pulumi.get(self, "ssh_agent")
+
+    @ssh_agent.setter
+    def ssh_agent(self, value: pulumi.Input[Optional[_builtins.bool]]):
+        pulumi.set(self, "ssh_agent", value)
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgentSocketPath")
+    def ssh_agent_socket_path(self) -> pulumi.Input[Optional[_builtins.str]]:
+        return pulumi.get(self, "ssh_agent_socket_path")
+
+    @ssh_agent_socket_path.setter
+    def ssh_agent_socket_path(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "ssh_agent_socket_path", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The user that we should use for the connection to the
+        proxy. Defaults to the current local user.
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "user", value)
+
+
+class ResourceConfigDict(TypedDict):
+    agent_gc_days: NotRequired[_builtins.int]
+    check: NotRequired[_builtins.bool]
+    delete_unreachable: NotRequired[_builtins.bool]
+    heartbeat_interval: NotRequired[_builtins.int]
+    heartbeat_timeout: NotRequired[_builtins.int]
+    parallel: NotRequired[_builtins.int]
+    python_interpreter: NotRequired[_builtins.str]
+    """
+    The Python interpreter used to run Ansible
+    modules on the remote host, either a path or a command name to look up in PATH.
+    It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+    /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+    installed. If not set, the agent picks the first suitable interpreter it finds.
+    """
+
+
+@pulumi.input_type
+class ResourceConfig:
+    def __init__(
+        __self__,
+        *,
+        agent_gc_days: Optional[_builtins.int] = None,
+        check: Optional[_builtins.bool] = None,
+        delete_unreachable: Optional[_builtins.bool] = None,
+        heartbeat_interval: Optional[_builtins.int] = None,
+        heartbeat_timeout: Optional[_builtins.int] = None,
+        parallel: Optional[_builtins.int] = None,
+        python_interpreter: Optional[_builtins.str] = None,
+    ):
+        """
+        :param _builtins.str python_interpreter: The Python interpreter used to run Ansible
+               modules on the remote host, either a path or a command name to look up in PATH.
+               It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+               /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+               installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        if agent_gc_days is not None:
+            pulumi.set(__self__, "agent_gc_days", agent_gc_days)
+        if check is not None:
+            pulumi.set(__self__, "check", check)
+        if delete_unreachable is not None:
+            pulumi.set(__self__, "delete_unreachable", delete_unreachable)
+        if heartbeat_interval is not None:
+            pulumi.set(__self__, "heartbeat_interval", heartbeat_interval)
+        if heartbeat_timeout is not None:
+            pulumi.set(__self__, "heartbeat_timeout", heartbeat_timeout)
+        if parallel is not None:
+            pulumi.set(__self__, "parallel", parallel)
+        if python_interpreter is not None:
+            pulumi.set(__self__, "python_interpreter", python_interpreter)
+
+    @_builtins.property
+    @pulumi.getter(name="agentGCDays")
+    def agent_gc_days(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "agent_gc_days")
+
+    @agent_gc_days.setter
+    def agent_gc_days(self, value: Optional[_builtins.int]):
+        pulumi.set(self, "agent_gc_days", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def check(self) -> Optional[_builtins.bool]:
+        return pulumi.get(self, "check")
+
+    @check.setter
+    def check(self, value: Optional[_builtins.bool]):
+        pulumi.set(self, "check", value)
+
+    @_builtins.property
+    @pulumi.getter(name="deleteUnreachable")
+    def delete_unreachable(self) -> Optional[_builtins.bool]:
+        return pulumi.get(self, "delete_unreachable")
+
+    @delete_unreachable.setter
+    def delete_unreachable(self, value: Optional[_builtins.bool]):
+        pulumi.set(self, "delete_unreachable", value)
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatInterval")
+    def heartbeat_interval(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "heartbeat_interval")
+
+    @heartbeat_interval.setter
+    def heartbeat_interval(self, value: Optional[_builtins.int]):
+        pulumi.set(self, "heartbeat_interval", value)
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatTimeout")
+    def heartbeat_timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "heartbeat_timeout")
+
+    @heartbeat_timeout.setter
+    def heartbeat_timeout(self, value: Optional[_builtins.int]):
+        pulumi.set(self, "heartbeat_timeout", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def parallel(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "parallel")
+
+    @parallel.setter
+    def parallel(self, value: Optional[_builtins.int]):
+        pulumi.set(self, "parallel", value)
+
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> Optional[_builtins.str]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return pulumi.get(self, "python_interpreter")
+
+    @python_interpreter.setter
+    def python_interpreter(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "python_interpreter", value)
+
+
+class ResourceConfigArgsDict(TypedDict):
+    agent_gc_days: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    check: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
+    delete_unreachable: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
+    heartbeat_interval: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    heartbeat_timeout: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    parallel: NotRequired[pulumi.Input[Optional[_builtins.int]]]
+    python_interpreter: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The Python interpreter used to run Ansible
+    modules on the remote host, either a path or a command name to look up in PATH.
+    It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+    /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+    installed. If not set, the agent picks the first suitable interpreter it finds.
+    """
+
+
+@pulumi.input_type
+class ResourceConfigArgs:
+    def __init__(
+        __self__,
+        *,
+        agent_gc_days: pulumi.Input[Optional[_builtins.int]] = None,
+        check: pulumi.Input[Optional[_builtins.bool]] = None,
+        delete_unreachable: pulumi.Input[Optional[_builtins.bool]] = None,
+        heartbeat_interval: pulumi.Input[Optional[_builtins.int]] = None,
+        heartbeat_timeout: pulumi.Input[Optional[_builtins.int]] = None,
+        parallel: pulumi.Input[Optional[_builtins.int]] = None,
+        python_interpreter: pulumi.Input[Optional[_builtins.str]] = None,
+    ):
+        """
+        :param pulumi.Input[_builtins.str] python_interpreter: The Python interpreter used to run Ansible
+               modules on the remote host, either a path or a command name to look up in PATH.
+               It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+               /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+               installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        if agent_gc_days is not None:
+            pulumi.set(__self__, "agent_gc_days", agent_gc_days)
+        if check is not None:
+            pulumi.set(__self__, "check", check)
+        if delete_unreachable is not None:
+            pulumi.set(__self__, "delete_unreachable", delete_unreachable)
+        if heartbeat_interval is not None:
+            pulumi.set(__self__, "heartbeat_interval", heartbeat_interval)
+        if heartbeat_timeout is not None:
+            pulumi.set(__self__, "heartbeat_timeout", heartbeat_timeout)
+        if parallel is not None:
+            pulumi.set(__self__, "parallel", parallel)
+        if python_interpreter is not None:
+            pulumi.set(__self__, "python_interpreter", python_interpreter)
+
+    @_builtins.property
+    @pulumi.getter(name="agentGCDays")
+    def agent_gc_days(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "agent_gc_days")
+
+    @agent_gc_days.setter
+    def agent_gc_days(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "agent_gc_days", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def check(self) -> pulumi.Input[Optional[_builtins.bool]]:
+        return pulumi.get(self, "check")
+
+    @check.setter
+    def check(self, value: pulumi.Input[Optional[_builtins.bool]]):
+        pulumi.set(self, "check", value)
+
+    @_builtins.property
+    @pulumi.getter(name="deleteUnreachable")
+    def delete_unreachable(self) -> pulumi.Input[Optional[_builtins.bool]]:
+        return pulumi.get(self, "delete_unreachable")
+
+    @delete_unreachable.setter
+    def delete_unreachable(self, value: pulumi.Input[Optional[_builtins.bool]]):
+        pulumi.set(self, "delete_unreachable", value)
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatInterval")
+    def heartbeat_interval(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "heartbeat_interval")
+
+    @heartbeat_interval.setter
+    def heartbeat_interval(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "heartbeat_interval", value)
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatTimeout")
+    def heartbeat_timeout(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "heartbeat_timeout")
+
+    @heartbeat_timeout.setter
+    def heartbeat_timeout(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "heartbeat_timeout", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def parallel(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "parallel")
+
+    @parallel.setter
+    def parallel(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "parallel", value)
+
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return pulumi.get(self, "python_interpreter")
+
+    @python_interpreter.setter
+    def python_interpreter(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "python_interpreter", value)
+
+
+class RunAsDict(TypedDict):
+    """
+    The user and groups to run as instead of the user the agent
+    runs as. Switching users requires the agent to run as root.
+    """
+
+    group: NotRequired[_builtins.str]
+    """
+    The name or numeric ID of the primary group. Defaults to
+    the primary group of the user.
+    """
+    groups: NotRequired[Sequence[_builtins.str]]
+    """
+    The names or numeric IDs of the supplementary groups.
+    Defaults to the groups the user is a member of.
+    """
+    login_env: NotRequired[_builtins.bool]
+    """
+    Start with the environment a login of the user would
+    get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+    if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+    DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+    unless another directory is given. Defaults to false.
+    """
+    user: NotRequired[_builtins.str]
+    """
+    The name or numeric ID of the user to run as. Defaults to
+    the agent's user.
+    """
+
+
+@pulumi.input_type
+class RunAs:
+    def __init__(
+        __self__,
+        *,
+        group: Optional[_builtins.str] = None,
+        groups: Optional[Sequence[_builtins.str]] = None,
+        login_env: Optional[_builtins.bool] = None,
+        user: Optional[_builtins.str] = None,
+    ):
+        """
+        The user and groups to run as instead of the user the agent
+        runs as. Switching users requires the agent to run as root.
+
+        :param _builtins.str group: The name or numeric ID of the primary group. Defaults to
+               the primary group of the user.
+        :param Sequence[_builtins.str] groups: The names or numeric IDs of the supplementary groups.
+               Defaults to the groups the user is a member of.
+        :param _builtins.bool login_env: Start with the environment a login of the user would
+               get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+               if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+               DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+               unless another directory is given. Defaults to false.
+        :param _builtins.str user: The name or numeric ID of the user to run as. Defaults to
+               the agent's user.
+        """
+        if group is not None:
+            pulumi.set(__self__, "group", group)
+        if groups is not None:
+            pulumi.set(__self__, "groups", groups)
+        if login_env is not None:
+            pulumi.set(__self__, "login_env", login_env)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def group(self) -> Optional[_builtins.str]:
+        """
+        The name or numeric ID of the primary group. Defaults to
+        the primary group of the user.
+        """
+        return pulumi.get(self, "group")
+
+    @group.setter
+    def group(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "group", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def groups(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The names or numeric IDs of the supplementary groups.
+        Defaults to the groups the user is a member of.
+        """
+        return pulumi.get(self, "groups")
+
+    @groups.setter
+    def groups(self, value: Optional[Sequence[_builtins.str]]):
+        pulumi.set(self, "groups", value)
+
+    @_builtins.property
+    @pulumi.getter(name="loginEnv")
+    def login_env(self) -> Optional[_builtins.bool]:
+        """
+        Start with the environment a login of the user would
+        get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+        if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+        DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+        unless another directory is given. Defaults to false.
+        """
+        return pulumi.get(self, "login_env")
+
+    @login_env.setter
+    def login_env(self, value: Optional[_builtins.bool]):
+        pulumi.set(self, "login_env", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The name or numeric ID of the user to run as. Defaults to
+        the agent's user.
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: Optional[_builtins.str]):
+        pulumi.set(self, "user", value)
+
+
+class RunAsArgsDict(TypedDict):
+    """
+    The user and groups to run as instead of the user the agent
+    runs as. Switching users requires the agent to run as root.
+    """
+
+    group: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The name or numeric ID of the primary group. Defaults to
+    the primary group of the user.
+    """
+    groups: NotRequired[pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]]
+    """
+    The names or numeric IDs of the supplementary groups.
+    Defaults to the groups the user is a member of.
+    """
+    login_env: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
+    """
+    Start with the environment a login of the user would
+    get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+    if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+    DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+    unless another directory is given. Defaults to false.
+    """
+    user: NotRequired[pulumi.Input[Optional[_builtins.str]]]
+    """
+    The name or numeric ID of the user to run as. Defaults to
+    the agent's user.
+    """
+
+
+@pulumi.input_type
+class RunAsArgs:
+    def __init__(
+        __self__,
+        *,
+        group: pulumi.Input[Optional[_builtins.str]] = None,
+        groups: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]] = None,
+        login_env: pulumi.Input[Optional[_builtins.bool]] = None,
+        user: pulumi.Input[Optional[_builtins.str]] = None,
+    ):
+        """
+        The user and groups to run as instead of the user the agent
+        runs as. Switching users requires the agent to run as root.
+
+        :param pulumi.Input[_builtins.str] group: The name or numeric ID of the primary group. Defaults to
+               the primary group of the user.
+        :param pulumi.Input[Sequence[pulumi.Input[_builtins.str]]] groups: The names or numeric IDs of the supplementary groups.
+               Defaults to the groups the user is a member of.
+        :param pulumi.Input[_builtins.bool] login_env: Start with the environment a login of the user would
+               get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+               if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+               DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+               unless another directory is given. Defaults to false.
+        :param pulumi.Input[_builtins.str] user: The name or numeric ID of the user to run as. Defaults to
+               the agent's user.
+        """
+        if group is not None:
+            pulumi.set(__self__, "group", group)
+        if groups is not None:
+            pulumi.set(__self__, "groups", groups)
+        if login_env is not None:
+            pulumi.set(__self__, "login_env", login_env)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def group(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The name or numeric ID of the primary group. Defaults to
+        the primary group of the user.
+        """
+        return pulumi.get(self, "group")
+
+    @group.setter
+    def group(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "group", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def groups(self) -> pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]:
+        """
+        The names or numeric IDs of the supplementary groups.
+        Defaults to the groups the user is a member of.
+        """
+        return pulumi.get(self, "groups")
+
+    @groups.setter
+    def groups(
+        self, value: pulumi.Input[Optional[Sequence[pulumi.Input[_builtins.str]]]]
+    ):
+        pulumi.set(self, "groups", value)
+
+    @_builtins.property
+    @pulumi.getter(name="loginEnv")
+    def login_env(self) -> pulumi.Input[Optional[_builtins.bool]]:
+        """
+        Start with the environment a login of the user would
+        get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+        if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+        DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+        unless another directory is given. Defaults to false.
+        """
+        return pulumi.get(self, "login_env")
+
+    @login_env.setter
+    def login_env(self, value: pulumi.Input[Optional[_builtins.bool]]):
+        pulumi.set(self, "login_env", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The name or numeric ID of the user to run as. Defaults to
+        the agent's user.
+        """
+        return pulumi.get(self, "user")
+
+    @user.setter
+    def user(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "user", value)
 
 
 class TriggersInputArgsDict(TypedDict):
@@ -8,6 +8,7 @@ import typing
 
 # Export this package's modules as members:
 from .agent_ping import *
+from .agent_uninstall import *
 from .ansible_execute import *
 from .exec_ import *
 from .file_stat import *
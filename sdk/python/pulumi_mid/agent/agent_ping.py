@@ -26,13 +26,61 @@ __all__ = [
 
 @pulumi.output_type
 class AgentPingResult:
-    def __init__(__self__, ping=None, pong=None):
+    def __init__(
+        __self__,
+        agent_version=None,
+        ansible_install_cached=None,
+        ansible_install_seconds=None,
+        host_key=None,
+        ping=None,
+        pong=None,
+        protocol_version=None,
+        rpc_functions=None,
+    ):
+        if agent_version and not isinstance(agent_version, str):
+            raise TypeError("Expected argument 'agent_version' to be a str")
+        pulumi.set(__self__, "agent_version", agent_version)
+        if ansible_install_cached and not isinstance(ansible_install_cached, bool):
+            raise TypeError("Expected argument 'ansible_install_cached' to be a bool")
+        pulumi.set(__self__, "ansible_install_cached", ansible_install_cached)
+        if ansible_install_seconds and not isinstance(ansible_install_seconds, float):
+            raise TypeError("Expected argument 'ansible_install_seconds' to be a float")
+        pulumi.set(__self__, "ansible_install_seconds", ansible_install_seconds)
+        if host_key and not isinstance(host_key, str):
+            raise TypeError("Expected argument 'host_key' to be a str")
+        pulumi.set(__self__, "host_key", host_key)
         if ping and not isinstance(ping, str):
             raise TypeError("Expected argument 'ping' to be a str")
         pulumi.set(__self__, "ping", ping)
         if pong and not isinstance(pong, str):
             raise TypeError("Expected argument 'pong' to be a str")
         pulumi.set(__self__, "pong", pong)
+        if protocol_version and not isinstance(protocol_version, int):
+            raise TypeError("Expected argument 'protocol_version' to be a int")
+        pulumi.set(__self__, "protocol_version", protocol_version)
+        if rpc_functions and not isinstance(rpc_functions, list):
+            raise TypeError("Expected argument 'rpc_functions' to be a list")
+        pulumi.set(__self__, "rpc_functions", rpc_functions)
+
+    @_builtins.property
+    @pulumi.getter(name="agentVersion")
+    def agent_version(self) -> _builtins.str:
+        return pulumi.get(self, "agent_version")
+
+    @_builtins.property
+    @pulumi.getter(name="ansibleInstallCached")
+    def ansible_install_cached(self) -> Optional[_builtins.bool]:
+        return pulumi.get(self, "ansible_install_cached")
+
+    @_builtins.property
+    @pulumi.getter(name="ansibleInstallSeconds")
+    def ansible_install_seconds(self) -> Optional[_builtins.float]:
+        return pulumi.get(self, "ansible_install_seconds")
+
+    @_builtins.property
+    @pulumi.getter(name="hostKey")
+    def host_key(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "host_key")
 
     @_builtins.property
     @pulumi.getter
@@ -44,13 +92,32 @@ class AgentPingResult:
     def pong(self) -> _builtins.str:
         return pulumi.get(self, "pong")
 
+    @_builtins.property
+    @pulumi.getter(name="protocolVersion")
+    def protocol_version(self) -> _builtins.int:
+        return pulumi.get(self, "protocol_version")
+
+    @_builtins.property
+    @pulumi.getter(name="rpcFunctions")
+    def rpc_functions(self) -> Sequence[_builtins.str]:
+        return pulumi.get(self, "rpc_functions")
+
 
 class AwaitableAgentPingResult(AgentPingResult):
     # pylint: disable=using-constant-test
     def __await__(self):
         if False:
             yield self
-        return AgentPingResult(ping=self.ping, pong=self.pong)
+        return AgentPingResult(
+            agent_version=self.agent_version,
+            ansible_install_cached=self.ansible_install_cached,
+            ansible_install_seconds=self.ansible_install_seconds,
+            host_key=self.host_key,
+            ping=self.ping,
+            pong=self.pong,
+            protocol_version=self.protocol_version,
+            rpc_functions=self.rpc_functions,
+        )
 
 
 def agent_ping(
@@ -76,7 +143,14 @@ def agent_ping(
     ).value
 
     return AwaitableAgentPingResult(
-        ping=pulumi.get(__ret__, "ping"), pong=pulumi.get(__ret__, "pong")
+        agent_version=pulumi.get(__ret__, "agent_version"),
+        ansible_install_cached=pulumi.get(__ret__, "ansible_install_cached"),
+        ansible_install_seconds=pulumi.get(__ret__, "ansible_install_seconds"),
+        host_key=pulumi.get(__ret__, "host_key"),
+        ping=pulumi.get(__ret__, "ping"),
+        pong=pulumi.get(__ret__, "pong"),
+        protocol_version=pulumi.get(__ret__, "protocol_version"),
+        rpc_functions=pulumi.get(__ret__, "rpc_functions"),
     )
 
 
@@ -109,6 +183,13 @@ def agent_ping_output(
     )
     return __ret__.apply(
         lambda __response__: AgentPingResult(
-            ping=pulumi.get(__response__, "ping"), pong=pulumi.get(__response__, "pong")
+            agent_version=pulumi.get(__response__, "agent_version"),
+            ansible_install_cached=pulumi.get(__response__, "ansible_install_cached"),
+            ansible_install_seconds=pulumi.get(__response__, "ansible_install_seconds"),
+            host_key=pulumi.get(__response__, "host_key"),
+            ping=pulumi.get(__response__, "ping"),
+            pong=pulumi.get(__response__, "pong"),
+            protocol_version=pulumi.get(__response__, "protocol_version"),
+            rpc_functions=pulumi.get(__response__, "rpc_functions"),
         )
     )
@@ -0,0 +1,112 @@
+# coding=utf-8
+# *** WARNING: this file was generated by pulumi-language-python. ***
+# *** Do not edit by hand unless you're certain you know what you are doing! ***
+
+import builtins as _builtins
+import warnings
+import sys
+import pulumi
+import pulumi.runtime
+from typing import Any, Mapping, Optional, Sequence, Union, overload
+
+if sys.version_info >= (3, 11):
+    from typing import NotRequired, TypedDict, TypeAlias
+else:
+    from typing_extensions import NotRequired, TypedDict, TypeAlias
+from .. import _utilities
+from .. import _inputs as _root_inputs
+
+__all__ = [
+    "AgentUninstallResult",
+    "AwaitableAgentUninstallResult",
+    "agent_uninstall",
+    "agent_uninstall_output",
+]
+
+
+@pulumi.output_type
+class AgentUninstallResult:
+    def __init__(__self__, agent_dir=None, removed=None):
+        if agent_dir and not isinstance(agent_dir, str):
+            raise TypeError("Expected argument 'agent_dir' to be a str")
+        pulumi.set(__self__, "agent_dir", agent_dir)
+        if removed and not isinstance(removed, bool):
+            raise TypeError("Expected argument 'removed' to be a bool")
+        pulumi.set(__self__, "removed", removed)
+
+    @_builtins.property
+    @pulumi.getter(name="agentDir")
+    def agent_dir(self) -> _builtins.str:
+        return pulumi.get(self, "agent_dir")
+
+    @_builtins.property
+    @pulumi.getter
+    def removed(self) -> _builtins.bool:
+        return pulumi.get(self, "removed")
+
+
+class AwaitableAgentUninstallResult(AgentUninstallResult):
+    # pylint: disable=using-constant-test
+    def __await__(self):
+        if False:
+            yield self
+        return AgentUninstallResult(agent_dir=self.agent_dir, removed=self.removed)
+
+
+def agent_uninstall(
+    config: Optional[
+        Union["_root_inputs.ResourceConfig", "_root_inputs.ResourceConfigDict"]
+    ] = None,
+    connection: Optional[
+        Union["_root_inputs.Connection", "_root_inputs.ConnectionDict"]
+    ] = None,
+    opts: Optional[pulumi.InvokeOptions] = None,
+) -> AwaitableAgentUninstallResult:
+    """
+    Use this data source to access information about an existing resource.
+    """
+    __args__ = dict()
+    __args__["config"] = config
+    __args__["connection"] = connection
+    opts = pulumi.InvokeOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
+    __ret__ = pulumi.runtime.invoke(
+        "mid:agent:agentUninstall", __args__, opts=opts, typ=AgentUninstallResult
+    ).value
+
+    return AwaitableAgentUninstallResult(
+        agent_dir=pulumi.get(__ret__, "agent_dir"),
+        removed=pulumi.get(__ret__, "removed"),
+    )
+
+
+def agent_uninstall_output(
+    config: pulumi.Input[
+        Optional[
+            Optional[
+                Union["_root_inputs.ResourceConfig", "_root_inputs.ResourceConfigDict"]
+            ]
+        ]
+    ] = None,
+    connection: pulumi.Input[
+        Optional[
+            Optional[Union["_root_inputs.Connection", "_root_inputs.ConnectionDict"]]
+        ]
+    ] = None,
+    opts: Optional[Union[pulumi.InvokeOptions, pulumi.InvokeOutputOptions]] = None,
+) -> pulumi.Output[AgentUninstallResult]:
+    """
+    Use this data source to access information about an existing resource.
+    """
+    __args__ = dict()
+    __args__["config"] = config
+    __args__["connection"] = connection
+    opts = pulumi.InvokeOutputOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
+    __ret__ = pulumi.runtime.invoke_output(
+        "mid:agent:agentUninstall", __args__, opts=opts, typ=AgentUninstallResult
+    )
+    return __ret__.apply(
+        lambda __response__: AgentUninstallResult(
+            agent_dir=pulumi.get(__response__, "agent_dir"),
+            removed=pulumi.get(__response__, "removed"),
+        )
+    )
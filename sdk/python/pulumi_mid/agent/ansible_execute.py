@@ -38,9 +38,12 @@ class AnsibleExecuteResult:
         environment=None,
         exit_code=None,
         name=None,
+        python_interpreter=None,
         result=None,
+        run_as=None,
         stderr=None,
         stdout=None,
+        timeout=None,
     ):
         if args and not isinstance(args, dict):
             raise TypeError("Expected argument 'args' to be a dict")
@@ -69,15 +72,24 @@ class AnsibleExecuteResult:
         if name and not isinstance(name, str):
             raise TypeError("Expected argument 'name' to be a str")
         pulumi.set(__self__, "name", name)
+        if python_interpreter and not isinstance(python_interpreter, str):
+            raise TypeError("Expected argument 'python_interpreter' to be a str")
+        pulumi.set(__self__, "python_interpreter", python_interpreter)
         if result and not isinstance(result, dict):
             raise TypeError("Expected argument 'result' to be a dict")
         pulumi.set(__self__, "result", result)
+        if run_as and not isinstance(run_as, dict):
+            raise TypeError("Expected argument 'run_as' to be a dict")
+        pulumi.set(__self__, "run_as", run_as)
         if stderr and not isinstance(stderr, str):
             raise TypeError("Expected argument 'stderr' to be a str")
         pulumi.set(__self__, "stderr", stderr)
         if stdout and not isinstance(stdout, str):
             raise TypeError("Expected argument 'stdout' to be a str")
         pulumi.set(__self__, "stdout", stdout)
+        if timeout and not isinstance(timeout, int):
+            raise TypeError("Expected argument 'timeout' to be a int")
+        pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -124,11 +136,21 @@ class AnsibleExecuteResult:
     def name(self) -> _builtins.str:
         return pulumi.get(self, "name")
 
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "python_interpreter")
+
     @_builtins.property
     @pulumi.getter
     def result(self) -> Mapping[str, Any]:
         return pulumi.get(self, "result")
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> Optional["_root_outputs.RunAs"]:
+        return pulumi.get(self, "run_as")
+
     @_builtins.property
     @pulumi.getter
     def stderr(self) -> _builtins.str:
@@ -139,6 +161,11 @@ class AnsibleExecuteResult:
     def stdout(self) -> _builtins.str:
         return pulumi.get(self, "stdout")
 
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "timeout")
+
 
 class AwaitableAnsibleExecuteResult(AnsibleExecuteResult):
     # pylint: disable=using-constant-test
@@ -155,9 +182,12 @@ class AwaitableAnsibleExecuteResult(AnsibleExecuteResult):
             environment=self.environment,
             exit_code=self.exit_code,
             name=self.name,
+            python_interpreter=self.python_interpreter,
             result=self.result,
+            run_as=self.run_as,
             stderr=self.stderr,
             stdout=self.stdout,
+            timeout=self.timeout,
         )
 
 
@@ -173,6 +203,8 @@ def ansible_execute(
     debug_keep_temp_files: Optional[_builtins.bool] = None,
     environment: Optional[Mapping[str, _builtins.str]] = None,
     name: Optional[_builtins.str] = None,
+    run_as: Optional[Union["_root_inputs.RunAs", "_root_inputs.RunAsDict"]] = None,
+    timeout: Optional[_builtins.int] = None,
     opts: Optional[pulumi.InvokeOptions] = None,
 ) -> AwaitableAnsibleExecuteResult:
     """
@@ -186,6 +218,8 @@ def ansible_execute(
     __args__["debugKeepTempFiles"] = debug_keep_temp_files
     __args__["environment"] = environment
     __args__["name"] = name
+    __args__["runAs"] = run_as
+    __args__["timeout"] = timeout
     opts = pulumi.InvokeOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
     __ret__ = pulumi.runtime.invoke(
         "mid:agent:ansibleExecute", __args__, opts=opts, typ=AnsibleExecuteResult
@@ -201,9 +235,12 @@ def ansible_execute(
         environment=pulumi.get(__ret__, "environment"),
         exit_code=pulumi.get(__ret__, "exit_code"),
         name=pulumi.get(__ret__, "name"),
+        python_interpreter=pulumi.get(__ret__, "python_interpreter"),
         result=pulumi.get(__ret__, "result"),
+        run_as=pulumi.get(__ret__, "run_as"),
         stderr=pulumi.get(__ret__, "stderr"),
         stdout=pulumi.get(__ret__, "stdout"),
+        timeout=pulumi.get(__ret__, "timeout"),
     )
 
 
@@ -225,6 +262,10 @@ def ansible_execute_output(
     debug_keep_temp_files: pulumi.Input[Optional[Optional[_builtins.bool]]] = None,
     environment: pulumi.Input[Optional[Optional[Mapping[str, _builtins.str]]]] = None,
     name: pulumi.Input[Optional[_builtins.str]] = None,
+    run_as: pulumi.Input[
+        Optional[Optional[Union["_root_inputs.RunAs", "_root_inputs.RunAsDict"]]]
+    ] = None,
+    timeout: pulumi.Input[Optional[Optional[_builtins.int]]] = None,
     opts: Optional[Union[pulumi.InvokeOptions, pulumi.InvokeOutputOptions]] = None,
 ) -> pulumi.Output[AnsibleExecuteResult]:
     """
@@ -238,6 +279,8 @@ def ansible_execute_output(
     __args__["debugKeepTempFiles"] = debug_keep_temp_files
     __args__["environment"] = environment
     __args__["name"] = name
+    __args__["runAs"] = run_as
+    __args__["timeout"] = timeout
     opts = pulumi.InvokeOutputOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
     __ret__ = pulumi.runtime.invoke_output(
         "mid:agent:ansibleExecute", __args__, opts=opts, typ=AnsibleExecuteResult
@@ -253,8 +296,11 @@ def ansible_execute_output(
             environment=pulumi.get(__response__, "environment"),
             exit_code=pulumi.get(__response__, "exit_code"),
             name=pulumi.get(__response__, "name"),
+            python_interpreter=pulumi.get(__response__, "python_interpreter"),
             result=pulumi.get(__response__, "result"),
+            run_as=pulumi.get(__response__, "run_as"),
             stderr=pulumi.get(__response__, "stderr"),
             stdout=pulumi.get(__response__, "stdout"),
+            timeout=pulumi.get(__response__, "timeout"),
         )
     )
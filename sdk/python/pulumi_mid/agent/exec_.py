@@ -37,9 +37,11 @@ class ExecResult:
         exit_code=None,
         expand_argument_vars=None,
         pid=None,
+        run_as=None,
         stderr=None,
         stdin=None,
         stdout=None,
+        timeout=None,
     ):
         if command and not isinstance(command, list):
             raise TypeError("Expected argument 'command' to be a list")
@@ -65,6 +67,9 @@ class ExecResult:
         if pid and not isinstance(pid, int):
             raise TypeError("Expected argument 'pid' to be a int")
         pulumi.set(__self__, "pid", pid)
+        if run_as and not isinstance(run_as, dict):
+            raise TypeError("Expected argument 'run_as' to be a dict")
+        pulumi.set(__self__, "run_as", run_as)
         if stderr and not isinstance(stderr, str):
             raise TypeError("Expected argument 'stderr' to be a str")
         pulumi.set(__self__, "stderr", stderr)
@@ -74,6 +79,9 @@ class ExecResult:
         if stdout and not isinstance(stdout, str):
             raise TypeError("Expected argument 'stdout' to be a str")
         pulumi.set(__self__, "stdout", stdout)
+        if timeout and not isinstance(timeout, int):
+            raise TypeError("Expected argument 'timeout' to be a int")
+        pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -115,6 +123,11 @@ class ExecResult:
     def pid(self) -> _builtins.int:
         return pulumi.get(self, "pid")
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> Optional["_root_outputs.RunAs"]:
+        return pulumi.get(self, "run_as")
+
     @_builtins.property
     @pulumi.getter
     def stderr(self) -> _builtins.str:
@@ -130,6 +143,11 @@ class ExecResult:
     def stdout(self) -> _builtins.str:
         return pulumi.get(self, "stdout")
 
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "timeout")
+
 
 class AwaitableExecResult(ExecResult):
     # pylint: disable=using-constant-test
@@ -145,9 +163,11 @@ class AwaitableExecResult(ExecResult):
             exit_code=self.exit_code,
             expand_argument_vars=self.expand_argument_vars,
             pid=self.pid,
+            run_as=self.run_as,
             stderr=self.stderr,
             stdin=self.stdin,
             stdout=self.stdout,
+            timeout=self.timeout,
         )
 
 
@@ -162,7 +182,9 @@ def exec_(
     dir: Optional[_builtins.str] = None,
     environment: Optional[Mapping[str, _builtins.str]] = None,
     expand_argument_vars: Optional[_builtins.bool] = None,
+    run_as: Optional[Union["_root_inputs.RunAs", "_root_inputs.RunAsDict"]] = None,
     stdin: Optional[_builtins.str] = None,
+    timeout: Optional[_builtins.int] = None,
     opts: Optional[pulumi.InvokeOptions] = None,
 ) -> AwaitableExecResult:
     """
@@ -175,7 +197,9 @@ def exec_(
     __args__["dir"] = dir
     __args__["environment"] = environment
     __args__["expandArgumentVars"] = expand_argument_vars
+    __args__["runAs"] = run_as
     __args__["stdin"] = stdin
+    __args__["timeout"] = timeout
     opts = pulumi.InvokeOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
     __ret__ = pulumi.runtime.invoke(
         "mid:agent:exec", __args__, opts=opts, typ=ExecResult
@@ -190,9 +214,11 @@ def exec_(
         exit_code=pulumi.get(__ret__, "exit_code"),
         expand_argument_vars=pulumi.get(__ret__, "expand_argument_vars"),
         pid=pulumi.get(__ret__, "pid"),
+        run_as=pulumi.get(__ret__, "run_as"),
         stderr=pulumi.get(__ret__, "stderr"),
         stdin=pulumi.get(__ret__, "stdin"),
         stdout=pulumi.get(__ret__, "stdout"),
+        timeout=pulumi.get(__ret__, "timeout"),
     )
 
 
@@ -213,7 +239,11 @@ def exec__output(
     dir: pulumi.Input[Optional[Optional[_builtins.str]]] = None,
     environment: pulumi.Input[Optional[Optional[Mapping[str, _builtins.str]]]] = None,
     expand_argument_vars: pulumi.Input[Optional[Optional[_builtins.bool]]] = None,
+    run_as: pulumi.Input[
+        Optional[Optional[Union["_root_inputs.RunAs", "_root_inputs.RunAsDict"]]]
+    ] = None,
     stdin: pulumi.Input[Optional[Optional[_builtins.str]]] = None,
+    timeout: pulumi.Input[Optional[Optional[_builtins.int]]] = None,
     opts: Optional[Union[pulumi.InvokeOptions, pulumi.InvokeOutputOptions]] = None,
 ) -> pulumi.Output[ExecResult]:
     """
@@ -226,7 +256,9 @@ def exec__output(
     __args__["dir"] = dir
     __args__["environment"] = environment
     __args__["expandArgumentVars"] = expand_argument_vars
+    __args__["runAs"] = run_as
     __args__["stdin"] = stdin
+    __args__["timeout"] = timeout
     opts = pulumi.InvokeOutputOptions.merge(_utilities.get_invoke_opts_defaults(), opts)
     __ret__ = pulumi.runtime.invoke_output(
         "mid:agent:exec", __args__, opts=opts, typ=ExecResult
@@ -241,8 +273,10 @@ def exec__output(
             exit_code=pulumi.get(__response__, "exit_code"),
             expand_argument_vars=pulumi.get(__response__, "expand_argument_vars"),
             pid=pulumi.get(__response__, "pid"),
+            run_as=pulumi.get(__response__, "run_as"),
             stderr=pulumi.get(__response__, "stderr"),
             stdin=pulumi.get(__response__, "stdin"),
             stdout=pulumi.get(__response__, "stdout"),
+            timeout=pulumi.get(__response__, "timeout"),
         )
     )
@@ -16,10 +16,25 @@ else:
 from .. import _utilities
 from .. import outputs as _root_outputs
 
+agentGCDays: Optional[int]
+
 check: Optional[bool]
 
 connection: Optional[str]
 
 deleteUnreachable: Optional[bool]
 
+heartbeatInterval: Optional[int]
+
+heartbeatTimeout: Optional[int]
+
 parallel: Optional[int]
+
+pythonInterpreter: Optional[str]
+"""
+The Python interpreter used to run Ansible
+modules on the remote host, either a path or a command name to look up in PATH.
+It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+/usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+installed. If not set, the agent picks the first suitable interpreter it finds.
+"""
@@ -22,6 +22,10 @@ __config__ = pulumi.Config("mid")
 
 
 class _ExportableConfig(types.ModuleType):
+    @_builtins.property
+    def agent_gc_days(self) -> Optional[int]:
+        return __config__.get_int("agentGCDays")
+
     @_builtins.property
     def check(self) -> Optional[bool]:
         return __config__.get_bool("check")
@@ -34,6 +38,25 @@ class _ExportableConfig(types.ModuleType):
     def delete_unreachable(self) -> Optional[bool]:
         return __config__.get_bool("deleteUnreachable")
 
+    @_builtins.property
+    def heartbeat_interval(self) -> Optional[int]:
+        return __config__.get_int("heartbeatInterval")
+
+    @_builtins.property
+    def heartbeat_timeout(self) -> Optional[int]:
+        return __config__.get_int("heartbeatTimeout")
+
     @_builtins.property
     def parallel(self) -> Optional[int]:
         return __config__.get_int("parallel")
+
+    @_builtins.property
+    def python_interpreter(self) -> Optional[str]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return __config__.get("pythonInterpreter")
@@ -17,15 +17,88 @@ from . import _utilities
 from . import outputs
 
 __all__ = [
+    "Become",
     "Connection",
     "ExecCommand",
     "FileStatFileMode",
     "FileStatState",
+    "ProxyConnection",
     "ResourceConfig",
+    "RunAs",
     "TriggersOutput",
 ]
 
 
+@pulumi.output_type
+class Become(dict):
+    """
+    Instructions for how the agent gets the privileges of another user.
+    """
+
+    def __init__(
+        __self__,
+        *,
+        flags: Optional[Sequence[_builtins.str]] = None,
+        method: Optional[_builtins.str] = None,
+        password: Optional[_builtins.str] = None,
+        user: Optional[_builtins.str] = None,
+    ):
+        """
+        Instructions for how the agent gets the privileges of another user.
+
+        :param Sequence[_builtins.str] flags: Extra flags passed to the become method.
+        :param _builtins.str method: How to switch users. One of "auto", "sudo", "doas", "su",
+               or "none". "auto" uses sudo unless the connection user already is the become
+               user, and "none" runs the agent as the connection user. Defaults to "auto".
+        :param _builtins.str password: The password to give the become method when it asks
+               for one. It is only ever written to the terminal of the become command.
+        :param _builtins.str user: The user the agent runs as. Defaults to "root".
+        """
+        if flags is not None:
+            pulumi.set(__self__, "flags", flags)
+        if method is not None:
+            pulumi.set(__self__, "method", method)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def flags(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        Extra flags passed to the become method.
+        """
+        return pulumi.get(self, "flags")
+
+    @_builtins.property
+    @pulumi.getter
+    def method(self) -> Optional[_builtins.str]:
+        """
+        How to switch users. One of "auto", "sudo", "doas", "su",
+        or "none". "auto" uses sudo unless the connection user already is the become
+        user, and "none" runs the agent as the connection user. Defaults to "auto".
+        """
+        return pulumi.get(self, "method")
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> Optional[_builtins.str]:
+        """
+        The password to give the become method when it asks
+        for one. It is only ever written to the terminal of the become command.
+        """
+        return pulumi.get(self, "password")
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The user the agent runs as. Defaults to "root".
+        """
+        return pulumi.get(self, "user")
+
+
 @pulumi.output_type
 class Connection(dict):
     """
@@ -35,8 +108,18 @@ class Connection(dict):
     @staticmethod
     def __key_warning(key: str):
         suggest = None
-        if key == "hostKey":
+        if key == "agentDir":
+            suggest = "agent_dir"
+        elif key == "hostCAKeys":
+            suggest = "host_ca_keys"
+        elif key == "hostKey":
             suggest = "host_key"
+        elif key == "hostKeyChecking":
+            suggest = "host_key_checking"
+        elif key == "knownHosts":
+            suggest = "known_hosts"
+        elif key == "knownHostsFile":
+            suggest = "known_hosts_file"
         elif key == "perDialTimeout":
             suggest = "per_dial_timeout"
         elif key == "privateKey":
@@ -47,6 +130,8 @@ class Connection(dict):
             suggest = "ssh_agent"
         elif key == "sshAgentSocketPath":
             suggest = "ssh_agent_socket_path"
+        elif key == "sshConfig":
+            suggest = "ssh_config"
 
         if suggest:
             pulumi.log.warn(
@@ -64,52 +149,153 @@ class Connection(dict):
     def __init__(
         __self__,
         *,
+        agent_dir: Optional[_builtins.str] = None,
+        become: Optional["outputs.Become"] = None,
+        certificate: Optional[_builtins.str] = None,
         host: Optional[_builtins.str] = None,
+        host_ca_keys: Optional[Sequence[_builtins.str]] = None,
         host_key: Optional[_builtins.str] = None,
+        host_key_checking: Optional[_builtins.str] = None,
+        known_hosts: Optional[_builtins.str] = None,
+        known_hosts_file: Optional[_builtins.str] = None,
         password: Optional[_builtins.str] = None,
         per_dial_timeout: Optional[_builtins.int] = None,
         port: Optional[_builtins.float] = None,
         private_key: Optional[_builtins.str] = None,
         private_key_password: Optional[_builtins.str] = None,
+        proxies: Optional[Sequence["outputs.ProxyConnection"]] = None,
         ssh_agent: Optional[_builtins.bool] = None,
         ssh_agent_socket_path: Optional[_builtins.str] = None,
+        ssh_config: Optional[_builtins.str] = None,
         user: Optional[_builtins.str] = None,
     ):
         """
         Instructions for how to connect to a remote endpoint.
 
+        :param _builtins.str agent_dir: The directory the agent, its staging area, and the
+               Ansible modules are installed in on the remote host. Relative paths are
+               relative to the home directory of the user and can't leave it. Since the
+               directory is removed when uninstalling the agent, absolute paths need at least
+               two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        :param 'Become' become: How the agent gets root (or another user's)
+               privileges. Each setting given for a resource overrides the same setting of the
+               provider.
+        :param _builtins.str certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
         :param _builtins.str host: The address of the resource to connect to.
+        :param Sequence[_builtins.str] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param _builtins.str host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param _builtins.str host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param _builtins.str known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param _builtins.str known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
         :param _builtins.str password: The password we should use for the connection.
         :param _builtins.float port: The port to connect to. Defaults to 22.
         :param _builtins.str private_key: The contents of an SSH key to use for the
                connection. This takes preference over the password if provided.
+        :param Sequence['ProxyConnection'] proxies: Proxy (or bastion) hosts to connect through, in order.
+               The first proxy is connected to directly, each following one through the one
+               before it, and the host itself through the last one. Connections to proxies are
+               shared between all hosts that go through them.
+        :param _builtins.str ssh_config: The path of an OpenSSH client config file on the
+               machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+               HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+               for the host are used for anything the connection doesn't set itself. Include
+               and "Match host" are supported. Defaults to not reading any config file.
         :param _builtins.str user: The user that we should use for the connection.
+               Defaults to the User set for the host in sshConfig, or else the current local
+               user.
         """
+        if agent_dir is not None:
+            pulumi.set(__self__, "agent_dir", agent_dir)
+        if become is not None:
+            pulumi.set(__self__, "become", become)
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
         if host is not None:
             pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
         if host_key is not None:
             pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
         if password is not None:
             pulumi.set(__self__, "password", password)
         if per_dial_timeout is not None:
             pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
-        if port is None:
-            port = 22
         if port is not None:
             pulumi.set(__self__, "port", port)
         if private_key is not None:
             pulumi.set(__self__, "private_key", private_key)
         if private_key_password is not None:
             pulumi.set(__self__, "private_key_password", private_key_password)
+        if proxies is not None:
+            pulumi.set(__self__, "proxies", proxies)
         if ssh_agent is not None:
             pulumi.set(__self__, "ssh_agent", ssh_agent)
         if ssh_agent_socket_path is not None:
             pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
-        if user is None:
-            user = "root"
+        if ssh_config is not None:
+            pulumi.set(__self__, "ssh_config", ssh_config)
         if user is not None:
             pulumi.set(__self__, "user", user)
 
+    @_builtins.property
+    @pulumi.getter(name="agentDir")
+    def agent_dir(self) -> Optional[_builtins.str]:
+        """
+        The directory the agent, its staging area, and the
+        Ansible modules are installed in on the remote host. Relative paths are
+        relative to the home directory of the user and can't leave it. Since the
+        directory is removed when uninstalling the agent, absolute paths need at least
+        two levels (e.g. "/opt/mid"). Defaults to ".mid".
+        """
+        return pulumi.get(self, "agent_dir")
+
+    @_builtins.property
+    @pulumi.getter
+    def become(self) -> Optional["outputs.Become"]:
+        """
+        How the agent gets root (or another user's)
+        privileges. Each setting given for a resource overrides the same setting of the
+        provider.
+        """
+        return pulumi.get(self, "become")
+
+    @_builtins.property
+    @pulumi.getter
+    def certificate(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
+
     @_builtins.property
     @pulumi.getter
     def host(self) -> Optional[_builtins.str]:
@@ -118,11 +304,61 @@ class Connection(dict):
         """
         return pulumi.get(self, "host")
 
+    @_builtins.property
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
+
     @_builtins.property
     @pulumi.getter(name="hostKey")
     def host_key(self) -> Optional[_builtins.str]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
         return pulumi.get(self, "host_key")
 
+    @_builtins.property
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> Optional[_builtins.str]:
+        """
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
+
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
     @_builtins.property
     @pulumi.getter
     def password(self) -> Optional[_builtins.str]:
@@ -158,6 +394,17 @@ class Connection(dict):
     def private_key_password(self) -> Optional[_builtins.str]:
         return pulumi.get(self, "private_key_password")
 
+    @_builtins.property
+    @pulumi.getter
+    def proxies(self) -> Optional[Sequence["outputs.ProxyConnection"]]:
+        """
+        Proxy (or bastion) hosts to connect through, in order.
+        The first proxy is connected to directly, each following one through the one
+        before it, and the host itself through the last one. Connections to proxies are
+        shared between all hosts that go through them.
+        """
+        return pulumi.get(self, "proxies")
+
     @_builtins.property
     @pulumi.getter(name="sshAgent")
     def ssh_agent(self) -> Optional[_builtins.bool]:
@@ -168,11 +415,25 @@ class Connection(dict):
     def ssh_agent_socket_path(self) -> Optional[_builtins.str]:
         return pulumi.get(self, "ssh_agent_socket_path")
 
+    @_builtins.property
+    @pulumi.getter(name="sshConfig")
+    def ssh_config(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH client config file on the
+        machine running Pulumi, e.g. "~/.ssh/config", to look the host up in. Its
+        HostName, User, Port, IdentityFile, ProxyJump, and UserKnownHostsFile settings
+        for the host are used for anything the connection doesn't set itself. Include
+        and "Match host" are supported. Defaults to not reading any config file.
+        """
+        return pulumi.get(self, "ssh_config")
+
     @_builtins.property
     @pulumi.getter
     def user(self) -> Optional[_builtins.str]:
         """
         The user that we should use for the connection.
+        Defaults to the User set for the host in sshConfig, or else the current local
+        user.
         """
         return pulumi.get(self, "user")
 
@@ -186,6 +447,7 @@ class ExecCommand(dict):
         dir: Optional[_builtins.str] = None,
         environment: Optional[Mapping[str, _builtins.str]] = None,
         stdin: Optional[_builtins.str] = None,
+        timeout: Optional[_builtins.int] = None,
     ):
         """
         :param Sequence[_builtins.str] command: List of arguments to execute. Under the hood, these are passed to `execve`, bypassing any shell
@@ -195,6 +457,9 @@ class ExecCommand(dict):
         :param Mapping[str, _builtins.str] environment: Key-value pairs of environment variables to pass to the process. These are
                merged with any system-wide environment variables.
         :param _builtins.str stdin: Pass a string to the command's process as standard in.
+        :param _builtins.int timeout: Maximum number of seconds the command is allowed to run. When exceeded, the
+               command's process group is sent SIGTERM, followed by SIGKILL if it has not
+               exited 10 seconds later. Defaults to no timeout.
         """
         pulumi.set(__self__, "command", command)
         if dir is not None:
@@ -203,6 +468,8 @@ class ExecCommand(dict):
             pulumi.set(__self__, "environment", environment)
         if stdin is not None:
             pulumi.set(__self__, "stdin", stdin)
+        if timeout is not None:
+            pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -239,6 +506,16 @@ class ExecCommand(dict):
         """
         return pulumi.get(self, "stdin")
 
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> Optional[_builtins.int]:
+        """
+        Maximum number of seconds the command is allowed to run. When exceeded, the
+        command's process group is sent SIGTERM, followed by SIGKILL if it has not
+        exited 10 seconds later. Defaults to no timeout.
+        """
+        return pulumi.get(self, "timeout")
+
 
 @pulumi.output_type
 class FileStatFileMode(dict):
@@ -464,13 +741,282 @@ class FileStatState(dict):
         return pulumi.get(self, "user_name")
 
 
+@pulumi.output_type
+class ProxyConnection(dict):
+    """
+    Instructions for how to connect to a proxy (or bastion) host.
+    """
+
+    @staticmethod
+    def __key_warning(key: str):
+        suggest = None
+        if key == "hostCAKeys":
+            suggest = "host_ca_keys"
+        elif key == "hostKey":
+            suggest = "host_key"
+        elif key == "hostKeyChecking":
+            suggest = "host_key_checking"
+        elif key == "knownHosts":
+            suggest = "known_hosts"
+        elif key == "knownHostsFile":
+            suggest = "known_hosts_file"
+        elif key == "perDialTimeout":
+            suggest = "per_dial_timeout"
+        elif key == "privateKey":
+            suggest = "private_key"
+        elif key == "privateKeyPassword":
+            suggest = "private_key_password"
+        elif key == "sshAgent":
+            suggest = "ssh_agent"
+        elif key == "sshAgentSocketPath":
+            suggest = "ssh_agent_socket_path"
+
+        if suggest:
+            pulumi.log.warn(
+                f"Key '{key}' not found in ProxyConnection. Access the value via the '{suggest}' property getter instead."
+            )
+
+    def __getitem__(self, key: str) -> Any:
+        ProxyConnection.__key_warning(key)
+        return super().__getitem__(key)
+
+    def get(self, key: str, default=None) -> Any:
+        ProxyConnection.__key_warning(key)
+        return super().get(key, default)
+
+    def __init__(
+        __self__,
+        *,
+        certificate: Optional[_builtins.str] = None,
+        host: Optional[_builtins.str] = None,
+        host_ca_keys: Optional[Sequence[_builtins.str]] = None,
+        host_key: Optional[_builtins.str] = None,
+        host_key_checking: Optional[_builtins.str] = None,
+        known_hosts: Optional[_builtins.str] = None,
+        known_hosts_file: Optional[_builtins.str] = None,
+        password: Optional[_builtins.str] = None,
+        per_dial_timeout: Optional[_builtins.int] = None,
+        port: Optional[_builtins.float] = None,
+        private_key: Optional[_builtins.str] = None,
+        private_key_password: Optional[_builtins.str] = None,
+        ssh_agent: Optional[_builtins.bool] = None,
+        ssh_agent_socket_path: Optional[_builtins.str] = None,
+        user: Optional[_builtins.str] = None,
+    ):
+        """
+        Instructions for how to connect to a proxy (or bastion) host.
+
+        :param _builtins.str certificate: The contents of an OpenSSH certificate (usually a
+               "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+               key. It is for privateKey if that is set, and otherwise for the key of the same
+               name in the SSH agent. Certificates held by the SSH agent itself are used
+               without setting this.
+        :param _builtins.str host: The address of the proxy to connect to.
+        :param Sequence[_builtins.str] host_ca_keys: The public keys, in authorized_keys format, of the
+               CAs that sign host certificates. A host presenting a certificate signed by one
+               of them is trusted if the certificate is valid and names the host as a
+               principal.
+        :param _builtins.str host_key: The public key the host is expected to have, in
+               authorized_keys format.
+        :param _builtins.str host_key_checking: How the host's key is checked. "strict" only
+               connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+               hostCAKeys.
+               "tofu" (trust on first use) does the same for hosts that are listed there, but
+               connects to any other host. The key it presents is the hostKey output of Exec
+               and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+               to any host. Defaults to "strict" if any of hostKey, knownHosts,
+               knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        :param _builtins.str known_hosts: The contents of an OpenSSH known_hosts file to
+               check the host's key against. Hashed hostnames, "@cert-authority", and
+               "@revoked" lines are supported.
+        :param _builtins.str known_hosts_file: The path of an OpenSSH known_hosts file on the
+               machine running Pulumi to check the host's key against, e.g.
+               "~/.ssh/known_hosts".
+        :param _builtins.str password: The password we should use for the connection to the proxy.
+        :param _builtins.float port: The port to connect to. Defaults to 22.
+        :param _builtins.str private_key: The contents of an SSH key to use for the
+               connection to the proxy. This takes preference over the password if provided.
+        :param _builtins.str user: The user that we should use for the connection to the
+               proxy. Defaults to the current local user.
+        """
+        if certificate is not None:
+            pulumi.set(__self__, "certificate", certificate)
+        if host is not None:
+            pulumi.set(__self__, "host", host)
+        if host_ca_keys is not None:
+            pulumi.set(__self__, "host_ca_keys", host_ca_keys)
+        if host_key is not None:
+            pulumi.set(__self__, "host_key", host_key)
+        if host_key_checking is not None:
+            pulumi.set(__self__, "host_key_checking", host_key_checking)
+        if known_hosts is not None:
+            pulumi.set(__self__, "known_hosts", known_hosts)
+        if known_hosts_file is not None:
+            pulumi.set(__self__, "known_hosts_file", known_hosts_file)
+        if password is not None:
+            pulumi.set(__self__, "password", password)
+        if per_dial_timeout is not None:
+            pulumi.set(__self__, "per_dial_timeout", per_dial_timeout)
+        if port is None:
+            port = 22
+        if port is not None:
+            pulumi.set(__self__, "port", port)
+        if private_key is not None:
+            pulumi.set(__self__, "private_key", private_key)
+        if private_key_password is not None:
+            pulumi.set(__self__, "private_key_password", private_key_password)
+        if ssh_agent is not None:
+            pulumi.set(__self__, "ssh_agent", ssh_agent)
+        if ssh_agent_socket_path is not None:
+            pulumi.set(__self__, "ssh_agent_socket_path", ssh_agent_socket_path)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def certificate(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH certificate (usually a
+        "-cert.pub" file) signed by a user CA, to authenticate with instead of the bare
+        key. It is for privateKey if that is set, and otherwise for the key of the same
+        name in the SSH agent. Certificates held by the SSH agent itself are used
+        without setting this.
+        """
+        return pulumi.get(self, "certificate")
+
+    @_builtins.property
+    @pulumi.getter
+    def host(self) -> Optional[_builtins.str]:
+        """
+        The address of the proxy to connect to.
+        """
+        return pulumi.get(self, "host")
+
+    @_builtins.property
+    @pulumi.getter(name="hostCAKeys")
+    def host_ca_keys(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The public keys, in authorized_keys format, of the
+        CAs that sign host certificates. A host presenting a certificate signed by one
+        of them is trusted if the certificate is valid and names the host as a
+        principal.
+        """
+        return pulumi.get(self, "host_ca_keys")
+
+    @_builtins.property
+    @pulumi.getter(name="hostKey")
+    def host_key(self) -> Optional[_builtins.str]:
+        """
+        The public key the host is expected to have, in
+        authorized_keys format.
+        """
+        return pulumi.get(self, "host_key")
+
+    @_builtins.property
+    @pulumi.getter(name="hostKeyChecking")
+    def host_key_checking(self) -> Optional[_builtins.str]:
+        """
+        How the host's key is checked. "strict" only
+        connects to hosts whose key is given by hostKey, knownHosts, knownHostsFile, or
+        hostCAKeys.
+        "tofu" (trust on first use) does the same for hosts that are listed there, but
+        connects to any other host. The key it presents is the hostKey output of Exec
+        and agentPing, so that it can be pinned with hostKey afterwards. "off" connects
+        to any host. Defaults to "strict" if any of hostKey, knownHosts,
+        knownHostsFile, or hostCAKeys are set and "off" otherwise.
+        """
+        return pulumi.get(self, "host_key_checking")
+
+    @_builtins.property
+    @pulumi.getter(name="knownHosts")
+    def known_hosts(self) -> Optional[_builtins.str]:
+        """
+        The contents of an OpenSSH known_hosts file to
+        check the host's key against. Hashed hostnames, "@cert-authority", and
+        "@revoked" lines are supported.
+        """
+        return pulumi.get(self, "known_hosts")
+
+    @_builtins.property
+    @pulumi.getter(name="knownHostsFile")
+    def known_hosts_file(self) -> Optional[_builtins.str]:
+        """
+        The path of an OpenSSH known_hosts file on the
+        machine running Pulumi to check the host's key against, e.g.
+        "~/.ssh/known_hosts".
+        """
+        return pulumi.get(self, "known_hosts_file")
+
+    @_builtins.property
+    @pulumi.getter
+    def password(self) -> Optional[_builtins.str]:
+        """
+        The password we should use for the connection to the proxy.
+        """
+        return pulumi.get(self, "password")
+
+    @_builtins.property
+    @pulumi.getter(name="perDialTimeout")
+    def per_dial_timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "per_dial_timeout")
+
+    @_builtins.property
+    @pulumi.getter
+    def port(self) -> Optional[_builtins.float]:
+        """
+        The port to connect to. Defaults to 22.
+        """
+        return pulumi.get(self, "port")
+
+    @_builtins.property
+    @pulumi.getter(name="privateKey")
+    def private_key(self) -> Optional[_builtins.str]:
+        """
+        The contents of an SSH key to use for the
+        connection to the proxy. This takes preference over the password if provided.
+        """
+        return pulumi.get(self, "private_key")
+
+    @_builtins.property
+    @pulumi.getter(name="privateKeyPassword")
+    def private_key_password(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "private_key_password")
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgent")
+    def ssh_agent(self) -> Optional[_builtins.bool]:
+        return pulumi.get(self, "ssh_agent")
+
+    @_builtins.property
+    @pulumi.getter(name="sshAgentSocketPath")
+    def ssh_agent_socket_path(self) -> Optional[_builtins.str]:
+        return pulumi.get(self, "ssh_agent_socket_path")
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The user that we should use for the connection to the
+        proxy. Defaults to the current local user.
+        """
+        return pulumi.get(self, "user")
+
+
 @pulumi.output_type
 class ResourceConfig(dict):
     @staticmethod
     def __key_warning(key: str):
         suggest = None
-        if key == "deleteUnreachable":
+        if key == "agentGCDays":
+            suggest = "agent_gc_days"
+        elif key == "deleteUnreachable":
             suggest = "delete_unreachable"
+        elif key == "heartbeatInterval":
+            suggest = "heartbeat_interval"
+        elif key == "heartbeatTimeout":
+            suggest = "heartbeat_timeout"
+        elif key == "pythonInterpreter":
+            suggest = "python_interpreter"
 
         if suggest:
             pulumi.log.warn(
@@ -488,16 +1034,40 @@ class ResourceConfig(dict):
     def __init__(
         __self__,
         *,
+        agent_gc_days: Optional[_builtins.int] = None,
         check: Optional[_builtins.bool] = None,
         delete_unreachable: Optional[_builtins.bool] = None,
+        heartbeat_interval: Optional[_builtins.int] = None,
+        heartbeat_timeout: Optional[_builtins.int] = None,
         parallel: Optional[_builtins.int] = None,
+        python_interpreter: Optional[_builtins.str] = None,
     ):
+        """
+        :param _builtins.str python_interpreter: The Python interpreter used to run Ansible
+               modules on the remote host, either a path or a command name to look up in PATH.
+               It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+               /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+               installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        if agent_gc_days is not None:
+            pulumi.set(__self__, "agent_gc_days", agent_gc_days)
         if check is not None:
             pulumi.set(__self__, "check", check)
         if delete_unreachable is not None:
             pulumi.set(__self__, "delete_unreachable", delete_unreachable)
+        if heartbeat_interval is not None:
+            pulumi.set(__self__, "heartbeat_interval", heartbeat_interval)
+        if heartbeat_timeout is not None:
+            pulumi.set(__self__, "heartbeat_timeout", heartbeat_timeout)
         if parallel is not None:
             pulumi.set(__self__, "parallel", parallel)
+        if python_interpreter is not None:
+            pulumi.set(__self__, "python_interpreter", python_interpreter)
+
+    @_builtins.property
+    @pulumi.getter(name="agentGCDays")
+    def agent_gc_days(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "agent_gc_days")
 
     @_builtins.property
     @pulumi.getter
@@ -509,11 +1079,132 @@ class ResourceConfig(dict):
     def delete_unreachable(self) -> Optional[_builtins.bool]:
         return pulumi.get(self, "delete_unreachable")
 
+    @_builtins.property
+    @pulumi.getter(name="heartbeatInterval")
+    def heartbeat_interval(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "heartbeat_interval")
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatTimeout")
+    def heartbeat_timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "heartbeat_timeout")
+
     @_builtins.property
     @pulumi.getter
     def parallel(self) -> Optional[_builtins.int]:
         return pulumi.get(self, "parallel")
 
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> Optional[_builtins.str]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return pulumi.get(self, "python_interpreter")
+
+
+@pulumi.output_type
+class RunAs(dict):
+    """
+    The user and groups to run as instead of the user the agent
+    runs as. Switching users requires the agent to run as root.
+    """
+
+    @staticmethod
+    def __key_warning(key: str):
+        suggest = None
+        if key == "loginEnv":
+            suggest = "login_env"
+
+        if suggest:
+            pulumi.log.warn(
+                f"Key '{key}' not found in RunAs. Access the value via the '{suggest}' property getter instead."
+            )
+
+    def __getitem__(self, key: str) -> Any:
+        RunAs.__key_warning(key)
+        return super().__getitem__(key)
+
+    def get(self, key: str, default=None) -> Any:
+        RunAs.__key_warning(key)
+        return super().get(key, default)
+
+    def __init__(
+        __self__,
+        *,
+        group: Optional[_builtins.str] = None,
+        groups: Optional[Sequence[_builtins.str]] = None,
+        login_env: Optional[_builtins.bool] = None,
+        user: Optional[_builtins.str] = None,
+    ):
+        """
+        The user and groups to run as instead of the user the agent
+        runs as. Switching users requires the agent to run as root.
+
+        :param _builtins.str group: The name or numeric ID of the primary group. Defaults to
+               the primary group of the user.
+        :param Sequence[_builtins.str] groups: The names or numeric IDs of the supplementary groups.
+               Defaults to the groups the user is a member of.
+        :param _builtins.bool login_env: Start with the environment a login of the user would
+               get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+               if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+               DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+               unless another directory is given. Defaults to false.
+        :param _builtins.str user: The name or numeric ID of the user to run as. Defaults to
+               the agent's user.
+        """
+        if group is not None:
+            pulumi.set(__self__, "group", group)
+        if groups is not None:
+            pulumi.set(__self__, "groups", groups)
+        if login_env is not None:
+            pulumi.set(__self__, "login_env", login_env)
+        if user is not None:
+            pulumi.set(__self__, "user", user)
+
+    @_builtins.property
+    @pulumi.getter
+    def group(self) -> Optional[_builtins.str]:
+        """
+        The name or numeric ID of the primary group. Defaults to
+        the primary group of the user.
+        """
+        return pulumi.get(self, "group")
+
+    @_builtins.property
+    @pulumi.getter
+    def groups(self) -> Optional[Sequence[_builtins.str]]:
+        """
+        The names or numeric IDs of the supplementary groups.
+        Defaults to the groups the user is a member of.
+        """
+        return pulumi.get(self, "groups")
+
+    @_builtins.property
+    @pulumi.getter(name="loginEnv")
+    def login_env(self) -> Optional[_builtins.bool]:
+        """
+        Start with the environment a login of the user would
+        get instead of the agent's: HOME, USER, LOGNAME, SHELL, PATH, the locale, and,
+        if the user has a running systemd user instance, XDG_RUNTIME_DIR and
+        DBUS_SESSION_BUS_ADDRESS. Commands also start in the user's home directory
+        unless another directory is given. Defaults to false.
+        """
+        return pulumi.get(self, "login_env")
+
+    @_builtins.property
+    @pulumi.getter
+    def user(self) -> Optional[_builtins.str]:
+        """
+        The name or numeric ID of the user to run as. Defaults to
+        the agent's user.
+        """
+        return pulumi.get(self, "user")
+
 
 @pulumi.output_type
 class TriggersOutput(dict):
@@ -24,22 +24,49 @@ class ProviderArgs:
     def __init__(
         __self__,
         *,
+        agent_gc_days: pulumi.Input[Optional[_builtins.int]] = None,
         check: pulumi.Input[Optional[_builtins.bool]] = None,
         connection: pulumi.Input[Optional["ConnectionArgs"]] = None,
         delete_unreachable: pulumi.Input[Optional[_builtins.bool]] = None,
+        heartbeat_interval: pulumi.Input[Optional[_builtins.int]] = None,
+        heartbeat_timeout: pulumi.Input[Optional[_builtins.int]] = None,
         parallel: pulumi.Input[Optional[_builtins.int]] = None,
+        python_interpreter: pulumi.Input[Optional[_builtins.str]] = None,
     ):
         """
         The set of arguments for constructing a Provider resource.
+
+        :param pulumi.Input[_builtins.str] python_interpreter: The Python interpreter used to run Ansible
+               modules on the remote host, either a path or a command name to look up in PATH.
+               It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+               /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+               installed. If not set, the agent picks the first suitable interpreter it finds.
         """
+        if agent_gc_days is not None:
+            pulumi.set(__self__, "agent_gc_days", agent_gc_days)
         if check is not None:
             pulumi.set(__self__, "check", check)
         if connection is not None:
             pulumi.set(__self__, "connection", connection)
         if delete_unreachable is not None:
             pulumi.set(__self__, "delete_unreachable", delete_unreachable)
+        if heartbeat_interval is not None:
+            pulumi.set(__self__, "heartbeat_interval", heartbeat_interval)
+        if heartbeat_timeout is not None:
+            pulumi.set(__self__, "heartbeat_timeout", heartbeat_timeout)
         if parallel is not None:
             pulumi.set(__self__, "parallel", parallel)
+        if python_interpreter is not None:
+            pulumi.set(__self__, "python_interpreter", python_interpreter)
+
+    @_builtins.property
+    @pulumi.getter(name="agentGCDays")
+    def agent_gc_days(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "agent_gc_days")
+
+    @agent_gc_days.setter
+    def agent_gc_days(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "agent_gc_days", value)
 
     @_builtins.property
     @pulumi.getter
@@ -68,6 +95,24 @@ class ProviderArgs:
     def delete_unreachable(self, value: pulumi.Input[Optional[_builtins.bool]]):
         pulumi.set(self, "delete_unreachable", value)
 
+    @_builtins.property
+    @pulumi.getter(name="heartbeatInterval")
+    def heartbeat_interval(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "heartbeat_interval")
+
+    @heartbeat_interval.setter
+    def heartbeat_interval(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "heartbeat_interval", value)
+
+    @_builtins.property
+    @pulumi.getter(name="heartbeatTimeout")
+    def heartbeat_timeout(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "heartbeat_timeout")
+
+    @heartbeat_timeout.setter
+    def heartbeat_timeout(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "heartbeat_timeout", value)
+
     @_builtins.property
     @pulumi.getter
     def parallel(self) -> pulumi.Input[Optional[_builtins.int]]:
@@ -77,6 +122,22 @@ class ProviderArgs:
     def parallel(self, value: pulumi.Input[Optional[_builtins.int]]):
         pulumi.set(self, "parallel", value)
 
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> pulumi.Input[Optional[_builtins.str]]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return pulumi.get(self, "python_interpreter")
+
+    @python_interpreter.setter
+    def python_interpreter(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "python_interpreter", value)
+
 
 @pulumi.type_token("pulumi:providers:mid")
 class Provider(pulumi.ProviderResource):
@@ -85,12 +146,16 @@ class Provider(pulumi.ProviderResource):
         __self__,
         resource_name: str,
         opts: Optional[pulumi.ResourceOptions] = None,
+        agent_gc_days: pulumi.Input[Optional[_builtins.int]] = None,
         check: pulumi.Input[Optional[_builtins.bool]] = None,
         connection: pulumi.Input[
             Optional[Union["ConnectionArgs", "ConnectionArgsDict"]]
         ] = None,
         delete_unreachable: pulumi.Input[Optional[_builtins.bool]] = None,
+        heartbeat_interval: pulumi.Input[Optional[_builtins.int]] = None,
+        heartbeat_timeout: pulumi.Input[Optional[_builtins.int]] = None,
         parallel: pulumi.Input[Optional[_builtins.int]] = None,
+        python_interpreter: pulumi.Input[Optional[_builtins.str]] = None,
         __props__=None,
     ):
         """
@@ -98,6 +163,11 @@ class Provider(pulumi.ProviderResource):
 
         :param str resource_name: The name of the resource.
         :param pulumi.ResourceOptions opts: Options for the resource.
+        :param pulumi.Input[_builtins.str] python_interpreter: The Python interpreter used to run Ansible
+               modules on the remote host, either a path or a command name to look up in PATH.
+               It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+               /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+               installed. If not set, the agent picks the first suitable interpreter it finds.
         """
         ...
 
@@ -130,12 +200,16 @@ class Provider(pulumi.ProviderResource):
         __self__,
         resource_name: str,
         opts: Optional[pulumi.ResourceOptions] = None,
+        agent_gc_days: pulumi.Input[Optional[_builtins.int]] = None,
         check: pulumi.Input[Optional[_builtins.bool]] = None,
         connection: pulumi.Input[
             Optional[Union["ConnectionArgs", "ConnectionArgsDict"]]
         ] = None,
         delete_unreachable: pulumi.Input[Optional[_builtins.bool]] = None,
+        heartbeat_interval: pulumi.Input[Optional[_builtins.int]] = None,
+        heartbeat_timeout: pulumi.Input[Optional[_builtins.int]] = None,
         parallel: pulumi.Input[Optional[_builtins.int]] = None,
+        python_interpreter: pulumi.Input[Optional[_builtins.str]] = None,
         __props__=None,
     ):
         opts = pulumi.ResourceOptions.merge(
@@ -152,6 +226,11 @@ class Provider(pulumi.ProviderResource):
                 )
             __props__ = ProviderArgs.__new__(ProviderArgs)
 
+            __props__.__dict__["agent_gc_days"] = (
+                pulumi.Output.from_input(agent_gc_days).apply(pulumi.runtime.to_json)
+                if agent_gc_days is not None
+                else None
+            )
             __props__.__dict__["check"] = (
                 pulumi.Output.from_input(check).apply(pulumi.runtime.to_json)
                 if check is not None
@@ -169,9 +248,36 @@ class Provider(pulumi.ProviderResource):
                 if delete_unreachable is not None
                 else None
             )
+            __props__.__dict__["heartbeat_interval"] = (
+                pulumi.Output.from_input(heartbeat_interval).apply(
+                    pulumi.runtime.to_json
+                )
+                if heartbeat_interval is not None
+                else None
+            )
+            __props__.__dict__["heartbeat_timeout"] = (
+                pulumi.Output.from_input(heartbeat_timeout).apply(
+                    pulumi.runtime.to_json
+                )
+                if heartbeat_timeout is not None
+                else None
+            )
             __props__.__dict__["parallel"] = (
                 pulumi.Output.from_input(parallel).apply(pulumi.runtime.to_json)
                 if parallel is not None
                 else None
             )
+            __props__.__dict__["python_interpreter"] = python_interpreter
         super(Provider, __self__).__init__("mid", resource_name, __props__, opts)
+
+    @_builtins.property
+    @pulumi.getter(name="pythonInterpreter")
+    def python_interpreter(self) -> pulumi.Output[Optional[_builtins.str]]:
+        """
+        The Python interpreter used to run Ansible
+        modules on the remote host, either a path or a command name to look up in PATH.
+        It has to be Python 3.8 or newer, so on RHEL 8 and its derivatives, whose
+        /usr/libexec/platform-python is 3.6, a newer python3.x package has to be
+        installed. If not set, the agent picks the first suitable interpreter it finds.
+        """
+        return pulumi.get(self, "python_interpreter")
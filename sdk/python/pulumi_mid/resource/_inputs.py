@@ -14,6 +14,7 @@ if sys.version_info >= (3, 11):
 else:
     from typing_extensions import NotRequired, TypedDict, TypeAlias
 from .. import _utilities
+from .. import _inputs as _root_inputs
 
 __all__ = [
     "AnsibleTaskListArgsTaskArgs",
@@ -31,6 +32,8 @@ class AnsibleTaskListArgsTaskArgsDict(TypedDict):
         pulumi.Input[Optional[Mapping[str, pulumi.Input[_builtins.str]]]]
     ]
     ignore_errors: NotRequired[pulumi.Input[Optional[_builtins.bool]]]
+    run_as: NotRequired[pulumi.Input[Optional["_root_inputs.RunAsArgsDict"]]]
+    timeout: NotRequired[pulumi.Input[Optional[_builtins.int]]]
 
 
 @pulumi.input_type
@@ -45,6 +48,8 @@ class AnsibleTaskListArgsTaskArgs:
             Optional[Mapping[str, pulumi.Input[_builtins.str]]]
         ] = None,
         ignore_errors: pulumi.Input[Optional[_builtins.bool]] = None,
+        run_as: pulumi.Input[Optional["_root_inputs.RunAsArgs"]] = None,
+        timeout: pulumi.Input[Optional[_builtins.int]] = None,
     ):
         pulumi.set(__self__, "args", args)
         pulumi.set(__self__, "module", module)
@@ -54,6 +59,10 @@ class AnsibleTaskListArgsTaskArgs:
             pulumi.set(__self__, "environment", environment)
         if ignore_errors is not None:
             pulumi.set(__self__, "ignore_errors", ignore_errors)
+        if run_as is not None:
+            pulumi.set(__self__, "run_as", run_as)
+        if timeout is not None:
+            pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -104,6 +113,24 @@ class AnsibleTaskListArgsTaskArgs:
     def ignore_errors(self, value: pulumi.Input[Optional[_builtins.bool]]):
         pulumi.set(self, "ignore_errors", value)
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> pulumi.Input[Optional["_root_inputs.RunAsArgs"]]:
+        return pulumi.get(self, "run_as")
+
+    @run_as.setter
+    def run_as(self, value: pulumi.Input[Optional["_root_inputs.RunAsArgs"]]):
+        pulumi.set(self, "run_as", value)
+
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> pulumi.Input[Optional[_builtins.int]]:
+        return pulumi.get(self, "timeout")
+
+    @timeout.setter
+    def timeout(self, value: pulumi.Input[Optional[_builtins.int]]):
+        pulumi.set(self, "timeout", value)
+
 
 class AnsibleTaskListArgsTasksArgsDict(TypedDict):
     create: pulumi.Input[Sequence[pulumi.Input["AnsibleTaskListArgsTaskArgsDict"]]]
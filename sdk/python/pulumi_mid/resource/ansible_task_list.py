@@ -30,6 +30,7 @@ class AnsibleTaskListArgs:
         tasks: pulumi.Input["AnsibleTaskListArgsTasksArgs"],
         config: pulumi.Input[Optional["_root_inputs.ResourceConfigArgs"]] = None,
         connection: pulumi.Input[Optional["_root_inputs.ConnectionArgs"]] = None,
+        logging: pulumi.Input[Optional[_builtins.str]] = None,
         triggers: pulumi.Input[Optional["_root_inputs.TriggersInputArgs"]] = None,
     ):
         """
@@ -40,6 +41,8 @@ class AnsibleTaskListArgs:
             pulumi.set(__self__, "config", config)
         if connection is not None:
             pulumi.set(__self__, "connection", connection)
+        if logging is not None:
+            pulumi.set(__self__, "logging", logging)
         if triggers is not None:
             pulumi.set(__self__, "triggers", triggers)
 
@@ -70,6 +73,15 @@ class AnsibleTaskListArgs:
     def connection(self, value: pulumi.Input[Optional["_root_inputs.ConnectionArgs"]]):
         pulumi.set(self, "connection", value)
 
+    @_builtins.property
+    @pulumi.getter
+    def logging(self) -> pulumi.Input[Optional[_builtins.str]]:
+        return pulumi.get(self, "logging")
+
+    @logging.setter
+    def logging(self, value: pulumi.Input[Optional[_builtins.str]]):
+        pulumi.set(self, "logging", value)
+
     @_builtins.property
     @pulumi.getter
     def triggers(self) -> pulumi.Input[Optional["_root_inputs.TriggersInputArgs"]]:
@@ -100,6 +112,7 @@ class AnsibleTaskList(pulumi.CustomResource):
                 Union["_root_inputs.ConnectionArgs", "_root_inputs.ConnectionArgsDict"]
             ]
         ] = None,
+        logging: pulumi.Input[Optional[_builtins.str]] = None,
         tasks: pulumi.Input[
             Optional[
                 Union[
@@ -167,6 +180,7 @@ class AnsibleTaskList(pulumi.CustomResource):
                 Union["_root_inputs.ConnectionArgs", "_root_inputs.ConnectionArgsDict"]
             ]
         ] = None,
+        logging: pulumi.Input[Optional[_builtins.str]] = None,
         tasks: pulumi.Input[
             Optional[
                 Union[
@@ -200,6 +214,7 @@ class AnsibleTaskList(pulumi.CustomResource):
 
             __props__.__dict__["config"] = config
             __props__.__dict__["connection"] = connection
+            __props__.__dict__["logging"] = logging
             if tasks is None and not opts.urn:
                 raise TypeError("Missing required property 'tasks'")
             __props__.__dict__["tasks"] = tasks
@@ -229,6 +244,7 @@ class AnsibleTaskList(pulumi.CustomResource):
 
         __props__.__dict__["config"] = None
         __props__.__dict__["connection"] = None
+        __props__.__dict__["logging"] = None
         __props__.__dict__["results"] = None
         __props__.__dict__["tasks"] = None
         __props__.__dict__["triggers"] = None
@@ -244,6 +260,11 @@ class AnsibleTaskList(pulumi.CustomResource):
     def connection(self) -> pulumi.Output[Optional["_root_outputs.Connection"]]:
         return pulumi.get(self, "connection")
 
+    @_builtins.property
+    @pulumi.getter
+    def logging(self) -> pulumi.Output[Optional[_builtins.str]]:
+        return pulumi.get(self, "logging")
+
     @_builtins.property
     @pulumi.getter
     def results(self) -> pulumi.Output["outputs.AnsibleTaskListStateResults"]:
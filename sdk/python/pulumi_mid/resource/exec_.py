@@ -36,6 +36,7 @@ class ExecArgs:
         ] = None,
         expand_argument_vars: pulumi.Input[Optional[_builtins.bool]] = None,
         logging: pulumi.Input[Optional[_builtins.str]] = None,
+        run_as: pulumi.Input[Optional["_root_inputs.RunAsArgs"]] = None,
         triggers: pulumi.Input[Optional["_root_inputs.TriggersInputArgs"]] = None,
         update: pulumi.Input[Optional["_root_inputs.ExecCommandArgs"]] = None,
     ):
@@ -59,6 +60,8 @@ class ExecArgs:
             pulumi.set(__self__, "expand_argument_vars", expand_argument_vars)
         if logging is not None:
             pulumi.set(__self__, "logging", logging)
+        if run_as is not None:
+            pulumi.set(__self__, "run_as", run_as)
         if triggers is not None:
             pulumi.set(__self__, "triggers", triggers)
         if update is not None:
@@ -149,6 +152,15 @@ class ExecArgs:
     def logging(self, value: pulumi.Input[Optional[_builtins.str]]):
         pulumi.set(self, "logging", value)
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> pulumi.Input[Optional["_root_inputs.RunAsArgs"]]:
+        return pulumi.get(self, "run_as")
+
+    @run_as.setter
+    def run_as(self, value: pulumi.Input[Optional["_root_inputs.RunAsArgs"]]):
+        pulumi.set(self, "run_as", value)
+
     @_builtins.property
     @pulumi.getter
     def triggers(self) -> pulumi.Input[Optional["_root_inputs.TriggersInputArgs"]]:
@@ -209,6 +221,9 @@ class Exec(pulumi.CustomResource):
         ] = None,
         expand_argument_vars: pulumi.Input[Optional[_builtins.bool]] = None,
         logging: pulumi.Input[Optional[_builtins.str]] = None,
+        run_as: pulumi.Input[
+            Optional[Union["_root_inputs.RunAsArgs", "_root_inputs.RunAsArgsDict"]]
+        ] = None,
         triggers: pulumi.Input[
             Optional[
                 Union[
@@ -297,6 +312,9 @@ class Exec(pulumi.CustomResource):
         ] = None,
         expand_argument_vars: pulumi.Input[Optional[_builtins.bool]] = None,
         logging: pulumi.Input[Optional[_builtins.str]] = None,
+        run_as: pulumi.Input[
+            Optional[Union["_root_inputs.RunAsArgs", "_root_inputs.RunAsArgsDict"]]
+        ] = None,
         triggers: pulumi.Input[
             Optional[
                 Union[
@@ -339,8 +357,10 @@ class Exec(pulumi.CustomResource):
             __props__.__dict__["environment"] = environment
             __props__.__dict__["expand_argument_vars"] = expand_argument_vars
             __props__.__dict__["logging"] = logging
+            __props__.__dict__["run_as"] = run_as
             __props__.__dict__["triggers"] = triggers
             __props__.__dict__["update"] = update
+            __props__.__dict__["host_key"] = None
             __props__.__dict__["stderr"] = None
             __props__.__dict__["stdout"] = None
         super(Exec, __self__).__init__(
@@ -373,7 +393,9 @@ class Exec(pulumi.CustomResource):
         __props__.__dict__["dir"] = None
         __props__.__dict__["environment"] = None
         __props__.__dict__["expand_argument_vars"] = None
+        __props__.__dict__["host_key"] = None
         __props__.__dict__["logging"] = None
+        __props__.__dict__["run_as"] = None
         __props__.__dict__["stderr"] = None
         __props__.__dict__["stdout"] = None
         __props__.__dict__["triggers"] = None
@@ -420,11 +442,21 @@ class Exec(pulumi.CustomResource):
     def expand_argument_vars(self) -> pulumi.Output[Optional[_builtins.bool]]:
         return pulumi.get(self, "expand_argument_vars")
 
+    @_builtins.property
+    @pulumi.getter(name="hostKey")
+    def host_key(self) -> pulumi.Output[Optional[_builtins.str]]:
+        return pulumi.get(self, "host_key")
+
     @_builtins.property
     @pulumi.getter
     def logging(self) -> pulumi.Output[Optional[_builtins.str]]:
         return pulumi.get(self, "logging")
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> pulumi.Output[Optional["_root_outputs.RunAs"]]:
+        return pulumi.get(self, "run_as")
+
     @_builtins.property
     @pulumi.getter
     def stderr(self) -> pulumi.Output[_builtins.str]:
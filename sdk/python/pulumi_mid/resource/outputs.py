@@ -15,6 +15,7 @@ else:
     from typing_extensions import NotRequired, TypedDict, TypeAlias
 from .. import _utilities
 from . import outputs
+from .. import outputs as _root_outputs
 
 __all__ = [
     "AnsibleTaskListArgsTask",
@@ -31,6 +32,8 @@ class AnsibleTaskListArgsTask(dict):
         suggest = None
         if key == "ignoreErrors":
             suggest = "ignore_errors"
+        elif key == "runAs":
+            suggest = "run_as"
 
         if suggest:
             pulumi.log.warn(
@@ -53,6 +56,8 @@ class AnsibleTaskListArgsTask(dict):
         check: Optional[_builtins.bool] = None,
         environment: Optional[Mapping[str, _builtins.str]] = None,
         ignore_errors: Optional[_builtins.bool] = None,
+        run_as: Optional["_root_outputs.RunAs"] = None,
+        timeout: Optional[_builtins.int] = None,
     ):
         pulumi.set(__self__, "args", args)
         pulumi.set(__self__, "module", module)
@@ -62,6 +67,10 @@ class AnsibleTaskListArgsTask(dict):
             pulumi.set(__self__, "environment", environment)
         if ignore_errors is not None:
             pulumi.set(__self__, "ignore_errors", ignore_errors)
+        if run_as is not None:
+            pulumi.set(__self__, "run_as", run_as)
+        if timeout is not None:
+            pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -88,6 +97,16 @@ class AnsibleTaskListArgsTask(dict):
     def ignore_errors(self) -> Optional[_builtins.bool]:
         return pulumi.get(self, "ignore_errors")
 
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> Optional["_root_outputs.RunAs"]:
+        return pulumi.get(self, "run_as")
+
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "timeout")
+
 
 @pulumi.output_type
 class AnsibleTaskListArgsTasks(dict):
@@ -151,6 +170,8 @@ class AnsibleTaskListStateTaskResult(dict):
             suggest = "exit_code"
         elif key == "ignoreErrors":
             suggest = "ignore_errors"
+        elif key == "runAs":
+            suggest = "run_as"
 
         if suggest:
             pulumi.log.warn(
@@ -178,6 +199,8 @@ class AnsibleTaskListStateTaskResult(dict):
         check: Optional[_builtins.bool] = None,
         environment: Optional[Mapping[str, _builtins.str]] = None,
         ignore_errors: Optional[_builtins.bool] = None,
+        run_as: Optional["_root_outputs.RunAs"] = None,
+        timeout: Optional[_builtins.int] = None,
     ):
         pulumi.set(__self__, "args", args)
         pulumi.set(__self__, "exit_code", exit_code)
@@ -192,6 +215,10 @@ class AnsibleTaskListStateTaskResult(dict):
             pulumi.set(__self__, "environment", environment)
         if ignore_errors is not None:
             pulumi.set(__self__, "ignore_errors", ignore_errors)
+        if run_as is not None:
+            pulumi.set(__self__, "run_as", run_as)
+        if timeout is not None:
+            pulumi.set(__self__, "timeout", timeout)
 
     @_builtins.property
     @pulumi.getter
@@ -242,3 +269,13 @@ class AnsibleTaskListStateTaskResult(dict):
     @pulumi.getter(name="ignoreErrors")
     def ignore_errors(self) -> Optional[_builtins.bool]:
         return pulumi.get(self, "ignore_errors")
+
+    @_builtins.property
+    @pulumi.getter(name="runAs")
+    def run_as(self) -> Optional["_root_outputs.RunAs"]:
+        return pulumi.get(self, "run_as")
+
+    @_builtins.property
+    @pulumi.getter
+    def timeout(self) -> Optional[_builtins.int]:
+        return pulumi.get(self, "timeout")
@@ -8,6 +8,7 @@ import (
 	p "github.com/sapslaj/mid/pkg/providerfw"
 	"github.com/stretchr/testify/require"
 
+	"github.com/sapslaj/mid/agent/rpc"
 	"github.com/sapslaj/mid/tests/testmachine"
 )
 
@@ -28,4 +29,8 @@ func TestAgentAgentPing(t *testing.T) {
 	require.Len(t, res.Failures, 0)
 
 	require.Equal(t, property.New("pong"), res.Return.Get("pong"))
+	require.Equal(t, property.New(float64(rpc.ProtocolVersion)), res.Return.Get("protocolVersion"))
+	require.Contains(t, res.Return.Get("rpcFunctions").AsArray().AsSlice(), property.New(string(rpc.RPCExec)))
+	require.True(t, res.Return.Get("ansibleInstallCached").IsBool())
+	require.True(t, res.Return.Get("ansibleInstallSeconds").IsNumber())
 }
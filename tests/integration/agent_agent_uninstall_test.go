@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+	p "github.com/sapslaj/mid/pkg/providerfw"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/tests/testmachine"
+)
+
+func TestAgentAgentUninstall(t *testing.T) {
+	t.Parallel()
+
+	harness := NewProviderTestHarness(t, testmachine.Config{
+		Backend: testmachine.DockerBackend,
+	})
+	defer harness.Close()
+
+	args := property.NewMap(map[string]property.Value{
+		"connection": property.New(property.NewMap(map[string]property.Value{
+			"agentDir": property.New("/opt/mid-agent-test"),
+		})),
+	})
+
+	res, err := harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:agentPing"),
+		Args:  args,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+	require.Equal(t, property.New("pong"), res.Return.Get("pong"))
+
+	res, err = harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:agentUninstall"),
+		Args:  args,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+	require.Equal(t, property.New("/opt/mid-agent-test"), res.Return.Get("agentDir"))
+	require.Equal(t, property.New(true), res.Return.Get("removed"))
+
+	res, err = harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:agentUninstall"),
+		Args:  args,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+	require.Equal(t, property.New(false), res.Return.Get("removed"))
+
+	// the agent is installed again on the next call.
+	res, err = harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:agentPing"),
+		Args:  args,
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+	require.Equal(t, property.New("pong"), res.Return.Get("pong"))
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+	p "github.com/sapslaj/mid/pkg/providerfw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/tests/testmachine"
+)
+
+func TestAgentBecome_sudoPassword(t *testing.T) {
+	t.Parallel()
+
+	harness := NewProviderTestHarness(t, testmachine.Config{
+		Backend: testmachine.DockerBackend,
+	})
+	defer harness.Close()
+
+	// unlike the default user, this one has to give its password to sudo.
+	require.True(t, harness.AssertCommand(t, "sudo useradd -m -s /bin/bash midsudo"))
+	require.True(t, harness.AssertCommand(t, "echo 'midsudo:hunter3' | sudo chpasswd"))
+	require.True(t, harness.AssertCommand(t, "echo 'midsudo ALL=(ALL) ALL' | sudo tee /etc/sudoers.d/midsudo"))
+
+	exec := func(becomePassword string) (p.InvokeResponse, error) {
+		return harness.Server.Invoke(p.InvokeRequest{
+			Token: tokens.Type("mid:agent:exec"),
+			Args: property.NewMap(map[string]property.Value{
+				"command": property.New([]property.Value{
+					property.New("id"),
+					property.New("-un"),
+				}),
+				"connection": property.New(map[string]property.Value{
+					"user":     property.New("midsudo"),
+					"password": property.New("hunter3"),
+					"become": property.New(map[string]property.Value{
+						"method":   property.New("sudo"),
+						"password": property.New(becomePassword),
+					}),
+				}),
+			}),
+		})
+	}
+
+	res, err := exec("hunter3")
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+	assert.Equal(t, property.New("root\n"), res.Return.Get("stdout"))
+	assert.Equal(t, property.New(float64(0)), res.Return.Get("exitCode"))
+
+	_, err = exec("wrong")
+	assert.Error(t, err)
+}
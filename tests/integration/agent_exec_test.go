@@ -272,3 +272,29 @@ func TestAgentExec_stdin(t *testing.T) {
 
 	harness.AssertCommand(t, "grep -q 'this is stdin' /tmp/tee-stdin")
 }
+
+func TestAgentExec_timeout(t *testing.T) {
+	t.Parallel()
+
+	harness := NewProviderTestHarness(t, testmachine.Config{
+		Backend: testmachine.DockerBackend,
+	})
+	defer harness.Close()
+
+	_, err := harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:exec"),
+		Args: property.NewMap(map[string]property.Value{
+			"command": property.New([]property.Value{
+				property.New("/bin/sh"),
+				property.New("-c"),
+				property.New("touch /tmp/timeout-start && sleep 60 && touch /tmp/timeout-end"),
+			}),
+			"timeout": property.New(float64(1)),
+		}),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "timed out")
+
+	harness.AssertCommand(t, "test -f /tmp/timeout-start")
+	harness.AssertCommand(t, "test ! -f /tmp/timeout-end")
+}
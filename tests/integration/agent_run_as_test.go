@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/property"
+	p "github.com/sapslaj/mid/pkg/providerfw"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sapslaj/mid/tests/testmachine"
+)
+
+func TestAgentRunAs_exec(t *testing.T) {
+	t.Parallel()
+
+	harness := NewProviderTestHarness(t, testmachine.Config{
+		Backend: testmachine.DockerBackend,
+	})
+	defer harness.Close()
+
+	require.True(t, harness.AssertCommand(t, "sudo useradd -m -s /bin/sh midrunas"))
+
+	res, err := harness.Server.Invoke(p.InvokeRequest{
+		Token: tokens.Type("mid:agent:exec"),
+		Args: property.NewMap(map[string]property.Value{
+			"command": property.New([]property.Value{
+				property.New("/bin/sh"),
+				property.New("-c"),
+				property.New("id -un; id -gn; pwd; echo $HOME; touch owned"),
+			}),
+			"runAs": property.New(map[string]property.Value{
+				"user":     property.New("midrunas"),
+				"loginEnv": property.New(true),
+			}),
+		}),
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Failures, 0)
+
+	assert.Equal(t, property.New(float64(0)), res.Return.Get("exitCode"))
+	assert.Equal(
+		t,
+		property.New("midrunas\nmidrunas\n/home/midrunas\n/home/midrunas\n"),
+		res.Return.Get("stdout"),
+	)
+	harness.AssertCommand(t, `test "$(stat -c %U /home/midrunas/owned)" = midrunas`)
+}
+
+func TestAgentRunAs_ansibleExecute(t *testing.T) {
+	t.Parallel()
+
+	harness := NewProviderTestHarness(t, testmachine.Config{
+		Backend: testmachine.DockerBackend,
+	})
+	defer harness.Close()
+
+	require.True(t, harness.AssertCommand(t, "sudo useradd -m -s /bin/sh midrunas"))
+
+	// the module can't read the agent directory as midrunas, so it runs from
+	// the shared copy of the Ansible package.
+	for range 2 {
+		res, err := harness.Server.Invoke(p.InvokeRequest{
+			Token: tokens.Type("mid:agent:ansibleExecute"),
+			Args: property.NewMap(map[string]property.Value{
+				"name": property.New("command"),
+				"args": property.New(map[string]property.Value{
+					"argv": property.New([]property.Value{
+						property.New("id"),
+						property.New("-un"),
+					}),
+				}),
+				"runAs": property.New(map[string]property.Value{
+					"user": property.New("midrunas"),
+				}),
+			}),
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Failures, 0)
+
+		assert.Equal(t, property.New(float64(0)), res.Return.Get("exitCode"))
+		result := res.Return.Get("result").AsMap()
+		assert.Equal(t, property.New("midrunas"), result.Get("stdout"))
+	}
+
+	// there is one shared copy, not one per call or agent process.
+	harness.AssertCommand(t, `test "$(ls -d /tmp/mid-0-ansible-* | wc -l)" = 1`)
+}